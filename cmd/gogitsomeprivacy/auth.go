@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/keyring"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Store the GitHub token in the system keyring",
+	Long: `Store the GitHub token in the keyring of the operating system (the macOS
+Keychain, or the Secret Service of GNOME Keyring or KWallet on Linux) instead of
+the configuration file. Where there is no keyring, the token is stored in a file
+encrypted with a passphrase. Commands read the stored token whenever no token is
+configured.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Check a GitHub token and store it in the keyring",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthLogin,
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored GitHub token",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthLogout,
+}
+
+var authWithToken bool
+
+func init() {
+	authLoginCmd.Flags().BoolVar(&authWithToken, "with-token", false, "read the token from standard input")
+
+	authCmd.AddCommand(authLoginCmd, authLogoutCmd)
+	rootCmd.AddCommand(authCmd)
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadSettings(configFile, profileName)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	token, err := readToken()
+	if err != nil {
+		return err
+	}
+
+	// Only store tokens GitHub accepts
+	client, err := newDoctorClient(cfg, token, nil)
+	if err != nil {
+		return err
+	}
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+	info, err := client.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	store, err := storeToken(cfg, token)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Logged in to %s as %s; token stored in the %s\n", cfg.TokenAccount(), info.Login, store.Name())
+	if len(cfg.GitHub.AllTokens()) > 0 {
+		fmt.Fprintln(os.Stderr, "A token is also set in the configuration file or environment; it takes precedence over the stored one")
+	}
+	return nil
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadSettings(configFile, profileName)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	stores, err := cfg.TokenStores()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: auth.keyring: %w", err)
+	}
+
+	removed := 0
+	for _, s := range stores {
+		err := s.Delete(cfg.TokenAccount())
+		if errors.Is(err, keyring.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove the token from the %s: %w", s.Name(), err)
+		}
+		fmt.Fprintf(os.Stderr, "Removed the %s token from the %s\n", cfg.TokenAccount(), s.Name())
+		removed++
+	}
+	if removed == 0 {
+		fmt.Fprintf(os.Stderr, "No %s token is stored\n", cfg.TokenAccount())
+	}
+	return nil
+}
+
+// readToken reads a token from standard input with --with-token, or else
+// asks for it on the terminal.
+func readToken() (string, error) {
+	fd := int(os.Stdin.Fd())
+	var raw string
+	switch {
+	case authWithToken:
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		raw = line
+	case term.IsTerminal(fd):
+		fmt.Fprintln(os.Stderr, "Create a token without any scope, or a fine-grained read-only token, at https://github.com/settings/tokens")
+		fmt.Fprint(os.Stderr, "Paste token: ")
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		raw = string(b)
+	default:
+		return "", fmt.Errorf("standard input is not a terminal: pass the token with --with-token")
+	}
+
+	token := strings.TrimSpace(raw)
+	if token == "" {
+		return "", fmt.Errorf("no token given")
+	}
+	return token, nil
+}
+
+// storeToken stores the GitHub token of cfg in the first token store that
+// accepts it.
+func storeToken(cfg *config.Config, token string) (keyring.Store, error) {
+	stores, err := cfg.TokenStores()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: auth.keyring: %w", err)
+	}
+	if len(stores) == 0 {
+		return nil, fmt.Errorf("token storage is disabled by auth.keyring: off")
+	}
+	for i, s := range stores {
+		err := s.Set(cfg.TokenAccount(), token)
+		if err == nil {
+			return s, nil
+		}
+		if i == len(stores)-1 {
+			return nil, err
+		}
+		slog.Warn("Failed to store token, trying the next store", "store", s.Name(), "error", err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage baseline files of accepted findings",
+}
+
+var baselineUpdateCmd = &cobra.Command{
+	Use:   "update [results.json]",
+	Short: "Regenerate a baseline from the latest scan",
+	Long: `Regenerate a baseline file from a saved JSON scan result, or from the latest
+scan of --user in a --store database. Every finding in the scan becomes an
+accepted entry keyed by repository, commit SHA, field and matched text.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBaselineUpdate,
+}
+
+var (
+	baselineFile      string
+	baselineStorePath string
+	baselineUser      string
+	baselineMerge     bool
+)
+
+func init() {
+	baselineUpdateCmd.Flags().StringVar(&baselineFile, "baseline", "", "baseline file to write (default: baseline.json in the state directory)")
+	baselineUpdateCmd.Flags().StringVar(&baselineStorePath, "store", "", "read the latest scan from this SQLite database")
+	baselineUpdateCmd.Flags().StringVar(&baselineUser, "user", "", "username whose latest stored scan is used (with --store)")
+	baselineUpdateCmd.Flags().BoolVar(&baselineMerge, "merge", false, "keep existing entries instead of replacing them")
+
+	baselineCmd.AddCommand(baselineUpdateCmd)
+	rootCmd.AddCommand(baselineCmd)
+}
+
+func runBaselineUpdate(cmd *cobra.Command, args []string) error {
+	var result *models.ScanResult
+	var err error
+
+	switch {
+	case len(args) == 1:
+		result, err = loadResultFile(args[0])
+	case baselineStorePath != "" && baselineUser != "":
+		result, err = loadLatestStored(baselineStorePath, baselineUser)
+	default:
+		return fmt.Errorf("either a results file or --store with --user must be specified")
+	}
+	if err != nil {
+		return err
+	}
+
+	path := baselineFile
+	if path == "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(cfg.State.Dir, 0700); err != nil {
+			return fmt.Errorf("failed to create state directory: %w", err)
+		}
+		path = filepath.Join(cfg.State.Dir, baseline.BaselineFile)
+	}
+
+	if result.Suppressed > 0 && !baselineMerge {
+		fmt.Fprintf(os.Stderr, "Warning: %d findings were suppressed in this result and will not be in the new baseline (use --merge to keep existing entries)\n", result.Suppressed)
+	}
+
+	entries := baseline.FromResult(result)
+	if baselineMerge {
+		existing, err := baseline.Load(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		st := &baseline.State{Baseline: existing}
+		st.Merge(&baseline.State{Baseline: entries})
+		entries = st.Baseline
+	}
+
+	if err := baseline.Save(path, entries); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d baseline entries to %s\n", len(entries), path)
+	return nil
+}
+
+// loadResultFile reads a ScanResult previously written with --output json.
+func loadResultFile(path string) (*models.ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	var result models.ScanResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse results %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+// loadLatestStored returns the most recent stored scan of a user.
+func loadLatestStored(path, username string) (*models.ScanResult, error) {
+	rs, err := store.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	scans, err := rs.ListScans(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scans: %w", err)
+	}
+	if len(scans) == 0 {
+		return nil, fmt.Errorf("no stored scans for %s", username)
+	}
+	return rs.LoadResult(scans[0].ID)
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/i18n"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/output"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+var scanBatchCmd = &cobra.Command{
+	Use:   "scan-batch",
+	Short: "Scan every user listed in a CSV file",
+	Long: `Scan the users listed in a CSV file, one per line, and write one result file
+per user plus an aggregate summary.json to the output directory.
+
+Each line holds a username, optionally followed by a full name and emails
+separated by semicolons. With a header line the columns username, full_name,
+first_name, last_name and emails can appear in any order. Users without a name
+or email are searched for by the name and email on their public profile, in
+addition to the identities and rules in the config file. Lines starting with
+# are ignored.`,
+	Args: cobra.NoArgs,
+	RunE: runScanBatch,
+}
+
+var (
+	batchInput       string
+	batchOutputDir   string
+	batchFormat      string
+	batchConcurrency int
+)
+
+func init() {
+	scanBatchCmd.Flags().StringVarP(&batchInput, "input", "i", "", "CSV file listing the users to scan (required)")
+	scanBatchCmd.Flags().StringVarP(&batchOutputDir, "output-dir", "d", "scan-results", "directory for the per-user results and summary.json")
+	scanBatchCmd.Flags().StringVarP(&batchFormat, "output", "o", "json", "per-user output format (json, ndjson, text, csv, markdown, html, junit)")
+	scanBatchCmd.Flags().StringVar(&outputLang, "lang", "", "language of the text, markdown and html output: en, fr, de or es (overrides config)")
+	scanBatchCmd.Flags().BoolVar(&redact, "redact", false, "mask the matched PII in the results so they can be shared")
+	scanBatchCmd.Flags().IntVarP(&batchConcurrency, "concurrency", "j", 1, "number of users scanned at the same time")
+	scanBatchCmd.Flags().StringVarP(&githubToken, "token", "t", "", "GitHub API token (overrides config)")
+	scanBatchCmd.Flags().StringVar(&providerName, "provider", "", "hosting provider to scan: github or bitbucket (overrides config)")
+	scanBatchCmd.Flags().IntVarP(&maxWorkers, "workers", "w", 0, "number of concurrent workers per user (overrides config)")
+	scanBatchCmd.Flags().BoolVar(&exactMatch, "exact", false, "only search for exact full names (don't split into first/last)")
+	scanBatchCmd.Flags().BoolVar(&nicknames, "expand-nicknames", false, "also search known nicknames of first names, at a lower confidence")
+	_ = scanBatchCmd.MarkFlagRequired("input")
+
+	rootCmd.AddCommand(scanBatchCmd)
+}
+
+// batchUser is a user listed in the batch input file.
+type batchUser struct {
+	Username  string
+	FullName  string
+	FirstName string
+	LastName  string
+	Emails    []string
+}
+
+// batchUserSummary is the outcome of scanning one user.
+type batchUserSummary struct {
+	Username   string `json:"username"`
+	File       string `json:"file,omitempty"`
+	Repos      int    `json:"repos"`
+	Commits    int    `json:"commits"`
+	Matches    int    `json:"matches"`
+	High       int    `json:"high"`
+	Medium     int    `json:"medium"`
+	Low        int    `json:"low"`
+	Incomplete bool   `json:"incomplete,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// batchSummary is the aggregate report of a batch scan.
+type batchSummary struct {
+	Users        []batchUserSummary `json:"users"`
+	TotalMatches int                `json:"total_matches"`
+	UsersWithPII int                `json:"users_with_pii"`
+	Failed       int                `json:"failed"`
+}
+
+func runScanBatch(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("output") && cfg.Output.Format != "" {
+		batchFormat = cfg.Output.Format
+	}
+	if !cmd.Flags().Changed("lang") && cfg.Output.Lang != "" {
+		outputLang = cfg.Output.Lang
+	}
+	if _, err := i18n.New(outputLang); err != nil {
+		return err
+	}
+	if providerName != "" {
+		cfg.Provider = providerName
+	}
+	if err := loadToken(cfg); err != nil {
+		return err
+	}
+	if maxWorkers > 0 {
+		cfg.Scan.MaxWorkers = maxWorkers
+	}
+	if nicknames {
+		cfg.Scan.ExpandNicknames = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if batchConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	format, ok := output.Lookup(batchFormat)
+	if !ok || format.Name == "template" {
+		return fmt.Errorf("unsupported output format: %s", batchFormat)
+	}
+	ext := format.Extension
+
+	f, err := os.Open(batchInput)
+	if err != nil {
+		return fmt.Errorf("failed to open input: %w", err)
+	}
+	users, err := readBatchUsers(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", batchInput, err)
+	}
+	if len(users) == 0 {
+		return fmt.Errorf("no users in %s", batchInput)
+	}
+	if err := os.MkdirAll(batchOutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	st, err := baseline.LoadDir(cfg.State.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	// All scans share one client, and so one rate limit
+	client, err := provider.New(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	var progress scanner.ProgressReporter
+	if logsRequested(cmd) {
+		progress = scanner.LogReporter{Logger: slog.Default()}
+	}
+	scannerConfig, err := newScannerConfig(cfg, progress)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+
+	scanOne := func(ctx context.Context, u batchUser) (batchUserSummary, error) {
+		sum := batchUserSummary{Username: u.Username}
+		criteria, err := batchCriteria(ctx, cfg, client, u)
+		if err != nil {
+			return sum, err
+		}
+		result, err := scanner.NewScanner(client, criteria, scannerConfig).ScanUser(ctx, u.Username)
+		if err != nil {
+			return sum, err
+		}
+		result.Suppressed += st.Filter(result)
+		result.Summary = report.Summarize(result)
+		result.PrivacyScore = report.Score(result)
+		report.Advise(result)
+		report.TrimContext(result, cfg.ContextSize(batchFormat))
+		if redact {
+			report.Redact(result)
+		}
+
+		sum.File = filepath.Join(batchOutputDir, safeFileName(u.Username)+ext)
+		if err := outputResults(result, batchFormat, sum.File); err != nil {
+			return sum, err
+		}
+		sum.Repos = result.SearchedRepos
+		sum.Commits = result.TotalCommits
+		sum.Matches = len(result.Matches)
+		sum.Incomplete = result.Incomplete
+		for _, m := range result.Matches {
+			switch m.Severity {
+			case models.SeverityHigh:
+				sum.High++
+			case models.SeverityMedium:
+				sum.Medium++
+			default:
+				sum.Low++
+			}
+		}
+		return sum, nil
+	}
+
+	pool := worker.NewPool(batchConcurrency, scanOne, worker.Options[batchUser]{Name: "batch"})
+	pool.Start(ctx)
+	go func() {
+		defer pool.Close()
+		for _, u := range users {
+			if pool.Submit(ctx, u) != nil {
+				return
+			}
+		}
+	}()
+
+	byUser := make(map[string]batchUserSummary, len(users))
+	done := 0
+	for task := range pool.Results() {
+		sum := task.Result
+		sum.Username = task.Input.Username
+		if task.Err != nil {
+			sum.Error = task.Err.Error()
+			slog.Warn("User scan failed", "user", sum.Username, "error", task.Err)
+		}
+		done++
+		slog.Info("User scanned", "user", sum.Username, "done", done, "total", len(users), "matches", sum.Matches)
+		byUser[sum.Username] = sum
+	}
+
+	// Report users in input order, including those never scanned
+	var summary batchSummary
+	for _, u := range users {
+		sum, ok := byUser[u.Username]
+		if !ok {
+			sum = batchUserSummary{Username: u.Username, Error: "not scanned: interrupted"}
+		}
+		if sum.Error != "" {
+			summary.Failed++
+		}
+		if sum.Matches > 0 {
+			summary.UsersWithPII++
+		}
+		summary.TotalMatches += sum.Matches
+		summary.Users = append(summary.Users, sum)
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary: %w", err)
+	}
+	summaryPath := filepath.Join(batchOutputDir, "summary.json")
+	if err := os.WriteFile(summaryPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+
+	printBatchSummary(cmd.OutOrStdout(), summary)
+	fmt.Fprintf(cmd.OutOrStdout(), "\nResults written to %s\n", batchOutputDir)
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d users could not be scanned", summary.Failed, len(users))
+	}
+	return nil
+}
+
+// batchCriteria builds the search criteria of a listed user. Users listed
+// without a name or email are searched for by their public profile.
+func batchCriteria(ctx context.Context, cfg *config.Config, client provider.Provider, u batchUser) (models.PIISearchCriteria, error) {
+	opts := config.SearchOptions{
+		FullName:  u.FullName,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Emails:    u.Emails,
+		Exact:     exactMatch,
+	}
+	if u.FullName == "" && u.FirstName == "" && u.LastName == "" && len(u.Emails) == 0 {
+		profile, err := client.GetUser(ctx, u.Username)
+		if err != nil {
+			return models.PIISearchCriteria{}, err
+		}
+		derived := criteriaFromProfile(profile)
+		opts.FullName, opts.FirstName, opts.LastName, opts.Emails = derived.FullName, derived.FirstName, derived.LastName, derived.Emails
+	}
+
+	criteria, err := cfg.Criteria(opts)
+	if errors.Is(err, config.ErrNoCriteria) {
+		return criteria, fmt.Errorf("nothing to search for: no name or email listed or on the public profile")
+	}
+	return criteria, err
+}
+
+// readBatchUsers parses the batch input file.
+func readBatchUsers(r io.Reader) ([]batchUser, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Columns by position unless the first line is a header
+	columns := map[string]int{"username": 0, "full_name": 1, "emails": 2}
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "username") {
+		columns = make(map[string]int)
+		for i, name := range records[0] {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		records = records[1:]
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var users []batchUser
+	seen := make(map[string]bool)
+	for _, record := range records {
+		u := batchUser{
+			Username:  field(record, "username"),
+			FullName:  field(record, "full_name"),
+			FirstName: field(record, "first_name"),
+			LastName:  field(record, "last_name"),
+		}
+		if u.Username == "" || seen[strings.ToLower(u.Username)] {
+			continue
+		}
+		seen[strings.ToLower(u.Username)] = true
+		for _, e := range strings.FieldsFunc(field(record, "emails"), func(r rune) bool { return r == ';' || r == ' ' }) {
+			u.Emails = append(u.Emails, e)
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// safeFileName turns a username into a file name.
+func safeFileName(username string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, username)
+}
+
+// printBatchSummary writes the aggregate report as a table.
+func printBatchSummary(w io.Writer, summary batchSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "USER\tREPOS\tCOMMITS\tMATCHES\tHIGH\tMEDIUM\tLOW\tSTATUS")
+	for _, u := range summary.Users {
+		status := "ok"
+		switch {
+		case u.Error != "":
+			status = "error: " + u.Error
+		case u.Incomplete:
+			status = "incomplete"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", u.Username, u.Repos, u.Commits, u.Matches, u.High, u.Medium, u.Low, status)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%d users, %d with PII, %d matches, %d failed\n", len(summary.Users), summary.UsersWithPII, summary.TotalMatches, summary.Failed)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap [username]",
+	Short: "Set up authentication and run a quick first scan",
+	Long: `Set up a GitHub token, derive search criteria from the user's public profile,
+run a shallow scan of their most recent activity and print a guided summary
+with next steps. This is the quickest way to find out whether your real name
+is in your git history.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBootstrap,
+}
+
+var (
+	bootstrapRepos   int
+	bootstrapCommits int
+)
+
+func init() {
+	bootstrapCmd.Flags().IntVar(&bootstrapRepos, "repos", 20, "maximum number of repositories in the quick scan")
+	bootstrapCmd.Flags().IntVar(&bootstrapCommits, "commits", 50, "maximum number of recent commits per repository in the quick scan")
+
+	rootCmd.AddCommand(bootstrapCmd)
+}
+
+func runBootstrap(cmd *cobra.Command, args []string) error {
+	username := args[0]
+	out := cmd.OutOrStdout()
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Step 1: authentication
+	fmt.Fprintln(out, "Step 1/3: GitHub authentication")
+	if cfg.GitHub.Token == "" {
+		if err := promptForToken(cfg); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(out, "  Using the token from your configuration or environment.")
+	}
+
+	client, err := github.NewClient(github.ClientConfig{
+		Token:                        cfg.GitHub.Token,
+		Tokens:                       cfg.GitHub.Tokens,
+		RateLimitPerSecond:           cfg.GitHub.RateLimitPerSecond,
+		RateLimitBurst:               cfg.GitHub.RateLimitBurst,
+		SearchRateLimitPerMinute:     cfg.GitHub.SearchRateLimitPerMinute,
+		CodeSearchRateLimitPerMinute: cfg.GitHub.CodeSearchRateLimitPerMinute,
+		Timeout:                      time.Duration(cfg.GitHub.TimeoutSeconds) * time.Second,
+		PageConcurrency:              cfg.GitHub.PageConcurrency,
+		BaseURL:                      cfg.GitHub.BaseURL,
+		UploadURL:                    cfg.GitHub.UploadURL,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Step 2: derive criteria from the public profile
+	fmt.Fprintln(out, "\nStep 2/3: Deriving search criteria from the public profile")
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+	profile, err := client.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	criteria := criteriaFromProfile(profile)
+	criteria.CaseSensitive = cfg.Scan.CaseSensitive
+	criteria.ExpandNicknames = cfg.Scan.ExpandNicknames
+	if criteria.FullName == "" && len(criteria.Emails) == 0 {
+		return fmt.Errorf("%s has no public name or email on their profile; run `gogitsomeprivacy scan %s --full-name \"Your Name\"` instead", username, username)
+	}
+	if criteria.FullName != "" {
+		fmt.Fprintf(out, "  Name:  %q (also searching %q and %q)\n", criteria.FullName, criteria.FirstName, criteria.LastName)
+	}
+	for _, email := range criteria.Emails {
+		fmt.Fprintf(out, "  Email: %s\n", email)
+	}
+
+	// Step 3: shallow scan
+	fmt.Fprintf(out, "\nStep 3/3: Quick scan (up to %d repositories, %d latest commits each)\n", bootstrapRepos, bootstrapCommits)
+	var progress scanner.ProgressReporter
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		progress = tui.NewProgressBar(os.Stderr)
+	}
+	// The configured scan, cut down to the latest commits of a few repositories
+	scannerConfig, err := newScannerConfig(cfg, progress)
+	if err != nil {
+		return err
+	}
+	scannerConfig.MaxRepos = bootstrapRepos
+	scannerConfig.MaxCommitsPerRepo = bootstrapCommits
+	scannerConfig.Sample = 0
+	s := scanner.NewScanner(client, criteria, scannerConfig)
+	result, err := s.ScanUser(ctx, username)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	if result.Incomplete {
+		fmt.Fprintf(out, "Scan interrupted (%s); the summary below is partial\n", result.IncompleteReason)
+	}
+
+	st, err := baseline.LoadDir(cfg.State.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	result.Suppressed += st.Filter(result)
+
+	fmt.Fprint(out, formatBootstrapSummary(result, criteria))
+	return nil
+}
+
+// promptForToken asks for a token on an interactive terminal and optionally
+// stores it in the keyring, as auth login does.
+func promptForToken(cfg *config.Config) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		fmt.Println("  No token found; continuing unauthenticated (60 requests/hour).")
+		fmt.Println("  Set GITHUB_TOKEN or run auth login for 5,000 requests/hour.")
+		return nil
+	}
+
+	fmt.Println("  No GitHub token found. Without one you are limited to 60 requests/hour.")
+	fmt.Println("  Create a token without any scope, or a fine-grained read-only token, at https://github.com/settings/tokens")
+	fmt.Print("  Paste token (leave empty to continue unauthenticated): ")
+	raw, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return fmt.Errorf("failed to read token: %w", err)
+	}
+
+	token := strings.TrimSpace(string(raw))
+	if token == "" {
+		return nil
+	}
+	cfg.GitHub.Token = token
+
+	fmt.Print("  Store the token in the keyring for later scans? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "y") {
+		store, err := storeToken(cfg, token)
+		if err != nil {
+			return fmt.Errorf("failed to store token: %w", err)
+		}
+		fmt.Printf("  Stored in the %s\n", store.Name())
+	}
+	return nil
+}
+
+// criteriaFromProfile derives search criteria from a public profile.
+func criteriaFromProfile(profile *models.UserProfile) models.PIISearchCriteria {
+	criteria := models.PIISearchCriteria{FullName: strings.TrimSpace(profile.Name)}
+
+	parts := strings.Fields(criteria.FullName)
+	if len(parts) >= 2 {
+		criteria.FirstName = parts[0]
+		criteria.LastName = parts[len(parts)-1]
+	}
+
+	if email := strings.TrimSpace(profile.Email); email != "" && !strings.HasSuffix(email, "@users.noreply.github.com") {
+		criteria.Emails = append(criteria.Emails, email)
+	}
+	return criteria
+}
+
+// formatBootstrapSummary renders the guided summary shown after the quick scan.
+func formatBootstrapSummary(result *models.ScanResult, criteria models.PIISearchCriteria) string {
+	var b strings.Builder
+
+	b.WriteString("\nSummary\n=======\n\n")
+	fmt.Fprintf(&b, "Scanned %d commits in %d repositories in %s.\n", result.TotalCommits, result.SearchedRepos, result.ScanDuration)
+
+	if len(result.Matches) == 0 {
+		b.WriteString("No PII found in this quick scan. That is a good sign, but only recent\n")
+		b.WriteString("commits were checked.\n")
+	} else {
+		fmt.Fprintf(&b, "Found %d commits exposing your identity. Main patterns:\n\n", len(result.Matches))
+		clusters := report.Clusters(result)
+		for i, c := range clusters {
+			if i == 5 {
+				fmt.Fprintf(&b, "  ...and %d more patterns\n", len(clusters)-5)
+				break
+			}
+			fmt.Fprintf(&b, "  - %q in %s", c.Matched, c.Field)
+			if c.Via != "" {
+				fmt.Fprintf(&b, " via %s", c.Via)
+			}
+			fmt.Fprintf(&b, " (%d repos)\n    %s\n", len(c.Repositories), c.Recommendation)
+		}
+	}
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(&b, "\n%d repositories could not be scanned; see a full scan for details.\n", len(result.Errors))
+	}
+
+	args := fmt.Sprintf("%s --full-name %q", result.Username, criteria.FullName)
+	if criteria.FullName == "" {
+		args = result.Username
+	}
+	for _, email := range criteria.Emails {
+		args += " --email " + email
+	}
+
+	b.WriteString("\nNext steps\n----------\n\n")
+	b.WriteString("1. Run a deep scan of your full history:\n")
+	fmt.Fprintf(&b, "     gogitsomeprivacy scan %s --clusters -f results.json\n", args)
+	b.WriteString("2. Generate remediation instructions for every affected repository:\n")
+	b.WriteString("     gogitsomeprivacy remediate results.json --out-dir remediation/\n")
+	b.WriteString("3. Accept intentional findings so future scans only show new leaks:\n")
+	b.WriteString("     gogitsomeprivacy baseline update results.json\n")
+
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init [file]",
+	Short: "Write a commented configuration file with example profiles",
+	Long: `Write a commented configuration file with the common settings and example
+quick, deep and ci profiles, selected with --profile. The file is written to
+$HOME/.config/gogitsomeprivacy/config.yaml unless another path is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigInit,
+}
+
+var configForce bool
+
+func init() {
+	configInitCmd.Flags().BoolVar(&configForce, "force", false, "overwrite an existing file")
+
+	configCmd.AddCommand(configInitCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) error {
+	path := config.DefaultPath()
+	if len(args) == 1 {
+		path = args[0]
+	}
+	if err := config.Init(path, configForce); err != nil {
+		if errors.Is(err, config.ErrExists) {
+			return fmt.Errorf("%w (use --force to overwrite)", err)
+		}
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", path)
+	return nil
+}
+
+// loadConfig loads the configuration file given with --config, or the
+// default one, with the profile selected with --profile. The GitHub token is
+// not read from the keyring: commands calling the API do so with loadToken.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadSettings(configFile, profileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadToken sets the GitHub token given with --token, if any, in place of the
+// configured ones. Otherwise, when neither the configuration nor the
+// environment gives one, the token stored by `auth login` is read from the
+// keyring. It must be called once the provider is selected.
+func loadToken(cfg *config.Config) error {
+	if githubToken != "" {
+		cfg.GitHub.Token = githubToken
+		cfg.GitHub.Tokens = nil
+		return nil
+	}
+	if err := cfg.LoadKeyringToken(); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return nil
+}
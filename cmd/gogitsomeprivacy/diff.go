@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/output"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [username]",
+	Short: "Compare the latest stored scan of a user against a previous one",
+	Long: `Compare two scans persisted with --store and report new, resolved and
+persisting findings. By default the latest scan is compared against the one
+before it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDiff,
+}
+
+var (
+	diffStorePath string
+	diffFromID    int64
+	diffToID      int64
+	diffOutput    string
+)
+
+func init() {
+	diffCmd.Flags().StringVar(&diffStorePath, "store", "", "SQLite results database (required)")
+	diffCmd.Flags().Int64Var(&diffFromID, "from", 0, "ID of the older scan (default: the scan before --to)")
+	diffCmd.Flags().Int64Var(&diffToID, "to", 0, "ID of the newer scan (default: latest scan)")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "text", "output format (json, text)")
+	diffCmd.MarkFlagRequired("store")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	username := args[0]
+
+	st, err := store.Open(diffStorePath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	fromID, toID := diffFromID, diffToID
+	if fromID == 0 || toID == 0 {
+		scans, err := st.ListScans(username)
+		if err != nil {
+			return fmt.Errorf("failed to list scans: %w", err)
+		}
+		if toID == 0 {
+			if len(scans) == 0 {
+				return fmt.Errorf("no stored scans for %s", username)
+			}
+			toID = scans[0].ID
+		}
+		if fromID == 0 {
+			for i, scan := range scans {
+				if scan.ID == toID && i+1 < len(scans) {
+					fromID = scans[i+1].ID
+					break
+				}
+			}
+			if fromID == 0 {
+				return fmt.Errorf("no earlier scan of %s to compare against scan %d", username, toID)
+			}
+		}
+	}
+
+	d, err := st.Diff(fromID, toID)
+	if err != nil {
+		return fmt.Errorf("failed to diff scans: %w", err)
+	}
+
+	switch diffOutput {
+	case "json":
+		output, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+	case "text":
+		fmt.Print(formatDiffText(d))
+	default:
+		return fmt.Errorf("unsupported output format: %s", diffOutput)
+	}
+
+	return nil
+}
+
+func formatDiffText(d *store.Diff) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Comparing scan %d (%s) -> scan %d (%s)\n\n",
+		d.From.ID, d.From.ScannedAt.Format(time.RFC3339),
+		d.To.ID, d.To.ScannedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "New: %d  Resolved: %d  Persisting: %d\n", len(d.New), len(d.Resolved), len(d.Persisting))
+
+	sections := []struct {
+		title    string
+		findings []store.Finding
+	}{
+		{"New findings", d.New},
+		{"Resolved findings", d.Resolved},
+		{"Persisting findings", d.Persisting},
+	}
+	for _, section := range sections {
+		if len(section.findings) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s:\n", section.title)
+		for _, f := range section.findings {
+			fmt.Fprintf(&b, "  - %s@%s %s: %q\n", f.Repository, output.ShortSHA(f.SHA), f.Field, f.Matched)
+		}
+	}
+
+	return b.String()
+}
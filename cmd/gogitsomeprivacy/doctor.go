@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [username]",
+	Short: "Check the token, rate limit and settings before a scan",
+	Long: `Validate the GitHub token and print its scopes and the remaining API rate
+limit. Given a username, also estimate the requests a scan of that user takes
+(see scan --dry-run) and warn when it cannot complete before the rate limit
+resets, with suggested settings.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().StringVarP(&githubToken, "token", "t", "", "GitHub API token (overrides config)")
+
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// unauthenticatedHourlyLimit is GitHub's hourly rate limit without a token.
+const unauthenticatedHourlyLimit = 60
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := loadToken(cfg); err != nil {
+		return err
+	}
+	if cfg.Provider == "bitbucket" {
+		return fmt.Errorf("doctor only checks the github provider")
+	}
+
+	client, err := newDoctorClient(cfg, cfg.GitHub.Token, cfg.GitHub.Tokens)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+
+	// Tokens, each checked on its own
+	tokens := cfg.GitHub.AllTokens()
+	fmt.Fprintln(out, "Token:")
+	var token *github.TokenInfo
+	var core, search github.RateStatus
+	if len(tokens) == 0 {
+		printTokenChecks(out, nil)
+		if core, search, err = client.RateLimits(ctx); err != nil {
+			return err
+		}
+	}
+	for i, t := range tokens {
+		c, err := newDoctorClient(cfg, t, nil)
+		if err != nil {
+			return err
+		}
+		if len(tokens) > 1 {
+			fmt.Fprintf(out, "  %s:\n", tokenLabel(i, t))
+		}
+		info, err := c.Token(ctx)
+		if err != nil {
+			if len(tokens) == 1 {
+				return err
+			}
+			warnf(out, "%v", err)
+			continue
+		}
+		printTokenChecks(out, info)
+		token = info
+
+		tokenCore, tokenSearch, err := c.RateLimits(ctx)
+		if err != nil {
+			return err
+		}
+		core = addRates(core, tokenCore)
+		search = addRates(search, tokenSearch)
+	}
+	if len(tokens) > 0 && token == nil {
+		return fmt.Errorf("all GitHub tokens were rejected")
+	}
+
+	// Rate limit
+	fmt.Fprintln(out, "\nRate limit:")
+	fmt.Fprintf(out, "  Core:   %d of %d remaining until %s\n", core.Remaining, core.Limit, core.Reset.Format(time.Kitchen))
+	fmt.Fprintf(out, "  Search: %d of %d remaining until %s\n", search.Remaining, search.Limit, search.Reset.Format(time.Kitchen))
+
+	// Settings; the rate limit applies to each token
+	fmt.Fprintln(out, "\nSettings:")
+	rps := cfg.GitHub.RateLimitPerSecond * float64(max(1, len(tokens)))
+	if hourly := rps * 3600; core.Limit > 0 && hourly > float64(core.Limit) {
+		warnf(out, "rate_limit_per_second %g allows %.0f requests per hour, more than the %d of the rate limit; a long scan stops once they are spent",
+			rps, hourly, core.Limit)
+		suggestf(out, "set github.rate_limit_per_second to %.3f to spread the budget over the hour", float64(core.Limit)/3600)
+	} else {
+		okf(out, "rate_limit_per_second %g stays within the hourly rate limit", rps)
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	// Estimated scan
+	username := args[0]
+	fmt.Fprintf(out, "\nScan of %s:\n", username)
+	scannerConfig, err := newScannerConfig(cfg, nil)
+	if err != nil {
+		return err
+	}
+	plan, err := scanner.NewScanner(client, models.PIISearchCriteria{}, scannerConfig).Plan(ctx, username)
+	if err != nil {
+		return fmt.Errorf("failed to plan scan: %w", err)
+	}
+	// The plan spent requests of its own
+	if rate := client.RateLimit(); rate.Limit > 0 {
+		core = rate
+	}
+	printPlanChecks(out, cfg, token, rps, plan, core)
+	return nil
+}
+
+// newDoctorClient creates a GitHub client authenticating with token and
+// tokens.
+func newDoctorClient(cfg *config.Config, token string, tokens []string) (*github.Client, error) {
+	client, err := github.NewClient(github.ClientConfig{
+		Token:                        token,
+		Tokens:                       tokens,
+		RateLimitPerSecond:           cfg.GitHub.RateLimitPerSecond,
+		RateLimitBurst:               cfg.GitHub.RateLimitBurst,
+		SearchRateLimitPerMinute:     cfg.GitHub.SearchRateLimitPerMinute,
+		CodeSearchRateLimitPerMinute: cfg.GitHub.CodeSearchRateLimitPerMinute,
+		Timeout:                      time.Duration(cfg.GitHub.TimeoutSeconds) * time.Second,
+		PageConcurrency:              cfg.GitHub.PageConcurrency,
+		BaseURL:                      cfg.GitHub.BaseURL,
+		UploadURL:                    cfg.GitHub.UploadURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return client, nil
+}
+
+// tokenLabel identifies the i-th configured token without revealing it, as
+// the GitHub client does in its logs.
+func tokenLabel(i int, token string) string {
+	suffix := token
+	if len(suffix) > 4 {
+		suffix = suffix[len(suffix)-4:]
+	}
+	return fmt.Sprintf("token %d (...%s)", i+1, suffix)
+}
+
+// addRates combines the budgets of two tokens.
+func addRates(a, b github.RateStatus) github.RateStatus {
+	if a.Limit == 0 {
+		return b
+	}
+	a.Limit += b.Limit
+	a.Remaining += b.Remaining
+	if b.Reset.Before(a.Reset) {
+		a.Reset = b.Reset
+	}
+	return a
+}
+
+// printTokenChecks reports on the configured token.
+func printTokenChecks(out io.Writer, token *github.TokenInfo) {
+	if token == nil {
+		warnf(out, "no token configured: GitHub allows %d requests per hour without one", unauthenticatedHourlyLimit)
+		suggestf(out, "create a token without any scope at https://github.com/settings/tokens and set GITHUB_TOKEN")
+		return
+	}
+
+	if !token.Classic {
+		okf(out, "authenticated as %s (fine-grained token)", token.Login)
+		return
+	}
+	scopes := "none"
+	if len(token.Scopes) > 0 {
+		scopes = strings.Join(token.Scopes, ", ")
+	}
+	okf(out, "authenticated as %s (classic token, scopes: %s)", token.Login, scopes)
+
+	var broad []string
+	for _, scope := range token.Scopes {
+		if !strings.HasPrefix(scope, "read:") {
+			broad = append(broad, scope)
+		}
+	}
+	if len(broad) > 0 {
+		warnf(out, "the token grants %s; scanning public data needs no scope", strings.Join(broad, ", "))
+		suggestf(out, "use a token without scopes, so a leaked token cannot change anything")
+	}
+}
+
+// printPlanChecks reports whether the scan of plan can complete with the
+// remaining rate limit, and suggests settings when it cannot.
+func printPlanChecks(out io.Writer, cfg *config.Config, token *github.TokenInfo, rps float64, plan *scanner.Plan, core github.RateStatus) {
+	p := newPlanOutput(plan, rps, core)
+	fmt.Fprintf(out, "  Repositories: %d", len(plan.Repos))
+	if plan.UnknownRepos > 0 {
+		fmt.Fprintf(out, " (%d without statistics)", plan.UnknownRepos)
+	}
+	fmt.Fprintf(out, "\n  Estimated API requests: %d, about %s\n", plan.Requests, p.EstimatedDuration)
+
+	if plan.Requests <= core.Remaining {
+		okf(out, "fits in the %d requests remaining until %s", core.Remaining, core.Reset.Format(time.Kitchen))
+		return
+	}
+	warnf(out, "needs about %d requests but %d remain until %s; the scan cannot complete before the reset",
+		plan.Requests, core.Remaining, core.Reset.Format(time.Kitchen))
+	if token == nil {
+		suggestf(out, "configure a token: authenticated requests get 5000 per hour")
+	}
+	if !cfg.Scan.SkipForks {
+		suggestf(out, "skip forked repositories with --skip-forks")
+	}
+	if cfg.Scan.IncludeCoAuthor {
+		suggestf(out, "drop include_co_author, which lists every commit of each repository")
+	}
+	suggestf(out, "exclude large repositories you do not need with ignore.repos")
+	suggestf(out, "or run the scan after %s", core.Reset.Format(time.Kitchen))
+}
+
+func okf(out io.Writer, format string, args ...any) {
+	fmt.Fprintf(out, "  ok:      %s\n", fmt.Sprintf(format, args...))
+}
+
+func warnf(out io.Writer, format string, args ...any) {
+	fmt.Fprintf(out, "  warning: %s\n", fmt.Sprintf(format, args...))
+}
+
+func suggestf(out io.Writer, format string, args ...any) {
+	fmt.Fprintf(out, "  try:     %s\n", fmt.Sprintf(format, args...))
+}
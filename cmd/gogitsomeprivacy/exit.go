@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Exit codes. Every command exits with exitErrors when it fails; scan also
+// exits with exitFindings or exitErrors after a complete run, depending on
+// --fail-on.
+const (
+	exitClean    = 0
+	exitFindings = 1
+	exitErrors   = 2
+)
+
+// --fail-on policies, from the strictest.
+const (
+	failOnFindings = "findings" // findings exit 1, scan errors 2
+	failOnErrors   = "errors"   // scan errors exit 2, findings do not fail
+	failOnNone     = "none"     // neither fails
+)
+
+// exitCode is the code the process exits with when the command succeeds.
+var exitCode = exitClean
+
+// parseFailOn validates a --fail-on policy.
+func parseFailOn(s string) (string, error) {
+	switch s {
+	case failOnFindings, failOnErrors, failOnNone:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid --fail-on %q: use findings, errors or none", s)
+}
+
+// scanExitCode returns the exit code of a scan under the failOn policy.
+// Errors are repositories or sources that could not be scanned and
+// interruptions; they take precedence over findings, since a partial scan
+// may have missed some.
+func scanExitCode(result *models.ScanResult, failOn string) int {
+	failed := len(result.Errors) > 0 || len(result.SkippedRepos) > 0 || result.Incomplete
+	switch {
+	case failOn == failOnNone:
+		return exitClean
+	case failed:
+		return exitErrors
+	case failOn == failOnFindings && result.MatchCount() > 0:
+		return exitFindings
+	}
+	return exitClean
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/i18n"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var scanIdentityCmd = &cobra.Command{
+	Use:   "scan-identity",
+	Short: "Find traces of a deleted or renamed account by email and name",
+	Long: `Search all of GitHub for traces of an identity without scanning an account:
+commits authored with the given emails or under the given name, and public
+code mentioning either. This finds what a deleted or renamed account left
+behind, in repositories no username leads to.
+
+Code search requires a token; without one only commits are searched.`,
+	Example: `  gogitsomeprivacy scan-identity --email john@example.com --name "John Doe"`,
+	Args:    cobra.NoArgs,
+	RunE:    runScanIdentity,
+}
+
+func init() {
+	scanIdentityCmd.Flags().StringSliceVar(&emails, "email", nil, "email address to search for (repeatable)")
+	scanIdentityCmd.Flags().StringVar(&fullName, "name", "", "full name to search for")
+	scanIdentityCmd.Flags().StringSliceVar(&identities, "identity", nil, "only search these identities from the config (default: all)")
+	scanIdentityCmd.Flags().BoolVar(&exactMatch, "exact", false, "only detect the exact full name (don't split into first/last)")
+	scanIdentityCmd.Flags().StringVarP(&outputFormat, "output", "o", "json", "output format (json, ndjson, text, csv, markdown, html, junit; overrides config)")
+	scanIdentityCmd.Flags().StringVar(&outputLang, "lang", "", "language of the text, markdown and html output: en, fr, de or es (overrides config)")
+	scanIdentityCmd.Flags().StringVarP(&outputFile, "file", "f", "", "output file (default: stdout)")
+	scanIdentityCmd.Flags().BoolVar(&redact, "redact", false, "mask the matched PII in the output so the report can be shared")
+	scanIdentityCmd.Flags().StringVarP(&githubToken, "token", "t", "", "GitHub API token (overrides config)")
+	scanIdentityCmd.Flags().BoolVar(&gravatar, "gravatar", false, "flag commit emails and avatar hashes sharing the Gravatar hash of a searched email")
+	scanIdentityCmd.Flags().BoolVar(&signatures, "signatures", false, "flag GPG keys of signed commits whose user IDs expose a personal email or name")
+	scanIdentityCmd.Flags().StringVar(&failOn, "fail-on", failOnFindings, "exit code policy: findings (1 on findings, 2 on scan errors), errors (2 on scan errors only) or none")
+
+	rootCmd.AddCommand(scanIdentityCmd)
+}
+
+func runScanIdentity(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("output") && cfg.Output.Format != "" {
+		outputFormat = cfg.Output.Format
+	}
+	if !cmd.Flags().Changed("lang") && cfg.Output.Lang != "" {
+		outputLang = cfg.Output.Lang
+	}
+	if _, err := i18n.New(outputLang); err != nil {
+		return err
+	}
+	if err := loadToken(cfg); err != nil {
+		return err
+	}
+	if gravatar {
+		cfg.Scan.CheckGravatar = true
+	}
+	if signatures {
+		cfg.Scan.CheckSignatures = true
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if cfg.Provider != provider.GitHub {
+		return fmt.Errorf("scan-identity is only supported with the github provider")
+	}
+	if _, err := parseFailOn(failOn); err != nil {
+		return err
+	}
+
+	criteria, err := cfg.Criteria(config.SearchOptions{
+		FullName:   fullName,
+		Emails:     emails,
+		Identities: identities,
+		Exact:      exactMatch,
+	})
+	if errors.Is(err, config.ErrNoCriteria) {
+		return fmt.Errorf("at least one of --email, --name or a config identity must be specified")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if len(cfg.GitHub.AllTokens()) == 0 {
+		slog.Warn("No GitHub token: code search will fail, only commits are searched")
+	}
+
+	st, err := baseline.LoadDir(cfg.State.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	client, err := provider.New(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	var progress scanner.ProgressReporter
+	if logsRequested(cmd) {
+		progress = scanner.LogReporter{Logger: slog.Default()}
+	}
+	scannerConfig, err := newScannerConfig(cfg, progress)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+	result, err := scanner.NewScanner(client, criteria, scannerConfig).ScanIdentity(ctx)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	if result.Incomplete {
+		fmt.Fprintf(os.Stderr, "Scan interrupted (%s); results are partial\n", result.IncompleteReason)
+	}
+
+	result.Suppressed += st.Filter(result)
+	result.Summary = report.Summarize(result)
+	result.PrivacyScore = report.Score(result)
+	report.Advise(result)
+	exitCode = scanExitCode(result, failOn)
+
+	report.TrimContext(result, cfg.ContextSize(outputFormat))
+	if redact {
+		report.Redact(result)
+	}
+	if err := outputResults(result, outputFormat, outputFile); err != nil {
+		return fmt.Errorf("failed to output results: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// setupLogging installs the default slog logger configured by --log-level,
+// --log-format and --verbose.
+func setupLogging(cmd *cobra.Command) error {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+	if cmd.Flags().Changed("log-level") {
+		if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+			return fmt.Errorf("invalid --log-level %q: use debug, info, warn or error", logLevel)
+		}
+	}
+
+	logger, err := newLogger(os.Stderr, logFormat, level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// newLogger creates a logger writing to w in format (text or json).
+func newLogger(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid --log-format %q: use text or json", format)
+	}
+}
+
+// logsRequested reports whether scan progress should be logged rather than
+// drawn as a progress bar.
+func logsRequested(cmd *cobra.Command) bool {
+	return verbose || cmd.Flags().Changed("log-level") || strings.EqualFold(logFormat, "json")
+}
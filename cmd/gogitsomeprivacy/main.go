@@ -2,18 +2,30 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"math"
 	"os"
-	"strings"
 	"time"
 
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/archive"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
 	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
 	"github.com/h4n0sh1/GoGitSomePrivacy/internal/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/i18n"
 	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/output"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
 	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/spool"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/store"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tui"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
@@ -29,6 +41,19 @@ var rootCmd = &cobra.Command{
 across all repositories they have participated in, searching for personally
 identifiable information (PII) such as real names.`,
 	Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
+
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupLogging(cmd); err != nil {
+			return err
+		}
+		if err := loadPlugins(); err != nil {
+			return err
+		}
+		if err := startProfiling(); err != nil {
+			return err
+		}
+		return startTracing(cmd, args)
+	},
 }
 
 var scanCmd = &cobra.Command{
@@ -42,6 +67,7 @@ searching for personally identifiable information like their real name.`,
 
 var (
 	configFile    string
+	profileName   string
 	firstName     string
 	lastName      string
 	fullName      string
@@ -49,112 +75,493 @@ var (
 	outputFile    string
 	githubToken   string
 	maxWorkers    int
+	detectWorkers int
 	caseSensitive bool
+	nicknames     bool
 	exactMatch    bool
 	verbose       bool
+	storePath     string
+	baselinePath  string
+	scanPages     bool
+	pagesURL      string
+	noIgnoreFiles bool
+	showClusters  bool
+	tuiMode       bool
+	processors    []string
+	noProgress    bool
+	emails        []string
+	streamOutput  bool
+	identities    []string
+	skipForks     bool
+	minConfidence float64
+	commonWords   string
+	scanFields    []string
+	skipBots      bool
+	otlpEndpoint  string
+	logLevel      string
+	logFormat     string
+	providerName  string
+	providerNames []string
+	providerUsers map[string]string
+	discovery     string
+	committer     bool
+	coAuthor      bool
+	emailSearch   bool
+	scanEvents    bool
+	scanRefs      bool
+	scanFiles     bool
+	codeSearch    bool
+	prContext     bool
+	spoolMatches  bool
+	globalSearch  bool
+	archiveFrom   string
+	archiveTo     string
+	failOn        string
+	redact        bool
+	templatePath  string
+	outputLang    string
+	incremental   bool
+	repoTimeout   time.Duration
+	maxRepoErrors int
+	maxCommits    int
+	sampleCommits int
+	dryRun        bool
+	contextSize   int
+	noEmailConfig bool
+	gravatar      bool
+	signatures    bool
+	associations  bool
 )
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file path")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "apply this profile of the config file (e.g. quick, deep, ci)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output (same as --log-level debug)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log format (text, json)")
+	rootCmd.PersistentFlags().StringSliceVar(&pluginPaths, "plugin", nil, "load output formats from this Go plugin (.so) before running (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&cpuProfile, "cpuprofile", "", "write a CPU profile to this file, for go tool pprof")
+	rootCmd.PersistentFlags().StringVar(&memProfile, "memprofile", "", "write a memory profile to this file when the command ends, for go tool pprof")
+	rootCmd.PersistentFlags().StringVar(&otlpEndpoint, "otlp-endpoint", "", "export OpenTelemetry traces to this OTLP/HTTP endpoint (e.g. http://localhost:4318)")
 
 	scanCmd.Flags().StringVar(&firstName, "first-name", "", "first name to search for")
 	scanCmd.Flags().StringVar(&lastName, "last-name", "", "last name to search for")
 	scanCmd.Flags().StringVar(&fullName, "full-name", "", "full name to search for (also searches first and last names unless --exact is used)")
-	scanCmd.Flags().StringVarP(&outputFormat, "output", "o", "json", "output format (json, text)")
+	scanCmd.Flags().StringSliceVar(&emails, "email", nil, "email address to search for (repeatable)")
+	scanCmd.Flags().StringSliceVar(&identities, "identity", nil, "only search these identities from the config (default: all)")
+	scanCmd.Flags().StringVarP(&outputFormat, "output", "o", "json", "output format (json, ndjson, text, csv, markdown, html, junit, template; overrides config)")
+	scanCmd.Flags().StringVar(&outputLang, "lang", "", "language of the text, markdown and html output: en, fr, de or es (overrides config)")
+	scanCmd.Flags().StringVar(&templatePath, "template", "", "Go text/template file rendering the result (requires --output template)")
+	scanCmd.Flags().IntVar(&contextSize, "context-size", 0, "characters of context shown on each side of matches in the chosen output format (overrides config)")
 	scanCmd.Flags().StringVarP(&outputFile, "file", "f", "", "output file (default: stdout)")
+	scanCmd.Flags().BoolVar(&redact, "redact", false, "mask the matched PII in the output so the report can be shared")
+	scanCmd.Flags().BoolVar(&streamOutput, "stream", false, "write each match as soon as it is found (requires --output ndjson)")
 	scanCmd.Flags().StringVarP(&githubToken, "token", "t", "", "GitHub API token (overrides config)")
+	scanCmd.Flags().StringVar(&providerName, "provider", "", "hosting provider to scan: github or bitbucket (overrides config)")
+	scanCmd.Flags().StringSliceVar(&providerNames, "providers", nil, "scan several hosting providers at once and merge the results, e.g. github,bitbucket (overrides config)")
+	scanCmd.Flags().StringToStringVar(&providerUsers, "provider-user", nil, "username on a provider of --providers when it differs, e.g. bitbucket=jdoe (repeatable)")
 	scanCmd.Flags().IntVarP(&maxWorkers, "workers", "w", 0, "number of concurrent workers (overrides config)")
+	scanCmd.Flags().IntVar(&detectWorkers, "detection-workers", 0, "number of goroutines scanning fetched commits for PII (default GOMAXPROCS; overrides config)")
 	scanCmd.Flags().BoolVar(&caseSensitive, "case-sensitive", false, "perform case-sensitive search")
 	scanCmd.Flags().BoolVar(&exactMatch, "exact", false, "only search for exact full name (don't split into first/last)")
+	scanCmd.Flags().BoolVar(&nicknames, "expand-nicknames", false, "also search known nicknames of the first name, e.g. Bob for Robert, at a lower confidence")
+	scanCmd.Flags().BoolVar(&skipForks, "skip-forks", false, "do not scan forked repositories")
+	scanCmd.Flags().BoolVar(&committer, "include-committer", false, "also scan commits the user committed for someone else")
+	scanCmd.Flags().BoolVar(&coAuthor, "include-co-author", false, "also scan commits crediting the user in a Co-authored-by trailer (lists every commit of each repository)")
+	scanCmd.Flags().BoolVar(&emailSearch, "email-discovery", false, "also search all of GitHub for commits authored with the --email addresses, under any account")
+	scanCmd.Flags().BoolVar(&scanEvents, "events", false, "also scan the user's recent public events: pushes, comments, issues, pull requests and releases")
+	scanCmd.Flags().StringVar(&discovery, "discovery", "", "how to find repositories: repos (owned), search (commit search, includes upstream projects), both or none (overrides config)")
+	scanCmd.Flags().StringVar(&commonWords, "common-words", "", "treatment of common words matched outside author fields: downgrade, suppress or off (overrides config)")
+	scanCmd.Flags().StringSliceVar(&scanFields, "fields", nil, "only scan these commit fields: message, trailers, author_name, committer_name, author_email, committer_email (overrides config)")
+	scanCmd.Flags().BoolVar(&skipBots, "skip-bots", false, "do not scan bot-generated and templated commits: dependency updates, merge commits (sets scan.bot_commits to skip)")
+	scanCmd.Flags().Float64Var(&minConfidence, "min-confidence", 0, "only report findings with at least this confidence (0-1, overrides config)")
+	scanCmd.Flags().BoolVar(&scanPages, "pages", false, "also scan gh-pages branches and the published GitHub Pages site")
+	scanCmd.Flags().StringVar(&failOn, "fail-on", failOnFindings, "exit code policy: findings (1 on findings, 2 on scan errors), errors (2 on scan errors only) or none")
+	scanCmd.Flags().BoolVar(&scanRefs, "refs", false, "also scan release notes, annotated tag messages and branch names")
+	scanCmd.Flags().BoolVar(&scanFiles, "files", false, "also scan package manifests, author lists and workflow files")
+	scanCmd.Flags().BoolVar(&prContext, "pr-context", false, "link matches in commit messages to their pull requests and scan those too")
+	scanCmd.Flags().BoolVar(&codeSearch, "code-search", false, "also search the contents of the user's repositories for the names and emails with code search (requires a token)")
+	scanCmd.Flags().BoolVar(&globalSearch, "global", false, "with --code-search, search all public repositories instead of the user's")
+	scanCmd.Flags().StringVar(&archiveFrom, "archive-from", "", "also scan the user's pushes recorded in GH Archive from this day or hour (2006-01-02 or 2006-01-02T15, UTC)")
+	scanCmd.Flags().StringVar(&archiveTo, "archive-to", "", "last day or hour of GH Archive scanned (default: the day of --archive-from)")
+	scanCmd.Flags().StringVar(&pagesURL, "pages-url", "", "Pages site URL to crawl (default: https://<username>.github.io/)")
+	scanCmd.Flags().BoolVar(&noIgnoreFiles, "no-ignore-files", false, "do not honor .gogitsomeprivacyignore files in scanned repos")
+	scanCmd.Flags().BoolVar(&noEmailConfig, "no-email-config", false, "do not flag commits made with a personal email address instead of the GitHub noreply one")
+	scanCmd.Flags().BoolVar(&gravatar, "gravatar", false, "flag commit emails and avatar hashes sharing the Gravatar hash of a searched email")
+	scanCmd.Flags().BoolVar(&signatures, "signatures", false, "flag GPG keys of signed commits whose user IDs expose a personal email or name")
+	scanCmd.Flags().BoolVar(&associations, "associations", false, "report public org memberships and CODEOWNERS/.mailmap entries linking the username to an identity")
+	scanCmd.Flags().StringSliceVar(&processors, "post-processors", nil, "ordered match post-processors (dedupe, merge_overlaps, allowlist, redact_context, score)")
+	scanCmd.Flags().BoolVar(&noProgress, "no-progress", false, "disable the progress bar")
+	scanCmd.Flags().BoolVar(&tuiMode, "tui", false, "show a live dashboard and browse results interactively (results are only written with --file)")
+	scanCmd.Flags().BoolVar(&showClusters, "clusters", false, "group findings into clusters of identical matched text and field")
+	scanCmd.Flags().StringVar(&baselinePath, "baseline", "", "suppress findings listed in this baseline file")
+	scanCmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the repositories and estimate the commits, API requests and duration of the scan without scanning")
+	scanCmd.Flags().DurationVar(&repoTimeout, "repo-timeout", 0, "skip repositories that take longer than this to fetch, e.g. 10m (overrides config)")
+	scanCmd.Flags().IntVar(&maxRepoErrors, "max-repo-errors", 0, "skip a repository after this many consecutive fetch errors (overrides config)")
+	scanCmd.Flags().IntVar(&maxCommits, "max-commits-per-repo", 0, "only scan the latest N commits of each repository, for a quick triage pass (overrides config)")
+	scanCmd.Flags().IntVar(&sampleCommits, "sample", 0, "only scan about N commits of each repository, picked at random across its history (overrides config)")
+	scanCmd.Flags().BoolVar(&incremental, "incremental", false, "only scan commits pushed since the previous scan in --store, keeping its findings")
+	scanCmd.Flags().StringVar(&storePath, "store", "", "persist results into this SQLite database for later diffs")
+	scanCmd.Flags().BoolVar(&spoolMatches, "spool", false, "keep matches in a temporary file instead of memory, for very large scans")
 
 	rootCmd.AddCommand(scanCmd)
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	err := rootCmd.Execute()
+	stopProfiling()
+	stopTracing()
+	if err != nil {
+		os.Exit(exitErrors)
 	}
+	os.Exit(exitCode)
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
 	username := args[0]
 
 	// Load configuration
-	cfg, err := config.Load(configFile)
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	// Override config with command-line flags
-	if githubToken != "" {
-		cfg.GitHub.Token = githubToken
+	if !cmd.Flags().Changed("output") && cfg.Output.Format != "" {
+		outputFormat = cfg.Output.Format
+	}
+	if !cmd.Flags().Changed("lang") && cfg.Output.Lang != "" {
+		outputLang = cfg.Output.Lang
+	}
+	if providerName != "" {
+		cfg.Provider = providerName
+		cfg.Providers = nil
+	}
+	if len(providerNames) > 0 {
+		cfg.Providers = providerNames
+	}
+	if err := loadToken(cfg); err != nil {
+		return err
 	}
 	if maxWorkers > 0 {
 		cfg.Scan.MaxWorkers = maxWorkers
 	}
+	if detectWorkers > 0 {
+		cfg.Scan.DetectionWorkers = detectWorkers
+	}
 	if caseSensitive {
 		cfg.Scan.CaseSensitive = caseSensitive
 	}
+	if cmd.Flags().Changed("context-size") {
+		if cfg.Output.ContextSizes == nil {
+			cfg.Output.ContextSizes = make(map[string]int)
+		}
+		format := outputFormat
+		if format == "md" {
+			format = "markdown"
+		}
+		cfg.Output.ContextSizes[format] = contextSize
+	}
+	if nicknames {
+		cfg.Scan.ExpandNicknames = true
+	}
+	if cmd.Flags().Changed("post-processors") {
+		cfg.Scan.PostProcessors = processors
+	}
+	if skipForks {
+		cfg.Scan.SkipForks = true
+	}
+	if committer {
+		cfg.Scan.IncludeCommitter = true
+	}
+	if coAuthor {
+		cfg.Scan.IncludeCoAuthor = true
+	}
+	if emailSearch {
+		cfg.Scan.EmailDiscovery = true
+	}
+	if scanEvents {
+		cfg.Scan.ScanEvents = true
+	}
+	if scanRefs {
+		cfg.Scan.ScanRefs = true
+	}
+	if scanFiles {
+		cfg.Scan.ScanFiles = true
+	}
+	if prContext {
+		cfg.Scan.PRContext = true
+	}
+	if globalSearch && !codeSearch {
+		return fmt.Errorf("--global requires --code-search")
+	}
+	if globalSearch {
+		cfg.Scan.CodeSearch = string(scanner.CodeSearchGlobal)
+	} else if codeSearch {
+		cfg.Scan.CodeSearch = string(scanner.CodeSearchRepos)
+	}
+	if archiveFrom != "" {
+		cfg.Archive.From, cfg.Archive.To = archiveFrom, archiveTo
+	}
+	if repoTimeout > 0 {
+		cfg.Scan.RepoTimeoutSeconds = int(math.Ceil(repoTimeout.Seconds()))
+	}
+	if maxRepoErrors > 0 {
+		cfg.Scan.MaxRepoErrors = maxRepoErrors
+	}
+	if maxCommits > 0 && sampleCommits > 0 {
+		return fmt.Errorf("--max-commits-per-repo and --sample cannot be used together")
+	}
+	if maxCommits > 0 {
+		cfg.Scan.MaxCommitsPerRepo, cfg.Scan.Sample = maxCommits, 0
+	}
+	if sampleCommits > 0 {
+		cfg.Scan.MaxCommitsPerRepo, cfg.Scan.Sample = 0, sampleCommits
+	}
+	if spoolMatches {
+		cfg.Scan.Spool = true
+	}
+	if incremental {
+		if storePath == "" {
+			return fmt.Errorf("--incremental requires --store")
+		}
+		cfg.Scan.Incremental = true
+	}
+	if discovery != "" {
+		cfg.Scan.Discovery = discovery
+	}
+	if commonWords != "" {
+		cfg.Scan.CommonWords = commonWords
+	}
+	if len(scanFields) > 0 {
+		fields, err := pii.ParseFields(scanFields)
+		if err != nil {
+			return fmt.Errorf("invalid --fields: %w", err)
+		}
+		cfg.Scan.Fields = fields
+	}
+	if skipBots {
+		cfg.Scan.BotCommits = string(pii.BotCommitsSkip)
+	}
+	if cmd.Flags().Changed("min-confidence") {
+		cfg.Scan.MinConfidence = minConfidence
+	}
+	if noIgnoreFiles {
+		cfg.Scan.RespectIgnoreFiles = false
+	}
+	if noEmailConfig {
+		cfg.Scan.CheckEmailConfig = false
+	}
+	if gravatar {
+		cfg.Scan.CheckGravatar = true
+	}
+	if signatures {
+		cfg.Scan.CheckSignatures = true
+	}
+	if associations {
+		cfg.Scan.Associations = true
+	}
+	if scanPages || pagesURL != "" {
+		cfg.Scan.ScanPages = true
+	}
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	// Auto-split full name into first and last names for better detection
-	// unless --exact flag is used
-	if fullName != "" && !exactMatch && firstName == "" && lastName == "" {
-		parts := strings.Fields(fullName)
-		if len(parts) >= 2 {
-			firstName = parts[0]
-			lastName = parts[len(parts)-1]
-			if verbose {
-				log.Printf("Auto-detecting: first name=%q, last name=%q (use --exact to disable)", firstName, lastName)
-			}
+	// Build search criteria, auto-splitting the full name into first and
+	// last names for better detection unless --exact is used
+	criteria, err := cfg.Criteria(config.SearchOptions{
+		FullName:   fullName,
+		FirstName:  firstName,
+		LastName:   lastName,
+		Emails:     emails,
+		Identities: identities,
+		Exact:      exactMatch,
+	})
+	if errors.Is(err, config.ErrNoCriteria) {
+		return fmt.Errorf("at least one of --first-name, --last-name, --full-name, --email, a config identity or a config rule must be specified")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if firstName == "" && lastName == "" && criteria.FirstName != "" {
+		slog.Debug("Auto-detected first and last name (use --exact to disable)",
+			"first_name", criteria.FirstName, "last_name", criteria.LastName)
+	}
+
+	if _, err := parseFailOn(failOn); err != nil {
+		return err
+	}
+	if cfg.Scan.Spool && (storePath != "" || tuiMode) {
+		return fmt.Errorf("--spool cannot be combined with --store or --tui")
+	}
+	if dryRun && (streamOutput || tuiMode) {
+		return fmt.Errorf("--dry-run cannot be combined with --stream or --tui")
+	}
+	if streamOutput && outputFormat != "ndjson" {
+		return fmt.Errorf("--stream requires --output ndjson")
+	}
+	if (outputFormat == "template") != (templatePath != "") {
+		return fmt.Errorf("--output template and --template must be used together")
+	}
+	if _, err := i18n.New(outputLang); err != nil {
+		return err
+	}
+	if templatePath != "" {
+		// Fail before a long scan rather than after it
+		if _, err := output.LoadTemplate(templatePath); err != nil {
+			return err
 		}
 	}
 
-	// Build search criteria
-	criteria := models.PIISearchCriteria{
-		FirstName:     firstName,
-		LastName:      lastName,
-		FullName:      fullName,
-		CaseSensitive: cfg.Scan.CaseSensitive,
+	// Load baselines, suppressions and triage decisions
+	st, err := baseline.LoadDir(cfg.State.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if baselinePath != "" {
+		entries, err := baseline.Load(baselinePath)
+		if err != nil {
+			return err
+		}
+		st.AddBaseline(entries)
 	}
 
-	// Validate search criteria
-	if criteria.FirstName == "" && criteria.LastName == "" && criteria.FullName == "" {
-		return fmt.Errorf("at least one of --first-name, --last-name, or --full-name must be specified")
+	if len(cfg.Providers) > 0 {
+		return runProviderScans(cmd, cfg, st, criteria, username)
+	}
+	if len(providerUsers) > 0 {
+		return fmt.Errorf("--provider-user requires --providers")
 	}
 
-	// Create GitHub client
-	githubClient := github.NewClient(github.ClientConfig{
-		Token:              cfg.GitHub.Token,
-		RateLimitPerSecond: cfg.GitHub.RateLimitPerSecond,
-		Timeout:            time.Duration(cfg.GitHub.TimeoutSeconds) * time.Second,
-	})
+	// Create the provider client
+	client, err := provider.New(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
 
 	// Create scanner
-	var progressLogger *log.Logger
-	if verbose {
-		progressLogger = log.New(os.Stderr, "[SCAN] ", log.LstdFlags)
+	var progress scanner.ProgressReporter
+	switch {
+	case tuiMode:
+	case logsRequested(cmd):
+		progress = scanner.LogReporter{Logger: slog.Default()}
+	case !noProgress && term.IsTerminal(int(os.Stderr.Fd())):
+		progress = tui.NewProgressBar(os.Stderr)
 	}
 
-	scannerConfig := scanner.Config{
-		MaxWorkers:     cfg.Scan.MaxWorkers,
-		ContextSize:    cfg.Scan.ContextSize,
-		ProgressLogger: progressLogger,
+	var streamer *ndjsonStreamer
+	if streamOutput {
+		streamer, err = newNDJSONStreamer(outputFile, username, cfg.ContextSize("ndjson"), st, redact)
+		if err != nil {
+			return err
+		}
+		defer streamer.Close()
+		if progress != nil {
+			progress = scanner.MultiReporter{progress, streamer}
+		} else {
+			progress = streamer
+		}
 	}
 
-	s := scanner.NewScanner(githubClient, criteria, scannerConfig)
+	scannerConfig, err := newScannerConfig(cfg, progress)
+	if err != nil {
+		return err
+	}
+	scannerConfig.PagesURL = pagesURL
+	if cfg.Scan.Spool {
+		sp, err := spool.New(cfg.Scan.SpoolDir)
+		if err != nil {
+			return err
+		}
+		defer sp.Close()
+		scannerConfig.Spool = sp
+	}
+	if cfg.Scan.Incremental && storePath != "" {
+		if scannerConfig.Incremental, err = loadIncremental(storePath, username); err != nil {
+			return err
+		}
+	}
 
-	// Run scan
-	ctx := context.Background()
+	s := scanner.NewScanner(client, criteria, scannerConfig)
+
+	if dryRun {
+		return runDryRun(s, client, cfg, username)
+	}
+
+	var dashboard *tui.Dashboard
+	if tuiMode {
+		dashboard = tui.NewDashboard(os.Stderr, func() tui.Snapshot {
+			stats := s.Stats()
+			snapshot := tui.Snapshot{
+				Username:     username,
+				StartedAt:    stats.StartedAt,
+				ReposTotal:   stats.ReposTotal,
+				ReposScanned: stats.ReposScanned,
+				Commits:      stats.Commits,
+				Matches:      stats.Matches,
+			}
+			if gh, ok := client.(*github.Client); ok {
+				rate := gh.RateLimit()
+				snapshot.RateLimit = rate.Limit
+				snapshot.RateRemaining = rate.Remaining
+				snapshot.RateReset = rate.Reset
+			}
+			return snapshot
+		})
+		dashboard.Start()
+	}
+
+	// Run scan; Ctrl-C stops it early and keeps the partial results
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
 	result, err := s.ScanUser(ctx, username)
+	if dashboard != nil {
+		dashboard.Stop()
+	}
 	if err != nil {
 		return fmt.Errorf("scan failed: %w", err)
 	}
+	if result.Incomplete {
+		fmt.Fprintf(os.Stderr, "Scan interrupted (%s); results are partial\n", result.IncompleteReason)
+	}
+
+	// Persist unfiltered results for historical comparison and baseline updates
+	if storePath != "" {
+		if err := saveToStore(storePath, result, s.RepoStates()); err != nil {
+			return err
+		}
+	}
+
+	// Hide findings covered by baselines, suppressions and triage decisions
+	result.Suppressed += st.Filter(result)
+	result.Summary = report.Summarize(result)
+	result.PrivacyScore = report.Score(result)
+	report.Advise(result)
+	exitCode = scanExitCode(result, failOn)
+
+	if showClusters {
+		result.Clusters = report.Clusters(result)
+	}
+	report.TrimContext(result, cfg.ContextSize(outputFormat))
+	if redact {
+		report.Redact(result)
+	}
+
+	if streamer != nil {
+		return streamer.Close()
+	}
+
+	if tuiMode {
+		if err := tui.Browse(os.Stdin, os.Stdout, result); err != nil {
+			return fmt.Errorf("result browser failed: %w", err)
+		}
+		if outputFile == "" {
+			return nil
+		}
+	}
 
 	// Output results
 	if err := outputResults(result, outputFormat, outputFile); err != nil {
@@ -164,87 +571,133 @@ func runScan(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func outputResults(result *models.ScanResult, format, outputPath string) error {
-	var output []byte
-	var err error
+// runDryRun prints the plan of a scan of username instead of running it.
+func runDryRun(s *scanner.Scanner, client provider.Provider, cfg *config.Config, username string) error {
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+	plan, err := s.Plan(ctx, username)
+	if err != nil {
+		return fmt.Errorf("failed to plan scan: %w", err)
+	}
+
+	// The GitHub rate limit applies to each token
+	rps := cfg.GitHub.RateLimitPerSecond * float64(max(1, len(cfg.GitHub.AllTokens())))
+	if cfg.Provider == provider.Bitbucket {
+		rps = cfg.Bitbucket.RateLimitPerSecond
+	}
+	var rate github.RateStatus
+	if gh, ok := client.(*github.Client); ok {
+		rate = gh.RateLimit()
+	}
 
-	switch format {
-	case "json":
-		output, err = json.MarshalIndent(result, "", "  ")
+	w := io.Writer(os.Stdout)
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
 		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %w", err)
+			return fmt.Errorf("failed to create output file: %w", err)
 		}
-	case "text":
-		output = []byte(formatTextOutput(result))
-	default:
-		return fmt.Errorf("unsupported output format: %s", format)
+		defer f.Close()
+		w = f
 	}
+	return writePlan(w, newPlanOutput(plan, rps, rate), outputFormat)
+}
 
-	if outputPath != "" {
-		if err := os.WriteFile(outputPath, output, 0644); err != nil {
-			return fmt.Errorf("failed to write output file: %w", err)
-		}
-		fmt.Fprintf(os.Stderr, "Results written to %s\n", outputPath)
-	} else {
-		fmt.Println(string(output))
+// saveToStore persists a result, and the repository states recorded by an
+// incremental scan, into the results store at path.
+func saveToStore(path string, result *models.ScanResult, states map[string]models.RepoState) error {
+	rs, err := store.Open(path)
+	if err != nil {
+		return err
 	}
+	defer rs.Close()
 
+	id, err := rs.SaveScan(result, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to store results: %w", err)
+	}
+	if err := rs.SaveRepoStates(result.Username, id, states); err != nil {
+		return fmt.Errorf("failed to store repository states: %w", err)
+	}
+	slog.Debug("Stored scan", "scan_id", id, "store", path)
 	return nil
 }
 
-func formatTextOutput(result *models.ScanResult) string {
-	var output string
-
-	output += fmt.Sprintf("Scan Results for: %s\n", result.Username)
-	output += fmt.Sprintf("====================%s\n\n", repeatChar('=', len(result.Username)))
-	output += fmt.Sprintf("Repositories Scanned: %d\n", result.SearchedRepos)
-	output += fmt.Sprintf("Total Commits: %d\n", result.TotalCommits)
-	output += fmt.Sprintf("PII Matches Found: %d\n", len(result.Matches))
-	output += fmt.Sprintf("Scan Duration: %s\n\n", result.ScanDuration)
-
-	if len(result.Matches) > 0 {
-		output += "Matches:\n"
-		output += "--------\n\n"
-
-		for i, match := range result.Matches {
-			output += fmt.Sprintf("%d. Repository: %s\n", i+1, match.Commit.Repository)
-			output += fmt.Sprintf("   Commit: %s\n", match.Commit.SHA[:8])
-			output += fmt.Sprintf("   Date: %s\n", match.Commit.Date.Format(time.RFC3339))
-			output += fmt.Sprintf("   URL: %s\n", match.Commit.URL)
-			output += fmt.Sprintf("   Confidence: %.2f\n", match.Confidence)
-			output += fmt.Sprintf("   Locations: %d match(es)\n", len(match.Locations))
-
-			for _, loc := range match.Locations {
-				output += fmt.Sprintf("     - Field: %s, Match: %q\n", loc.Field, loc.Matched)
-			}
-
-			if match.Context != "" {
-				output += fmt.Sprintf("   Context: %s\n", match.Context)
-			}
-			output += "\n"
-		}
+// loadIncremental loads what an incremental scan of username builds on from
+// the results store at path.
+func loadIncremental(path, username string) (*scanner.Incremental, error) {
+	rs, err := store.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer rs.Close()
 
-	if len(result.Errors) > 0 {
-		output += "\nErrors:\n"
-		output += "-------\n\n"
-
-		for i, err := range result.Errors {
-			output += fmt.Sprintf("%d. [%s] %s", i+1, err.Severity, err.Message)
-			if err.Repository != "" {
-				output += fmt.Sprintf(" (Repository: %s)", err.Repository)
-			}
-			output += "\n"
-		}
+	states, matches, err := rs.LoadIncremental(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load repository states: %w", err)
 	}
-
-	return output
+	slog.Debug("Loaded repository states", "repos", len(states), "store", path)
+	return &scanner.Incremental{Repos: states, Matches: matches}, nil
 }
 
-func repeatChar(char rune, count int) string {
-	result := make([]rune, count)
-	for i := range result {
-		result[i] = char
+// newScannerConfig builds the scanner configuration for cfg.
+func newScannerConfig(cfg *config.Config, progress scanner.ProgressReporter) (scanner.Config, error) {
+	chain, err := pii.NewChain(cfg.Scan.PostProcessors, pii.PostProcessorOptions{
+		Allowlist:     cfg.Scan.Allowlist,
+		MinConfidence: cfg.Scan.MinConfidence,
+	})
+	if err != nil {
+		return scanner.Config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	ignoreRules, err := cfg.IgnoreRules()
+	if err != nil {
+		return scanner.Config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	var archiveClient *archive.Client
+	var archiveFrom, archiveTo time.Time
+	if cfg.Archive.From != "" {
+		if archiveFrom, archiveTo, err = archive.ParseRange(cfg.Archive.From, cfg.Archive.To); err != nil {
+			return scanner.Config{}, fmt.Errorf("invalid configuration: archive: %w", err)
+		}
+		archiveClient = archive.NewClient(archive.ClientConfig{
+			BaseURL:     cfg.Archive.BaseURL,
+			Concurrency: cfg.Archive.Concurrency,
+		})
 	}
-	return string(result)
+
+	return scanner.Config{
+		MaxWorkers:       cfg.Scan.MaxWorkers,
+		DetectionWorkers: cfg.Scan.DetectionWorkers,
+		ContextSize:      cfg.MaxContextSize(),
+		SkipForks:        cfg.Scan.SkipForks,
+		Progress:         progress,
+		ScanPages:        cfg.Scan.ScanPages,
+		MaxPages:         cfg.Scan.MaxPages,
+
+		MinConfidence:      cfg.Scan.MinConfidence,
+		Discovery:          scanner.Discovery(cfg.Scan.Discovery),
+		CommitRoles:        cfg.CommitRoles(),
+		EmailDiscovery:     cfg.Scan.EmailDiscovery,
+		ScanEvents:         cfg.Scan.ScanEvents,
+		ScanRefs:           cfg.Scan.ScanRefs,
+		ScanFiles:          cfg.Scan.ScanFiles,
+		CodeSearch:         scanner.CodeSearch(cfg.Scan.CodeSearch),
+		PRContext:          cfg.Scan.PRContext,
+		Archive:            archiveClient,
+		ArchiveFrom:        archiveFrom,
+		ArchiveTo:          archiveTo,
+		CommonWords:        pii.CommonWordMode(cfg.Scan.CommonWords),
+		Fields:             cfg.Scan.Fields,
+		BotCommits:         pii.BotCommitMode(cfg.Scan.BotCommits),
+		RespectIgnoreFiles: cfg.Scan.RespectIgnoreFiles,
+		CheckEmailConfig:   cfg.Scan.CheckEmailConfig,
+		CheckGravatar:      cfg.Scan.CheckGravatar,
+		CheckSignatures:    cfg.Scan.CheckSignatures,
+		Associations:       cfg.Scan.Associations,
+		RepoTimeout:        time.Duration(cfg.Scan.RepoTimeoutSeconds) * time.Second,
+		MaxRepoErrors:      cfg.Scan.MaxRepoErrors,
+		MaxCommitsPerRepo:  cfg.Scan.MaxCommitsPerRepo,
+		Sample:             cfg.Scan.Sample,
+		Ignore:             ignoreRules,
+		PostProcessors:     chain,
+	}, nil
 }
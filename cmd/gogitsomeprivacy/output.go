@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/output"
+)
+
+// outputResults writes result in format to outputPath, or stdout when empty,
+// in the language given with --lang.
+func outputResults(result *models.ScanResult, format, outputPath string) error {
+	f, ok := output.Lookup(format)
+	if !ok {
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+	opts := output.Options{Lang: outputLang, Template: templatePath}
+
+	// Spooled matches are written as they are read back. Other results are
+	// rendered first, so no partial file is left when rendering fails.
+	if result.Spool != nil && f.Streams {
+		return writeOutput(outputPath, func(w io.Writer) error {
+			return output.Write(w, result, format, opts)
+		})
+	}
+	var buf bytes.Buffer
+	if err := output.Write(&buf, result, format, opts); err != nil {
+		return err
+	}
+	return writeOutput(outputPath, func(w io.Writer) error {
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+}
+
+// writeOutput creates outputPath, or uses stdout when empty, and writes to it
+// with write. Output on stdout always ends with a newline.
+func writeOutput(outputPath string, write func(io.Writer) error) error {
+	out := os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	bw := bufio.NewWriter(out)
+	w := &lastByteWriter{w: bw}
+	err := write(w)
+	if err == nil && outputPath == "" && w.last != '\n' {
+		_, err = w.Write([]byte{'\n'})
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	if outputPath != "" {
+		fmt.Fprintf(os.Stderr, "Results written to %s\n", outputPath)
+	}
+	return nil
+}
+
+// lastByteWriter remembers the last byte written through it.
+type lastByteWriter struct {
+	w    io.Writer
+	last byte
+}
+
+func (l *lastByteWriter) Write(p []byte) (int, error) {
+	n, err := l.w.Write(p)
+	if n > 0 {
+		l.last = p[n-1]
+	}
+	return n, err
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+)
+
+// planOutput is a scan plan with its estimated duration, as printed by
+// scan --dry-run.
+type planOutput struct {
+	*scanner.Plan
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	EstimatedDuration string  `json:"estimated_duration"`
+	// RateLimit and RateRemaining are the hourly API budget, when known.
+	RateLimit     int       `json:"rate_limit,omitempty"`
+	RateRemaining int       `json:"rate_remaining,omitempty"`
+	RateReset     time.Time `json:"rate_reset,omitzero"`
+}
+
+// newPlanOutput estimates how long the requests of plan take at rps requests
+// per second.
+func newPlanOutput(plan *scanner.Plan, rps float64, rate github.RateStatus) planOutput {
+	d := time.Duration(float64(plan.Requests) / rps * float64(time.Second))
+	return planOutput{
+		Plan:              plan,
+		RequestsPerSecond: rps,
+		EstimatedDuration: d.Round(time.Second).String(),
+		RateLimit:         rate.Limit,
+		RateRemaining:     rate.Remaining,
+		RateReset:         rate.Reset,
+	}
+}
+
+// writePlan prints a scan plan as JSON, or as a table of repositories.
+func writePlan(w io.Writer, p planOutput, format string) error {
+	if format == "json" {
+		output, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(output))
+		return err
+	}
+
+	fmt.Fprintf(w, "Scan Plan for: %s\n", p.Username)
+	fmt.Fprintf(w, "================%s\n\n", strings.Repeat("=", len(p.Username)))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tCOMMITS\tREQUESTS")
+	for _, r := range p.Repos {
+		commits := fmt.Sprint(r.Commits)
+		if r.Unknown {
+			commits = "?"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", r.Repository, commits, r.Requests)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nRepositories: %d\n", len(p.Repos))
+	if p.UnknownRepos > 0 {
+		fmt.Fprintf(w, "Without Statistics: %d (counted as one page each)\n", p.UnknownRepos)
+	}
+	if p.SkippedForks > 0 {
+		fmt.Fprintf(w, "Skipped Forks: %d\n", p.SkippedForks)
+	}
+	if p.IgnoredRepos > 0 {
+		fmt.Fprintf(w, "Ignored Repositories: %d\n", p.IgnoredRepos)
+	}
+	fmt.Fprintf(w, "Estimated Commits: %d\n", p.Commits)
+	fmt.Fprintf(w, "Estimated API Requests: %d\n", p.Requests)
+	fmt.Fprintf(w, "Estimated Duration: %s (at %g requests/second)\n", p.EstimatedDuration, p.RequestsPerSecond)
+	if p.RateLimit > 0 {
+		fmt.Fprintf(w, "Rate Limit: %d of %d remaining until %s\n", p.RateRemaining, p.RateLimit, p.RateReset.Format(time.Kitchen))
+		if p.Requests > p.RateRemaining {
+			fmt.Fprintf(w, "Warning: the scan needs more requests than remain; narrow it with --skip-forks or ignore rules, or wait for the reset\n")
+		}
+	}
+
+	for _, err := range p.Errors {
+		fmt.Fprintf(w, "Warning: %s", err.Message)
+		if err.Repository != "" {
+			fmt.Fprintf(w, " (Repository: %s)", err.Repository)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
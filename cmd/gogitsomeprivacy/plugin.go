@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"plugin"
+)
+
+// pluginPaths are the Go plugins given with --plugin.
+var pluginPaths []string
+
+// loadPlugins opens the Go plugins given with --plugin. Plugins register
+// their output formats with ggsp.RegisterOutputFormat from init functions,
+// which run as they are opened.
+func loadPlugins() error {
+	for _, path := range pluginPaths {
+		if err := openPlugin(path); err != nil {
+			return err
+		}
+		slog.Debug("Loaded plugin", "path", path)
+	}
+	return nil
+}
+
+// openPlugin opens the plugin at path. A panic in its init functions, such as
+// registering a format name already taken, is returned as an error.
+func openPlugin(path string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to load plugin %s: %v", path, r)
+		}
+	}()
+	if _, err := plugin.Open(path); err != nil {
+		return fmt.Errorf("failed to load plugin %s: %w", path, err)
+	}
+	return nil
+}
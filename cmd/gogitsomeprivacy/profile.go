@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+var (
+	cpuProfile string
+	memProfile string
+
+	// cpuProfileFile is the file the CPU profile is being written to, if any.
+	cpuProfileFile *os.File
+)
+
+// startProfiling starts writing a CPU profile to the file given with
+// --cpuprofile.
+func startProfiling() error {
+	if cpuProfile == "" {
+		return nil
+	}
+	f, err := os.Create(cpuProfile)
+	if err != nil {
+		return fmt.Errorf("failed to create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+	cpuProfileFile = f
+	return nil
+}
+
+// stopProfiling completes the CPU profile and writes the heap profile given
+// with --memprofile, reporting failures as warnings since the command has
+// already run.
+func stopProfiling() {
+	if cpuProfileFile != nil {
+		pprof.StopCPUProfile()
+		if err := cpuProfileFile.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write CPU profile: %v\n", err)
+		}
+	}
+	if memProfile == "" {
+		return
+	}
+	f, err := os.Create(memProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create memory profile: %v\n", err)
+		return
+	}
+	defer f.Close()
+	// Collect garbage first so the in-use figures are up to date
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write memory profile: %v\n", err)
+	}
+}
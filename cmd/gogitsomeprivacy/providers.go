@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// runProviderScans scans username on each of cfg.Providers at once, as named
+// by --provider-user, and outputs the merged result.
+func runProviderScans(cmd *cobra.Command, cfg *config.Config, st *baseline.State, criteria models.PIISearchCriteria, username string) error {
+	if streamOutput || tuiMode || dryRun || cfg.Scan.Incremental || cfg.Scan.Spool {
+		return fmt.Errorf("--providers cannot be combined with --stream, --tui, --dry-run, --incremental or --spool")
+	}
+	for name := range providerUsers {
+		if !slices.Contains(cfg.Providers, name) {
+			return fmt.Errorf("--provider-user: %s is not one of the providers scanned", name)
+		}
+	}
+
+	// Progress bars of concurrent scans would overwrite each other
+	var progress scanner.ProgressReporter
+	if logsRequested(cmd) {
+		progress = scanner.LogReporter{Logger: slog.Default()}
+	}
+
+	scans := make([]scanner.ProviderScan, 0, len(cfg.Providers))
+	for _, name := range cfg.Providers {
+		pcfg := cfg.ForProvider(name)
+		client, err := provider.New(pcfg)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		scannerConfig, err := newScannerConfig(pcfg, progress)
+		if err != nil {
+			return err
+		}
+		scannerConfig.PagesURL = pagesURL
+
+		user := username
+		if u, ok := providerUsers[name]; ok {
+			user = u
+		}
+		scans = append(scans, scanner.ProviderScan{
+			Provider: name,
+			Scanner:  scanner.NewScanner(client, criteria, scannerConfig),
+			Username: user,
+		})
+	}
+
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+	result, err := scanner.ScanProviders(ctx, scans)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	if result.Incomplete {
+		fmt.Fprintf(os.Stderr, "Scan interrupted (%s); results are partial\n", result.IncompleteReason)
+	}
+
+	if storePath != "" {
+		if err := saveToStore(storePath, result, nil); err != nil {
+			return err
+		}
+	}
+
+	result.Suppressed += st.Filter(result)
+	result.Summary = report.Summarize(result)
+	result.PrivacyScore = report.Score(result)
+	report.Advise(result)
+	exitCode = scanExitCode(result, failOn)
+
+	if showClusters {
+		result.Clusters = report.Clusters(result)
+	}
+	report.TrimContext(result, cfg.ContextSize(outputFormat))
+	if redact {
+		report.Redact(result)
+	}
+	if err := outputResults(result, outputFormat, outputFile); err != nil {
+		return fmt.Errorf("failed to output results: %w", err)
+	}
+	return nil
+}
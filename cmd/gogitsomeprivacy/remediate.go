@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/remediate"
+	"github.com/spf13/cobra"
+)
+
+var remediateCmd = &cobra.Command{
+	Use:   "remediate [results.json]",
+	Short: "Generate per-repository remediation instructions from scan results",
+	Long: `Generate git filter-repo mailmap and --replace-message files for every
+repository with findings, together with the force-push and GitHub support steps
+needed to purge the leaked data.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRemediate,
+}
+
+var (
+	remediateOutDir      string
+	remediateName        string
+	remediateEmail       string
+	remediateReplacement string
+)
+
+func init() {
+	remediateCmd.Flags().StringVarP(&remediateOutDir, "out-dir", "d", "", "write one directory of files per repository here (default: print to stdout)")
+	remediateCmd.Flags().StringVar(&remediateName, "new-name", "", "author name to rewrite leaked identities to (default: username)")
+	remediateCmd.Flags().StringVar(&remediateEmail, "new-email", "", "author email to rewrite leaked identities to (default: GitHub noreply address)")
+	remediateCmd.Flags().StringVar(&remediateReplacement, "replacement", remediate.DefaultReplacement, "text that replaces PII in commit messages")
+
+	rootCmd.AddCommand(remediateCmd)
+}
+
+func runRemediate(cmd *cobra.Command, args []string) error {
+	result, err := loadResultFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	plan := remediate.BuildPlan(result, remediate.Options{
+		Identity:    remediate.Identity{Name: remediateName, Email: remediateEmail},
+		Replacement: remediateReplacement,
+	})
+	if len(plan.Repos) == 0 {
+		fmt.Fprintln(os.Stderr, "No findings to remediate")
+		return nil
+	}
+
+	if remediateOutDir == "" {
+		// Files are expected next to the mirror clone, one level above its directory
+		for _, rp := range plan.Repos {
+			fmt.Print(rp.Instructions(".."))
+			if mm := rp.MailmapFile(); mm != "" {
+				fmt.Printf("\n### mailmap\n\n```\n%s```\n", mm)
+			}
+			if rt := rp.ReplacementsFile(); rt != "" {
+				fmt.Printf("\n### replacements.txt\n\n```\n%s```\n", rt)
+			}
+			fmt.Println()
+		}
+		return nil
+	}
+
+	for _, rp := range plan.Repos {
+		dir, err := filepath.Abs(filepath.Join(remediateOutDir, strings.ReplaceAll(rp.Repository, "/", "__")))
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+
+		files := map[string]string{
+			"README.md":        rp.Instructions(dir),
+			"mailmap":          rp.MailmapFile(),
+			"replacements.txt": rp.ReplacementsFile(),
+		}
+		for name, content := range files {
+			if content == "" {
+				continue
+			}
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote remediation plans for %d repositories to %s\n", len(plan.Repos), remediateOutDir)
+	return nil
+}
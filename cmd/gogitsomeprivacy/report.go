@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/i18n"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/output"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [results.json]",
+	Short: "Re-render saved scan results without scanning again",
+	Long: `Load a result saved with --output json, or the latest scan of a user stored
+with --store, and render it again in any output format. Findings can be
+narrowed further by confidence and repository, and baselines, suppressions
+and triage decisions recorded since the scan are applied. The summary and
+privacy score are recomputed from the remaining findings.`,
+	Example: `  gogitsomeprivacy report results.json --output html -f report.html
+  gogitsomeprivacy report results.json -o markdown --min-confidence 0.8 --exclude-repo "acme/*"
+  gogitsomeprivacy report --store results.db --user johndoe -o text`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReport,
+}
+
+var (
+	reportFormat       string
+	reportFile         string
+	reportStorePath    string
+	reportUser         string
+	reportRepos        []string
+	reportExcludeRepos []string
+)
+
+func init() {
+	reportCmd.Flags().StringVarP(&reportFormat, "output", "o", "text", "output format (json, ndjson, text, csv, markdown, html, junit, template; overrides config)")
+	reportCmd.Flags().StringVar(&outputLang, "lang", "", "language of the text, markdown and html output: en, fr, de or es (overrides config)")
+	reportCmd.Flags().StringVar(&templatePath, "template", "", "Go text/template file rendering the result (requires --output template)")
+	reportCmd.Flags().StringVarP(&reportFile, "file", "f", "", "output file (default: stdout)")
+	reportCmd.Flags().StringVar(&reportStorePath, "store", "", "read the latest scan of --user from this SQLite results database instead of a file")
+	reportCmd.Flags().StringVar(&reportUser, "user", "", "user whose latest stored scan to report (requires --store)")
+	reportCmd.Flags().Float64Var(&minConfidence, "min-confidence", 0, "only report findings with at least this confidence (0-1)")
+	reportCmd.Flags().StringSliceVar(&reportRepos, "repo", nil, "only report findings in repositories matching this owner/name pattern, e.g. acme/* (repeatable)")
+	reportCmd.Flags().StringSliceVar(&reportExcludeRepos, "exclude-repo", nil, "drop findings in repositories matching this owner/name pattern (repeatable)")
+	reportCmd.Flags().StringVar(&baselinePath, "baseline", "", "suppress findings listed in this baseline file")
+	reportCmd.Flags().BoolVar(&showClusters, "clusters", false, "group findings into clusters of identical matched text and field")
+	reportCmd.Flags().BoolVar(&redact, "redact", false, "mask the matched PII in the output so the report can be shared")
+
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("output") && cfg.Output.Format != "" {
+		reportFormat = cfg.Output.Format
+	}
+	if !cmd.Flags().Changed("lang") && cfg.Output.Lang != "" {
+		outputLang = cfg.Output.Lang
+	}
+	if _, err := i18n.New(outputLang); err != nil {
+		return err
+	}
+	if (reportFormat == "template") != (templatePath != "") {
+		return fmt.Errorf("--output template and --template must be used together")
+	}
+	if templatePath != "" {
+		if _, err := output.LoadTemplate(templatePath); err != nil {
+			return err
+		}
+	}
+	if minConfidence < 0 || minConfidence > 1 {
+		return fmt.Errorf("--min-confidence must be between 0 and 1")
+	}
+	filter := report.Filter{
+		MinConfidence: minConfidence,
+		Repos:         reportRepos,
+		ExcludeRepos:  reportExcludeRepos,
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	var result *models.ScanResult
+	switch {
+	case len(args) == 1 && reportStorePath != "":
+		return fmt.Errorf("specify either a results file or --store, not both")
+	case len(args) == 1:
+		result, err = loadResultFile(args[0])
+	case reportStorePath != "" && reportUser != "":
+		result, err = loadLatestStored(reportStorePath, reportUser)
+	case reportStorePath != "":
+		return fmt.Errorf("--store requires --user")
+	default:
+		return fmt.Errorf("a results file or --store and --user must be specified")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Apply state recorded since the scan, then the new filters
+	st, err := baseline.LoadDir(cfg.State.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if baselinePath != "" {
+		entries, err := baseline.Load(baselinePath)
+		if err != nil {
+			return err
+		}
+		st.AddBaseline(entries)
+	}
+	result.Suppressed += st.Filter(result)
+	filter.Apply(result)
+
+	result.Summary = report.Summarize(result)
+	result.PrivacyScore = report.Score(result)
+	report.Advise(result)
+	if showClusters || result.Clusters != nil {
+		result.Clusters = report.Clusters(result)
+	}
+	report.TrimContext(result, cfg.ContextSize(reportFormat))
+	if redact {
+		report.Redact(result)
+	}
+
+	if err := outputResults(result, reportFormat, reportFile); err != nil {
+		return fmt.Errorf("failed to output results: %w", err)
+	}
+	return nil
+}
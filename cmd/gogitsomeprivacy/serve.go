@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/server"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/sink"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run scans on demand over an HTTP REST API",
+	Long: `Start an HTTP server that queues and runs scans on request:
+
+  POST   /scans              start a scan (202 with the job)
+  GET    /scans              list scans, newest first
+  GET    /scans/{id}         scan status, progress and result
+  GET    /scans/{id}/events  server-sent events stream of progress
+  DELETE /scans/{id}         cancel a scan, keeping partial results
+
+All scans share the configured GitHub token and rate limit.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var (
+	serveAddr        string
+	serveConcurrency int
+	serveQueueSize   int
+	serveMaxFinished int
+	serveJobTTL      time.Duration
+	serveAuthToken   string
+	serveStorePath   string
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "listen", "127.0.0.1:8080", "address to listen on")
+	serveCmd.Flags().IntVar(&serveConcurrency, "concurrency", 1, "number of scans run at the same time")
+	serveCmd.Flags().IntVar(&serveQueueSize, "queue-size", 100, "maximum number of queued scans")
+	serveCmd.Flags().IntVar(&serveMaxFinished, "max-finished-jobs", 100, "number of finished scans kept in memory, oldest forgotten first")
+	serveCmd.Flags().DurationVar(&serveJobTTL, "job-ttl", 0, "forget finished scans this long after they finish (default: keep until --max-finished-jobs is reached)")
+	serveCmd.Flags().StringVar(&serveAuthToken, "auth-token", "", "require this bearer token on every request (default: $GGSP_SERVE_TOKEN)")
+	serveCmd.Flags().StringVar(&serveStorePath, "store", "", "persist finished results into this SQLite database")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := loadToken(cfg); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if serveAuthToken == "" {
+		serveAuthToken = os.Getenv("GGSP_SERVE_TOKEN")
+	}
+
+	srv, err := server.New(cfg, server.Options{
+		Concurrency:     serveConcurrency,
+		QueueSize:       serveQueueSize,
+		MaxFinishedJobs: serveMaxFinished,
+		JobTTL:          serveJobTTL,
+		AuthToken:       serveAuthToken,
+		StorePath:       serveStorePath,
+		Sinks:           sink.New(cfg.Sinks),
+	})
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{
+		Addr:              serveAddr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+
+	runnersDone := make(chan struct{})
+	go func() {
+		srv.Run(ctx)
+		close(runnersDone)
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+	slog.Info("Listening", "addr", "http://"+serveAddr)
+	if serveAuthToken == "" {
+		slog.Warn("No --auth-token set; anyone who can reach the server can start scans", "addr", serveAddr)
+	}
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+	case <-ctx.Done():
+		slog.Info("Shutting down", "reason", context.Cause(ctx))
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	<-runnersDone
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Manage baselines, suppressions and triage decisions",
+	Long: `Manage the local state used to filter scan results: baseline entries,
+suppression rules and triage decisions. The state can be exported to a single
+portable YAML file and imported on another machine or by a teammate.`,
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export local state to a portable YAML file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStateExport,
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import a portable YAML file into local state",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStateImport,
+}
+
+var (
+	stateDir     string
+	stateReplace bool
+)
+
+func init() {
+	stateCmd.PersistentFlags().StringVar(&stateDir, "state-dir", "", "state directory (overrides config)")
+	stateImportCmd.Flags().BoolVar(&stateReplace, "replace", false, "replace local state instead of merging")
+
+	stateCmd.AddCommand(stateExportCmd, stateImportCmd)
+	rootCmd.AddCommand(stateCmd)
+}
+
+// resolveStateDir returns the state directory from flags or configuration.
+func resolveStateDir(cfg *config.Config) string {
+	if stateDir != "" {
+		return stateDir
+	}
+	return cfg.State.Dir
+}
+
+func runStateExport(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	st, err := baseline.LoadDir(resolveStateDir(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if err := st.Export(args[0]); err != nil {
+		return fmt.Errorf("failed to export state: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d baseline entries, %d suppressions and %d triage decisions to %s\n",
+		len(st.Baseline), len(st.Suppressions), len(st.Triage), args[0])
+	return nil
+}
+
+func runStateImport(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	imported, err := baseline.Import(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to import state: %w", err)
+	}
+
+	dir := resolveStateDir(cfg)
+	st := imported
+	if !stateReplace {
+		st, err = baseline.LoadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to load state: %w", err)
+		}
+		st.Merge(imported)
+	}
+
+	if err := st.SaveDir(dir); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "State in %s now has %d baseline entries, %d suppressions and %d triage decisions\n",
+		dir, len(st.Baseline), len(st.Suppressions), len(st.Triage))
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+)
+
+// ndjsonStreamer writes each match as a JSON line as soon as the scanner
+// reports it, applying suppressions on the fly.
+type ndjsonStreamer struct {
+	mu       sync.Mutex
+	state    *baseline.State
+	username string // the user scanned, for the advice of each match
+	context  int    // characters of context kept around matches
+	redact   bool
+	file     *os.File
+	buf      *bufio.Writer
+	enc      *json.Encoder
+	closed   bool
+}
+
+// newNDJSONStreamer streams the matches of a scan of username to path, or to
+// stdout when path is empty, with contextSize characters of context around
+// matches. With redact, the matched PII is masked in each line.
+func newNDJSONStreamer(path, username string, contextSize int, state *baseline.State, redact bool) (*ndjsonStreamer, error) {
+	var w io.Writer = os.Stdout
+	var file *os.File
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output file: %w", err)
+		}
+		file, w = f, f
+	}
+
+	buf := bufio.NewWriter(w)
+	return &ndjsonStreamer{
+		state:    state,
+		username: username,
+		context:  contextSize,
+		redact:   redact,
+		file:     file,
+		buf:      buf,
+		enc:      json.NewEncoder(buf),
+	}, nil
+}
+
+// Report writes match events; other events are ignored.
+func (s *ndjsonStreamer) Report(e scanner.Event) {
+	if e.Type != scanner.EventMatchFound || e.Match == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	match, _ := s.state.FilterMatch(*e.Match)
+	if len(match.Locations) == 0 {
+		return
+	}
+	match.Advice = report.Advice(match, s.username)
+	match = report.TrimMatchContext(match, s.context)
+	if s.redact {
+		match = report.RedactMatch(match)
+	}
+	if err := s.enc.Encode(match); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to stream match: %v\n", err)
+		return
+	}
+	// Flush per line so downstream consumers see matches immediately
+	s.buf.Flush()
+}
+
+// Close flushes and closes the output. It is safe to call more than once.
+func (s *ndjsonStreamer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if s.file != nil {
+		return s.file.Close()
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"github.com/spf13/cobra"
+)
+
+// shutdownTracing flushes exported spans. It is nil unless tracing is enabled.
+var shutdownTracing func(context.Context) error
+
+// startTracing enables OpenTelemetry trace export when --otlp-endpoint or the
+// standard OTEL_EXPORTER_OTLP_* environment variables are set.
+func startTracing(cmd *cobra.Command, args []string) error {
+	if !tracing.Enabled(otlpEndpoint) {
+		return nil
+	}
+	shutdown, err := tracing.Setup(cmd.Context(), otlpEndpoint, version)
+	if err != nil {
+		return err
+	}
+	shutdownTracing = shutdown
+	return nil
+}
+
+// stopTracing flushes pending spans, giving up after a few seconds so an
+// unreachable collector does not hang the exit.
+func stopTracing() {
+	if shutdownTracing == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to flush traces: %v\n", err)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [results.json...]",
+	Short: "Check scan results against the published JSON Schema",
+	Long: `Check files written with --output json against the JSON Schema of scan
+results, version ` + schema.Version + `, and report every value that does not
+conform. With --schema, print the schema instead.`,
+	RunE: runValidate,
+}
+
+var validatePrintSchema bool
+
+func init() {
+	validateCmd.Flags().BoolVar(&validatePrintSchema, "schema", false, "print the JSON Schema of scan results and exit")
+
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	if validatePrintSchema {
+		_, err := out.Write(schema.JSON)
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no results file given")
+	}
+
+	invalid := 0
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read results: %w", err)
+		}
+		violations, err := schema.Validate(data)
+		if err != nil {
+			return fmt.Errorf("failed to validate %s: %w", path, err)
+		}
+		if len(violations) == 0 {
+			fmt.Fprintf(out, "%s: valid (schema version %s)\n", path, schema.Version)
+			continue
+		}
+		invalid++
+		fmt.Fprintf(out, "%s: %d violations of schema version %s\n", path, len(violations), schema.Version)
+		for _, v := range violations {
+			fmt.Fprintf(out, "  %s\n", v)
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d files do not conform to the schema", invalid, len(args))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/metrics"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/server"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/sink"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Re-scan configured users on a schedule and notify about new findings",
+	Long: `Continuously monitor the users listed under watch.targets in the config file.
+Each round scans every target, stores the result in the watch database and
+compares it with the previous scan of that user. Findings that were not there
+before are sent to the configured notifiers (Slack, webhook, email), or logged
+if none is configured. The first scan of a user only records a baseline.`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+var (
+	watchOnce        bool
+	watchSchedule    string
+	watchStorePath   string
+	watchMetricsAddr string
+)
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "run a single round and exit (for external schedulers)")
+	watchCmd.Flags().StringVar(&watchSchedule, "schedule", "", "cron expression or @every <duration> (overrides config)")
+	watchCmd.Flags().StringVar(&watchStorePath, "store", "", "SQLite database of previous scans (overrides config)")
+	watchCmd.Flags().StringVar(&watchMetricsAddr, "metrics-addr", "", "serve /metrics and /healthz on this address (e.g. 127.0.0.1:9090)")
+
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := loadToken(cfg); err != nil {
+		return err
+	}
+	if watchSchedule != "" {
+		cfg.Watch.Schedule = watchSchedule
+	}
+	if watchStorePath != "" {
+		cfg.Watch.Store = watchStorePath
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if len(cfg.Watch.Targets) == 0 {
+		return fmt.Errorf("no users to watch: add watch.targets to the config file")
+	}
+	schedule, err := watch.ParseSchedule(cfg.Watch.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	logger := slog.Default().With("component", "watch")
+	notifiers := watch.NewNotifiers(cfg.Watch.Notify)
+	if len(notifiers) == 0 {
+		logger.Warn("No notifiers configured; new findings will be logged")
+	}
+	w, err := watch.New(cfg, notifiers, sink.New(cfg.Sinks), logger)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+
+	if watchMetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /healthz", server.HealthHandler)
+		mux.Handle("GET /metrics", metrics.Handler())
+		metricsServer := &http.Server{Addr: watchMetricsAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("Metrics server failed", "error", err)
+			}
+		}()
+		defer metricsServer.Close()
+		logger.Info("Serving metrics", "addr", "http://"+watchMetricsAddr+"/metrics")
+	}
+
+	if watchOnce {
+		if failed := w.RunOnce(ctx); failed > 0 {
+			return fmt.Errorf("%d of %d targets failed", failed, len(cfg.Watch.Targets))
+		}
+		return nil
+	}
+	logger.Info("Watching users", "targets", len(cfg.Watch.Targets), "schedule", cfg.Watch.Schedule)
+	return w.Run(ctx, schedule)
+}
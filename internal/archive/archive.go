@@ -0,0 +1,263 @@
+// Package archive reads a user's past public push events from GH Archive
+// (https://www.gharchive.org), which records every public GitHub event in
+// hourly dumps. Commits rewritten or deleted on GitHub remain there.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// DefaultBaseURL is where GH Archive publishes its hourly dumps.
+const DefaultBaseURL = "https://data.gharchive.org/"
+
+// MaxHours caps the hours of a range, each a download of up to a few hundred
+// megabytes.
+const MaxHours = 31 * 24
+
+// hourLayout names the dump of an hour, e.g. 2015-01-01-15.json.gz. Hours are
+// not zero-padded.
+const hourLayout = "2006-01-02-15"
+
+// ClientConfig contains configuration for the archive client.
+type ClientConfig struct {
+	// BaseURL is the location of the dumps (default DefaultBaseURL).
+	BaseURL string
+	// Concurrency is the number of hours downloaded at a time (default 4).
+	Concurrency int
+	// Timeout caps the download of one hour (default 10 minutes).
+	Timeout time.Duration
+}
+
+// Client downloads and filters archive dumps.
+type Client struct {
+	httpClient  *http.Client
+	baseURL     string
+	concurrency int
+}
+
+// NewClient creates a new archive client.
+func NewClient(cfg ClientConfig) *Client {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if !strings.HasSuffix(cfg.BaseURL, "/") {
+		cfg.BaseURL += "/"
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Minute
+	}
+	return &Client{
+		httpClient:  &http.Client{Timeout: cfg.Timeout},
+		baseURL:     cfg.BaseURL,
+		concurrency: cfg.Concurrency,
+	}
+}
+
+// ParseRange parses the first and last hour of a range, each a date
+// (2006-01-02) or an hour (2006-01-02T15 or 2006-01-02-15), in UTC. A date
+// starts the range at its first hour and ends it at its last; an empty to
+// ends the range with the day of from.
+func ParseRange(from, to string) (first, last time.Time, err error) {
+	first, _, err = parseHour(from)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to == "" {
+		to = first.Format(time.DateOnly)
+	}
+	last, hour, err := parseHour(to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !hour {
+		last = last.Add(23 * time.Hour)
+	}
+	if last.Before(first) {
+		return time.Time{}, time.Time{}, fmt.Errorf("archive range ends before it starts")
+	}
+	if n := int(last.Sub(first)/time.Hour) + 1; n > MaxHours {
+		return time.Time{}, time.Time{}, fmt.Errorf("archive range of %d hours is too long, at most %d are scanned at a time", n, MaxHours)
+	}
+	return first, last, nil
+}
+
+// parseHour parses a date or an hour, reporting which.
+func parseHour(s string) (time.Time, bool, error) {
+	for _, layout := range []string{"2006-01-02T15", hourLayout} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true, nil
+		}
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid archive date %q: use 2006-01-02 or 2006-01-02T15", s)
+	}
+	return t, false, nil
+}
+
+// PushEvents returns the push events of login recorded between the first and
+// last hour, inclusive, oldest first. Hours missing from the archive are
+// skipped.
+func (c *Client) PushEvents(ctx context.Context, login string, first, last time.Time) ([]*models.ActivityEvent, error) {
+	hours := make(chan time.Time)
+	var (
+		mu     sync.Mutex
+		events []*models.ActivityEvent
+		errs   []error
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < c.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hour := range hours {
+				found, err := c.hourPushEvents(ctx, login, hour)
+				mu.Lock()
+				events = append(events, found...)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for hour := first; !hour.After(last); hour = hour.Add(time.Hour) {
+		select {
+		case hours <- hour:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(hours)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(events, func(a, b *models.ActivityEvent) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return events, nil
+}
+
+// hourPushEvents returns the push events of login in the dump of an hour.
+func (c *Client) hourPushEvents(ctx context.Context, login string, hour time.Time) ([]*models.ActivityEvent, error) {
+	name := hour.UTC().Format("2006-01-02-") + fmt.Sprint(hour.UTC().Hour()) + ".json.gz"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch archive %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch archive %s: %s", name, resp.Status)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", name, err)
+	}
+	defer gz.Close()
+
+	// Most events are skipped without decoding them
+	needle := []byte(`"login":"` + login + `"`)
+	var events []*models.ActivityEvent
+	r := bufio.NewReaderSize(gz, 1<<20)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 && bytes.Contains(line, needle) && bytes.Contains(line, []byte(`"PushEvent"`)) {
+			if ev := parsePushEvent(line, login); ev != nil {
+				events = append(events, ev)
+			}
+		}
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", name, err)
+		}
+	}
+}
+
+// archivedEvent is the part of an archived event read.
+type archivedEvent struct {
+	Type  string `json:"type"`
+	Actor struct {
+		Login string `json:"login"`
+	} `json:"actor"`
+	Repo struct {
+		Name string `json:"name"`
+	} `json:"repo"`
+	CreatedAt time.Time `json:"created_at"`
+	Payload   struct {
+		Before  string `json:"before"`
+		Head    string `json:"head"`
+		Commits []struct {
+			SHA     string `json:"sha"`
+			Message string `json:"message"`
+			Author  struct {
+				Name  string `json:"name"`
+				Email string `json:"email"`
+			} `json:"author"`
+		} `json:"commits"`
+	} `json:"payload"`
+}
+
+// parsePushEvent parses a line of a dump, returning nil unless it is a push
+// event of login.
+func parsePushEvent(line []byte, login string) *models.ActivityEvent {
+	var e archivedEvent
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil
+	}
+	if e.Type != "PushEvent" || !strings.EqualFold(e.Actor.Login, login) {
+		return nil
+	}
+
+	ev := &models.ActivityEvent{
+		Type:       e.Type,
+		Repository: e.Repo.Name,
+		URL:        "https://github.com/" + e.Repo.Name + "/compare/" + e.Payload.Before + "..." + e.Payload.Head,
+		CreatedAt:  e.CreatedAt,
+	}
+	for _, pc := range e.Payload.Commits {
+		ev.Commits = append(ev.Commits, &models.Commit{
+			SHA:        pc.SHA,
+			Repository: e.Repo.Name,
+			Message:    pc.Message,
+			Trailers:   models.ParseTrailers(pc.Message),
+			URL:        "https://github.com/" + e.Repo.Name + "/commit/" + pc.SHA,
+			Date:       e.CreatedAt,
+			Author:     models.Author{Name: pc.Author.Name, Email: pc.Author.Email},
+		})
+	}
+	return ev
+}
@@ -0,0 +1,74 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Load reads a baseline file. It is an error for the file not to exist.
+func Load(path string) ([]Entry, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	return loadEntries(path)
+}
+
+// Save writes a baseline file.
+func Save(path string, entries []Entry) error {
+	return saveEntries(path, entries)
+}
+
+// FromResult builds baseline entries for every match location in a result,
+// sorted and deduplicated so that regenerated baselines diff cleanly.
+func FromResult(result *models.ScanResult) []Entry {
+	seen := make(map[string]bool)
+	entries := []Entry{}
+
+	for match := range result.AllMatches() {
+		for _, loc := range match.Locations {
+			e := EntryFor(match, loc)
+			if seen[e.Key()] {
+				continue
+			}
+			seen[e.Key()] = true
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key() < entries[j].Key()
+	})
+	return entries
+}
+
+// loadEntries reads baseline entries from a JSON file. A missing file yields no entries.
+func loadEntries(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// saveEntries writes baseline entries to a JSON file.
+func saveEntries(path string, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,312 @@
+// Package baseline manages accepted findings, suppression rules and triage
+// decisions that filter scan results.
+package baseline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// StateVersion is the version of the portable state format.
+const StateVersion = 1
+
+// File names used inside a state directory.
+const (
+	BaselineFile     = "baseline.json"
+	SuppressionsFile = "suppressions.yaml"
+	TriageFile       = "triage.yaml"
+)
+
+// Entry identifies a single finding by repository, commit, field and matched
+// text, or by its fingerprint (see models.Fingerprint). Entries recorded
+// before fingerprints existed have none.
+type Entry struct {
+	Repository  string `json:"repository" yaml:"repository"`
+	SHA         string `json:"sha" yaml:"sha"`
+	Field       string `json:"field" yaml:"field"`
+	Matched     string `json:"matched" yaml:"matched"`
+	Fingerprint string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+}
+
+// Key returns the lookup key for the entry.
+func (e Entry) Key() string {
+	return strings.Join([]string{e.Repository, e.SHA, e.Field, e.Matched}, "\x00")
+}
+
+// Same reports whether e and other identify the same finding: their
+// fingerprints or their keys are equal.
+func (e Entry) Same(other Entry) bool {
+	if e.Fingerprint != "" && e.Fingerprint == other.Fingerprint {
+		return true
+	}
+	return e.Key() == other.Key()
+}
+
+// id returns what tells the entry apart in a state: its key, or its
+// fingerprint for an entry holding nothing else.
+func (e Entry) id() string {
+	if e.Repository == "" && e.SHA == "" && e.Field == "" && e.Matched == "" {
+		return fingerprintKey(e.Fingerprint)
+	}
+	return e.Key()
+}
+
+// fingerprintKey returns the index key of a fingerprint, which never equals
+// an entry key.
+func fingerprintKey(fingerprint string) string {
+	return "fingerprint:" + fingerprint
+}
+
+// Suppression is a rule that hides findings. Empty fields match anything.
+type Suppression struct {
+	Repository  string `yaml:"repository,omitempty"`
+	Field       string `yaml:"field,omitempty"`
+	Matched     string `yaml:"matched,omitempty"`
+	Fingerprint string `yaml:"fingerprint,omitempty"`
+	Reason      string `yaml:"reason,omitempty"`
+}
+
+// Matches reports whether the rule covers the given entry.
+func (s Suppression) Matches(e Entry) bool {
+	if s.Repository == "" && s.Field == "" && s.Matched == "" && s.Fingerprint == "" {
+		return false
+	}
+	if s.Fingerprint != "" && s.Fingerprint != e.Fingerprint {
+		return false
+	}
+	if s.Repository != "" && s.Repository != e.Repository {
+		return false
+	}
+	if s.Field != "" && s.Field != e.Field {
+		return false
+	}
+	if s.Matched != "" && !strings.EqualFold(s.Matched, e.Matched) {
+		return false
+	}
+	return true
+}
+
+// Decision is the outcome of triaging a finding.
+type Decision string
+
+const (
+	DecisionAccepted      Decision = "accepted"
+	DecisionFalsePositive Decision = "false_positive"
+	DecisionToFix         Decision = "to_fix"
+)
+
+// Triage records a decision taken on a single finding.
+type Triage struct {
+	Entry     `yaml:",inline"`
+	Decision  Decision  `yaml:"decision"`
+	Note      string    `yaml:"note,omitempty"`
+	DecidedAt time.Time `yaml:"decided_at,omitempty"`
+}
+
+// State bundles baseline entries, suppression rules and triage decisions.
+type State struct {
+	Version      int           `yaml:"version"`
+	Baseline     []Entry       `yaml:"baseline,omitempty"`
+	Suppressions []Suppression `yaml:"suppressions,omitempty"`
+	Triage       []Triage      `yaml:"triage,omitempty"`
+
+	index map[string]bool
+}
+
+// AddBaseline appends baseline entries to the state.
+func (st *State) AddBaseline(entries []Entry) {
+	st.Baseline = append(st.Baseline, entries...)
+	st.index = nil
+}
+
+// LoadDir loads state from a state directory. Missing files are treated as empty.
+func LoadDir(dir string) (*State, error) {
+	st := &State{Version: StateVersion}
+
+	entries, err := loadEntries(filepath.Join(dir, BaselineFile))
+	if err != nil {
+		return nil, err
+	}
+	st.Baseline = entries
+
+	if err := readYAML(filepath.Join(dir, SuppressionsFile), &st.Suppressions); err != nil {
+		return nil, err
+	}
+	if err := readYAML(filepath.Join(dir, TriageFile), &st.Triage); err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
+
+// SaveDir writes state into a state directory.
+func (st *State) SaveDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := saveEntries(filepath.Join(dir, BaselineFile), st.Baseline); err != nil {
+		return err
+	}
+	if err := writeYAML(filepath.Join(dir, SuppressionsFile), st.Suppressions); err != nil {
+		return err
+	}
+	return writeYAML(filepath.Join(dir, TriageFile), st.Triage)
+}
+
+// Export writes the state as a single portable YAML document.
+func (st *State) Export(path string) error {
+	st.Version = StateVersion
+	return writeYAML(path, st)
+}
+
+// Import reads a portable YAML document produced by Export.
+func Import(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var st State
+	if err := yaml.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if st.Version > StateVersion {
+		return nil, fmt.Errorf("state file %s has unsupported version %d", path, st.Version)
+	}
+	return &st, nil
+}
+
+// Merge adds entries from other that are not already present. Triage
+// decisions from other take precedence over existing ones for the same finding.
+func (st *State) Merge(other *State) {
+	seen := make(map[string]bool, len(st.Baseline))
+	for _, e := range st.Baseline {
+		seen[e.id()] = true
+	}
+	for _, e := range other.Baseline {
+		if !seen[e.id()] {
+			st.Baseline = append(st.Baseline, e)
+			seen[e.id()] = true
+		}
+	}
+	st.index = nil
+
+	rules := make(map[Suppression]bool, len(st.Suppressions))
+	for _, s := range st.Suppressions {
+		rules[s] = true
+	}
+	for _, s := range other.Suppressions {
+		if !rules[s] {
+			st.Suppressions = append(st.Suppressions, s)
+			rules[s] = true
+		}
+	}
+
+	index := make(map[string]int, len(st.Triage))
+	for i, t := range st.Triage {
+		index[t.id()] = i
+	}
+	for _, t := range other.Triage {
+		if i, ok := index[t.id()]; ok {
+			st.Triage[i] = t
+			continue
+		}
+		index[t.id()] = len(st.Triage)
+		st.Triage = append(st.Triage, t)
+	}
+}
+
+// Suppressed reports whether a finding is hidden by the state.
+func (st *State) Suppressed(e Entry) bool {
+	if st.index == nil {
+		st.index = make(map[string]bool, len(st.Baseline))
+		for _, b := range st.Baseline {
+			st.index[b.Key()] = true
+			if b.Fingerprint != "" {
+				st.index[fingerprintKey(b.Fingerprint)] = true
+			}
+		}
+	}
+	if st.index[e.Key()] || (e.Fingerprint != "" && st.index[fingerprintKey(e.Fingerprint)]) {
+		return true
+	}
+	for _, s := range st.Suppressions {
+		if s.Matches(e) {
+			return true
+		}
+	}
+	for _, t := range st.Triage {
+		if t.Same(e) && t.Decision != DecisionToFix {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter removes suppressed locations from the result and drops matches left
+// without any location. It returns the number of suppressed locations.
+func (st *State) Filter(result *models.ScanResult) int {
+	suppressed := 0
+	result.RewriteMatches(func(match models.PIIMatch) (models.PIIMatch, bool) {
+		filtered, n := st.FilterMatch(match)
+		suppressed += n
+		return filtered, len(filtered.Locations) > 0
+	})
+	return suppressed
+}
+
+// FilterMatch removes suppressed locations from a single match. It returns the
+// filtered match and the number of suppressed locations; a match left without
+// locations is fully suppressed.
+func (st *State) FilterMatch(match models.PIIMatch) (models.PIIMatch, int) {
+	suppressed := 0
+	var locations []models.Location
+	for _, loc := range match.Locations {
+		if st.Suppressed(EntryFor(match, loc)) {
+			suppressed++
+			continue
+		}
+		locations = append(locations, loc)
+	}
+	match.Locations = locations
+	return match, suppressed
+}
+
+// EntryFor builds the entry identifying a match location.
+func EntryFor(match models.PIIMatch, loc models.Location) Entry {
+	return Entry{
+		Repository: match.Commit.Repository,
+		SHA:        match.Commit.SHA,
+		Field:      loc.Field,
+		Matched:    loc.Matched,
+
+		Fingerprint: loc.Fingerprint,
+	}
+}
+
+func readYAML(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeYAML(path string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
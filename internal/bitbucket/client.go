@@ -0,0 +1,491 @@
+// Package bitbucket provides a Bitbucket Cloud API client for GoGitSomePrivacy.
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/metrics"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+// DefaultBaseURL is the Bitbucket Cloud REST API root.
+const DefaultBaseURL = "https://api.bitbucket.org/2.0"
+
+var tracer = tracing.Tracer("github.com/h4n0sh1/GoGitSomePrivacy/internal/bitbucket")
+
+// ClientConfig contains configuration for the Bitbucket client.
+type ClientConfig struct {
+	// Username and AppPassword authenticate with an app password. Without
+	// them only public repositories are visible, at a lower rate limit.
+	Username    string
+	AppPassword string
+
+	RateLimitPerSecond float64
+	Timeout            time.Duration
+
+	// BaseURL overrides the API root (default DefaultBaseURL).
+	BaseURL string
+}
+
+// Client is a rate-limited Bitbucket Cloud API client. Bitbucket has no user
+// namespaces for repositories, so the scanned username is a workspace slug,
+// and commits are attributed by the Bitbucket nickname of their author.
+type Client struct {
+	http        *http.Client
+	baseURL     string
+	username    string
+	appPassword string
+	rateLimiter *rate.Limiter
+
+	mu       sync.Mutex
+	branches map[string]string // main branch by repository full name
+	latency  time.Duration     // moving average of request durations
+}
+
+// NewClient creates a new Bitbucket API client.
+func NewClient(cfg ClientConfig) *Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	rps := cfg.RateLimitPerSecond
+	if rps <= 0 {
+		rps = 0.25 // 1,000 requests per hour for repository data
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		http:        &http.Client{Timeout: timeout},
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		username:    cfg.Username,
+		appPassword: cfg.AppPassword,
+		rateLimiter: rate.NewLimiter(rate.Limit(rps), 1),
+		branches:    make(map[string]string),
+	}
+}
+
+// Name returns the provider name.
+func (c *Client) Name() string { return "bitbucket" }
+
+// Pace returns the requests per second the rate limiter allows and the
+// average duration of recent requests. Bitbucket does not report its rate
+// limit budget.
+func (c *Client) Pace() (rps float64, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return float64(c.rateLimiter.Limit()), c.latency
+}
+
+func (c *Client) observeLatency(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latency == 0 {
+		c.latency = d
+	} else {
+		c.latency += (d - c.latency) / 5
+	}
+}
+
+// apiError is an error response from the API.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("bitbucket API returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("bitbucket API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// Classify returns the type of an error returned by the client for a
+// Bitbucket response, or an empty type for other errors, such as network
+// failures.
+func Classify(err error) models.ErrorType {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return models.HTTPErrorType(apiErr.StatusCode)
+	}
+	return ""
+}
+
+// notFound reports whether err is a 403 or 404 response, which the scanner
+// treats like an empty result.
+func notFound(err error) bool {
+	apiErr, ok := err.(*apiError)
+	return ok && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusForbidden)
+}
+
+// get fetches rawURL, waiting for the rate limiter, and decodes a JSON
+// response into v unless v is nil, in which case the raw body is returned.
+func (c *Client) get(ctx context.Context, endpoint, rawURL string, v any, attrs ...attribute.KeyValue) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "bitbucket."+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	body, status, err := c.do(ctx, rawURL)
+	metrics.ObserveAPIRequest("bitbucket_"+endpoint, status)
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	if err == nil && v != nil {
+		if err = json.Unmarshal(body, v); err != nil {
+			err = fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	tracing.EndSpan(span, err)
+	return body, err
+}
+
+// do performs a GET request and returns the body of a successful response.
+func (c *Client) do(ctx context.Context, rawURL string) ([]byte, int, error) {
+	start := time.Now()
+	err := c.rateLimiter.Wait(ctx)
+	metrics.ObserveRateLimitWait(time.Since(start))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.username != "" && c.appPassword != "" {
+		req.SetBasicAuth(c.username, c.appPassword)
+	}
+
+	sent := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	c.observeLatency(time.Since(sent))
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return nil, resp.StatusCode, &apiError{StatusCode: resp.StatusCode, Message: e.Error.Message}
+	}
+	return body, resp.StatusCode, nil
+}
+
+// GetUser retrieves the workspace of a Bitbucket user.
+func (c *Client) GetUser(ctx context.Context, username string) (*models.UserProfile, error) {
+	var ws struct {
+		Slug  string `json:"slug"`
+		Name  string `json:"name"`
+		Links struct {
+			Avatar struct {
+				Href string `json:"href"`
+			} `json:"avatar"`
+		} `json:"links"`
+	}
+	u := fmt.Sprintf("%s/workspaces/%s", c.baseURL, url.PathEscape(username))
+	if _, err := c.get(ctx, "get_user", u, &ws, attribute.String("bitbucket.workspace", username)); err != nil {
+		return nil, fmt.Errorf("failed to get workspace %s: %w", username, err)
+	}
+
+	return &models.UserProfile{
+		Login:     ws.Slug,
+		Name:      ws.Name,
+		AvatarURL: ws.Links.Avatar.Href,
+	}, nil
+}
+
+// repository is a repository as returned by the API.
+type repository struct {
+	FullName    string `json:"full_name"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+	Size        int    `json:"size"` // in bytes
+	Parent      *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	MainBranch *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// ListUserRepos lists all public repositories in a user's workspace.
+func (c *Client) ListUserRepos(ctx context.Context, username string) ([]*models.Repository, error) {
+	var allRepos []*models.Repository
+	next := fmt.Sprintf("%s/repositories/%s?pagelen=100", c.baseURL, url.PathEscape(username))
+
+	for next != "" {
+		var page struct {
+			Values []repository `json:"values"`
+			Next   string       `json:"next"`
+		}
+		if _, err := c.get(ctx, "list_repos", next, &page, attribute.String("bitbucket.workspace", username)); err != nil {
+			return nil, fmt.Errorf("failed to list repos for %s: %w", username, err)
+		}
+
+		for _, repo := range page.Values {
+			if repo.IsPrivate {
+				continue
+			}
+			if repo.MainBranch != nil {
+				c.mu.Lock()
+				c.branches[repo.FullName] = repo.MainBranch.Name
+				c.mu.Unlock()
+			}
+			allRepos = append(allRepos, &models.Repository{
+				FullName:    repo.FullName,
+				Name:        repo.Slug,
+				Owner:       username,
+				Description: repo.Description,
+				URL:         repo.Links.HTML.Href,
+				Fork:        repo.Parent != nil,
+				Size:        repo.Size / 1024,
+			})
+		}
+		next = page.Next
+	}
+
+	return allRepos, nil
+}
+
+// mainBranch returns the main branch of a repository, fetching it if it was
+// not seen while listing repositories.
+func (c *Client) mainBranch(ctx context.Context, owner, repo string) (string, error) {
+	fullName := owner + "/" + repo
+	c.mu.Lock()
+	branch, ok := c.branches[fullName]
+	c.mu.Unlock()
+	if ok {
+		return branch, nil
+	}
+
+	var r repository
+	u := fmt.Sprintf("%s/repositories/%s/%s", c.baseURL, url.PathEscape(owner), url.PathEscape(repo))
+	if _, err := c.get(ctx, "get_repo", u, &r, attribute.String("bitbucket.repository", fullName)); err != nil {
+		return "", err
+	}
+	if r.MainBranch != nil {
+		branch = r.MainBranch.Name
+	}
+	c.mu.Lock()
+	c.branches[fullName] = branch
+	c.mu.Unlock()
+	return branch, nil
+}
+
+// commit is a commit as returned by the API.
+type commit struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Date    time.Time `json:"date"`
+	Author  struct {
+		Raw  string `json:"raw"`
+		User *struct {
+			Nickname string `json:"nickname"`
+		} `json:"user"`
+	} `json:"author"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// StreamBranchCommits hands each page of the commits selected by filter on a
+// branch of a repository to fn, newest first, stopping after limit commits
+// (0 means no limit). An empty branch lists the main branch. Missing branches
+// and inaccessible repositories yield no commits. An error returned by fn
+// stops the listing and is returned as is. The API does not report
+// committers, so only authors and co-authors are matched.
+func (c *Client) StreamBranchCommits(ctx context.Context, owner, repo, branch string, filter models.CommitFilter, limit int, fn func([]*models.Commit) error) error {
+	if branch == "" {
+		var err error
+		if branch, err = c.mainBranch(ctx, owner, repo); err != nil {
+			if notFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to get %s/%s: %w", owner, repo, err)
+		}
+	}
+
+	next := fmt.Sprintf("%s/repositories/%s/%s/commits", c.baseURL, url.PathEscape(owner), url.PathEscape(repo))
+	if branch != "" {
+		next += "/" + url.PathEscape(branch)
+	}
+	next += "?pagelen=100"
+
+	total := 0
+	for next != "" {
+		var page struct {
+			Values []commit `json:"values"`
+			Next   string   `json:"next"`
+		}
+		_, err := c.get(ctx, "list_commits", next, &page,
+			attribute.String("bitbucket.repository", owner+"/"+repo),
+			attribute.String("bitbucket.branch", branch))
+		if err != nil {
+			if notFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to list commits in %s/%s: %w", owner, repo, err)
+		}
+
+		// The API cannot filter by author or date, so commits are filtered here
+		var commits []*models.Commit
+		for _, bc := range page.Values {
+			commit := convertCommit(bc, owner, repo)
+			if !filter.Since.IsZero() && commit.Date.Before(filter.Since) {
+				continue
+			}
+			if commit.Roles = filter.Roles(commit); len(commit.Roles) > 0 {
+				commits = append(commits, commit)
+			}
+		}
+		if limit > 0 && total+len(commits) > limit {
+			commits = commits[:limit-total]
+		}
+		total += len(commits)
+
+		if len(commits) > 0 {
+			if err := fn(commits); err != nil {
+				return err
+			}
+		}
+		if limit > 0 && total >= limit {
+			return nil
+		}
+		next = page.Next
+	}
+	return nil
+}
+
+// GetFileContent retrieves a file from a repository's main branch.
+// It returns nil content and no error when the file does not exist.
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, path string) ([]byte, error) {
+	branch, err := c.mainBranch(ctx, owner, repo)
+	if err != nil {
+		if notFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s/%s: %w", owner, repo, err)
+	}
+	if branch == "" {
+		return nil, nil
+	}
+
+	u := fmt.Sprintf("%s/repositories/%s/%s/src/%s/%s", c.baseURL,
+		url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(branch), strings.TrimPrefix(path, "/"))
+	data, err := c.get(ctx, "get_contents", u, nil,
+		attribute.String("bitbucket.repository", owner+"/"+repo),
+		attribute.String("bitbucket.path", path))
+	if err != nil {
+		if notFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s in %s/%s: %w", path, owner, repo, err)
+	}
+	return data, nil
+}
+
+// ListFiles lists the files and directories in dir of a repository's main
+// branch, its root when dir is empty. Missing directories and inaccessible
+// repositories have none.
+func (c *Client) ListFiles(ctx context.Context, owner, repo, dir string) ([]*models.RepoFile, error) {
+	branch, err := c.mainBranch(ctx, owner, repo)
+	if err != nil {
+		if notFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s/%s: %w", owner, repo, err)
+	}
+	if branch == "" {
+		return nil, nil
+	}
+
+	dir = strings.Trim(dir, "/")
+	next := fmt.Sprintf("%s/repositories/%s/%s/src/%s/%s", c.baseURL,
+		url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(branch), dir)
+	if dir != "" {
+		next += "/"
+	}
+	next += "?pagelen=100"
+
+	var files []*models.RepoFile
+	for next != "" {
+		var page struct {
+			Values []struct {
+				Path string `json:"path"`
+				Type string `json:"type"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		_, err := c.get(ctx, "list_contents", next, &page,
+			attribute.String("bitbucket.repository", owner+"/"+repo),
+			attribute.String("bitbucket.path", dir))
+		if err != nil {
+			if notFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to list %s in %s/%s: %w", dir, owner, repo, err)
+		}
+		for _, v := range page.Values {
+			files = append(files, &models.RepoFile{
+				Path: v.Path,
+				Dir:  v.Type == "commit_directory",
+				URL:  fmt.Sprintf("https://bitbucket.org/%s/%s/src/%s/%s", owner, repo, branch, v.Path),
+			})
+		}
+		next = page.Next
+	}
+	return files, nil
+}
+
+func convertCommit(bc commit, owner, repo string) *models.Commit {
+	author := models.Author{Name: bc.Author.Raw}
+	if addr, err := mail.ParseAddress(bc.Author.Raw); err == nil {
+		author = models.Author{Name: addr.Name, Email: addr.Address}
+	}
+	if bc.Author.User != nil {
+		author.Login = bc.Author.User.Nickname
+	}
+
+	return &models.Commit{
+		SHA:        bc.Hash,
+		Repository: owner + "/" + repo,
+		Message:    bc.Message,
+		Trailers:   models.ParseTrailers(bc.Message),
+		Author:     author,
+		Date:       bc.Date,
+		URL:        bc.Links.HTML.Href,
+	}
+}
@@ -2,22 +2,208 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/archive"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/i18n"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/ignore"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/keyring"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/output"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the application configuration.
 type Config struct {
-	GitHub GitHubConfig `yaml:"github"`
-	Scan   ScanConfig   `yaml:"scan"`
+	// Provider selects the hosting service to scan: github (default) or bitbucket.
+	Provider string `yaml:"provider"`
+	// Providers scans the same identity on each of these hosting services
+	// at once and merges the results, in place of Provider.
+	Providers []string `yaml:"providers"`
+
+	GitHub    GitHubConfig    `yaml:"github"`
+	Bitbucket BitbucketConfig `yaml:"bitbucket"`
+	Auth      AuthConfig      `yaml:"auth"`
+
+	Scan    ScanConfig    `yaml:"scan"`
+	Output  OutputConfig  `yaml:"output"`
+	Archive ArchiveConfig `yaml:"archive"`
+	State   StateConfig   `yaml:"state"`
+	Rules   []RuleConfig  `yaml:"rules"`
+
+	Identities []IdentityConfig `yaml:"identities"`
+
+	Ignore IgnoreConfig `yaml:"ignore"`
+
+	Watch WatchConfig `yaml:"watch"`
+
+	// Sinks receive the results of serve and watch scans.
+	Sinks []SinkConfig `yaml:"sinks"`
+
+	// Profiles are named sets of settings selected with --profile, e.g.
+	// quick, deep or ci. A profile holds any sections of the configuration
+	// and is applied over the rest of it: its settings replace those of the
+	// configuration, lists included, and maps are merged.
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+}
+
+// SinkConfig configures where scan results are exported. Type selects the
+// sink and which of the other settings apply.
+type SinkConfig struct {
+	Type string `yaml:"type"` // webhook, s3 or file
+
+	// webhook: results are POSTed as JSON, signed with Secret if set
+	URL      string            `yaml:"url"`
+	Headers  map[string]string `yaml:"headers"`
+	Secret   string            `yaml:"secret"`
+	PerMatch bool              `yaml:"per_match"` // POST each match instead of the whole result
+
+	// s3: results are uploaded to an S3-compatible bucket
+	Endpoint        string `yaml:"endpoint"` // default https://s3.<region>.amazonaws.com
+	Bucket          string `yaml:"bucket"`
+	Region          string `yaml:"region"`
+	Prefix          string `yaml:"prefix"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	PathStyle       bool   `yaml:"path_style"` // address the bucket in the path, as MinIO requires
+
+	// file: results are written to Dir
+	Dir string `yaml:"dir"`
+}
+
+// IgnoreConfig lists known-safe findings that are never reported, in every
+// scanned repository.
+type IgnoreConfig struct {
+	Strings []string `yaml:"strings"` // matched texts (case-insensitive)
+	Regexes []string `yaml:"regexes"` // matched against matched text and context
+	Repos   []string `yaml:"repos"`   // owner/name globs of repositories to skip
+	Paths   []string `yaml:"paths"`   // file path globs, for file-based sources
+}
+
+// WatchConfig contains settings for the watch daemon.
+type WatchConfig struct {
+	// Schedule is a cron expression, @hourly/@daily/@weekly/@monthly or "@every <duration>".
+	Schedule string        `yaml:"schedule"`
+	Store    string        `yaml:"store"`
+	Targets  []WatchTarget `yaml:"targets"`
+	Notify   NotifyConfig  `yaml:"notify"`
+}
+
+// WatchTarget is a user re-scanned on every round.
+type WatchTarget struct {
+	Username   string   `yaml:"username"`
+	FullName   string   `yaml:"full_name"`
+	FirstName  string   `yaml:"first_name"`
+	LastName   string   `yaml:"last_name"`
+	Emails     []string `yaml:"emails"`
+	Identities []string `yaml:"identities"`
+	Exact      bool     `yaml:"exact"`
+}
+
+// NotifyConfig configures where new findings are sent. Unset channels are disabled.
+type NotifyConfig struct {
+	Slack   *SlackConfig   `yaml:"slack"`
+	Webhook *WebhookConfig `yaml:"webhook"`
+	Email   *EmailConfig   `yaml:"email"`
+}
+
+// SlackConfig configures Slack incoming webhook notifications.
+type SlackConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+// WebhookConfig configures generic JSON webhook notifications.
+type WebhookConfig struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// EmailConfig configures email notifications sent over SMTP.
+type EmailConfig struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// IdentityConfig declares a named person or alias to search for.
+type IdentityConfig struct {
+	Name      string   `yaml:"name"`
+	FullName  string   `yaml:"full_name"`
+	FirstName string   `yaml:"first_name"`
+	LastName  string   `yaml:"last_name"`
+	Emails    []string `yaml:"emails"`
+}
+
+// RuleConfig declares a custom PII rule matched by regular expression.
+type RuleConfig struct {
+	Name          string  `yaml:"name"`
+	Regex         string  `yaml:"regex"`
+	PIIType       string  `yaml:"pii_type"`
+	Weight        float64 `yaml:"weight"`
+	CaseSensitive bool    `yaml:"case_sensitive"`
 }
 
 // GitHubConfig contains GitHub API settings.
 type GitHubConfig struct {
-	Token              string  `yaml:"token"`
+	Token string `yaml:"token"`
+	// Tokens are rotated with Token to add up their rate limits.
+	Tokens             []string `yaml:"tokens"`
+	RateLimitPerSecond float64  `yaml:"rate_limit_per_second"`
+	// RateLimitBurst is the number of requests sent at once before
+	// RateLimitPerSecond paces them.
+	RateLimitBurst int `yaml:"rate_limit_burst"`
+	// Search requests have budgets of their own, apart from
+	// RateLimitPerSecond.
+	SearchRateLimitPerMinute     float64 `yaml:"search_rate_limit_per_minute"`
+	CodeSearchRateLimitPerMinute float64 `yaml:"code_search_rate_limit_per_minute"`
+	TimeoutSeconds               int     `yaml:"timeout_seconds"`
+	// PageConcurrency is the number of pages of a repository's commit
+	// listing fetched at a time.
+	PageConcurrency int `yaml:"page_concurrency"`
+
+	// BaseURL and UploadURL select a GitHub Enterprise Server instance.
+	BaseURL   string `yaml:"base_url"`
+	UploadURL string `yaml:"upload_url"`
+}
+
+// AllTokens returns Token followed by Tokens, without empty and repeated ones.
+func (g GitHubConfig) AllTokens() []string {
+	var tokens []string
+	for _, t := range append([]string{g.Token}, g.Tokens...) {
+		if t = strings.TrimSpace(t); t != "" && !slices.Contains(tokens, t) {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// AuthConfig selects where `auth login` stores the GitHub token, which is
+// read from there when no token is configured.
+type AuthConfig struct {
+	// Keyring is auto (the system keyring, else the encrypted file),
+	// system, file or off.
+	Keyring string `yaml:"keyring"`
+	// File is the encrypted file used without a system keyring.
+	File string `yaml:"file"`
+}
+
+// BitbucketConfig contains Bitbucket Cloud API settings. Username and
+// AppPassword authenticate with an app password with repository read access.
+type BitbucketConfig struct {
+	Username           string  `yaml:"username"`
+	AppPassword        string  `yaml:"app_password"`
 	RateLimitPerSecond float64 `yaml:"rate_limit_per_second"`
 	TimeoutSeconds     int     `yaml:"timeout_seconds"`
 }
@@ -25,32 +211,220 @@ type GitHubConfig struct {
 // ScanConfig contains scanning settings.
 type ScanConfig struct {
 	MaxWorkers       int  `yaml:"max_workers"`
+	DetectionWorkers int  `yaml:"detection_workers"` // goroutines scanning fetched commits, 0 for GOMAXPROCS
 	ContextSize      int  `yaml:"context_size"`
 	CaseSensitive    bool `yaml:"case_sensitive"`
-	IncludeAuthor    bool `yaml:"include_author"`
-	IncludeCommitter bool `yaml:"include_committer"`
+	ExpandNicknames  bool `yaml:"expand_nicknames"`  // also search known nicknames of first names
+	IncludeAuthor    bool `yaml:"include_author"`    // scan commits the user authored
+	IncludeCommitter bool `yaml:"include_committer"` // scan commits the user committed for others
+	IncludeCoAuthor  bool `yaml:"include_co_author"` // scan commits crediting the user as Co-authored-by
+	ScanPages        bool `yaml:"scan_pages"`
+	MaxPages         int  `yaml:"max_pages"`
+	SkipForks        bool `yaml:"skip_forks"`
+
+	// Fields toggles the commit fields scanned: message, trailers,
+	// author_name, committer_name, author_email and committer_email. Fields
+	// not listed are scanned.
+	Fields map[string]bool `yaml:"fields"`
+
+	// Discovery selects how repositories are found: repos, search, both or
+	// none.
+	Discovery string `yaml:"discovery"`
+	// EmailDiscovery also searches commits by the configured emails across
+	// the provider, whichever account made them.
+	EmailDiscovery bool `yaml:"email_discovery"`
+	// ScanEvents also scans the user's recent public events: pushes,
+	// comments, issues, pull requests, releases and created repositories.
+	ScanEvents bool `yaml:"scan_events"`
+	// ScanRefs also scans release names and notes, annotated tag messages
+	// and branch names of every repository.
+	ScanRefs bool `yaml:"scan_refs"`
+	// ScanFiles also scans the package manifests, author lists and GitHub
+	// Actions workflows of the default branch of every repository.
+	ScanFiles bool `yaml:"scan_files"`
+	// CodeSearch also searches file contents for the configured emails and
+	// full names with code search: repos (the user's repositories and those
+	// scanned), global (all public repositories) or empty to skip it.
+	CodeSearch string `yaml:"code_search"`
+	// PRContext links the matches found in commit messages to the pull
+	// requests including their commit, and scans the title and description
+	// of those pull requests.
+	PRContext bool `yaml:"pr_context"`
+	// RepoTimeoutSeconds caps the time spent fetching one repository; 0 means
+	// no limit. MaxRepoErrors is the number of consecutive failed attempts
+	// after which a repository is skipped.
+	RepoTimeoutSeconds int `yaml:"repo_timeout_seconds"`
+	MaxRepoErrors      int `yaml:"max_repo_errors"`
+	// MaxCommitsPerRepo scans only the latest commits of each repository;
+	// 0 means all. Sample instead scans about this many commits picked
+	// across the history of each repository, for a quick triage pass.
+	MaxCommitsPerRepo int `yaml:"max_commits_per_repo"`
+	Sample            int `yaml:"sample"`
+	// Incremental re-scans only the commits pushed since the previous scan
+	// recorded in the results store (scan --store and watch).
+	Incremental bool `yaml:"incremental"`
+	// Spool keeps matches in a temporary file in SpoolDir (the system
+	// temporary directory when empty) instead of memory, for very large
+	// scans.
+	Spool    bool   `yaml:"spool"`
+	SpoolDir string `yaml:"spool_dir"`
+
+	RespectIgnoreFiles bool `yaml:"respect_ignore_files"`
+	// CheckEmailConfig flags commits made with a personal email address
+	// rather than the GitHub noreply one.
+	CheckEmailConfig bool `yaml:"check_email_config"`
+	// CheckGravatar flags commit emails and hashes sharing the Gravatar hash
+	// of a configured email.
+	CheckGravatar bool `yaml:"check_gravatar"`
+	// CheckSignatures flags the user IDs of the GPG keys commits were signed
+	// with that expose a personal email or a configured name the commits do
+	// not show.
+	CheckSignatures bool `yaml:"check_signatures"`
+	// Associations reports the public organizations of the user and the
+	// CODEOWNERS and .mailmap entries linking the username to an identity.
+	Associations bool `yaml:"associations"`
+
+	PostProcessors []string `yaml:"post_processors"`
+	Allowlist      []string `yaml:"allowlist"`
+	MinConfidence  float64  `yaml:"min_confidence"`
+	CommonWords    string   `yaml:"common_words"`
+	// BotCommits selects how bot-generated and templated commits, such as
+	// dependency updates and merge commits, are treated: off, downgrade or
+	// skip.
+	BotCommits string `yaml:"bot_commits"`
+}
+
+// OutputConfig contains settings of the scan output.
+type OutputConfig struct {
+	// Format is the output format used when --output is not given
+	// (default json).
+	Format string `yaml:"format"`
+	// ContextSizes sets the characters of context kept on each side of
+	// matches by output format; other formats keep scan.context_size.
+	ContextSizes map[string]int `yaml:"context_sizes"`
+	// Lang is the language of the text, markdown and html output (default en).
+	Lang string `yaml:"lang"`
+}
+
+// ArchiveConfig selects the hours of GH Archive scanned for the user's past
+// pushes. Nothing is scanned without From.
+type ArchiveConfig struct {
+	// From and To are the first and last day (2006-01-02) or hour
+	// (2006-01-02T15) scanned, in UTC; To defaults to the day of From.
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+	// BaseURL is the location of the hourly dumps (default GH Archive's).
+	BaseURL string `yaml:"base_url"`
+	// Concurrency is the number of hours downloaded at a time.
+	Concurrency int `yaml:"concurrency"`
+}
+
+// ContextSize returns the characters of context kept around matches in the
+// given output format.
+func (c *Config) ContextSize(format string) int {
+	if format == "md" {
+		format = "markdown"
+	}
+	if size, ok := c.Output.ContextSizes[format]; ok {
+		return size
+	}
+	return c.Scan.ContextSize
+}
+
+// MaxContextSize returns the largest context size of any output format, which
+// is the context extracted by scans.
+func (c *Config) MaxContextSize() int {
+	size := c.Scan.ContextSize
+	for _, s := range c.Output.ContextSizes {
+		size = max(size, s)
+	}
+	return size
+}
+
+// StateConfig contains settings for baselines, suppressions and triage decisions.
+type StateConfig struct {
+	Dir string `yaml:"dir"`
 }
 
 // DefaultConfig returns the default configuration.
 func DefaultConfig() *Config {
 	return &Config{
+		Provider: "github",
 		GitHub: GitHubConfig{
-			Token:              "",
-			RateLimitPerSecond: 1.3,
+			Token:                        "",
+			RateLimitPerSecond:           1.3,
+			RateLimitBurst:               1,
+			SearchRateLimitPerMinute:     30,
+			CodeSearchRateLimitPerMinute: 10,
+			TimeoutSeconds:               30,
+			PageConcurrency:              4,
+		},
+		Bitbucket: BitbucketConfig{
+			RateLimitPerSecond: 0.25,
 			TimeoutSeconds:     30,
 		},
+		Auth: AuthConfig{
+			Keyring: "auto",
+			File:    filepath.Join(os.Getenv("HOME"), ".config", "gogitsomeprivacy", "credentials.enc"),
+		},
 		Scan: ScanConfig{
 			MaxWorkers:       10,
 			ContextSize:      50,
 			CaseSensitive:    false,
 			IncludeAuthor:    true,
-			IncludeCommitter: true,
+			IncludeCommitter: false,
+			IncludeCoAuthor:  false,
+			ScanPages:        false,
+			MaxPages:         100,
+			MaxRepoErrors:    3,
+
+			RespectIgnoreFiles: true,
+			CheckEmailConfig:   true,
+
+			PostProcessors: []string{"dedupe"},
+			CommonWords:    "downgrade",
+			BotCommits:     "off",
+			Discovery:      "repos",
+		},
+		Output: OutputConfig{
+			ContextSizes: map[string]int{"text": 30, "template": 120},
+		},
+		State: StateConfig{
+			Dir: filepath.Join(os.Getenv("HOME"), ".config", "gogitsomeprivacy", "state"),
+		},
+		Watch: WatchConfig{
+			Schedule: "@daily",
+			Store:    filepath.Join(os.Getenv("HOME"), ".config", "gogitsomeprivacy", "watch.db"),
 		},
 	}
 }
 
-// Load loads configuration from file and environment variables.
+// Load loads configuration from file and environment variables. Settings
+// are taken from the GGSP_ environment variables, then the file, then the
+// defaults; command-line flags override all of them.
 func Load(configPath string) (*Config, error) {
+	return LoadProfile(configPath, "")
+}
+
+// LoadProfile loads configuration like Load, applying the named profile of
+// the configuration file, if any, before the environment variables. An empty
+// profile selects the one named by GGSP_PROFILE. Without a configured GitHub
+// token, the one stored by `auth login` is read from the keyring.
+func LoadProfile(configPath, profile string) (*Config, error) {
+	cfg, err := LoadSettings(configPath, profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.LoadKeyringToken(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadSettings loads configuration like LoadProfile without reading the
+// keyring, for commands that do not call the API or that take the token
+// from elsewhere first (see LoadKeyringToken).
+func LoadSettings(configPath, profile string) (*Config, error) {
 	cfg := DefaultConfig()
 
 	// Try to load from config file
@@ -75,12 +449,101 @@ func Load(configPath string) (*Config, error) {
 		}
 	}
 
+	if profile == "" {
+		profile = os.Getenv(EnvPrefix + "PROFILE")
+	}
+	if profile != "" {
+		if err := cfg.ApplyProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+
 	// Override with environment variables
-	loadFromEnv(cfg)
+	if err := loadFromEnv(cfg); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
 
+// DefaultPath returns the default user configuration file path.
+func DefaultPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "gogitsomeprivacy", "config.yaml")
+}
+
+// TokenAccount names the GitHub token of the configured instance in the
+// keyring: the host of github.base_url, or github.com.
+func (c *Config) TokenAccount() string {
+	if u, err := url.Parse(c.GitHub.BaseURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "github.com"
+}
+
+// TokenStores returns the stores the GitHub token is read from, in order, as
+// selected by auth.keyring. Tokens are stored in the first that accepts them.
+func (c *Config) TokenStores() ([]keyring.Store, error) {
+	file := &keyring.File{Path: c.Auth.File, Passphrase: keyring.Passphrase}
+	switch c.Auth.Keyring {
+	case "off":
+		return nil, nil
+	case "file":
+		return []keyring.Store{file}, nil
+	case "system":
+		sys, err := keyring.System()
+		if err != nil {
+			return nil, err
+		}
+		return []keyring.Store{sys}, nil
+	default:
+		if sys, err := keyring.System(); err == nil {
+			return []keyring.Store{sys, file}, nil
+		}
+		return []keyring.Store{file}, nil
+	}
+}
+
+// LoadKeyringToken reads the GitHub token from the token stores unless one
+// is configured. Stores that need a passphrase or a keyring daemon are only
+// asked when there is no other token.
+func (c *Config) LoadKeyringToken() error {
+	if c.GitHub.Token != "" || len(c.GitHub.Tokens) > 0 || c.Provider == "bitbucket" {
+		return nil
+	}
+	stores, err := c.TokenStores()
+	if err != nil {
+		return fmt.Errorf("auth.keyring: %w", err)
+	}
+	for _, s := range stores {
+		token, err := s.Get(c.TokenAccount())
+		if errors.Is(err, keyring.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the GitHub token from the %s: %w", s.Name(), err)
+		}
+		c.GitHub.Token = token
+		return nil
+	}
+	return nil
+}
+
+// ApplyProfile applies the settings of the named profile over c.
+func (c *Config) ApplyProfile(name string) error {
+	node, ok := c.Profiles[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(c.Profiles))
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %q: the configuration defines no profiles", name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	if err := node.Decode(c); err != nil {
+		return fmt.Errorf("invalid profile %q: %w", name, err)
+	}
+	return nil
+}
+
 func loadFromFile(cfg *Config, path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -89,26 +552,426 @@ func loadFromFile(cfg *Config, path string) error {
 	return yaml.Unmarshal(data, cfg)
 }
 
-func loadFromEnv(cfg *Config) {
+func loadFromEnv(cfg *Config) error {
 	// GitHub token from environment
 	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
 		cfg.GitHub.Token = token
 	}
-	if token := os.Getenv("GGSP_GITHUB_TOKEN"); token != "" {
-		cfg.GitHub.Token = token
+	if tokens := os.Getenv("GITHUB_TOKENS"); tokens != "" {
+		cfg.GitHub.Tokens = strings.Split(tokens, ",")
+	}
+
+	// Bitbucket app password from environment
+	if username := os.Getenv("BITBUCKET_USERNAME"); username != "" {
+		cfg.Bitbucket.Username = username
+	}
+	if password := os.Getenv("BITBUCKET_APP_PASSWORD"); password != "" {
+		cfg.Bitbucket.AppPassword = password
 	}
+
+	// S3 sink credentials, unless set in the config file
+	for i := range cfg.Sinks {
+		s := &cfg.Sinks[i]
+		if s.Type != "s3" || s.AccessKeyID != "" {
+			continue
+		}
+		s.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		s.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+
+	// GGSP_ variables, overriding the names above
+	return loadFromPrefixedEnv(cfg)
+}
+
+// ForProvider returns a copy of the configuration scanning provider alone, as
+// each scan of Providers does. Settings the provider does not support are
+// turned off rather than rejected, so that one configuration serves every
+// provider listed.
+func (c *Config) ForProvider(provider string) *Config {
+	cp := *c
+	cp.Provider = provider
+	cp.Providers = nil
+	if provider == "bitbucket" {
+		cp.Scan.ScanPages = false
+		cp.Scan.EmailDiscovery = false
+		cp.Scan.ScanEvents = false
+		cp.Scan.ScanRefs = false
+		cp.Scan.CodeSearch = ""
+		cp.Scan.PRContext = false
+		cp.Scan.CheckSignatures = false
+		cp.Scan.Incremental = false
+		cp.Archive.From, cp.Archive.To = "", ""
+		if cp.Scan.Discovery == "search" || cp.Scan.Discovery == "both" {
+			cp.Scan.Discovery = "repos"
+		}
+	}
+	return &cp
 }
 
 // Validate validates the configuration.
 func (c *Config) Validate() error {
+	for i, p := range c.Providers {
+		if slices.Contains(c.Providers[:i], p) {
+			return fmt.Errorf("providers: %s is listed twice", p)
+		}
+		if err := c.ForProvider(p).Validate(); err != nil {
+			return fmt.Errorf("providers: %s: %w", p, err)
+		}
+	}
 	if c.Scan.MaxWorkers < 1 {
 		return fmt.Errorf("max_workers must be at least 1")
 	}
+	if c.Scan.DetectionWorkers < 0 {
+		return fmt.Errorf("detection_workers must not be negative")
+	}
 	if c.GitHub.RateLimitPerSecond <= 0 {
 		return fmt.Errorf("rate_limit_per_second must be positive")
 	}
+	if c.GitHub.RateLimitBurst < 1 {
+		return fmt.Errorf("rate_limit_burst must be at least 1")
+	}
+	if c.GitHub.SearchRateLimitPerMinute <= 0 {
+		return fmt.Errorf("search_rate_limit_per_minute must be positive")
+	}
+	if c.GitHub.CodeSearchRateLimitPerMinute <= 0 {
+		return fmt.Errorf("code_search_rate_limit_per_minute must be positive")
+	}
 	if c.GitHub.TimeoutSeconds < 1 {
 		return fmt.Errorf("timeout_seconds must be at least 1")
 	}
+	if c.GitHub.PageConcurrency < 1 {
+		return fmt.Errorf("page_concurrency must be at least 1")
+	}
+	if err := validateURL("github.base_url", c.GitHub.BaseURL); err != nil {
+		return err
+	}
+	if err := validateURL("github.upload_url", c.GitHub.UploadURL); err != nil {
+		return err
+	}
+	if c.GitHub.UploadURL != "" && c.GitHub.BaseURL == "" {
+		return fmt.Errorf("github.upload_url requires github.base_url")
+	}
+	switch c.Auth.Keyring {
+	case "", "auto", "system", "file", "off":
+	default:
+		return fmt.Errorf("auth.keyring must be auto, system, file or off")
+	}
+	switch c.Provider {
+	case "", "github":
+	case "bitbucket":
+		if c.Bitbucket.RateLimitPerSecond <= 0 {
+			return fmt.Errorf("bitbucket.rate_limit_per_second must be positive")
+		}
+		if c.Bitbucket.TimeoutSeconds < 1 {
+			return fmt.Errorf("bitbucket.timeout_seconds must be at least 1")
+		}
+		if (c.Bitbucket.Username == "") != (c.Bitbucket.AppPassword == "") {
+			return fmt.Errorf("bitbucket.username and bitbucket.app_password must be set together")
+		}
+		if c.Scan.ScanPages {
+			return fmt.Errorf("scan_pages is only supported with the github provider")
+		}
+	default:
+		return fmt.Errorf("provider must be github or bitbucket")
+	}
+	if c.Scan.RepoTimeoutSeconds < 0 {
+		return fmt.Errorf("repo_timeout_seconds must not be negative")
+	}
+	if c.Scan.MaxRepoErrors < 1 {
+		return fmt.Errorf("max_repo_errors must be at least 1")
+	}
+	if c.Scan.MaxCommitsPerRepo < 0 {
+		return fmt.Errorf("max_commits_per_repo must not be negative")
+	}
+	if c.Scan.Sample < 0 {
+		return fmt.Errorf("sample must not be negative")
+	}
+	if c.Scan.MaxCommitsPerRepo > 0 && c.Scan.Sample > 0 {
+		return fmt.Errorf("max_commits_per_repo and sample cannot be used together")
+	}
+	if c.Scan.Incremental && (c.Scan.MaxCommitsPerRepo > 0 || c.Scan.Sample > 0) {
+		// The next incremental scan would never fetch the commits skipped
+		return fmt.Errorf("incremental cannot be combined with max_commits_per_repo or sample")
+	}
+	if c.Scan.MinConfidence < 0 || c.Scan.MinConfidence > 1 {
+		return fmt.Errorf("min_confidence must be between 0 and 1")
+	}
+	if !c.Scan.IncludeAuthor && !c.Scan.IncludeCommitter && !c.Scan.IncludeCoAuthor {
+		return fmt.Errorf("at least one of include_author, include_committer and include_co_author must be set")
+	}
+	if c.Scan.EmailDiscovery && c.Provider == "bitbucket" {
+		return fmt.Errorf("email_discovery is only supported with the github provider")
+	}
+	if c.Scan.ScanEvents && c.Provider == "bitbucket" {
+		return fmt.Errorf("scan_events is only supported with the github provider")
+	}
+	if c.Scan.ScanRefs && c.Provider == "bitbucket" {
+		return fmt.Errorf("scan_refs is only supported with the github provider")
+	}
+	if c.Scan.PRContext && c.Provider == "bitbucket" {
+		return fmt.Errorf("pr_context is only supported with the github provider")
+	}
+	if c.Scan.CheckSignatures && c.Provider == "bitbucket" {
+		return fmt.Errorf("check_signatures is only supported with the github provider")
+	}
+	if c.Archive.From != "" {
+		if _, _, err := archive.ParseRange(c.Archive.From, c.Archive.To); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+	} else if c.Archive.To != "" {
+		return fmt.Errorf("archive: to requires from")
+	}
+	switch c.Scan.CodeSearch {
+	case "", "off":
+	case "repos", "global":
+		if c.Provider == "bitbucket" {
+			return fmt.Errorf("code_search is only supported with the github provider")
+		}
+	default:
+		return fmt.Errorf("code_search must be repos, global or off")
+	}
+	if c.Scan.Incremental && c.Provider == "bitbucket" {
+		return fmt.Errorf("incremental is only supported with the github provider")
+	}
+	switch c.Scan.Discovery {
+	case "", "repos", "none":
+	case "search", "both":
+		if c.Provider == "bitbucket" {
+			return fmt.Errorf("discovery %q is only supported with the github provider", c.Scan.Discovery)
+		}
+	default:
+		return fmt.Errorf("discovery must be repos, search, both or none")
+	}
+	if _, ok := output.Lookup(c.Output.Format); c.Output.Format != "" && !ok {
+		return fmt.Errorf("output.format must be one of %s", strings.Join(output.Names(), ", "))
+	}
+	if _, err := i18n.New(c.Output.Lang); err != nil {
+		return fmt.Errorf("output.lang: %w", err)
+	}
+	for format, size := range c.Output.ContextSizes {
+		if f, ok := output.Lookup(format); !ok || f.Name != format {
+			return fmt.Errorf("output.context_sizes: unknown format %q", format)
+		}
+		if size < 0 {
+			return fmt.Errorf("output.context_sizes.%s must not be negative", format)
+		}
+	}
+	switch c.Scan.CommonWords {
+	case "", "downgrade", "suppress", "off":
+	default:
+		return fmt.Errorf("common_words must be downgrade, suppress or off")
+	}
+	if _, err := pii.ParseBotCommitMode(c.Scan.BotCommits); err != nil {
+		return fmt.Errorf("bot_commits must be off, downgrade or skip")
+	}
+	for field := range c.Scan.Fields {
+		if err := pii.ValidateField(field); err != nil {
+			return fmt.Errorf("fields: %w", err)
+		}
+	}
+	if len(pii.Fields(c.Scan.Fields).Disabled()) == len(pii.CommitFields) {
+		return fmt.Errorf("fields must enable at least one field")
+	}
+	for i, rule := range c.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rules[%d]: name is required", i)
+		}
+		if rule.Regex == "" {
+			return fmt.Errorf("rule %q: regex is required", rule.Name)
+		}
+		if _, err := regexp.Compile(rule.Regex); err != nil {
+			return fmt.Errorf("rule %q: invalid regex: %w", rule.Name, err)
+		}
+		if rule.Weight < 0 || rule.Weight > 1 {
+			return fmt.Errorf("rule %q: weight must be between 0 and 1", rule.Name)
+		}
+	}
+	if _, err := c.IgnoreRules(); err != nil {
+		return fmt.Errorf("ignore: %w", err)
+	}
+	for i, t := range c.Watch.Targets {
+		if t.Username == "" {
+			return fmt.Errorf("watch.targets[%d]: username is required", i)
+		}
+	}
+	for i, s := range c.Sinks {
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("sinks[%d]: %w", i, err)
+		}
+	}
+	seen := make(map[string]bool)
+	for i, id := range c.Identities {
+		if id.Name == "" {
+			return fmt.Errorf("identities[%d]: name is required", i)
+		}
+		if seen[id.Name] {
+			return fmt.Errorf("identity %q is defined more than once", id.Name)
+		}
+		seen[id.Name] = true
+	}
+	return nil
+}
+
+// CommitRoles returns the commit roles selected by the include_* settings.
+func (c *Config) CommitRoles() []models.CommitRole {
+	var roles []models.CommitRole
+	if c.Scan.IncludeAuthor {
+		roles = append(roles, models.RoleAuthor)
+	}
+	if c.Scan.IncludeCommitter {
+		roles = append(roles, models.RoleCommitter)
+	}
+	if c.Scan.IncludeCoAuthor {
+		roles = append(roles, models.RoleCoAuthor)
+	}
+	return roles
+}
+
+// validate checks the settings required by the sink type.
+func (s SinkConfig) validate() error {
+	switch s.Type {
+	case "webhook":
+		if s.URL == "" {
+			return fmt.Errorf("url is required")
+		}
+		return validateURL("url", s.URL)
+	case "s3":
+		if s.Bucket == "" {
+			return fmt.Errorf("bucket is required")
+		}
+		if s.Endpoint == "" && s.Region == "" {
+			return fmt.Errorf("endpoint or region is required")
+		}
+		if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+			return fmt.Errorf("access_key_id and secret_access_key are required")
+		}
+		return validateURL("endpoint", s.Endpoint)
+	case "file":
+		if s.Dir == "" {
+			return fmt.Errorf("dir is required")
+		}
+		return nil
+	default:
+		return fmt.Errorf("type must be webhook, s3 or file")
+	}
+}
+
+// validateURL checks that a non-empty setting is an absolute http(s) URL.
+func validateURL(name, value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("%s must be an http or https URL", name)
+	}
 	return nil
 }
+
+// SelectIdentities converts the configured identities into search identities.
+// If names is non-empty, only the identities with those names are returned.
+func (c *Config) SelectIdentities(names []string) ([]models.Identity, error) {
+	byName := make(map[string]IdentityConfig, len(c.Identities))
+	for _, id := range c.Identities {
+		byName[id.Name] = id
+	}
+
+	selected := c.Identities
+	if len(names) > 0 {
+		selected = nil
+		for _, name := range names {
+			id, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown identity %q", name)
+			}
+			selected = append(selected, id)
+		}
+	}
+
+	ids := make([]models.Identity, 0, len(selected))
+	for _, id := range selected {
+		ids = append(ids, models.Identity{
+			Name:      id.Name,
+			FirstName: id.FirstName,
+			LastName:  id.LastName,
+			FullName:  id.FullName,
+			Emails:    id.Emails,
+		})
+	}
+	return ids, nil
+}
+
+// CustomRules converts the configured rules into detector rules.
+func (c *Config) CustomRules() []models.Rule {
+	rules := make([]models.Rule, 0, len(c.Rules))
+	for _, r := range c.Rules {
+		rules = append(rules, models.Rule{
+			Name:          r.Name,
+			Pattern:       r.Regex,
+			PIIType:       models.PIIType(r.PIIType),
+			Weight:        r.Weight,
+			CaseSensitive: r.CaseSensitive,
+		})
+	}
+	return rules
+}
+
+// IgnoreRules compiles the ignore section.
+func (c *Config) IgnoreRules() (*ignore.Rules, error) {
+	return ignore.New(ignore.Spec{
+		Texts:   c.Ignore.Strings,
+		Regexes: c.Ignore.Regexes,
+		Paths:   c.Ignore.Paths,
+		Repos:   c.Ignore.Repos,
+	})
+}
+
+// SearchOptions are the names and emails to search for, as given on the command
+// line or in a request, plus the names of configured identities to include.
+type SearchOptions struct {
+	FullName      string
+	FirstName     string
+	LastName      string
+	Emails        []string
+	Identities    []string
+	Exact         bool // don't split FullName into first and last names
+	CaseSensitive bool
+	// ExpandNicknames also searches the nicknames of the first names.
+	ExpandNicknames bool
+}
+
+// ErrNoCriteria is returned by Criteria when there is nothing to search for.
+var ErrNoCriteria = errors.New("no name, email, identity or rule to search for")
+
+// Criteria builds search criteria from opts, the selected identities and the
+// custom rules. Unless opts.Exact is set, a full name given without first and
+// last names is split into them.
+func (c *Config) Criteria(opts SearchOptions) (models.PIISearchCriteria, error) {
+	ids, err := c.SelectIdentities(opts.Identities)
+	if err != nil {
+		return models.PIISearchCriteria{}, err
+	}
+
+	criteria := models.PIISearchCriteria{
+		FirstName:     opts.FirstName,
+		LastName:      opts.LastName,
+		FullName:      opts.FullName,
+		Emails:        opts.Emails,
+		CaseSensitive: c.Scan.CaseSensitive || opts.CaseSensitive,
+		Rules:         c.CustomRules(),
+		Identities:    ids,
+
+		ExpandNicknames: c.Scan.ExpandNicknames || opts.ExpandNicknames,
+	}
+	if criteria.FullName != "" && !opts.Exact && criteria.FirstName == "" && criteria.LastName == "" {
+		if parts := strings.Fields(criteria.FullName); len(parts) >= 2 {
+			criteria.FirstName = parts[0]
+			criteria.LastName = parts[len(parts)-1]
+		}
+	}
+
+	if len(criteria.AllIdentities()) == 0 && len(criteria.Rules) == 0 {
+		return models.PIISearchCriteria{}, ErrNoCriteria
+	}
+	return criteria, nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// initConfig is the commented configuration written by Init.
+//
+//go:embed init.yaml
+var initConfig []byte
+
+// ErrExists is returned by Init when the file exists and is not overwritten.
+var ErrExists = errors.New("config file already exists")
+
+// Init writes a commented configuration with example profiles to path, with
+// owner-only permissions like Save. An existing file is only replaced with
+// overwrite.
+func Init(path string, overwrite bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrExists, path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	if _, err := f.Write(initConfig); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return f.Close()
+}
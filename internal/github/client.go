@@ -3,41 +3,101 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v58/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/metrics"
 	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 	"golang.org/x/time/rate"
 )
 
+var tracer = tracing.Tracer("github.com/h4n0sh1/GoGitSomePrivacy/internal/github")
+
 // ClientConfig contains configuration for the GitHub client.
 type ClientConfig struct {
-	Token              string
+	Token string
+	// Tokens are rotated with Token, each request using the one with the
+	// most rate limit left. RateLimitPerSecond applies to each token.
+	Tokens             []string
 	RateLimitPerSecond float64
-	Timeout            time.Duration
+	// RateLimitBurst is the number of requests sent at once before
+	// RateLimitPerSecond paces them (default 1).
+	RateLimitBurst int
+	// SearchRateLimitPerMinute and CodeSearchRateLimitPerMinute pace the
+	// search API, which has budgets of its own (default 30 and 10 per
+	// minute and token), apart from the requests of RateLimitPerSecond.
+	// Unauthenticated commit searches are limited to 10 per minute.
+	SearchRateLimitPerMinute     float64
+	CodeSearchRateLimitPerMinute float64
+	Timeout                      time.Duration
+	// PageConcurrency is the number of pages of a commit listing fetched at
+	// a time (default 4), so that repositories with many commits are not
+	// listed one page after another. Requests are still paced by the rate
+	// limiter.
+	PageConcurrency int
+
+	// BaseURL and UploadURL point the client at a GitHub Enterprise Server
+	// instance, e.g. https://github.example.com/ (the /api/v3/ suffix is
+	// added when missing). UploadURL defaults to BaseURL.
+	BaseURL   string
+	UploadURL string
 }
 
 // Client wraps the GitHub API client with rate limiting.
 type Client struct {
 	client      *github.Client
 	rateLimiter *rate.Limiter
+	search      *rate.Limiter // commit search requests
+	codeSearch  *rate.Limiter // code search requests
 	timeout     time.Duration
+	token       bool
+	latency     *latencyTransport
+	pages       int // concurrent page requests of a listing
+
+	mu   sync.Mutex
+	rate RateStatus
+}
+
+// RateStatus is the API rate-limit budget reported by the last response.
+type RateStatus struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
 }
 
 // NewClient creates a new GitHub API client.
-func NewClient(cfg ClientConfig) *Client {
+func NewClient(cfg ClientConfig) (*Client, error) {
 	var httpClient *http.Client
 
-	if cfg.Token != "" {
+	var tokens []string
+	for _, t := range append([]string{cfg.Token}, cfg.Tokens...) {
+		if t != "" && !slices.Contains(tokens, t) {
+			tokens = append(tokens, t)
+		}
+	}
+	switch len(tokens) {
+	case 0:
+		httpClient = &http.Client{}
+	case 1:
 		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: cfg.Token},
+			&oauth2.Token{AccessToken: tokens[0]},
 		)
 		httpClient = oauth2.NewClient(context.Background(), ts)
-	} else {
-		httpClient = http.DefaultClient
+	default:
+		httpClient = &http.Client{Transport: newTokenPool(tokens, http.DefaultTransport)}
 	}
 
 	if cfg.Timeout > 0 {
@@ -46,32 +106,265 @@ func NewClient(cfg ClientConfig) *Client {
 		httpClient.Timeout = 30 * time.Second
 	}
 
+	latency := &latencyTransport{base: httpClient.Transport}
+	if latency.base == nil {
+		latency.base = http.DefaultTransport
+	}
+	httpClient.Transport = latency
+
 	// Create rate limiter
 	rps := cfg.RateLimitPerSecond
 	if rps <= 0 {
 		rps = 1.0 // Default: 1 request per second
 	}
-	limiter := rate.NewLimiter(rate.Limit(rps), 1)
+	rps *= float64(max(1, len(tokens)))
+	limiter := rate.NewLimiter(rate.Limit(rps), max(1, cfg.RateLimitBurst))
+	search := searchLimiter(cfg.SearchRateLimitPerMinute, 30, len(tokens))
+	if len(tokens) == 0 {
+		search = searchLimiter(min(cfg.SearchRateLimitPerMinute, 10), 10, 0)
+	}
+	codeSearch := searchLimiter(cfg.CodeSearchRateLimitPerMinute, 10, len(tokens))
+
+	client := github.NewClient(httpClient)
+	if cfg.BaseURL != "" {
+		uploadURL := cfg.UploadURL
+		if uploadURL == "" {
+			uploadURL = cfg.BaseURL
+		}
+		var err error
+		if client, err = client.WithEnterpriseURLs(cfg.BaseURL, uploadURL); err != nil {
+			return nil, fmt.Errorf("invalid GitHub Enterprise URL: %w", err)
+		}
+	}
+
+	pages := cfg.PageConcurrency
+	if pages <= 0 {
+		pages = 4
+	}
 
 	return &Client{
-		client:      github.NewClient(httpClient),
+		client:      client,
 		rateLimiter: limiter,
+		search:      search,
+		codeSearch:  codeSearch,
 		timeout:     cfg.Timeout,
+		token:       len(tokens) > 0,
+		latency:     latency,
+		pages:       pages,
+	}, nil
+}
+
+// searchLimiter paces search requests at perMinute per token, or
+// defaultPerMinute when perMinute is not positive.
+func searchLimiter(perMinute, defaultPerMinute float64, tokens int) *rate.Limiter {
+	if perMinute <= 0 {
+		perMinute = defaultPerMinute
+	}
+	return rate.NewLimiter(rate.Limit(perMinute*float64(max(1, tokens))/60), 1)
+}
+
+// begin starts a span for a request to endpoint and waits for the rate
+// limiter of endpoint. The returned context carries the span and must be used
+// for the request, which is completed with end.
+func (c *Client) begin(ctx context.Context, endpoint string, attrs ...attribute.KeyValue) (context.Context, trace.Span, error) {
+	ctx, span := tracer.Start(ctx, "github."+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	if err := c.wait(ctx, c.limiter(endpoint)); err != nil {
+		tracing.EndSpan(span, err)
+		return ctx, nil, err
+	}
+	return ctx, span, nil
+}
+
+// limiter returns the rate limiter of the budget endpoint counts against:
+// search requests have their own, so they neither hold back nor are held
+// back by other requests.
+func (c *Client) limiter(endpoint string) *rate.Limiter {
+	switch endpoint {
+	case "search_commits":
+		return c.search
+	case "search_code":
+		return c.codeSearch
+	}
+	return c.rateLimiter
+}
+
+// wait waits for limiter before making a request.
+func (c *Client) wait(ctx context.Context, limiter *rate.Limiter) error {
+	_, span := tracer.Start(ctx, "github.rate_limit_wait")
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	metrics.ObserveRateLimitWait(time.Since(start))
+	tracing.EndSpan(span, err)
+	return err
+}
+
+// end completes a request started with begin, counting it and storing the
+// rate-limit budget reported by its response.
+func (c *Client) end(span trace.Span, endpoint string, resp *github.Response, err error) {
+	c.record(endpoint, resp)
+	span.SetAttributes(attribute.Int("http.response.status_code", statusCode(resp)))
+	if resp != nil && resp.Rate.Limit != 0 {
+		span.SetAttributes(attribute.Int("github.rate_limit.remaining", resp.Rate.Remaining))
 	}
+	tracing.EndSpan(span, err)
+}
+
+// record counts a request to endpoint and stores the rate-limit budget
+// reported by its response.
+func (c *Client) record(endpoint string, resp *github.Response) {
+	metrics.ObserveAPIRequest(endpoint, statusCode(resp))
+	if resp == nil || resp.Rate.Limit == 0 {
+		return
+	}
+	metrics.SetRateLimitRemaining(resp.Rate.Remaining)
+	c.mu.Lock()
+	c.rate = RateStatus{
+		Limit:     resp.Rate.Limit,
+		Remaining: resp.Rate.Remaining,
+		Reset:     resp.Rate.Reset.Time,
+	}
+	c.mu.Unlock()
+}
+
+// statusCode returns the HTTP status of resp, or 0 if there was no response.
+func statusCode(resp *github.Response) int {
+	if resp == nil || resp.Response == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// Name returns the provider name.
+func (c *Client) Name() string { return "github" }
+
+// RateLimit returns the most recently observed rate-limit budget. It is zero
+// until a response reports one, which GitHub Enterprise Server instances with
+// rate limiting disabled never do.
+func (c *Client) RateLimit() RateStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rate
+}
+
+// Pace returns the requests per second the rate limiter allows, lowered to
+// what spreads the remaining rate limit until its reset, and the average
+// duration of recent requests.
+func (c *Client) Pace() (rps float64, latency time.Duration) {
+	rps = float64(c.rateLimiter.Limit())
+	now := time.Now()
+	if budget := c.RateLimit(); budget.Limit > 0 && budget.Reset.After(now) {
+		rps = min(rps, float64(budget.Remaining)/budget.Reset.Sub(now).Seconds())
+	}
+	return rps, c.latency.average()
+}
+
+// latencyTransport is an http.RoundTripper keeping a moving average of the
+// time until response headers arrive.
+type latencyTransport struct {
+	base http.RoundTripper
+
+	mu  sync.Mutex
+	avg time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *latencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		d := time.Since(start)
+		t.mu.Lock()
+		if t.avg == 0 {
+			t.avg = d
+		} else {
+			t.avg += (d - t.avg) / 5
+		}
+		t.mu.Unlock()
+	}
+	return resp, err
+}
+
+func (t *latencyTransport) average() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.avg
+}
+
+// RateLimits fetches the current core and search API budgets. The request does
+// not count against them.
+func (c *Client) RateLimits(ctx context.Context) (core, search RateStatus, err error) {
+	ctx, span, err := c.begin(ctx, "rate_limit")
+	if err != nil {
+		return RateStatus{}, RateStatus{}, err
+	}
+
+	limits, resp, err := c.client.RateLimit.Get(ctx)
+	c.end(span, "rate_limit", resp, err)
+	if err != nil {
+		return RateStatus{}, RateStatus{}, fmt.Errorf("failed to get rate limits: %w", err)
+	}
+	status := func(r *github.Rate) RateStatus {
+		if r == nil {
+			return RateStatus{}
+		}
+		return RateStatus{Limit: r.Limit, Remaining: r.Remaining, Reset: r.Reset.Time}
+	}
+	return status(limits.GetCore()), status(limits.GetSearch()), nil
+}
+
+// TokenInfo describes the token a client authenticates with.
+type TokenInfo struct {
+	// Login is the account the token belongs to.
+	Login string
+	// Classic is set for classic personal access tokens and OAuth tokens,
+	// which report their Scopes. Fine-grained tokens have no scopes.
+	Classic bool
+	Scopes  []string
 }
 
-// wait waits for rate limiter before making a request.
-func (c *Client) wait(ctx context.Context) error {
-	return c.rateLimiter.Wait(ctx)
+// Token describes the token of the client, or returns nil without one. A
+// rejected token is an error.
+func (c *Client) Token(ctx context.Context) (*TokenInfo, error) {
+	if !c.token {
+		return nil, nil
+	}
+	ctx, span, err := c.begin(ctx, "get_authenticated_user")
+	if err != nil {
+		return nil, err
+	}
+
+	user, resp, err := c.client.Users.Get(ctx, "")
+	c.end(span, "get_authenticated_user", resp, err)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("token rejected: %w", err)
+		}
+		return nil, fmt.Errorf("failed to check token: %w", err)
+	}
+
+	info := &TokenInfo{Login: user.GetLogin()}
+	if header, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		info.Classic = true
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+	}
+	return info, nil
 }
 
 // GetUser retrieves a GitHub user's profile.
 func (c *Client) GetUser(ctx context.Context, username string) (*models.UserProfile, error) {
-	if err := c.wait(ctx); err != nil {
+	ctx, span, err := c.begin(ctx, "get_user", attribute.String("github.user", username))
+	if err != nil {
 		return nil, err
 	}
 
-	user, _, err := c.client.Users.Get(ctx, username)
+	user, resp, err := c.client.Users.Get(ctx, username)
+	c.end(span, "get_user", resp, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user %s: %w", username, err)
 	}
@@ -96,11 +389,15 @@ func (c *Client) ListUserRepos(ctx context.Context, username string) ([]*models.
 	}
 
 	for {
-		if err := c.wait(ctx); err != nil {
+		reqCtx, span, err := c.begin(ctx, "list_repos",
+			attribute.String("github.user", username),
+			attribute.Int("github.page", opts.Page))
+		if err != nil {
 			return nil, err
 		}
 
-		repos, resp, err := c.client.Repositories.List(ctx, username, opts)
+		repos, resp, err := c.client.Repositories.List(reqCtx, username, opts)
+		c.end(span, "list_repos", resp, err)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list repos for %s: %w", username, err)
 		}
@@ -116,6 +413,9 @@ func (c *Client) ListUserRepos(ctx context.Context, username string) ([]*models.
 				Description: repo.GetDescription(),
 				URL:         repo.GetHTMLURL(),
 				Private:     repo.GetPrivate(),
+				Fork:        repo.GetFork(),
+				Size:        repo.GetSize(),
+				HasPages:    repo.GetHasPages(),
 			})
 		}
 
@@ -130,70 +430,526 @@ func (c *Client) ListUserRepos(ctx context.Context, username string) ([]*models.
 
 // ListUserCommits lists all commits by a user in a repository.
 func (c *Client) ListUserCommits(ctx context.Context, owner, repo, username string) ([]*models.Commit, error) {
+	return c.ListBranchCommits(ctx, owner, repo, "", models.AuthorFilter(username), 0)
+}
+
+// ListBranchCommits lists the commits selected by filter on a branch of a
+// repository, stopping after limit commits (0 means no limit). An empty
+// branch lists the default branch. Missing branches yield no commits.
+func (c *Client) ListBranchCommits(ctx context.Context, owner, repo, branch string, filter models.CommitFilter, limit int) ([]*models.Commit, error) {
 	var allCommits []*models.Commit
-	opts := &github.CommitsListOptions{
-		Author:      username,
-		ListOptions: github.ListOptions{PerPage: 100},
+	err := c.StreamBranchCommits(ctx, owner, repo, branch, filter, limit, func(commits []*models.Commit) error {
+		allCommits = append(allCommits, commits...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return allCommits, nil
+}
 
-	for {
-		if err := c.wait(ctx); err != nil {
-			return nil, err
+// StreamBranchCommits is like ListBranchCommits but hands each page of commits
+// to fn as soon as it is fetched instead of accumulating them. An error returned
+// by fn stops the listing and is returned as is.
+//
+// Authored and committed commits are listed with server-side filters, one
+// listing each, newest first within a listing. Co-authors are only named in
+// commit messages, so selecting them lists every commit on the branch.
+func (c *Client) StreamBranchCommits(ctx context.Context, owner, repo, branch string, filter models.CommitFilter, limit int, fn func([]*models.Commit) error) error {
+	return selectCommits(filter, limit, fn, func(param string, perPage int, done func() bool, fn func([]*models.Commit) error) error {
+		return c.streamCommits(ctx, owner, repo, branch, param, filter.Username, filter.Since, perPage, done, fn)
+	})
+}
+
+// selectCommits hands the commits selected by filter to fn, up to limit (0
+// means no limit), with their Roles set. list hands each page of a listing of
+// the branch to its fn until done reports true, filtered by the param query
+// parameter (author or committer) when set.
+func selectCommits(filter models.CommitFilter, limit int, fn func([]*models.Commit) error, list func(param string, perPage int, done func() bool, fn func([]*models.Commit) error) error) error {
+	total := 0
+	keep := func(commits []*models.Commit) error {
+		if limit > 0 && total+len(commits) > limit {
+			commits = commits[:limit-total]
+		}
+		total += len(commits)
+		if len(commits) == 0 {
+			return nil
 		}
+		return fn(commits)
+	}
+	done := func() bool { return limit > 0 && total >= limit }
+	perPage := 100
+	if limit > 0 && limit < perPage {
+		perPage = limit
+	}
 
-		commits, resp, err := c.client.Repositories.ListCommits(ctx, owner, repo, opts)
-		if err != nil {
-			// Skip repos we can't access
-			if _, ok := err.(*github.ErrorResponse); ok {
-				break
+	if filter.CoAuthor {
+		return list("", 100, done, func(page []*models.Commit) error {
+			var commits []*models.Commit
+			for _, commit := range page {
+				if commit.Roles = filter.Roles(commit); len(commit.Roles) > 0 {
+					commits = append(commits, commit)
+				}
 			}
-			return nil, fmt.Errorf("failed to list commits in %s/%s: %w", owner, repo, err)
+			return keep(commits)
+		})
+	}
+
+	// Commits both authored and committed by the user appear in both listings
+	seen := make(map[string]bool)
+	for _, pass := range []struct {
+		enabled bool
+		param   string
+		role    models.CommitRole
+	}{
+		{filter.Author, "author", models.RoleAuthor},
+		{filter.Committer, "committer", models.RoleCommitter},
+	} {
+		if !pass.enabled || done() {
+			continue
+		}
+		err := list(pass.param, perPage, done, func(page []*models.Commit) error {
+			var commits []*models.Commit
+			for _, commit := range page {
+				if seen[commit.SHA] {
+					continue
+				}
+				seen[commit.SHA] = true
+				commit.Roles = filter.Roles(commit)
+				if !slices.Contains(commit.Roles, pass.role) {
+					commit.Roles = append([]models.CommitRole{pass.role}, commit.Roles...)
+				}
+				commits = append(commits, commit)
+			}
+			return keep(commits)
+		})
+		if err != nil {
+			return err
 		}
+	}
+	return nil
+}
+
+// streamCommits lists the commits on a branch, filtered by the param query
+// parameter (author or committer) when set and by date when since is set, and
+// hands each page of perPage commits to fn, in order, until done reports
+// true. Once the first page tells the number of pages, the others are
+// fetched concurrently. Repositories that cannot be listed yield no commits.
+func (c *Client) streamCommits(ctx context.Context, owner, repo, branch, param, username string, since time.Time, perPage int, done func() bool, fn func([]*models.Commit) error) error {
+	query := url.Values{"per_page": {strconv.Itoa(perPage)}}
+	if branch != "" {
+		query.Set("sha", branch)
+	}
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	if param != "" {
+		query.Set(param, username)
+	}
+	fetch := func(ctx context.Context, page int) ([]*models.Commit, *github.Response, error) {
+		return c.listCommitPage(ctx, owner, repo, branch, param, query, page)
+	}
 
-		for _, commit := range commits {
-			c := convertCommit(commit, owner, repo)
-			if c != nil {
-				allCommits = append(allCommits, c)
+	commits, resp, err := fetch(ctx, 0)
+	if resp == nil || err != nil {
+		return err
+	}
+	if err := fn(commits); err != nil {
+		return err
+	}
+	if done() || resp.NextPage == 0 {
+		return nil
+	}
+	if resp.LastPage > resp.NextPage && c.pages > 1 {
+		return c.streamPages(ctx, resp.NextPage, resp.LastPage, fetch, done, fn)
+	}
+
+	// Without the number of pages, follow the next page links
+	page := resp.NextPage
+	for {
+		commits, resp, err := fetch(ctx, page)
+		if resp == nil || err != nil {
+			return err
+		}
+		if err := fn(commits); err != nil {
+			return err
+		}
+		if done() || resp.NextPage == 0 {
+			return nil
+		}
+		page = resp.NextPage
+	}
+}
+
+// streamPages fetches the pages first to last of a listing, up to c.pages at
+// a time, and hands them to fn in order until done reports true. Pages are
+// fetched ahead of fn by at most c.pages. A page ending the listing (a nil
+// response) stops it there.
+func (c *Client) streamPages(ctx context.Context, first, last int, fetch func(context.Context, int) ([]*models.Commit, *github.Response, error), done func() bool, fn func([]*models.Commit) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type pageResult struct {
+		commits []*models.Commit
+		end     bool
+		err     error
+	}
+	results := make([]chan pageResult, last-first+1)
+	for i := range results {
+		results[i] = make(chan pageResult, 1)
+	}
+	slots := make(chan struct{}, c.pages)
+	go func() {
+		for i := range results {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
 			}
+			go func() {
+				commits, resp, err := fetch(ctx, first+i)
+				results[i] <- pageResult{commits: commits, end: resp == nil, err: err}
+			}()
 		}
+	}()
 
-		if resp.NextPage == 0 {
-			break
+	for i := range results {
+		var r pageResult
+		select {
+		case r = <-results[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		<-slots
+		if r.end || r.err != nil {
+			return r.err
+		}
+		if err := fn(r.commits); err != nil {
+			return err
+		}
+		if done() {
+			return nil
 		}
-		opts.Page = resp.NextPage
 	}
+	return nil
+}
 
-	return allCommits, nil
+// listCommitPage fetches a page of a commit listing; page 0 is the first.
+// Repositories that cannot be listed return a nil response and no error.
+func (c *Client) listCommitPage(ctx context.Context, owner, repo, branch, param string, query url.Values, page int) ([]*models.Commit, *github.Response, error) {
+	ctx, span, err := c.begin(ctx, "list_commits",
+		attribute.String("github.repository", owner+"/"+repo),
+		attribute.String("github.branch", branch),
+		attribute.String("github.filter", param),
+		attribute.Int("github.page", page))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// CommitsListOptions has no committer filter, so the request is built here
+	if page > 0 {
+		query = maps.Clone(query)
+		query.Set("page", strconv.Itoa(page))
+	}
+	var commits []*github.RepositoryCommit
+	req, err := c.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/commits?%s", url.PathEscape(owner), url.PathEscape(repo), query.Encode()), nil)
+	var resp *github.Response
+	if err == nil {
+		resp, err = c.client.Do(ctx, req, &commits)
+	}
+	c.end(span, "list_commits", resp, err)
+	if err != nil {
+		// Skip repos we can't access
+		if inaccessible(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to list commits in %s/%s: %w", owner, repo, err)
+	}
+
+	converted := make([]*models.Commit, 0, len(commits))
+	for _, commit := range commits {
+		if c := convertCommit(commit, owner, repo); c != nil {
+			converted = append(converted, c)
+		}
+	}
+	return converted, resp, nil
+}
+
+// ErrNotModified is returned by Head when the branch has not moved since the
+// request that returned the given ETag.
+var ErrNotModified = errors.New("not modified")
+
+// Head returns the newest commit of a repository's default branch. With the
+// ETag of a previous call the request is conditional: GitHub answers 304 Not
+// Modified, which does not count against the rate limit, if the branch has
+// not moved, and ErrNotModified is returned. Empty and inaccessible
+// repositories return a zero state.
+func (c *Client) Head(ctx context.Context, owner, repo, etag string) (models.RepoState, error) {
+	ctx, span, err := c.begin(ctx, "get_head", attribute.String("github.repository", owner+"/"+repo))
+	if err != nil {
+		return models.RepoState{}, err
+	}
+
+	var commits []*github.RepositoryCommit
+	req, err := c.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/commits?per_page=1", url.PathEscape(owner), url.PathEscape(repo)), nil)
+	var resp *github.Response
+	if err == nil {
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err = c.client.Do(ctx, req, &commits)
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotModified {
+		c.end(span, "get_head", resp, nil)
+		return models.RepoState{}, ErrNotModified
+	}
+	c.end(span, "get_head", resp, err)
+	if err != nil {
+		if inaccessible(err) {
+			return models.RepoState{}, nil
+		}
+		return models.RepoState{}, fmt.Errorf("failed to get head of %s/%s: %w", owner, repo, err)
+	}
+	if len(commits) == 0 {
+		return models.RepoState{}, nil
+	}
+
+	return models.RepoState{
+		Head: commits[0].GetSHA(),
+		Date: commits[0].GetCommit().GetCommitter().GetDate().Time,
+		ETag: resp.Header.Get("ETag"),
+	}, nil
+}
+
+// CountCommits counts the commits on a repository's default branch from its
+// contributor statistics, which cover the top 100 contributors. GitHub
+// computes them in the background on first request and answers 202 Accepted
+// meanwhile, in which case ok is false. Inaccessible repositories count no
+// commits.
+func (c *Client) CountCommits(ctx context.Context, owner, repo, username string) (user, total int, ok bool, err error) {
+	ctx, span, err := c.begin(ctx, "contributor_stats", attribute.String("github.repository", owner+"/"+repo))
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	stats, resp, err := c.client.Repositories.ListContributorsStats(ctx, owner, repo)
+	if _, accepted := err.(*github.AcceptedError); accepted {
+		c.end(span, "contributor_stats", resp, nil)
+		return 0, 0, false, nil
+	}
+	c.end(span, "contributor_stats", resp, err)
+	if err != nil {
+		if inaccessible(err) {
+			return 0, 0, true, nil
+		}
+		return 0, 0, false, fmt.Errorf("failed to get contributor statistics of %s/%s: %w", owner, repo, err)
+	}
+
+	for _, s := range stats {
+		total += s.GetTotal()
+		if strings.EqualFold(s.GetAuthor().GetLogin(), username) {
+			user = s.GetTotal()
+		}
+	}
+	return user, total, true, nil
+}
+
+// GetFileContent retrieves a file from a repository's default branch.
+// It returns nil content and no error when the file does not exist.
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, path string) ([]byte, error) {
+	ctx, span, err := c.begin(ctx, "get_contents",
+		attribute.String("github.repository", owner+"/"+repo),
+		attribute.String("github.path", path))
+	if err != nil {
+		return nil, err
+	}
+
+	file, _, resp, err := c.client.Repositories.GetContents(ctx, owner, repo, path, nil)
+	c.end(span, "get_contents", resp, err)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s in %s/%s: %w", path, owner, repo, err)
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s in %s/%s: %w", path, owner, repo, err)
+	}
+	return []byte(content), nil
+}
+
+// ListFiles lists the files and directories in dir of a repository's default
+// branch, its root when dir is empty. Missing directories and inaccessible
+// repositories have none.
+func (c *Client) ListFiles(ctx context.Context, owner, repo, dir string) ([]*models.RepoFile, error) {
+	ctx, span, err := c.begin(ctx, "list_contents",
+		attribute.String("github.repository", owner+"/"+repo),
+		attribute.String("github.path", dir))
+	if err != nil {
+		return nil, err
+	}
+
+	_, entries, resp, err := c.client.Repositories.GetContents(ctx, owner, repo, dir, nil)
+	c.end(span, "list_contents", resp, err)
+	if err != nil {
+		if inaccessible(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list %s in %s/%s: %w", dir, owner, repo, err)
+	}
+
+	files := make([]*models.RepoFile, 0, len(entries))
+	for _, e := range entries {
+		files = append(files, &models.RepoFile{
+			Path: e.GetPath(),
+			Dir:  e.GetType() == "dir",
+			URL:  e.GetHTMLURL(),
+		})
+	}
+	return files, nil
 }
 
 // SearchUserCommits searches for commits by a user across GitHub.
 func (c *Client) SearchUserCommits(ctx context.Context, username string) ([]*models.Commit, error) {
+	return c.searchCommitResults(ctx, fmt.Sprintf("author:%s", username))
+}
+
+// SearchCommitsByEmail searches for commits authored with email across
+// GitHub, whichever account (if any) they are attributed to. Like all commit
+// searches it returns at most 1,000 commits.
+func (c *Client) SearchCommitsByEmail(ctx context.Context, email string) ([]*models.Commit, error) {
+	return c.searchCommitResults(ctx, fmt.Sprintf("author-email:%s", email))
+}
+
+// SearchCommitsByName searches for commits whose author name is name across
+// GitHub, whichever account (if any) they are attributed to. Like all commit
+// searches it returns at most 1,000 commits.
+func (c *Client) SearchCommitsByName(ctx context.Context, name string) ([]*models.Commit, error) {
+	return c.searchCommitResults(ctx, fmt.Sprintf("author-name:%q", name))
+}
+
+// searchCommitResults returns the commits in public repositories found by a
+// commit search query.
+func (c *Client) searchCommitResults(ctx context.Context, query string) ([]*models.Commit, error) {
 	var allCommits []*models.Commit
-	query := fmt.Sprintf("author:%s", username)
+	err := c.searchCommits(ctx, query, func(commit *github.CommitResult) {
+		if commit.Repository.GetPrivate() {
+			return
+		}
+		repoOwner := ""
+		repoName := ""
+		if commit.Repository != nil {
+			repoOwner = commit.Repository.GetOwner().GetLogin()
+			repoName = commit.Repository.GetName()
+		}
+		if c := convertCommitResult(commit, repoOwner, repoName); c != nil {
+			allCommits = append(allCommits, c)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allCommits, nil
+}
+
+// SearchContributedRepos discovers the public repositories containing
+// commits authored by a user, including repositories the user does not own,
+// through commit search. The search API returns at most 1,000 commits, so
+// repositories with only older contributions may be missed.
+func (c *Client) SearchContributedRepos(ctx context.Context, username string) ([]*models.Repository, error) {
+	var repos []*models.Repository
+	seen := make(map[string]bool)
+	err := c.searchCommits(ctx, fmt.Sprintf("author:%s", username), func(commit *github.CommitResult) {
+		repo := commit.Repository
+		if repo == nil || repo.GetPrivate() || seen[strings.ToLower(repo.GetFullName())] {
+			return
+		}
+		seen[strings.ToLower(repo.GetFullName())] = true
+		repos = append(repos, &models.Repository{
+			FullName:    repo.GetFullName(),
+			Name:        repo.GetName(),
+			Owner:       repo.GetOwner().GetLogin(),
+			Description: repo.GetDescription(),
+			URL:         repo.GetHTMLURL(),
+			Fork:        repo.GetFork(),
+			Size:        repo.GetSize(),
+			HasPages:    repo.GetHasPages(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// searchCommits calls fn for every commit found by a commit search query.
+func (c *Client) searchCommits(ctx context.Context, query string, fn func(*github.CommitResult)) error {
 	opts := &github.SearchOptions{
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
 	for {
-		if err := c.wait(ctx); err != nil {
-			return nil, err
+		reqCtx, span, err := c.begin(ctx, "search_commits",
+			attribute.String("github.query", query),
+			attribute.Int("github.page", opts.Page))
+		if err != nil {
+			return err
 		}
 
-		result, resp, err := c.client.Search.Commits(ctx, query, opts)
+		// The search API has its own rate budget, so only count the request
+		result, resp, err := c.client.Search.Commits(reqCtx, query, opts)
+		metrics.ObserveAPIRequest("search_commits", statusCode(resp))
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode(resp)))
+		tracing.EndSpan(span, err)
 		if err != nil {
-			return nil, fmt.Errorf("failed to search commits for %s: %w", username, err)
+			return fmt.Errorf("failed to search commits for %q: %w", query, err)
 		}
 
 		for _, commit := range result.Commits {
-			repoOwner := ""
-			repoName := ""
-			if commit.Repository != nil {
-				repoOwner = commit.Repository.GetOwner().GetLogin()
-				repoName = commit.Repository.GetName()
-			}
-			c := convertCommitResult(commit, repoOwner, repoName)
-			if c != nil {
-				allCommits = append(allCommits, c)
+			fn(commit)
+		}
+
+		if resp.NextPage == 0 {
+			return nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// maxEventPages is the number of event pages GitHub serves: the 300 most
+// recent events of the last 90 days.
+const maxEventPages = 3
+
+// ListUserEvents lists the recent public events performed by a user, newest
+// first. Only the events carrying text are returned: pushes, comments,
+// reviews, opened issues and pull requests, releases and created
+// repositories.
+func (c *Client) ListUserEvents(ctx context.Context, username string) ([]*models.ActivityEvent, error) {
+	var events []*models.ActivityEvent
+	opts := &github.ListOptions{PerPage: 100}
+
+	for page := 0; page < maxEventPages; page++ {
+		reqCtx, span, err := c.begin(ctx, "list_events",
+			attribute.String("github.user", username),
+			attribute.Int("github.page", opts.Page))
+		if err != nil {
+			return nil, err
+		}
+
+		list, resp, err := c.client.Activity.ListEventsPerformedByUser(reqCtx, username, true, opts)
+		c.end(span, "list_events", resp, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events for %s: %w", username, err)
+		}
+
+		for _, e := range list {
+			if ev := c.convertEvent(e); ev != nil {
+				events = append(events, ev)
 			}
 		}
 
@@ -203,7 +959,79 @@ func (c *Client) SearchUserCommits(ctx context.Context, username string) ([]*mod
 		opts.Page = resp.NextPage
 	}
 
-	return allCommits, nil
+	return events, nil
+}
+
+// convertEvent converts an event, returning nil for events without text.
+func (c *Client) convertEvent(e *github.Event) *models.ActivityEvent {
+	payload, err := e.ParsePayload()
+	if err != nil {
+		return nil
+	}
+
+	ev := &models.ActivityEvent{
+		Type:       e.GetType(),
+		Repository: e.GetRepo().GetName(),
+		CreatedAt:  e.GetCreatedAt().Time,
+	}
+	switch p := payload.(type) {
+	case *github.PushEvent:
+		for _, hc := range p.Commits {
+			commit := &models.Commit{
+				SHA:        hc.GetSHA(),
+				Repository: ev.Repository,
+				Message:    hc.GetMessage(),
+				Trailers:   models.ParseTrailers(hc.GetMessage()),
+				URL:        c.webURL(ev.Repository + "/commit/" + hc.GetSHA()),
+				Date:       ev.CreatedAt,
+			}
+			if hc.Author != nil {
+				commit.Author = models.Author{Name: hc.Author.GetName(), Email: hc.Author.GetEmail()}
+			}
+			ev.Commits = append(ev.Commits, commit)
+		}
+		ev.URL = c.webURL(ev.Repository + "/compare/" + p.GetBefore() + "..." + p.GetHead())
+	case *github.CreateEvent:
+		if p.GetRefType() != "repository" {
+			return nil
+		}
+		ev.Body = p.GetDescription()
+		ev.URL = c.webURL(ev.Repository)
+	case *github.IssueCommentEvent:
+		ev.Body, ev.URL = p.GetComment().GetBody(), p.GetComment().GetHTMLURL()
+	case *github.CommitCommentEvent:
+		ev.Body, ev.URL = p.GetComment().GetBody(), p.GetComment().GetHTMLURL()
+	case *github.PullRequestReviewEvent:
+		ev.Body, ev.URL = p.GetReview().GetBody(), p.GetReview().GetHTMLURL()
+	case *github.PullRequestReviewCommentEvent:
+		ev.Body, ev.URL = p.GetComment().GetBody(), p.GetComment().GetHTMLURL()
+	case *github.IssuesEvent:
+		if p.GetAction() != "opened" {
+			return nil
+		}
+		ev.Title, ev.Body, ev.URL = p.GetIssue().GetTitle(), p.GetIssue().GetBody(), p.GetIssue().GetHTMLURL()
+	case *github.PullRequestEvent:
+		if p.GetAction() != "opened" {
+			return nil
+		}
+		ev.Title, ev.Body, ev.URL = p.GetPullRequest().GetTitle(), p.GetPullRequest().GetBody(), p.GetPullRequest().GetHTMLURL()
+	case *github.ReleaseEvent:
+		ev.Title, ev.Body, ev.URL = p.GetRelease().GetName(), p.GetRelease().GetBody(), p.GetRelease().GetHTMLURL()
+	default:
+		return nil
+	}
+	return ev
+}
+
+// webURL returns the address of path on the web interface of the GitHub
+// instance the client talks to.
+func (c *Client) webURL(path string) string {
+	u := *c.client.BaseURL
+	if u.Host == "api.github.com" {
+		u.Host = "github.com"
+	}
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/api/v3") + "/" + path
+	return u.String()
 }
 
 func convertCommit(rc *github.RepositoryCommit, owner, repo string) *models.Commit {
@@ -215,6 +1043,7 @@ func convertCommit(rc *github.RepositoryCommit, owner, repo string) *models.Comm
 		SHA:        rc.GetSHA(),
 		Repository: fmt.Sprintf("%s/%s", owner, repo),
 		Message:    rc.Commit.GetMessage(),
+		Trailers:   models.ParseTrailers(rc.Commit.GetMessage()),
 		URL:        rc.GetHTMLURL(),
 	}
 
@@ -240,6 +1069,7 @@ func convertCommit(rc *github.RepositoryCommit, owner, repo string) *models.Comm
 	if rc.Committer != nil {
 		commit.Committer.Login = rc.Committer.GetLogin()
 	}
+	commit.Signature = rc.Commit.GetVerification().GetSignature()
 
 	return commit
 }
@@ -253,6 +1083,7 @@ func convertCommitResult(cr *github.CommitResult, owner, repo string) *models.Co
 		SHA:        cr.GetSHA(),
 		Repository: fmt.Sprintf("%s/%s", owner, repo),
 		Message:    cr.Commit.GetMessage(),
+		Trailers:   models.ParseTrailers(cr.Commit.GetMessage()),
 		URL:        cr.GetHTMLURL(),
 	}
 
@@ -278,6 +1109,7 @@ func convertCommitResult(cr *github.CommitResult, owner, repo string) *models.Co
 	if cr.Committer != nil {
 		commit.Committer.Login = cr.Committer.GetLogin()
 	}
+	commit.Signature = cr.Commit.GetVerification().GetSignature()
 
 	return commit
 }
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/metrics"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxCodeResults caps the files returned per code search query. Code search
+// allows only ten requests a minute.
+const maxCodeResults = 300
+
+// SearchCode searches the default branch of public repositories for files
+// matching query, returning each with the fragments that matched. Code
+// search requires a token and returns at most maxCodeResults files.
+func (c *Client) SearchCode(ctx context.Context, query string) ([]*models.CodeResult, error) {
+	opts := &github.SearchOptions{
+		TextMatch:   true,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var results []*models.CodeResult
+	for {
+		reqCtx, span, err := c.begin(ctx, "search_code",
+			attribute.String("github.query", query),
+			attribute.Int("github.page", opts.Page))
+		if err != nil {
+			return nil, err
+		}
+
+		// The search API has its own rate budget, so only count the request
+		found, resp, err := c.client.Search.Code(reqCtx, query, opts)
+		metrics.ObserveAPIRequest("search_code", statusCode(resp))
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode(resp)))
+		tracing.EndSpan(span, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search code for %q: %w", query, err)
+		}
+
+		for _, r := range found.CodeResults {
+			if r.GetRepository().GetPrivate() {
+				continue
+			}
+			result := &models.CodeResult{
+				Repository: r.GetRepository().GetFullName(),
+				Path:       r.GetPath(),
+				URL:        r.GetHTMLURL(),
+			}
+			for _, tm := range r.TextMatches {
+				if tm.GetFragment() != "" {
+					result.Fragments = append(result.Fragments, tm.GetFragment())
+				}
+			}
+			results = append(results, result)
+		}
+
+		if resp.NextPage == 0 || len(results) >= maxCodeResults {
+			return results, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
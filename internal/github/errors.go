@@ -0,0 +1,34 @@
+package github
+
+import (
+	"errors"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Classify returns the type of an error returned by the client for a GitHub
+// response, or an empty type for other errors, such as network failures.
+func Classify(err error) models.ErrorType {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var respErr *github.ErrorResponse
+	switch {
+	case errors.As(err, &rateErr), errors.As(err, &abuseErr):
+		return models.ErrorRateLimited
+	case errors.Is(err, errNoToken):
+		return models.ErrorAccessDenied
+	case errors.As(err, &respErr) && respErr.Response != nil:
+		return models.HTTPErrorType(respErr.Response.StatusCode)
+	}
+	return ""
+}
+
+// inaccessible reports whether err is an error response for something that
+// cannot be read, such as a missing, empty or blocked repository, which
+// listings treat as empty. Rate limits and server errors are not: the
+// listing may succeed when retried.
+func inaccessible(err error) bool {
+	var respErr *github.ErrorResponse
+	return errors.As(err, &respErr) && !Classify(err).Retryable()
+}
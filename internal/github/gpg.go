@@ -0,0 +1,48 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/gpg"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ListGPGKeys lists the public GPG keys of the user login. The user IDs of a
+// key are read from the key itself, or are its emails when it cannot be
+// parsed.
+func (c *Client) ListGPGKeys(ctx context.Context, login string) ([]*models.GPGKey, error) {
+	ctx, span, err := c.begin(ctx, "list_gpg_keys", attribute.String("github.user", login))
+	if err != nil {
+		return nil, err
+	}
+
+	keys, resp, err := c.client.Users.ListGPGKeys(ctx, login, &github.ListOptions{PerPage: 100})
+	c.end(span, "list_gpg_keys", resp, err)
+	if err != nil {
+		if inaccessible(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list GPG keys of %s: %w", login, err)
+	}
+
+	var result []*models.GPGKey
+	for _, k := range keys {
+		key := &models.GPGKey{KeyIDs: []string{strings.ToUpper(k.GetKeyID())}}
+		for _, sub := range k.Subkeys {
+			key.KeyIDs = append(key.KeyIDs, strings.ToUpper(sub.GetKeyID()))
+		}
+		if uids, err := gpg.UserIDs(k.GetRawKey()); err == nil {
+			key.UserIDs = uids
+		} else {
+			for _, e := range k.Emails {
+				key.UserIDs = append(key.UserIDs, "<"+e.GetEmail()+">")
+			}
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
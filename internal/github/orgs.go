@@ -0,0 +1,55 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ListUserOrgs lists the organizations username is a public member of. The
+// listing only has their logins and descriptions, so the profile of each is
+// fetched as well, one request per organization; a profile that cannot be
+// fetched is left as listed.
+func (c *Client) ListUserOrgs(ctx context.Context, username string) ([]*models.Organization, error) {
+	reqCtx, span, err := c.begin(ctx, "list_orgs", attribute.String("github.user", username))
+	if err != nil {
+		return nil, err
+	}
+	orgs, resp, err := c.client.Organizations.List(reqCtx, username, &github.ListOptions{PerPage: 100})
+	c.end(span, "list_orgs", resp, err)
+	if err != nil {
+		if inaccessible(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list organizations of %s: %w", username, err)
+	}
+
+	result := make([]*models.Organization, 0, len(orgs))
+	for _, listed := range orgs {
+		org := listed
+		reqCtx, span, err := c.begin(ctx, "get_org", attribute.String("github.org", listed.GetLogin()))
+		if err != nil {
+			return nil, err
+		}
+		full, resp, err := c.client.Organizations.Get(reqCtx, listed.GetLogin())
+		c.end(span, "get_org", resp, err)
+		if err == nil {
+			org = full
+		} else if !inaccessible(err) {
+			return nil, fmt.Errorf("failed to get organization %s: %w", listed.GetLogin(), err)
+		}
+		result = append(result, &models.Organization{
+			Login:       org.GetLogin(),
+			Name:        org.GetName(),
+			Email:       org.GetEmail(),
+			Blog:        org.GetBlog(),
+			Location:    org.GetLocation(),
+			Description: org.GetDescription(),
+			URL:         c.webURL(org.GetLogin()),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,35 @@
+package github
+
+import (
+	"context"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// ListCommitPullRequests lists the pull requests of a repository that include
+// the commit sha. Repositories that cannot be read have none.
+func (c *Client) ListCommitPullRequests(ctx context.Context, owner, repo, sha string) ([]*models.PullRequest, error) {
+	var prs []*models.PullRequest
+	err := c.listPages(ctx, "list_commit_pulls", owner, repo, func(ctx context.Context, opts github.ListOptions) (*github.Response, error) {
+		list, resp, err := c.client.PullRequests.ListPullRequestsWithCommit(ctx, owner, repo, sha, &opts)
+		for _, pr := range list {
+			state := pr.GetState()
+			if pr.MergedAt != nil {
+				state = "merged"
+			}
+			prs = append(prs, &models.PullRequest{
+				Number: pr.GetNumber(),
+				Title:  pr.GetTitle(),
+				Body:   pr.GetBody(),
+				URL:    pr.GetHTMLURL(),
+				State:  state,
+			})
+		}
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
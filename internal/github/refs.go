@@ -0,0 +1,125 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxTagMessages caps the annotated tags whose message is fetched per
+// repository, one request each.
+const maxTagMessages = 100
+
+// ListRefs lists the releases, annotated tags and branches of a repository.
+// Repositories that cannot be listed, or are empty, have none.
+func (c *Client) ListRefs(ctx context.Context, owner, repo string) ([]*models.Ref, error) {
+	var refs []*models.Ref
+
+	err := c.listPages(ctx, "list_releases", owner, repo, func(ctx context.Context, opts github.ListOptions) (*github.Response, error) {
+		releases, resp, err := c.client.Repositories.ListReleases(ctx, owner, repo, &opts)
+		for _, r := range releases {
+			ref := &models.Ref{
+				Kind: models.RefRelease,
+				Name: r.GetName(),
+				Body: r.GetBody(),
+				URL:  r.GetHTMLURL(),
+				Date: r.GetCreatedAt().Time,
+			}
+			if ref.Name == "" {
+				ref.Name = r.GetTagName()
+			}
+			refs = append(refs, ref)
+		}
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only annotated tags point to a tag object with a message
+	var tags []string
+	err = c.listPages(ctx, "list_tags", owner, repo, func(ctx context.Context, opts github.ListOptions) (*github.Response, error) {
+		list, resp, err := c.client.Git.ListMatchingRefs(ctx, owner, repo, &github.ReferenceListOptions{Ref: "tags", ListOptions: opts})
+		for _, r := range list {
+			if r.GetObject().GetType() == "tag" && len(tags) < maxTagMessages {
+				tags = append(tags, r.GetObject().GetSHA())
+			}
+		}
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, sha := range tags {
+		reqCtx, span, err := c.begin(ctx, "get_tag", attribute.String("github.repository", owner+"/"+repo))
+		if err != nil {
+			return nil, err
+		}
+		tag, resp, err := c.client.Git.GetTag(reqCtx, owner, repo, sha)
+		c.end(span, "get_tag", resp, err)
+		if err != nil {
+			if inaccessible(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get tag %s in %s/%s: %w", sha, owner, repo, err)
+		}
+		refs = append(refs, &models.Ref{
+			Kind:   models.RefTag,
+			Name:   tag.GetTag(),
+			Body:   strings.TrimSpace(tag.GetMessage()),
+			Tagger: models.Author{Name: tag.GetTagger().GetName(), Email: tag.GetTagger().GetEmail()},
+			URL:    c.webURL(owner + "/" + repo + "/releases/tag/" + tag.GetTag()),
+			Date:   tag.GetTagger().GetDate().Time,
+		})
+	}
+
+	err = c.listPages(ctx, "list_branches", owner, repo, func(ctx context.Context, opts github.ListOptions) (*github.Response, error) {
+		branches, resp, err := c.client.Repositories.ListBranches(ctx, owner, repo, &github.BranchListOptions{ListOptions: opts})
+		for _, b := range branches {
+			refs = append(refs, &models.Ref{
+				Kind: models.RefBranch,
+				Name: b.GetName(),
+				URL:  c.webURL(owner + "/" + repo + "/tree/" + b.GetName()),
+			})
+		}
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refs, nil
+}
+
+// listPages calls list for every page of 100 items of a repository listing
+// until the last. Repositories that cannot be read (see inaccessible), such
+// as empty ones, end it early without an error.
+func (c *Client) listPages(ctx context.Context, endpoint, owner, repo string, list func(context.Context, github.ListOptions) (*github.Response, error)) error {
+	opts := github.ListOptions{PerPage: 100}
+	for {
+		reqCtx, span, err := c.begin(ctx, endpoint,
+			attribute.String("github.repository", owner+"/"+repo),
+			attribute.Int("github.page", opts.Page))
+		if err != nil {
+			return err
+		}
+
+		resp, err := list(reqCtx, opts)
+		c.end(span, endpoint, resp, err)
+		if err != nil {
+			if inaccessible(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to %s in %s/%s: %w", strings.ReplaceAll(endpoint, "_", " "), owner, repo, err)
+		}
+
+		if resp.NextPage == 0 {
+			return nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
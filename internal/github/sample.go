@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// sampleRun is the number of consecutive commits fetched at each point of
+// history a sample picks: the page size of sampled listings.
+const sampleRun = 10
+
+// SampleBranchCommits is like StreamBranchCommits but hands about n of the
+// selected commits spread across the branch history, in runs of consecutive
+// commits picked at random, newest first. Only the first page of each listing
+// and the picked pages are fetched. Listings of n commits or fewer are
+// fetched entirely.
+func (c *Client) SampleBranchCommits(ctx context.Context, owner, repo, branch string, filter models.CommitFilter, n int, fn func([]*models.Commit) error) error {
+	return selectCommits(filter, n, fn, func(param string, _ int, done func() bool, fn func([]*models.Commit) error) error {
+		return c.sampleCommits(ctx, owner, repo, branch, param, filter.Username, filter.Since, n, done, fn)
+	})
+}
+
+// sampleCommits lists n commits of a branch picked as in SampleBranchCommits,
+// filtered as in streamCommits, and hands each page to fn until done reports
+// true.
+func (c *Client) sampleCommits(ctx context.Context, owner, repo, branch, param, username string, since time.Time, n int, done func() bool, fn func([]*models.Commit) error) error {
+	query := url.Values{"per_page": {strconv.Itoa(sampleRun)}}
+	if branch != "" {
+		query.Set("sha", branch)
+	}
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	if param != "" {
+		query.Set(param, username)
+	}
+
+	first, resp, err := c.listCommitPage(ctx, owner, repo, branch, param, query, 0)
+	if resp == nil || err != nil {
+		return err
+	}
+	if resp.NextPage == 0 {
+		return fn(first)
+	}
+	runs := (n + sampleRun - 1) / sampleRun
+	if resp.LastPage <= runs {
+		// Too short to sample, or the number of pages is unknown: list the
+		// latest commits until done
+		return c.streamCommits(ctx, owner, repo, branch, param, username, since, 100, done, fn)
+	}
+
+	// Index 0 is the page fetched above; GitHub numbers pages from 1
+	pages := rand.Perm(resp.LastPage)[:runs]
+	slices.Sort(pages)
+	for _, page := range pages {
+		commits := first
+		if page > 0 {
+			commits, resp, err = c.listCommitPage(ctx, owner, repo, branch, param, query, page+1)
+			if resp == nil || err != nil {
+				return err
+			}
+		}
+		if err := fn(commits); err != nil {
+			return err
+		}
+		if done() {
+			return nil
+		}
+	}
+	return nil
+}
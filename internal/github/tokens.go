@@ -0,0 +1,219 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errNoToken is returned when every token of a pool was rejected.
+var errNoToken = errors.New("all GitHub tokens were rejected")
+
+// tokenPool is an http.RoundTripper authenticating each request with the
+// token that has the most rate limit left, so several tokens add up their
+// hourly budgets. A request rate limited or rejected with one token is retried
+// with another. Responses report the budget of the whole pool, which is what
+// go-github checks before making requests.
+type tokenPool struct {
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	tokens []*poolToken
+	next   int // where the search for a token starts, to rotate among equals
+}
+
+// poolToken is a token of a pool and its core API budget.
+type poolToken struct {
+	value string
+	// label identifies the token in logs and errors without revealing it.
+	label    string
+	rate     RateStatus // zero Limit until a response reports it
+	rejected bool
+}
+
+func newTokenPool(tokens []string, base http.RoundTripper) *tokenPool {
+	p := &tokenPool{base: base}
+	for i, t := range tokens {
+		suffix := t
+		if len(suffix) > 4 {
+			suffix = suffix[len(suffix)-4:]
+		}
+		p.tokens = append(p.tokens, &poolToken{
+			value: t,
+			label: fmt.Sprintf("token %d (...%s)", i+1, suffix),
+		})
+	}
+	return p
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *tokenPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests with a body that cannot be replayed are sent once
+	attempts := len(p.tokens)
+	if req.Body != nil && req.GetBody == nil {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		t := p.pick()
+		if t == nil {
+			return nil, errNoToken
+		}
+
+		r := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		r.Header.Set("Authorization", "Bearer "+t.value)
+		resp, err := p.base.RoundTrip(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.label, err)
+		}
+
+		if !p.update(t, resp) {
+			p.rewrite(resp)
+			return resp, nil
+		}
+		if attempt >= attempts || !p.available() {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// pick returns the usable token with the most rate limit left, preferring
+// tokens whose budget is not known yet. It returns nil when every token was
+// rejected.
+func (p *tokenPool) pick() *poolToken {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var best *poolToken
+	bestLeft := -1
+	var earliest *poolToken
+	for i := range p.tokens {
+		t := p.tokens[(p.next+i)%len(p.tokens)]
+		if t.rejected {
+			continue
+		}
+		if earliest == nil || t.rate.Reset.Before(earliest.rate.Reset) {
+			earliest = t
+		}
+		if left := t.left(now); left > bestLeft {
+			best, bestLeft = t, left
+		}
+	}
+	p.next = (p.next + 1) % len(p.tokens)
+	if bestLeft == 0 {
+		// Every budget is spent; the token reset first fails the shortest
+		return earliest
+	}
+	return best
+}
+
+// available reports whether a token with budget left remains.
+func (p *tokenPool) available() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for _, t := range p.tokens {
+		if !t.rejected && t.left(now) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// left returns the requests t has left, or MaxInt when unknown.
+func (t *poolToken) left(now time.Time) int {
+	if t.rate.Limit == 0 || now.After(t.rate.Reset) {
+		return math.MaxInt
+	}
+	return t.rate.Remaining
+}
+
+// update records the budget reported by resp for t. It reports whether the
+// request failed because of the token, in which case another token may be
+// tried.
+func (p *tokenPool) update(t *poolToken, resp *http.Response) (retry bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		t.rejected = true
+		slog.Warn("GitHub token rejected; it is no longer used", "token", t.label)
+		return true
+	}
+
+	// Search and other APIs have budgets of their own
+	if res := resp.Header.Get("X-RateLimit-Resource"); res != "" && res != "core" {
+		return false
+	}
+	limit, err1 := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	remaining, err2 := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	reset, err3 := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return false
+	}
+	t.rate = RateStatus{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}
+
+	limited := remaining == 0 && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests)
+	if limited {
+		slog.Warn("GitHub token rate limited", "token", t.label, "reset", t.rate.Reset.Format(time.RFC3339))
+	}
+	return limited
+}
+
+// rewrite replaces the core budget reported by resp with that of the pool.
+func (p *tokenPool) rewrite(resp *http.Response) {
+	if res := resp.Header.Get("X-RateLimit-Resource"); res != "" && res != "core" {
+		return
+	}
+	if resp.Header.Get("X-RateLimit-Limit") == "" {
+		return
+	}
+	total := p.total()
+	resp.Header.Set("X-RateLimit-Limit", strconv.Itoa(total.Limit))
+	resp.Header.Set("X-RateLimit-Remaining", strconv.Itoa(total.Remaining))
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(total.Reset.Unix(), 10))
+}
+
+// total returns the combined budget of the tokens in use: the sum of their
+// limits and remaining requests, reset when the first of them resets.
+func (p *tokenPool) total() RateStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var total RateStatus
+	for _, t := range p.tokens {
+		if t.rejected || t.rate.Limit == 0 {
+			continue
+		}
+		total.Limit += t.rate.Limit
+		if now.After(t.rate.Reset) {
+			total.Remaining += t.rate.Limit
+			continue
+		}
+		total.Remaining += t.rate.Remaining
+		if total.Reset.IsZero() || t.rate.Reset.Before(total.Reset) {
+			total.Reset = t.rate.Reset
+		}
+	}
+	if total.Reset.IsZero() {
+		total.Reset = now.Add(time.Hour)
+	}
+	return total
+}
@@ -0,0 +1,330 @@
+// Package gpg reads the parts of OpenPGP signatures and public keys that
+// identify a signer: the issuer key ID of a signature and the user IDs of a
+// key. It does not verify signatures.
+package gpg
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// Packet tags (RFC 9580, section 5).
+const (
+	tagSignature = 2
+	tagUserID    = 13
+)
+
+// Signature subpacket types (RFC 9580, section 5.2.3.7).
+const (
+	subpacketIssuer            = 16
+	subpacketSignerUserID      = 28
+	subpacketIssuerFingerprint = 33
+)
+
+var errTruncated = errors.New("truncated packet")
+
+// Signature is the signer of an OpenPGP signature.
+type Signature struct {
+	// KeyID is the ID of the signing key, 16 uppercase hex digits as
+	// GitHub reports key IDs.
+	KeyID string
+	// UserID is the signer's user ID, when the signature names it.
+	UserID string
+}
+
+// IsSignature reports whether armored is an ASCII-armored OpenPGP
+// signature, rather than an SSH or X.509 one.
+func IsSignature(armored string) bool {
+	return strings.Contains(armored, "-----BEGIN PGP SIGNATURE-----")
+}
+
+// ParseSignature reads the signer of an ASCII-armored OpenPGP signature.
+func ParseSignature(armored string) (*Signature, error) {
+	data, err := decodeArmor(armored)
+	if err != nil {
+		return nil, err
+	}
+	var sig *Signature
+	err = readPackets(data, func(tag byte, body []byte) error {
+		if tag != tagSignature || sig != nil {
+			return nil
+		}
+		sig, err = parseSignaturePacket(body)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signature: %w", err)
+	}
+	if sig == nil || sig.KeyID == "" {
+		return nil, fmt.Errorf("failed to parse signature: no issuer key")
+	}
+	return sig, nil
+}
+
+// UserIDs returns the user IDs of an ASCII-armored OpenPGP public key, such
+// as "John Doe <john@example.com>".
+func UserIDs(armored string) ([]string, error) {
+	data, err := decodeArmor(armored)
+	if err != nil {
+		return nil, err
+	}
+	var uids []string
+	err = readPackets(data, func(tag byte, body []byte) error {
+		if tag == tagUserID {
+			uids = append(uids, string(body))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return uids, nil
+}
+
+// ParseUserID splits a user ID into its name and email, either of which may
+// be empty. A comment in parentheses is dropped from the name.
+func ParseUserID(uid string) (name, email string) {
+	uid = strings.TrimSpace(uid)
+	if addr, err := mail.ParseAddress(uid); err == nil {
+		name, email = addr.Name, addr.Address
+	} else if i := strings.LastIndexByte(uid, '<'); i >= 0 && strings.HasSuffix(uid, ">") {
+		name, email = uid[:i], uid[i+1:len(uid)-1]
+	} else if strings.Contains(uid, "@") && !strings.ContainsAny(uid, " \t") {
+		email = uid
+	} else {
+		name = uid
+	}
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+	return strings.TrimSpace(name), strings.TrimSpace(email)
+}
+
+// decodeArmor returns the binary data of an ASCII-armored block, skipping
+// its armor headers and checksum.
+func decodeArmor(armored string) ([]byte, error) {
+	var body strings.Builder
+	begun, headers := false, false
+	sc := bufio.NewScanner(strings.NewReader(armored))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(line, "-----BEGIN PGP "):
+			begun, headers = true, true
+		case !begun:
+		case strings.HasPrefix(line, "-----END PGP "):
+			data, err := base64.StdEncoding.DecodeString(body.String())
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode armor: %w", err)
+			}
+			return data, nil
+		case headers && strings.Contains(line, ": "):
+		case line == "":
+			headers = false
+		case strings.HasPrefix(line, "="): // checksum
+		default:
+			headers = false
+			body.WriteString(line)
+		}
+	}
+	return nil, fmt.Errorf("failed to decode armor: no PGP block")
+}
+
+// readPackets calls fn with the tag and body of each packet of data.
+func readPackets(data []byte, fn func(tag byte, body []byte) error) error {
+	for len(data) > 0 {
+		b := data[0]
+		if b&0x80 == 0 {
+			return fmt.Errorf("invalid packet header 0x%02x", b)
+		}
+		data = data[1:]
+
+		var tag byte
+		var body []byte
+		var err error
+		if b&0x40 != 0 {
+			tag = b & 0x3f
+			body, data, err = newFormatBody(data)
+		} else {
+			tag = (b >> 2) & 0x0f
+			body, data, err = oldFormatBody(b&0x03, data)
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(tag, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newFormatBody splits data into the body of a new-format packet and what
+// follows it, joining partial body lengths.
+func newFormatBody(data []byte) (body, rest []byte, err error) {
+	for {
+		if len(data) == 0 {
+			return nil, nil, errTruncated
+		}
+		var n int
+		partial := false
+		switch o := int(data[0]); {
+		case o < 192:
+			n, data = o, data[1:]
+		case o < 224:
+			if len(data) < 2 {
+				return nil, nil, errTruncated
+			}
+			n, data = (o-192)<<8+int(data[1])+192, data[2:]
+		case o < 255:
+			n, data, partial = 1<<(o&0x1f), data[1:], true
+		default:
+			if len(data) < 5 {
+				return nil, nil, errTruncated
+			}
+			n, data = int(binary.BigEndian.Uint32(data[1:5])), data[5:]
+		}
+		if n < 0 || n > len(data) {
+			return nil, nil, errTruncated
+		}
+		body = append(body, data[:n]...)
+		data = data[n:]
+		if !partial {
+			return body, data, nil
+		}
+	}
+}
+
+// oldFormatBody splits data into the body of an old-format packet of
+// lengthType and what follows it.
+func oldFormatBody(lengthType byte, data []byte) (body, rest []byte, err error) {
+	var n int
+	switch lengthType {
+	case 0:
+		if len(data) < 1 {
+			return nil, nil, errTruncated
+		}
+		n, data = int(data[0]), data[1:]
+	case 1:
+		if len(data) < 2 {
+			return nil, nil, errTruncated
+		}
+		n, data = int(binary.BigEndian.Uint16(data)), data[2:]
+	case 2:
+		if len(data) < 4 {
+			return nil, nil, errTruncated
+		}
+		n, data = int(binary.BigEndian.Uint32(data)), data[4:]
+	default: // indeterminate: the rest of the data
+		n = len(data)
+	}
+	if n < 0 || n > len(data) {
+		return nil, nil, errTruncated
+	}
+	return data[:n], data[n:], nil
+}
+
+// parseSignaturePacket reads the issuer key ID and signer's user ID of a
+// signature packet. Version 3 signatures carry the key ID in a fixed field,
+// later versions in subpackets.
+func parseSignaturePacket(body []byte) (*Signature, error) {
+	if len(body) == 0 {
+		return nil, errTruncated
+	}
+	switch version := body[0]; version {
+	case 3:
+		if len(body) < 15 {
+			return nil, errTruncated
+		}
+		return &Signature{KeyID: keyID(body[7:15])}, nil
+	case 4, 5, 6:
+		// Version, signature type, public-key and hash algorithms
+		if len(body) < 4 {
+			return nil, errTruncated
+		}
+		sig := &Signature{}
+		data := body[4:]
+		for range 2 { // hashed, then unhashed subpackets
+			n, size := 0, 2
+			if version == 6 {
+				size = 4
+			}
+			if len(data) < size {
+				return nil, errTruncated
+			}
+			if size == 2 {
+				n = int(binary.BigEndian.Uint16(data))
+			} else {
+				n = int(binary.BigEndian.Uint32(data))
+			}
+			data = data[size:]
+			if n < 0 || n > len(data) {
+				return nil, errTruncated
+			}
+			if err := parseSubpackets(data[:n], sig); err != nil {
+				return nil, err
+			}
+			data = data[n:]
+		}
+		return sig, nil
+	default:
+		return nil, fmt.Errorf("unsupported signature version %d", version)
+	}
+}
+
+// parseSubpackets reads the issuer and signer's user ID subpackets of data
+// into sig. A key ID already read is kept.
+func parseSubpackets(data []byte, sig *Signature) error {
+	for len(data) > 0 {
+		var n int
+		switch o := int(data[0]); {
+		case o < 192:
+			n, data = o, data[1:]
+		case o < 255:
+			if len(data) < 2 {
+				return errTruncated
+			}
+			n, data = (o-192)<<8+int(data[1])+192, data[2:]
+		default:
+			if len(data) < 5 {
+				return errTruncated
+			}
+			n, data = int(binary.BigEndian.Uint32(data[1:5])), data[5:]
+		}
+		if n < 1 || n > len(data) {
+			return errTruncated
+		}
+		typ, value := data[0]&0x7f, data[1:n]
+		data = data[n:]
+
+		switch typ {
+		case subpacketIssuer:
+			if len(value) == 8 && sig.KeyID == "" {
+				sig.KeyID = keyID(value)
+			}
+		case subpacketIssuerFingerprint:
+			// A version 4 fingerprint ends with the key ID, later
+			// versions start with it.
+			switch {
+			case sig.KeyID != "":
+			case len(value) == 21 && value[0] == 4:
+				sig.KeyID = keyID(value[13:])
+			case len(value) == 33:
+				sig.KeyID = keyID(value[1:9])
+			}
+		case subpacketSignerUserID:
+			sig.UserID = string(value)
+		}
+	}
+	return nil
+}
+
+func keyID(b []byte) string {
+	return strings.ToUpper(hex.EncodeToString(b))
+}
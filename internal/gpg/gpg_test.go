@@ -0,0 +1,126 @@
+package gpg
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// issuer is an issuer subpacket of key ID 0102030405060708.
+var issuer = []byte{9, subpacketIssuer, 1, 2, 3, 4, 5, 6, 7, 8}
+
+func TestParseSignaturePacket(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      []byte
+		wantKeyID string
+		wantErr   error
+	}{
+		{
+			name:      "v3 key ID",
+			body:      []byte{3, 5, 0, 0, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8},
+			wantKeyID: "0102030405060708",
+		},
+		{
+			name:      "v4 issuer in unhashed subpackets",
+			body:      append([]byte{4, 0, 1, 8, 0, 0, 0, 10}, issuer...),
+			wantKeyID: "0102030405060708",
+		},
+		{
+			name:      "v6 issuer in hashed subpackets",
+			body:      append(append([]byte{6, 0, 1, 8, 0, 0, 0, 10}, issuer...), 0, 0, 0, 0),
+			wantKeyID: "0102030405060708",
+		},
+		{name: "empty", body: nil, wantErr: errTruncated},
+		{name: "v3 truncated", body: []byte{3, 5, 0, 0, 0, 0, 0, 1, 2}, wantErr: errTruncated},
+		{name: "v4 two bytes", body: []byte{4, 0}, wantErr: errTruncated},
+		{name: "v4 header only", body: []byte{4, 0, 1}, wantErr: errTruncated},
+		{name: "v5 no subpacket length", body: []byte{5, 0, 1, 8}, wantErr: errTruncated},
+		{name: "v4 half subpacket length", body: []byte{4, 0, 1, 8, 0}, wantErr: errTruncated},
+		{name: "v4 hashed length past end", body: []byte{4, 0, 1, 8, 0, 20, 1}, wantErr: errTruncated},
+		{name: "v4 no unhashed length", body: []byte{4, 0, 1, 8, 0, 0}, wantErr: errTruncated},
+		{name: "v6 two bytes", body: []byte{6, 0}, wantErr: errTruncated},
+		{name: "v6 short subpacket length", body: []byte{6, 0, 1, 8, 0, 0}, wantErr: errTruncated},
+		{name: "v6 hashed length past end", body: []byte{6, 0, 1, 8, 0, 0, 1, 0, 9}, wantErr: errTruncated},
+		{name: "v6 huge hashed length", body: []byte{6, 0, 1, 8, 0xff, 0xff, 0xff, 0xff}, wantErr: errTruncated},
+		{name: "subpacket past end", body: []byte{4, 0, 1, 8, 0, 2, 9, subpacketIssuer, 0, 0}, wantErr: errTruncated},
+		{name: "empty subpacket", body: []byte{4, 0, 1, 8, 0, 1, 0, 0, 0}, wantErr: errTruncated},
+		{name: "two-octet subpacket length cut", body: []byte{4, 0, 1, 8, 0, 1, 200, 0, 0}, wantErr: errTruncated},
+		{name: "five-octet subpacket length cut", body: []byte{4, 0, 1, 8, 0, 3, 255, 0, 0, 0, 0}, wantErr: errTruncated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := parseSignaturePacket(tt.body)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("parseSignaturePacket() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSignaturePacket() error = %v", err)
+			}
+			if sig.KeyID != tt.wantKeyID {
+				t.Errorf("KeyID = %q, want %q", sig.KeyID, tt.wantKeyID)
+			}
+		})
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		packet    []byte
+		wantKeyID string
+		wantErr   bool
+	}{
+		{
+			name:      "v4 signature",
+			packet:    append([]byte{0xc2, 18, 4, 0, 1, 8, 0, 0, 0, 10}, issuer...),
+			wantKeyID: "0102030405060708",
+		},
+		{name: "truncated v4 signature", packet: []byte{0xc2, 2, 4, 0}, wantErr: true},
+		{name: "truncated v6 signature", packet: []byte{0xc2, 6, 6, 0, 1, 8, 0, 0}, wantErr: true},
+		{name: "packet length past end", packet: []byte{0xc2, 30, 4, 0}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := ParseSignature(armor(tt.packet))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSignature() = %+v, want error", sig)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSignature() error = %v", err)
+			}
+			if sig.KeyID != tt.wantKeyID {
+				t.Errorf("KeyID = %q, want %q", sig.KeyID, tt.wantKeyID)
+			}
+		})
+	}
+}
+
+// FuzzParseSignature checks that no signature packet, however malformed,
+// makes the parser panic: anyone can push a commit with any gpgsig header.
+func FuzzParseSignature(f *testing.F) {
+	f.Add([]byte{3, 5, 0, 0, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8})
+	f.Add(append([]byte{4, 0, 1, 8, 0, 0, 0, 10}, issuer...))
+	f.Add([]byte{4, 0})
+	f.Add([]byte{6, 0, 1, 8, 0, 0})
+	f.Add([]byte{4, 0, 1, 8, 0, 3, 255, 0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, body []byte) {
+		_, _ = parseSignaturePacket(body)
+		_, _ = ParseSignature(armor(append([]byte{0xc2, byte(min(len(body), 191))}, body...)))
+	})
+}
+
+// armor wraps data in an ASCII-armored signature block.
+func armor(data []byte) string {
+	return "-----BEGIN PGP SIGNATURE-----\n\n" +
+		base64.StdEncoding.EncodeToString(data) +
+		"\n-----END PGP SIGNATURE-----\n"
+}
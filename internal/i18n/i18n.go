@@ -0,0 +1,168 @@
+// Package i18n translates the human-readable reports and formats their dates
+// and durations for the reader's language.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default is the language of reports when none is given.
+const Default = "en"
+
+// Languages lists the languages reports can be written in.
+var Languages = []string{"en", "fr", "de", "es"}
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// catalog is the content of a locale file.
+type catalog struct {
+	// Months are the abbreviated month names, January first.
+	Months []string `yaml:"months"`
+	// Date and DateTime lay out dates with the placeholders {day},
+	// {month}, {year} and {time}. Empty keeps ISO 8601 dates.
+	Date     string `yaml:"date"`
+	DateTime string `yaml:"date_time"`
+	// Units name hours (h), minutes (m) and seconds (s) in durations.
+	// Empty keeps Go durations such as 2m34.5s.
+	Units map[string]string `yaml:"units"`
+	// Decimal separates the integer and fractional parts of numbers
+	// (default ".").
+	Decimal string `yaml:"decimal"`
+	// Messages maps English format strings to their translation.
+	Messages map[string]string `yaml:"messages"`
+}
+
+// Printer writes messages, dates and durations in one language. Messages
+// missing from its catalog are written in English.
+type Printer struct {
+	lang string
+	cat  catalog
+}
+
+// New returns the printer of lang, an ISO 639-1 code optionally followed by
+// a region or encoding ("fr", "fr-CA", "de_DE.UTF-8"). Empty selects
+// English.
+func New(lang string) (*Printer, error) {
+	base := strings.ToLower(lang)
+	if i := strings.IndexAny(base, "-_."); i >= 0 {
+		base = base[:i]
+	}
+	if base == "" {
+		base = Default
+	}
+
+	data, err := locales.ReadFile("locales/" + base + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unsupported language %q: use one of %s", lang, strings.Join(Languages, ", "))
+	}
+	p := &Printer{lang: base}
+	if err := yaml.Unmarshal(data, &p.cat); err != nil {
+		return nil, fmt.Errorf("failed to parse the %s catalog: %w", base, err)
+	}
+	return p, nil
+}
+
+// Lang returns the language code of the printer.
+func (p *Printer) Lang() string {
+	return p.lang
+}
+
+// T translates a message.
+func (p *Printer) T(msg string) string {
+	if t, ok := p.cat.Messages[msg]; ok {
+		return t
+	}
+	return msg
+}
+
+// Sprintf formats according to the translation of format.
+func (p *Printer) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(p.T(format), args...)
+}
+
+// Date formats the day of t, such as 2024-03-01 in English and 1 mars 2024
+// in French.
+func (p *Printer) Date(t time.Time) string {
+	if p.cat.Date == "" {
+		return t.Format("2006-01-02")
+	}
+	return p.layout(p.cat.Date, t)
+}
+
+// DateTime formats t to the minute with its time zone, such as
+// 2024-03-01T15:04:05Z in English and 1 mars 2024 15:04 UTC in French.
+func (p *Printer) DateTime(t time.Time) string {
+	if p.cat.DateTime == "" {
+		return t.Format(time.RFC3339)
+	}
+	return p.layout(p.cat.DateTime, t)
+}
+
+func (p *Printer) layout(layout string, t time.Time) string {
+	month := t.Format("Jan")
+	if m := int(t.Month()) - 1; m < len(p.cat.Months) {
+		month = p.cat.Months[m]
+	}
+	return strings.NewReplacer(
+		"{day}", strconv.Itoa(t.Day()),
+		"{month}", month,
+		"{year}", strconv.Itoa(t.Year()),
+		"{time}", t.Format("15:04 MST"),
+	).Replace(layout)
+}
+
+// Duration formats d in hours, minutes and seconds, such as 2m34.5s in
+// English and 2 min 35 s in French. Durations under a minute keep a tenth
+// of a second.
+func (p *Printer) Duration(d time.Duration) string {
+	if len(p.cat.Units) == 0 {
+		return d.String()
+	}
+	if d < time.Minute {
+		s := strconv.FormatFloat(math.Round(d.Seconds()*10)/10, 'f', -1, 64)
+		return p.decimal(s) + " " + p.cat.Units["s"]
+	}
+
+	d = d.Round(time.Second)
+	var parts []string
+	if h := int(d.Hours()); h > 0 {
+		parts = append(parts, strconv.Itoa(h)+" "+p.cat.Units["h"])
+	}
+	if m := int(d.Minutes()) % 60; m > 0 {
+		parts = append(parts, strconv.Itoa(m)+" "+p.cat.Units["m"])
+	}
+	if s := int(d.Seconds()) % 60; s > 0 {
+		parts = append(parts, strconv.Itoa(s)+" "+p.cat.Units["s"])
+	}
+	return strings.Join(parts, " ")
+}
+
+// Float formats f with prec decimals.
+func (p *Printer) Float(f float64, prec int) string {
+	return p.decimal(strconv.FormatFloat(f, 'f', prec, 64))
+}
+
+func (p *Printer) decimal(s string) string {
+	if p.cat.Decimal == "" {
+		return s
+	}
+	return strings.Replace(s, ".", p.cat.Decimal, 1)
+}
+
+// DurationString formats a duration written by time.Duration.String, as
+// scan results record it, leaving other text as is.
+func (p *Printer) DurationString(s string) string {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return s
+	}
+	return p.Duration(d)
+}
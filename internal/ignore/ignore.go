@@ -0,0 +1,209 @@
+// Package ignore parses .gogitsomeprivacyignore files that repository
+// maintainers use to mark intentional attributions as accepted.
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// FileName is the name of the in-repo ignore file.
+const FileName = ".gogitsomeprivacyignore"
+
+// Rules holds the parsed contents of an ignore file.
+//
+// Each non-empty line that is not a comment is one rule:
+//
+//	# comment
+//	MAINTAINERS          path glob (matched against repository file paths)
+//	docs/**/*.md         "**" matches any number of directories
+//	text:John Doe        literal matched text to accept (case-insensitive)
+//	regex:^Signed-off-by regular expression matched against matched text and context
+type Rules struct {
+	paths []*regexp.Regexp
+	texts []string
+	regex []*regexp.Regexp
+	repos []string
+}
+
+// Spec lists rules configured outside a repository, such as in the config file.
+type Spec struct {
+	Texts   []string // literal matched texts (case-insensitive)
+	Regexes []string // matched against matched text and context
+	Paths   []string // repository file path globs
+	Repos   []string // owner/name globs of repositories to skip, e.g. "acme/*"
+}
+
+// New builds rules from a spec.
+func New(spec Spec) (*Rules, error) {
+	r := &Rules{}
+	for _, text := range spec.Texts {
+		if text = strings.TrimSpace(text); text != "" {
+			r.texts = append(r.texts, strings.ToLower(text))
+		}
+	}
+	for _, expr := range spec.Regexes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", expr, err)
+		}
+		r.regex = append(r.regex, re)
+	}
+	for _, glob := range spec.Paths {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			r.paths = append(r.paths, globToRegexp(glob))
+		}
+	}
+	for _, glob := range spec.Repos {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid repository pattern %q: %w", glob, err)
+		}
+		r.repos = append(r.repos, strings.ToLower(glob))
+	}
+	return r, nil
+}
+
+// Merge returns rules that ignore whatever r or other ignores. Either may be nil.
+func (r *Rules) Merge(other *Rules) *Rules {
+	switch {
+	case other.Empty():
+		return r
+	case r.Empty():
+		return other
+	}
+	return &Rules{
+		paths: append(append([]*regexp.Regexp(nil), r.paths...), other.paths...),
+		texts: append(append([]string(nil), r.texts...), other.texts...),
+		regex: append(append([]*regexp.Regexp(nil), r.regex...), other.regex...),
+		repos: append(append([]string(nil), r.repos...), other.repos...),
+	}
+}
+
+// Parse parses an ignore file.
+func Parse(data []byte) (*Rules, error) {
+	r := &Rules{}
+	sc := bufio.NewScanner(strings.NewReader(string(data)))
+
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "text:"):
+			if text := strings.TrimSpace(strings.TrimPrefix(line, "text:")); text != "" {
+				r.texts = append(r.texts, strings.ToLower(text))
+			}
+		case strings.HasPrefix(line, "regex:"):
+			re, err := regexp.Compile(strings.TrimSpace(strings.TrimPrefix(line, "regex:")))
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid regex: %w", FileName, lineNo, err)
+			}
+			r.regex = append(r.regex, re)
+		default:
+			r.paths = append(r.paths, globToRegexp(line))
+		}
+	}
+
+	return r, sc.Err()
+}
+
+// Empty reports whether the rules contain nothing.
+func (r *Rules) Empty() bool {
+	return r == nil || len(r.paths)+len(r.texts)+len(r.regex)+len(r.repos) == 0
+}
+
+// MatchRepo reports whether a repository, given as owner/name, is ignored.
+// Patterns are matched case-insensitively with path.Match.
+func (r *Rules) MatchRepo(fullName string) bool {
+	if r == nil {
+		return false
+	}
+	fullName = strings.ToLower(fullName)
+	for _, glob := range r.repos {
+		if ok, _ := path.Match(glob, fullName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether a repository file path is ignored.
+func (r *Rules) MatchPath(p string) bool {
+	if r == nil {
+		return false
+	}
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	for _, re := range r.paths {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchText reports whether a finding with the given matched text and
+// surrounding context is ignored.
+func (r *Rules) MatchText(matched, context string) bool {
+	if r == nil {
+		return false
+	}
+	lower := strings.ToLower(matched)
+	for _, t := range r.texts {
+		if t == lower {
+			return true
+		}
+	}
+	for _, re := range r.regex {
+		if re.MatchString(matched) || re.MatchString(context) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp converts a gitignore-style glob into an anchored regular expression.
+// Patterns without a slash match at any depth; a trailing slash matches a directory.
+func globToRegexp(glob string) *regexp.Regexp {
+	anchored := strings.HasPrefix(glob, "/")
+	glob = strings.TrimPrefix(glob, "/")
+	dir := strings.HasSuffix(glob, "/")
+	glob = strings.TrimSuffix(glob, "/")
+	if !strings.Contains(glob, "/") && !anchored {
+		glob = "**/" + glob
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			if i+1 < len(glob) && glob[i+1] == '/' {
+				i++
+				b.WriteString("(?:.*/)?")
+			} else {
+				b.WriteString(".*")
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dir {
+		b.WriteString("/.*")
+	} else {
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,174 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// fileVersion is the version of the encrypted file format.
+	fileVersion = 1
+	// fileIterations is the PBKDF2 iteration count deriving the key.
+	fileIterations = 600_000
+)
+
+// ErrWrongPassphrase is returned when the encrypted file cannot be
+// decrypted with the passphrase given.
+var ErrWrongPassphrase = errors.New("wrong passphrase for the encrypted token file")
+
+// File keeps secrets in a file encrypted with AES-256-GCM, under a key
+// derived from a passphrase with PBKDF2-SHA256. The passphrase is only asked
+// for when the file is read or written.
+type File struct {
+	Path string
+	// Passphrase returns the passphrase of the file, a new one with create.
+	Passphrase func(create bool) (string, error)
+}
+
+// encryptedFile is the format of the file.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (f *File) Name() string { return "encrypted file " + f.Path }
+
+func (f *File) Get(account string) (string, error) {
+	secrets, _, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (f *File) Set(account, secret string) error {
+	secrets, passphrase, err := f.load()
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if secrets == nil {
+		secrets = make(map[string]string)
+	}
+	secrets[account] = secret
+	return f.save(secrets, passphrase)
+}
+
+func (f *File) Delete(account string) error {
+	secrets, passphrase, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[account]; !ok {
+		return ErrNotFound
+	}
+	delete(secrets, account)
+	if len(secrets) == 0 {
+		return os.Remove(f.Path)
+	}
+	return f.save(secrets, passphrase)
+}
+
+// load decrypts the secrets of the file, returning ErrNotFound without asking
+// for the passphrase when there is no file.
+func (f *File) load() (map[string]string, string, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	var ef encryptedFile
+	if err := json.Unmarshal(data, &ef); err != nil {
+		return nil, "", fmt.Errorf("failed to parse token file %s: %w", f.Path, err)
+	}
+	if ef.Version != fileVersion {
+		return nil, "", fmt.Errorf("unsupported token file version %d", ef.Version)
+	}
+
+	passphrase, err := f.Passphrase(false)
+	if err != nil {
+		return nil, "", err
+	}
+	gcm, err := newGCM(passphrase, ef.Salt)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err := gcm.Open(nil, ef.Nonce, ef.Ciphertext, nil)
+	if err != nil {
+		return nil, "", ErrWrongPassphrase
+	}
+	var secrets map[string]string
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, "", fmt.Errorf("failed to parse token file %s: %w", f.Path, err)
+	}
+	return secrets, passphrase, nil
+}
+
+// save encrypts secrets into the file with a new salt and nonce. A new
+// passphrase is asked for unless given.
+func (f *File) save(secrets map[string]string, passphrase string) error {
+	if passphrase == "" {
+		var err error
+		if passphrase, err = f.Passphrase(true); err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return fmt.Errorf("the passphrase of the token file must not be empty")
+		}
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	ef := encryptedFile{Version: fileVersion, Salt: make([]byte, 16)}
+	rand.Read(ef.Salt)
+	gcm, err := newGCM(passphrase, ef.Salt)
+	if err != nil {
+		return err
+	}
+	ef.Nonce = make([]byte, gcm.NonceSize())
+	rand.Read(ef.Nonce)
+	ef.Ciphertext = gcm.Seal(nil, ef.Nonce, plaintext, nil)
+
+	data, err := json.Marshal(ef)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create token file directory: %w", err)
+	}
+	if err := os.WriteFile(f.Path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	return nil
+}
+
+// newGCM derives the key of passphrase and salt.
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, fileIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
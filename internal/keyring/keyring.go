@@ -0,0 +1,37 @@
+// Package keyring stores secrets such as API tokens outside of the
+// configuration file: in the keyring of the operating system (the macOS
+// Keychain, or the Secret Service of GNOME Keyring or KWallet on Linux), or
+// where there is none, in a file encrypted with a passphrase.
+package keyring
+
+import (
+	"errors"
+)
+
+// Service names the secrets of GoGitSomePrivacy in the system keyring.
+const Service = "gogitsomeprivacy"
+
+var (
+	// ErrNotFound is returned when a store holds no secret for an account.
+	ErrNotFound = errors.New("secret not found")
+	// ErrUnavailable is returned by System when the operating system has no
+	// keyring usable from this session.
+	ErrUnavailable = errors.New("no system keyring available")
+)
+
+// Store keeps one secret per account.
+type Store interface {
+	// Name describes the store in messages.
+	Name() string
+	// Get returns the secret of account, or ErrNotFound.
+	Get(account string) (string, error)
+	// Set stores the secret of account, replacing any previous one.
+	Set(account, secret string) error
+	// Delete removes the secret of account, or returns ErrNotFound.
+	Delete(account string) error
+}
+
+// System returns the keyring of the operating system, or ErrUnavailable.
+func System() (Store, error) {
+	return system()
+}
@@ -0,0 +1,65 @@
+package keyring
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// notFoundStatus is the exit status of security when no item matches.
+const notFoundStatus = 44
+
+// keychain stores secrets in the login keychain with the security tool.
+type keychain struct {
+	path string
+}
+
+func system() (Store, error) {
+	path, err := exec.LookPath("security")
+	if err != nil {
+		return nil, ErrUnavailable
+	}
+	return keychain{path: path}, nil
+}
+
+func (k keychain) Name() string { return "macOS Keychain" }
+
+func (k keychain) Get(account string) (string, error) {
+	out, err := exec.Command(k.path, "find-generic-password", "-s", Service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", k.error(err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (k keychain) Set(account, secret string) error {
+	// Commands read from stdin keep the secret out of the process list
+	cmd := exec.Command(k.path, "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %q -a %q -X %s\n",
+		Service, account, hex.EncodeToString([]byte(secret))))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to store secret in keychain: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (k keychain) Delete(account string) error {
+	if err := exec.Command(k.path, "delete-generic-password", "-s", Service, "-a", account).Run(); err != nil {
+		return k.error(err)
+	}
+	return nil
+}
+
+// error converts a failure of the security tool.
+func (k keychain) error(err error) error {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == notFoundStatus {
+		return ErrNotFound
+	}
+	return fmt.Errorf("failed to access keychain: %w", err)
+}
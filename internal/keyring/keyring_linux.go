@@ -0,0 +1,70 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secretService stores secrets with the Secret Service API of the desktop
+// session through the secret-tool command of libsecret.
+type secretService struct {
+	path string
+}
+
+func system() (Store, error) {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil, ErrUnavailable
+	}
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil, ErrUnavailable
+	}
+	return secretService{path: path}, nil
+}
+
+func (s secretService) Name() string { return "Secret Service keyring" }
+
+func (s secretService) Get(account string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(s.path, "lookup", "service", Service, "account", account)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	// lookup exits with status 1 and no output when nothing matches
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && stdout.Len() == 0 && stderr.Len() == 0 {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read keyring: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
+
+func (s secretService) Set(account, secret string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(s.path, "store", "--label", "GoGitSomePrivacy ("+account+")", "service", Service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to store secret in keyring: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (s secretService) Delete(account string) error {
+	// clear succeeds whether or not a secret matched
+	if _, err := s.Get(account); err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(s.path, "clear", "service", Service, "account", account)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to delete secret from keyring: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
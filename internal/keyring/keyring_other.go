@@ -0,0 +1,7 @@
+//go:build !darwin && !linux
+
+package keyring
+
+func system() (Store, error) {
+	return nil, ErrUnavailable
+}
@@ -0,0 +1,51 @@
+package keyring
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// PassphraseEnv names the environment variable holding the passphrase of the
+// encrypted file, for non-interactive use.
+const PassphraseEnv = "GGSP_KEYRING_PASSPHRASE"
+
+// Passphrase returns the passphrase of the encrypted file from PassphraseEnv,
+// or else asks for it on the terminal, twice for a new one.
+func Passphrase(create bool) (string, error) {
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return p, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("the encrypted token file needs a passphrase: set %s", PassphraseEnv)
+	}
+
+	prompt := "Passphrase of the encrypted token file: "
+	if create {
+		prompt = "New passphrase for the encrypted token file: "
+	}
+	p, err := readPassword(fd, prompt)
+	if err != nil || !create {
+		return p, err
+	}
+	again, err := readPassword(fd, "Repeat the passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if again != p {
+		return "", fmt.Errorf("the passphrases do not match")
+	}
+	return p, nil
+}
+
+func readPassword(fd int, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	raw, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,190 @@
+// Package metrics defines the Prometheus metrics exported by the serve and
+// watch commands. Metrics are always collected; they are only exposed when a
+// command serves Handler.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ggsp"
+
+var (
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "github_api_requests_total",
+		Help:      "GitHub API requests by endpoint and HTTP status code (\"error\" if no response).",
+	}, []string{"endpoint", "code"})
+
+	rateLimitWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "github_rate_limit_wait_seconds",
+		Help:      "Time spent waiting on the client-side rate limiter before each API request.",
+		Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+	})
+
+	rateLimitRemaining = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "github_rate_limit_remaining",
+		Help:      "Remaining GitHub API requests reported by the last response.",
+	})
+
+	commitsScanned = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commits_scanned_total",
+		Help:      "Commits scanned for PII.",
+	})
+
+	matchesFound = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "matches_found_total",
+		Help:      "Commits with PII matches, before baselines and suppressions.",
+	})
+
+	scanErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scan_errors_total",
+		Help:      "Scan errors by severity; failed scans count as fatal.",
+	}, []string{"severity"})
+
+	scans = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scans_total",
+		Help:      "Finished scans by status (completed, incomplete, failed).",
+	}, []string{"status"})
+
+	scanDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scan_duration_seconds",
+		Help:      "Duration of scans by status.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200},
+	}, []string{"status"})
+
+	scansRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scans_running",
+		Help:      "Scans currently running.",
+	})
+
+	queueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scan_queue_depth",
+		Help:      "Scans waiting for a runner in serve mode.",
+	})
+
+	workerQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_queue_depth",
+		Help:      "Tasks waiting for a worker, by pool (fetch for repositories, batch for users).",
+	}, []string{"pool"})
+
+	workersActive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workers_active",
+		Help:      "Workers processing a task, by pool.",
+	}, []string{"pool"})
+
+	workerLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_limit",
+		Help:      "Workers allowed to process tasks at a time, by pool; fetch pools adapt it to the API rate budget.",
+	}, []string{"pool"})
+
+	registry = prometheus.NewRegistry()
+)
+
+func init() {
+	registry.MustRegister(
+		apiRequests, rateLimitWait, rateLimitRemaining,
+		commitsScanned, matchesFound, scanErrors,
+		scans, scanDuration, scansRunning, queueDepth,
+		workerQueueDepth, workersActive, workerLimit,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveAPIRequest counts an API request. A status of 0 means the
+// request failed without a response.
+func ObserveAPIRequest(endpoint string, status int) {
+	code := "error"
+	if status != 0 {
+		code = strconv.Itoa(status)
+	}
+	apiRequests.WithLabelValues(endpoint, code).Inc()
+}
+
+// ObserveRateLimitWait records time spent waiting on the rate limiter.
+func ObserveRateLimitWait(d time.Duration) {
+	rateLimitWait.Observe(d.Seconds())
+}
+
+// SetRateLimitRemaining records the remaining API budget.
+func SetRateLimitRemaining(n int) {
+	rateLimitRemaining.Set(float64(n))
+}
+
+// ObserveCommits counts scanned commits and commits with matches.
+func ObserveCommits(commits, matches int) {
+	commitsScanned.Add(float64(commits))
+	matchesFound.Add(float64(matches))
+}
+
+// ScanStarted marks a scan as running.
+func ScanStarted() {
+	scansRunning.Inc()
+}
+
+// ScanFinished records the outcome of a scan started with ScanStarted.
+func ScanFinished(d time.Duration, result *models.ScanResult, err error) {
+	scansRunning.Dec()
+
+	status := "completed"
+	switch {
+	case err != nil:
+		status = "failed"
+		scanErrors.WithLabelValues("fatal").Inc()
+	case result.Incomplete:
+		status = "incomplete"
+	}
+	if result != nil {
+		for _, e := range result.Errors {
+			scanErrors.WithLabelValues(e.Severity).Inc()
+		}
+	}
+	scans.WithLabelValues(status).Inc()
+	scanDuration.WithLabelValues(status).Observe(d.Seconds())
+}
+
+// SetQueueDepth records the number of queued scans.
+func SetQueueDepth(n int) {
+	queueDepth.Set(float64(n))
+}
+
+// AddWorkerQueueDepth adds delta to the tasks queued in the named pool.
+// Pools of the same name, such as those of concurrent scans, add up.
+func AddWorkerQueueDepth(pool string, delta int) {
+	workerQueueDepth.WithLabelValues(pool).Add(float64(delta))
+}
+
+// AddWorkersActive adds delta to the busy workers of the named pool.
+func AddWorkersActive(pool string, delta int) {
+	workersActive.WithLabelValues(pool).Add(float64(delta))
+}
+
+// AddWorkerLimit adds delta to the worker limit of the named pool.
+func AddWorkerLimit(pool string, delta int) {
+	workerLimit.WithLabelValues(pool).Add(float64(delta))
+}
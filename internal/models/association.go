@@ -0,0 +1,37 @@
+package models
+
+// AssociationType is a kind of public link between an account and a context
+// revealing who is behind it.
+type AssociationType string
+
+const (
+	// AssociationOrgMembership is a public membership of an organization,
+	// which often names an employer, school or place.
+	AssociationOrgMembership AssociationType = "org_membership"
+	// AssociationOrgProfile is a field of the profile of such an
+	// organization naming the user, such as its public email.
+	AssociationOrgProfile AssociationType = "org_profile"
+	// AssociationCodeowners is a CODEOWNERS rule naming the account or one
+	// of the emails searched for.
+	AssociationCodeowners AssociationType = "codeowners"
+	// AssociationMailmap is a .mailmap entry mapping the account's noreply
+	// email to another name or email.
+	AssociationMailmap AssociationType = "mailmap"
+)
+
+// Association is a place where the scanned username itself is publicly
+// linked to an identity-revealing context. Unlike matches, associations do
+// not need the PII searched for to appear: the link is the exposure.
+type Association struct {
+	Type         AssociationType `json:"type"`
+	Organization string          `json:"organization,omitempty"`
+	Repository   string          `json:"repository,omitempty"`
+	Path         string          `json:"path,omitempty"`
+	Line         int             `json:"line,omitempty"`
+	// Field is the profile field of an org_profile association.
+	Field string `json:"field,omitempty"`
+	// Detail is what the link reveals: the organization's profile, or the
+	// line of the file.
+	Detail string `json:"detail,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
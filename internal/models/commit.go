@@ -7,10 +7,17 @@ type Commit struct {
 	SHA        string    `json:"sha"`
 	Repository string    `json:"repository"`
 	Message    string    `json:"message"`
+	Trailers   []Trailer `json:"trailers,omitempty"`
 	Author     Author    `json:"author"`
 	Committer  Author    `json:"committer"`
 	Date       time.Time `json:"date"`
 	URL        string    `json:"url"`
+
+	// Roles are the scanned user's roles on the commit, when known.
+	Roles []CommitRole `json:"roles,omitempty"`
+
+	// Signature is the ASCII-armored signature of a signed commit.
+	Signature string `json:"-"`
 }
 
 // Author represents commit author information.
@@ -20,6 +27,18 @@ type Author struct {
 	Login string `json:"login"`
 }
 
+// RepoState records the head of a repository's default branch when it was
+// last scanned, so an incremental scan can skip the repository or fetch only
+// newer commits.
+type RepoState struct {
+	Head string    `json:"head"`
+	Date time.Time `json:"date"` // commit date of Head
+	ETag string    `json:"etag,omitempty"`
+	// Fingerprint identifies the settings the repository was scanned with;
+	// a state recorded with other settings is not reused.
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
 // Repository represents a GitHub repository.
 type Repository struct {
 	FullName    string `json:"full_name"`
@@ -28,4 +47,45 @@ type Repository struct {
 	Description string `json:"description"`
 	URL         string `json:"url"`
 	Private     bool   `json:"private"`
+	Fork        bool   `json:"fork"`
+	// Size is the repository size in kilobytes, zero when unknown.
+	Size int `json:"size,omitempty"`
+	// HasPages is set when the repository publishes a GitHub Pages site.
+	HasPages bool `json:"has_pages,omitempty"`
+}
+
+// CodeResult is a file found by code search, with the fragments of its
+// content that matched.
+type CodeResult struct {
+	Repository string   `json:"repository"`
+	Path       string   `json:"path"`
+	URL        string   `json:"url"`
+	Fragments  []string `json:"fragments,omitempty"`
+}
+
+// PullRequest is a pull request that includes a commit, linked to the
+// findings in the commit message to show where it was discussed.
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"-"`
+	URL    string `json:"url"`
+	State  string `json:"state"` // open, closed or merged
+	// Leaks is set when the title or description of the pull request
+	// contain PII too; it is then also reported as a finding of its own.
+	Leaks bool `json:"leaks,omitempty"`
+}
+
+// GPGKey is a public GPG key of an account, with the IDs of the key and its
+// subkeys and the user IDs it was published with.
+type GPGKey struct {
+	KeyIDs  []string // 16 uppercase hex digits
+	UserIDs []string // e.g. "John Doe <john@example.com>"
+}
+
+// RepoFile is a file or directory of a repository's default branch.
+type RepoFile struct {
+	Path string `json:"path"` // from the root of the repository
+	Dir  bool   `json:"dir,omitempty"`
+	URL  string `json:"url"`
 }
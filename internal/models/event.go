@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ActivityEvent is an entry of a user's recent public activity, such as a
+// push, a comment or a repository creation.
+type ActivityEvent struct {
+	// Type is the provider's name for the event, e.g. PushEvent.
+	Type       string
+	Repository string
+	URL        string
+	CreatedAt  time.Time
+
+	// Commits are the commits of a push.
+	Commits []*Commit
+	// Title is the title of an opened issue or pull request, and Body its
+	// description, the text of a comment or review, or the description of
+	// a created repository.
+	Title string
+	Body  string
+}
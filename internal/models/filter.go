@@ -0,0 +1,115 @@
+package models
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// CommitRole is how a user is related to a commit.
+type CommitRole string
+
+const (
+	RoleAuthor    CommitRole = "author"
+	RoleCommitter CommitRole = "committer"
+	RoleCoAuthor  CommitRole = "co-author"
+)
+
+// coAuthorTrailer is the trailer key crediting additional authors.
+const coAuthorTrailer = "co-authored-by"
+
+// CommitFilter selects the commits of a user by their role on them.
+type CommitFilter struct {
+	Username string
+	// Emails identify the user in co-author trailers, in addition to the
+	// username and its noreply addresses.
+	Emails []string
+
+	Author    bool
+	Committer bool
+	CoAuthor  bool
+
+	// Since, if set, skips commits dated before it.
+	Since time.Time
+}
+
+// AuthorFilter selects the commits authored by username.
+func AuthorFilter(username string) CommitFilter {
+	return CommitFilter{Username: username, Author: true}
+}
+
+// NewCommitFilter selects the commits on which username has one of roles.
+func NewCommitFilter(username string, emails []string, roles []CommitRole) CommitFilter {
+	f := CommitFilter{Username: username, Emails: emails}
+	for _, role := range roles {
+		switch role {
+		case RoleAuthor:
+			f.Author = true
+		case RoleCommitter:
+			f.Committer = true
+		case RoleCoAuthor:
+			f.CoAuthor = true
+		}
+	}
+	return f
+}
+
+// Roles returns the selected roles the user has on commit, in the order
+// author, committer, co-author.
+func (f CommitFilter) Roles(commit *Commit) []CommitRole {
+	var roles []CommitRole
+	if f.Author && f.isUser(commit.Author) {
+		roles = append(roles, RoleAuthor)
+	}
+	if f.Committer && f.isUser(commit.Committer) {
+		roles = append(roles, RoleCommitter)
+	}
+	if f.CoAuthor && f.isCoAuthor(commit) {
+		roles = append(roles, RoleCoAuthor)
+	}
+	return roles
+}
+
+// isUser reports whether a commit identity belongs to the user.
+func (f CommitFilter) isUser(a Author) bool {
+	return (a.Login != "" && strings.EqualFold(a.Login, f.Username)) || f.isEmail(a.Email)
+}
+
+// isCoAuthor reports whether a Co-authored-by trailer of commit names the
+// user, by email or by a name equal to the username.
+func (f CommitFilter) isCoAuthor(commit *Commit) bool {
+	for _, t := range commit.Trailers {
+		if !strings.EqualFold(t.Key, coAuthorTrailer) {
+			continue
+		}
+		addr, err := mail.ParseAddress(t.Value)
+		if err != nil {
+			continue
+		}
+		if f.isEmail(addr.Address) || strings.EqualFold(addr.Name, f.Username) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmail reports whether email is one of the user's emails or a GitHub
+// noreply address of the username (username@ or id+username@).
+func (f CommitFilter) isEmail(email string) bool {
+	if email == "" {
+		return false
+	}
+	for _, e := range f.Emails {
+		if strings.EqualFold(e, email) {
+			return true
+		}
+	}
+	local, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if !ok || domain != "users.noreply.github.com" || f.Username == "" {
+		return false
+	}
+	if _, name, found := strings.Cut(local, "+"); found {
+		local = name
+	}
+	return local == strings.ToLower(f.Username)
+}
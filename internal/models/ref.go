@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RefKind is the kind of a repository ref scanned besides commits.
+type RefKind string
+
+const (
+	RefRelease RefKind = "release"
+	RefTag     RefKind = "tag" // annotated tags only; lightweight tags have no message
+	RefBranch  RefKind = "branch"
+)
+
+// Ref is a release, annotated tag or branch of a repository, whose name and
+// text are written by hand.
+type Ref struct {
+	Kind RefKind
+	Name string
+	// Body is the notes of a release or the message of a tag.
+	Body string
+	// Tagger is who created a tag.
+	Tagger Author
+	URL    string
+	Date   time.Time
+}
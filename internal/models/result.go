@@ -1,12 +1,72 @@
 package models
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // PIIMatch represents a detected instance of PII in a commit.
 type PIIMatch struct {
-	Commit      Commit       `json:"commit"`
-	PIIType     PIIType      `json:"pii_type"`
-	Locations   []Location   `json:"locations"`
-	Confidence  float64      `json:"confidence"`
-	Context     string       `json:"context"`
+	Commit     Commit     `json:"commit"`
+	PIIType    PIIType    `json:"pii_type"`
+	Locations  []Location `json:"locations"`
+	Confidence float64    `json:"confidence"`
+	Severity   Severity   `json:"severity,omitempty"`
+	Context    string     `json:"context"`
+	Source     Source     `json:"source,omitempty"`
+	// PullRequests are the pull requests including the commit, looked up
+	// for matches in its message.
+	PullRequests []PullRequest `json:"pull_requests,omitempty"`
+	// Provider is the hosting service the match was found on, set in
+	// multi-provider scans.
+	Provider string `json:"provider,omitempty"`
+	// Advice tells the person scanned how to remediate the match.
+	Advice string `json:"advice,omitempty"`
+}
+
+// Source identifies where the scanned content came from.
+type Source string
+
+const (
+	SourceCommit      Source = "commit"
+	SourcePagesBranch Source = "pages_branch"
+	SourcePagesSite   Source = "pages_site"
+	SourceEmailSearch Source = "email_search" // commits found by searching an author email
+	SourceEvents      Source = "events"       // pushes, comments and repositories of the user's public activity
+	SourceRefs        Source = "refs"         // release notes, tag messages and branch names
+	SourceArchive     Source = "archive"      // pushed commits recorded in GH Archive, no longer found on GitHub
+	SourceFiles       Source = "files"        // package manifests, author lists and workflows of the default branch
+	SourceNameSearch  Source = "name_search"  // commits found by searching an author name
+	SourceCodeSearch  Source = "code_search"  // file contents found by code search
+	SourcePullRequest Source = "pull_request" // titles and descriptions of the pull requests of matching commits
+	SourceSignature   Source = "signature"    // user IDs of the GPG keys commits were signed with
+)
+
+// Severity ranks how likely a match is to expose the person searched for.
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+// Rank orders severities from low (1) to high (3); unknown severities rank 0.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	}
+	return 0
 }
 
 // PIIType represents the type of personally identifiable information.
@@ -18,29 +78,271 @@ const (
 	PIITypeLastName  PIIType = "last_name"
 	PIITypeEmail     PIIType = "email"
 	PIITypePhone     PIIType = "phone"
+	PIITypeCustom    PIIType = "custom"
+
+	// PIITypeExposedEmailConfig marks commits made with a personal email
+	// address instead of the GitHub noreply one, whatever their names.
+	PIITypeExposedEmailConfig PIIType = "exposed_email_config"
+	// PIITypeGravatar marks commit emails and hashes that share the Gravatar
+	// hash of an email searched for, linking them to it.
+	PIITypeGravatar PIIType = "gravatar"
 )
 
 // Location represents where PII was found in the commit.
 type Location struct {
-	Field    string `json:"field"`     // e.g., "message", "author_name", "diff"
-	Line     int    `json:"line"`      // Line number if applicable
-	Column   int    `json:"column"`    // Column number if applicable
-	Matched  string `json:"matched"`   // The actual text that matched
+	Field   string `json:"field"`   // e.g., "message", "author_name", "diff"
+	Line    int    `json:"line"`    // Line number if applicable
+	Column  int    `json:"column"`  // Column number if applicable
+	Matched string `json:"matched"` // The actual text that matched
+
+	Confidence float64 `json:"confidence,omitempty"` // Per-location score, when scored
+	Rule       string  `json:"rule,omitempty"`       // Custom rule that matched, if any
+	Identity   string  `json:"identity,omitempty"`   // Named identity that matched, if any
+	Alias      string  `json:"alias,omitempty"`      // Nickname of the first name that matched, if any
+
+	// Fingerprint identifies the finding across runs and output formats
+	// (see Fingerprint).
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// Fingerprint returns the stable ID of a finding: a hash of the repository
+// and commit it was found in, its field, its byte offset in the field and
+// its type. Documents without a commit SHA, such as events and web pages,
+// are identified by their URL.
+func Fingerprint(commit Commit, field string, offset int, piiType PIIType) string {
+	id := commit.SHA
+	if id == "" {
+		id = commit.URL
+	}
+	data := strings.Join([]string{commit.Repository, id, field, strconv.Itoa(offset), string(piiType)}, "\x00")
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:8])
 }
 
+// SchemaVersion is the version of the JSON Schema results follow (see
+// package schema).
+const SchemaVersion = "1"
+
 // ScanResult represents the complete scan results for a user.
 type ScanResult struct {
-	Username      string      `json:"username"`
-	SearchedRepos int         `json:"searched_repos"`
-	TotalCommits  int         `json:"total_commits"`
-	Matches       []PIIMatch  `json:"matches"`
-	ScanDuration  string      `json:"scan_duration"`
-	Errors        []ScanError `json:"errors,omitempty"`
+	SchemaVersion      string        `json:"schema_version"`
+	Username           string        `json:"username"`
+	Providers          []string      `json:"providers,omitempty"` // Hosting services scanned, in multi-provider scans
+	SearchedRepos      int           `json:"searched_repos"`
+	TotalCommits       int           `json:"total_commits"`
+	Matches            []PIIMatch    `json:"matches"`
+	ScanDuration       string        `json:"scan_duration"`
+	Incomplete         bool          `json:"incomplete,omitempty"`        // Set when the scan was interrupted
+	IncompleteReason   string        `json:"incomplete_reason,omitempty"` // Why the scan stopped early
+	Suppressed         int           `json:"suppressed,omitempty"`
+	LowConfidence      int           `json:"low_confidence,omitempty"` // Findings below the confidence threshold
+	SkippedForks       int           `json:"skipped_forks,omitempty"`
+	IgnoredRepos       int           `json:"ignored_repos,omitempty"`        // Repositories skipped by ignore rules
+	ExternalRepos      int           `json:"external_repos,omitempty"`       // Repositories owned by others, found by commit search
+	DuplicateCommits   int           `json:"duplicate_commits,omitempty"`    // Commits already scanned in another repo, e.g. a fork
+	BotCommits         int           `json:"bot_commits,omitempty"`          // Bot-generated and templated commits not scanned
+	MirroredCommits    int           `json:"mirrored_commits,omitempty"`     // Matching commits also found on another provider, reported once
+	EmailSearchCommits int           `json:"email_search_commits,omitempty"` // Commits found only by searching author emails
+	NameSearchCommits  int           `json:"name_search_commits,omitempty"`  // Commits found only by searching author names
+	CodeResults        int           `json:"code_results,omitempty"`         // Files found by code search
+	PullRequests       int           `json:"pull_requests,omitempty"`        // Pull requests of commits with matches in their message
+	SigningKeys        int           `json:"signing_keys,omitempty"`         // GPG keys commits were signed with
+	EventCommits       int           `json:"event_commits,omitempty"`        // Commits found only in the user's public events
+	Events             int           `json:"events,omitempty"`               // Public events scanned
+	Refs               int           `json:"refs,omitempty"`                 // Releases, annotated tags and branches scanned
+	Files              int           `json:"files,omitempty"`                // Leak-prone files scanned
+	ArchiveCommits     int           `json:"archive_commits,omitempty"`      // Commits found only in GH Archive
+	UnchangedRepos     int           `json:"unchanged_repos,omitempty"`      // Repositories skipped by an incremental scan
+	CarriedMatches     int           `json:"carried_matches,omitempty"`      // Matches kept from the previous scan by an incremental scan
+	Summary            *Summary      `json:"summary,omitempty"`
+	PrivacyScore       *PrivacyScore `json:"privacy_score,omitempty"`
+	Clusters           []Cluster     `json:"clusters,omitempty"`
+	Associations       []Association `json:"associations,omitempty"`  // Public links between the username and identity-revealing contexts
+	SkippedRepos       []SkippedRepo `json:"skipped_repos,omitempty"` // Repositories given up on after errors or a timeout
+	Errors             []ScanError   `json:"errors,omitempty"`
+
+	// Spool holds the matches of scans too large to keep in Matches, on
+	// disk. Use AllMatches, MatchCount and RewriteMatches to cover both.
+	Spool MatchSpool `json:"-"`
+}
+
+// MatchSpool holds matches outside of memory (see package spool). Read and
+// write errors are kept and reported by Err.
+type MatchSpool interface {
+	// Len returns the number of matches held.
+	Len() int
+	// All iterates over the matches in the order they were added.
+	All() iter.Seq[PIIMatch]
+	// Rewrite replaces each match with the one fn returns, dropping those
+	// for which it returns false.
+	Rewrite(fn func(PIIMatch) (PIIMatch, bool))
+	// Err returns the first error met reading or writing matches.
+	Err() error
+}
+
+// AllMatches iterates over the matches of the result, in memory then spooled.
+func (r *ScanResult) AllMatches() iter.Seq[PIIMatch] {
+	return func(yield func(PIIMatch) bool) {
+		for _, match := range r.Matches {
+			if !yield(match) {
+				return
+			}
+		}
+		if r.Spool != nil {
+			for match := range r.Spool.All() {
+				if !yield(match) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// MatchCount returns the number of matches of the result, spooled or not.
+func (r *ScanResult) MatchCount() int {
+	n := len(r.Matches)
+	if r.Spool != nil {
+		n += r.Spool.Len()
+	}
+	return n
+}
+
+// RewriteMatches replaces each match of the result with the one fn returns,
+// dropping those for which it returns false.
+func (r *ScanResult) RewriteMatches(fn func(PIIMatch) (PIIMatch, bool)) {
+	kept := r.Matches[:0]
+	for _, match := range r.Matches {
+		if match, ok := fn(match); ok {
+			kept = append(kept, match)
+		}
+	}
+	r.Matches = kept
+	if r.Spool != nil {
+		r.Spool.Rewrite(fn)
+	}
+}
+
+// Summary aggregates the matches and errors of a scan result. Field counts
+// are per location; the other match counts are per match, that is per commit.
+type Summary struct {
+	ByRepository    map[string]int  `json:"by_repository,omitempty"`
+	ByPIIType       map[PIIType]int `json:"by_pii_type,omitempty"`
+	ByField         map[string]int  `json:"by_field,omitempty"`
+	FirstLeak       *time.Time      `json:"first_leak,omitempty"` // Date of the earliest matching commit
+	LastLeak        *time.Time      `json:"last_leak,omitempty"`  // Date of the latest matching commit
+	TopRepositories []RepoCount     `json:"top_repositories,omitempty"`
+	// ByErrorType counts the errors of the scan by type; rate limits and
+	// network errors mean a later scan may find more.
+	ByErrorType map[ErrorType]int `json:"by_error_type,omitempty"`
+	// ByProvider counts the matches of a multi-provider scan by provider.
+	ByProvider map[string]int `json:"by_provider,omitempty"`
+}
+
+// PrivacyScore rates how much of a user's identity a scan found exposed,
+// from 0 (nothing found) to 100, overall and by category of exposure.
+type PrivacyScore struct {
+	Score           int             `json:"score"`
+	Rating          ExposureRating  `json:"rating"`
+	Categories      []CategoryScore `json:"categories"`
+	Recommendations []string        `json:"recommendations,omitempty"`
+}
+
+// CategoryScore is the exposure score of one category of findings.
+type CategoryScore struct {
+	Category ExposureCategory `json:"category"`
+	Score    int              `json:"score"`    // 0 to 100
+	Findings int              `json:"findings"` // locations in the category
+}
+
+// ExposureCategory groups findings by what exposes the user.
+type ExposureCategory string
+
+const (
+	CategoryCommitMetadata ExposureCategory = "commit_metadata" // author, committer and tagger names and emails, trailers
+	CategoryMessages       ExposureCategory = "messages"        // commit and tag messages, release notes, ref names
+	CategoryProfile        ExposureCategory = "profile"         // public activity and the Pages site's title and metadata
+	CategoryContent        ExposureCategory = "content"         // page text, repository files and anything else
+)
+
+// ExposureRating labels a privacy score.
+type ExposureRating string
+
+const (
+	RatingNone     ExposureRating = "none"
+	RatingLow      ExposureRating = "low"
+	RatingModerate ExposureRating = "moderate"
+	RatingHigh     ExposureRating = "high"
+	RatingSevere   ExposureRating = "severe"
+)
+
+// RepoCount is the number of matches in a repository.
+type RepoCount struct {
+	Repository string `json:"repository"`
+	Matches    int    `json:"matches"`
+}
+
+// Cluster groups findings that share the same matched text and field across
+// repositories, so they can be remediated as one pattern.
+type Cluster struct {
+	Matched        string   `json:"matched"`
+	Field          string   `json:"field"`
+	Via            string   `json:"via,omitempty"` // e.g., "Signed-off-by" trailer
+	Repositories   []string `json:"repositories"`
+	Commits        int      `json:"commits"`
+	Findings       int      `json:"findings"`
+	Recommendation string   `json:"recommendation"`
+}
+
+// SkippedRepo is a repository given up on before it was completely fetched,
+// after an error or a timeout.
+type SkippedRepo struct {
+	Repository string `json:"repository"`
+	Reason     string `json:"reason"`
+	Provider   string `json:"provider,omitempty"` // Set in multi-provider scans
 }
 
 // ScanError represents errors encountered during scanning.
 type ScanError struct {
-	Repository string `json:"repository,omitempty"`
-	Message    string `json:"message"`
-	Severity   string `json:"severity"` // "warning", "error", "fatal"
+	Repository string    `json:"repository,omitempty"`
+	Message    string    `json:"message"`
+	Severity   string    `json:"severity"`            // "warning", "error", "fatal"
+	Type       ErrorType `json:"type,omitempty"`      // What went wrong, when known
+	Retryable  bool      `json:"retryable,omitempty"` // Set when a later scan may succeed
+	Provider   string    `json:"provider,omitempty"`  // Set in multi-provider scans
+}
+
+// ErrorType classifies scan errors, telling apart those that leave results
+// incomplete for a while, such as rate limits, from those that will not go
+// away, such as deleted repositories.
+type ErrorType string
+
+const (
+	ErrorRateLimited  ErrorType = "rate_limited"
+	ErrorNotFound     ErrorType = "not_found"
+	ErrorAccessDenied ErrorType = "access_denied"
+	ErrorNetwork      ErrorType = "network" // connection failures, timeouts and server errors
+	ErrorParse        ErrorType = "parse"   // responses that could not be decoded
+	ErrorOther        ErrorType = "other"
+)
+
+// Retryable reports whether errors of the type may not happen again when
+// the request is retried.
+func (t ErrorType) Retryable() bool {
+	return t == ErrorRateLimited || t == ErrorNetwork
+}
+
+// HTTPErrorType returns the type of an error response with the given HTTP
+// status, or an empty type for statuses that are not errors of any type.
+func HTTPErrorType(status int) ErrorType {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrorAccessDenied
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return ErrorNotFound
+	case status == http.StatusTooManyRequests:
+		return ErrorRateLimited
+	case status == http.StatusRequestTimeout || status >= http.StatusInternalServerError:
+		return ErrorNetwork
+	}
+	return ""
 }
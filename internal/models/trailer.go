@@ -0,0 +1,60 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Trailer is a "Key: value" line in the last paragraph of a commit message,
+// such as "Signed-off-by: Jane Doe <jane@example.com>".
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Line  int    `json:"line"`            // 1-based line of the key in the message
+	Lines int    `json:"lines,omitempty"` // Number of lines including continuations, when more than one
+}
+
+// TrailerFieldPrefix prefixes the Location.Field of matches found in a
+// trailer; the rest is the lowercased key, e.g. "trailer:co-authored-by".
+const TrailerFieldPrefix = "trailer:"
+
+// TrailerField returns the Location.Field for matches in trailer key.
+func TrailerField(key string) string {
+	return TrailerFieldPrefix + strings.ToLower(key)
+}
+
+// trailerLine matches the first line of a trailer.
+var trailerLine = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)[ \t]*:[ \t]*(.*)$`)
+
+// ParseTrailers parses the trailer block of a commit message the way
+// git interpret-trailers does: the last paragraph, provided it is not the
+// subject and every line is a trailer or an indented continuation of one.
+func ParseTrailers(message string) []Trailer {
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+
+	// The block starts after the last blank line
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	if start == 0 || start == len(lines) {
+		return nil
+	}
+
+	var trailers []Trailer
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(trailers) > 0 {
+			t := &trailers[len(trailers)-1]
+			t.Value += " " + strings.TrimSpace(line)
+			t.Lines = i - t.Line + 2
+			continue
+		}
+		m := trailerLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: m[1], Value: strings.TrimSpace(m[2]), Line: i + 1})
+	}
+	return trailers
+}
@@ -18,4 +18,68 @@ type PIISearchCriteria struct {
 	FullName      string   `json:"full_name"`
 	Emails        []string `json:"emails,omitempty"`
 	CaseSensitive bool     `json:"case_sensitive"`
+	Rules         []Rule   `json:"rules,omitempty"`
+	// ExpandNicknames also searches the known nicknames of first names,
+	// e.g. Bob for Robert.
+	ExpandNicknames bool `json:"expand_nicknames,omitempty"`
+
+	// Identities are additional named people or aliases searched in the same scan.
+	Identities []Identity `json:"identities,omitempty"`
+}
+
+// Identity is a named set of names and emails belonging to one person or alias,
+// such as a legal name, a maiden name or an old email address.
+type Identity struct {
+	Name      string   `json:"name"`
+	FirstName string   `json:"first_name,omitempty"`
+	LastName  string   `json:"last_name,omitempty"`
+	FullName  string   `json:"full_name,omitempty"`
+	Emails    []string `json:"emails,omitempty"`
+}
+
+// Empty reports whether the identity has nothing to search for.
+func (i Identity) Empty() bool {
+	return i.FirstName == "" && i.LastName == "" && i.FullName == "" && len(i.Emails) == 0
+}
+
+// AllIdentities returns every identity to search for: the unnamed identity made
+// of the top-level fields, if any, followed by Identities.
+func (c PIISearchCriteria) AllIdentities() []Identity {
+	var ids []Identity
+	primary := Identity{
+		FirstName: c.FirstName,
+		LastName:  c.LastName,
+		FullName:  c.FullName,
+		Emails:    c.Emails,
+	}
+	if !primary.Empty() {
+		ids = append(ids, primary)
+	}
+	for _, id := range c.Identities {
+		if !id.Empty() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Rule is a user-defined regular expression reported as PII.
+type Rule struct {
+	Name          string  `json:"name"`
+	Pattern       string  `json:"pattern"`
+	PIIType       PIIType `json:"pii_type,omitempty"` // defaults to "custom"
+	Weight        float64 `json:"weight,omitempty"`   // base confidence of matches, 0 for the default
+	CaseSensitive bool    `json:"case_sensitive,omitempty"`
+}
+
+// Organization is the public profile of an organization a user is a public
+// member of.
+type Organization struct {
+	Login       string `json:"login"`
+	Name        string `json:"name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Blog        string `json:"blog,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
 }
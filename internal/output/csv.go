@@ -0,0 +1,66 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+func init() {
+	Register(Format{Name: "csv", Extension: ".csv", Streams: true, Writer: WriterFunc(writeCSV)})
+}
+
+// writeCSV writes one row per match location so results can be loaded
+// directly into a spreadsheet.
+func writeCSV(w io.Writer, result *models.ScanResult, _ Options) error {
+	if err := writeCSVOutput(w, result); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// writeCSVOutput writes one row per match location.
+func writeCSVOutput(out io.Writer, result *models.ScanResult) error {
+	w := csv.NewWriter(out)
+
+	if err := w.Write([]string{"repository", "sha", "date", "field", "matched", "confidence", "url", "severity", "advice", "fingerprint"}); err != nil {
+		return err
+	}
+
+	for match := range result.AllMatches() {
+		for _, loc := range match.Locations {
+			record := []string{
+				match.Commit.Repository,
+				match.Commit.SHA,
+				match.Commit.Date.Format(time.RFC3339),
+				loc.Field,
+				csvCell(loc.Matched),
+				strconv.FormatFloat(match.Confidence, 'f', 2, 64),
+				match.Commit.URL,
+				string(match.Severity),
+				csvCell(match.Advice),
+				loc.Fingerprint,
+			}
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// csvCell prefixes scanned text starting with =, +, - or @ with a quote, so
+// that a spreadsheet opening the file does not evaluate it as a formula.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,224 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/i18n"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// htmlReport is the self-contained HTML page of --output html. It has the
+// content of the Markdown report, with the matches of each repository in a
+// collapsible section.
+const htmlReport = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{tf "Scan Results for: %s" .Result.Username}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 72rem; padding: 0 1rem; color: #1f2328; }
+table { border-collapse: collapse; margin: 0.5rem 0 1rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code { font-size: 0.9em; }
+summary { cursor: pointer; font-size: 1.2rem; font-weight: 600; margin: 1rem 0 0.5rem; }
+.notice { background: #fff8c5; border: 1px solid #d4a72c; padding: 0.5rem 1rem; }
+.high { color: #cf222e; font-weight: 600; }
+.medium { color: #9a6700; }
+.low { color: #57606a; }
+</style>
+</head>
+<body>
+<h1>{{tf "Scan Results for: %s" .Result.Username}}</h1>
+<table>
+<tr><th>{{t "Repositories Scanned"}}</th><th>{{t "Total Commits"}}</th><th>{{t "PII Matches"}}</th><th>{{t "Duration"}}</th></tr>
+<tr><td>{{.Result.SearchedRepos}}</td><td>{{.Result.TotalCommits}}</td><td>{{len .Result.Matches}}</td><td>{{duration .Result.ScanDuration}}</td></tr>
+</table>
+{{- if .Result.Incomplete}}
+<p class="notice"><strong>{{t "Incomplete scan:"}}</strong> {{.Result.IncompleteReason}}</p>
+{{- end}}
+{{- if .Result.SkippedRepos}}
+<p class="notice"><strong>{{t "Skipped repositories:"}}</strong> {{tf "%d, see Errors" (len .Result.SkippedRepos)}}</p>
+{{- end}}
+{{- with .Result.PrivacyScore}}
+<h2>{{tf "Privacy Score: %d/100 (%s)" .Score (t (printf "%s exposure" .Rating))}}</h2>
+<table>
+<tr><th>{{t "Category"}}</th><th>{{t "Score"}}</th><th>{{t "Findings"}}</th></tr>
+{{- range .Categories}}
+<tr><td>{{category .Category}}</td><td>{{.Score}}</td><td>{{.Findings}}</td></tr>
+{{- end}}
+</table>
+{{- if .Recommendations}}
+<ul>
+{{- range .Recommendations}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+{{- with .Result.Summary}}
+<h2>{{t "Summary"}}</h2>
+<ul>
+{{- if .FirstLeak}}
+<li><strong>{{t "First leak:"}}</strong> {{date .FirstLeak}}</li>
+<li><strong>{{t "Last leak:"}}</strong> {{date .LastLeak}}</li>
+{{- end}}
+{{- if .TopRepositories}}
+<li><strong>{{t "By type:"}}</strong> {{$.ByType}}</li>
+<li><strong>{{t "By field:"}}</strong> {{$.ByField}}</li>
+{{- end}}
+{{- if .ByErrorType}}
+<li><strong>{{t "Errors by type:"}}</strong> {{$.ByError}}</li>
+{{- end}}
+</ul>
+{{- if .TopRepositories}}
+<table>
+<tr><th>{{t "Repository"}}</th><th>{{t "Matches"}}</th></tr>
+{{- range .TopRepositories}}
+<tr><td>{{.Repository}}</td><td>{{.Matches}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+{{- if .Result.Clusters}}
+<h2>{{t "Clusters"}}</h2>
+<table>
+<tr><th>{{t "Matched"}}</th><th>{{t "Field"}}</th><th>{{t "Via"}}</th><th>{{t "Repos"}}</th><th>{{t "Commits"}}</th><th>{{t "Recommendation"}}</th></tr>
+{{- range .Result.Clusters}}
+<tr><td><code>{{.Matched}}</code></td><td>{{.Field}}</td><td>{{.Via}}</td><td>{{len .Repositories}}</td><td>{{.Commits}}</td><td>{{.Recommendation}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Result.Associations}}
+<h2>{{t "Associations"}}</h2>
+<table>
+<tr><th>{{t "Type"}}</th><th>{{t "Location"}}</th><th>{{t "Detail"}}</th></tr>
+{{- range .Result.Associations}}
+<tr><td>{{.Type}}</td><td>{{if .URL}}<a href="{{.URL}}">{{place .}}</a>{{else}}{{place .}}{{end}}</td><td><code>{{.Detail}}</code></td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .Repos}}
+<details open>
+<summary>{{.Name}}</summary>
+<table>
+<tr><th>{{t "Commit"}}</th><th>{{t "Date"}}</th><th>{{t "Field"}}</th><th>{{t "Match"}}</th><th>{{t "Severity"}}</th><th>{{t "Confidence"}}</th><th>{{t "ID"}}</th></tr>
+{{- range .Matches}}
+{{- $match := .}}
+{{- range .Locations}}
+{{- $ref := or (shortSHA $match.Commit.SHA) (print $match.Source)}}
+<tr><td>{{if $match.Commit.URL}}<a href="{{$match.Commit.URL}}">{{$ref}}</a>{{else}}{{$ref}}{{end}}</td><td>{{date $match.Commit.Date}}</td><td>{{.Field}}</td><td><code>{{.Matched}}</code></td><td class="{{$match.Severity}}">{{t (print $match.Severity)}}</td><td>{{float $match.Confidence}}</td><td><code>{{.Fingerprint}}</code></td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- if or .PullRequests .Advice}}
+<ul>
+{{- range .PullRequests}}
+<li><strong>{{t "Pull request:"}}</strong> <a href="{{.URL}}">#{{.Number}} {{.Title}}</a> ({{t .State}}){{if .Leaks}}, {{t "also leaks"}}{{end}}</li>
+{{- end}}
+{{- range .Advice}}
+<li><strong>{{t "Advice:"}}</strong> {{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</details>
+{{- end}}
+{{- if .Result.Errors}}
+<h2>{{t "Errors"}}</h2>
+<ul>
+{{- range .Result.Errors}}
+<li><strong>{{t .Severity}}</strong> {{.Message}}{{if .Repository}} (<code>{{.Repository}}</code>){{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`
+
+// htmlRepo is the matches of one repository in the HTML report, most severe
+// first, with the pull requests and advice of those matches.
+type htmlRepo struct {
+	Name         string
+	Matches      []models.PIIMatch
+	PullRequests []models.PullRequest
+	Advice       []string
+}
+
+func init() {
+	Register(Format{Name: "html", Extension: ".html", Writer: WriterFunc(writeHTML)})
+}
+
+// writeHTML writes a self-contained HTML page in the language of opts.
+func writeHTML(w io.Writer, result *models.ScanResult, opts Options) error {
+	p, err := i18n.New(opts.Lang)
+	if err != nil {
+		return err
+	}
+	data, err := formatHTMLOutput(result, p)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// formatHTMLOutput renders result as a self-contained HTML page in the
+// language of p.
+func formatHTMLOutput(result *models.ScanResult, p *i18n.Printer) ([]byte, error) {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"t":        p.T,
+		"tf":       p.Sprintf,
+		"date":     p.Date,
+		"duration": p.DurationString,
+		"float":    func(f float64) string { return p.Float(f, 2) },
+		"category": func(c models.ExposureCategory) string { return categoryTitle(p, c) },
+		"shortSHA": ShortSHA,
+		"place":    associationPlace,
+	}).Parse(htmlReport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML template: %w", err)
+	}
+
+	// Group matches by repository, most severe first
+	var repos []*htmlRepo
+	byRepo := make(map[string]*htmlRepo)
+	for _, match := range bySeverity(result.Matches) {
+		repo, ok := byRepo[match.Commit.Repository]
+		if !ok {
+			repo = &htmlRepo{Name: match.Commit.Repository}
+			byRepo[repo.Name] = repo
+			repos = append(repos, repo)
+		}
+		repo.Matches = append(repo.Matches, match)
+		if match.Advice != "" && !slices.Contains(repo.Advice, match.Advice) {
+			repo.Advice = append(repo.Advice, match.Advice)
+		}
+		for _, pr := range match.PullRequests {
+			if !slices.Contains(repo.PullRequests, pr) {
+				repo.PullRequests = append(repo.PullRequests, pr)
+			}
+		}
+	}
+
+	data := struct {
+		Lang                     string
+		Result                   *models.ScanResult
+		Repos                    []*htmlRepo
+		ByType, ByField, ByError string
+	}{Lang: p.Lang(), Result: result, Repos: repos}
+	if s := result.Summary; s != nil {
+		data.ByType, data.ByField, data.ByError = formatCounts(s.ByPIIType), formatCounts(s.ByField), formatCounts(s.ByErrorType)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+func init() {
+	Register(Format{Name: "json", Extension: ".json", Streams: true, Writer: WriterFunc(writeJSON)})
+	Register(Format{Name: "ndjson", Extension: ".ndjson", Streams: true, Writer: WriterFunc(writeNDJSON)})
+}
+
+// writeJSON writes the whole result as indented JSON.
+func writeJSON(w io.Writer, result *models.ScanResult, _ Options) error {
+	if err := writeJSONOutput(w, result); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// writeNDJSON writes one JSON-encoded match per line.
+func writeNDJSON(w io.Writer, result *models.ScanResult, _ Options) error {
+	if err := writeNDJSONOutput(w, result); err != nil {
+		return fmt.Errorf("failed to write NDJSON: %w", err)
+	}
+	return nil
+}
+
+// writeJSONOutput writes result as json.MarshalIndent would, encoding its
+// matches one at a time.
+func writeJSONOutput(w io.Writer, result *models.ScanResult) error {
+	head := *result
+	head.Matches = []models.PIIMatch{}
+	data, err := json.MarshalIndent(&head, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Only the top-level key is indented by two spaces
+	before, after, ok := bytes.Cut(data, []byte("\n  \"matches\": []"))
+	if !ok {
+		return fmt.Errorf("matches not found in result")
+	}
+
+	if _, err := fmt.Fprintf(w, "%s\n  \"matches\": [", before); err != nil {
+		return err
+	}
+	n := 0
+	for match := range result.AllMatches() {
+		data, err := json.MarshalIndent(match, "    ", "  ")
+		if err != nil {
+			return err
+		}
+		sep := ",\n    "
+		if n == 0 {
+			sep = "\n    "
+		}
+		if _, err := fmt.Fprintf(w, "%s%s", sep, data); err != nil {
+			return err
+		}
+		n++
+	}
+	end := "]"
+	if n > 0 {
+		end = "\n  ]"
+	}
+	_, err = fmt.Fprintf(w, "%s%s", end, after)
+	return err
+}
+
+// writeNDJSONOutput writes one JSON-encoded match per line.
+func writeNDJSONOutput(w io.Writer, result *models.ScanResult) error {
+	enc := json.NewEncoder(w)
+	for match := range result.AllMatches() {
+		if err := enc.Encode(match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// JUnit XML, as read by Jenkins, GitLab CI and most CI dashboards.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func init() {
+	Register(Format{Name: "junit", Extension: ".xml", Writer: WriterFunc(writeJUnit)})
+}
+
+// writeJUnit writes a JUnit XML report.
+func writeJUnit(w io.Writer, result *models.ScanResult, _ Options) error {
+	data, err := formatJUnitOutput(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit XML: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// formatJUnitOutput renders one test suite per repository with a failed test
+// case per finding. Scan errors are reported as errored test cases, and a
+// clean scan as a single passing test case.
+func formatJUnitOutput(result *models.ScanResult) ([]byte, error) {
+	suites := junitTestSuites{Name: "gogitsomeprivacy: " + result.Username}
+
+	var order []string
+	byRepo := make(map[string]*junitTestSuite)
+	suite := func(name string) *junitTestSuite {
+		s, ok := byRepo[name]
+		if !ok {
+			s = &junitTestSuite{Name: name}
+			byRepo[name] = s
+			order = append(order, name)
+		}
+		return s
+	}
+
+	for _, match := range bySeverity(result.Matches) {
+		repo := match.Commit.Repository
+		s := suite(repo)
+		s.Tests++
+		s.Failures++
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      junitCaseName(match),
+			ClassName: repo,
+			Failure: &junitFailure{
+				Message: fmt.Sprintf("%s found (severity %s, confidence %.2f): %s", match.PIIType, match.Severity, match.Confidence, match.Context),
+				Type:    string(match.Severity),
+				Text:    junitFailureText(match),
+			},
+		})
+	}
+
+	for _, scanErr := range result.Errors {
+		name := scanErr.Repository
+		if name == "" {
+			name = result.Username
+		}
+		s := suite(name)
+		s.Tests++
+		s.Errors++
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      "scan",
+			ClassName: name,
+			Error:     &junitFailure{Message: scanErr.Message, Type: scanErr.Severity},
+		})
+	}
+
+	if len(order) == 0 {
+		s := suite(result.Username)
+		s.Tests++
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:      fmt.Sprintf("no PII in %d repositories, %d commits", result.SearchedRepos, result.TotalCommits),
+			ClassName: result.Username,
+		})
+	}
+
+	for _, name := range order {
+		s := byRepo[name]
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Errors += s.Errors
+		suites.Suites = append(suites.Suites, *s)
+	}
+
+	output, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+// junitCaseName names the test case of a finding after its commit and fields.
+func junitCaseName(match models.PIIMatch) string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, loc := range match.Locations {
+		if !seen[loc.Field] {
+			seen[loc.Field] = true
+			fields = append(fields, loc.Field)
+		}
+	}
+	ref := ShortSHA(match.Commit.SHA)
+	if ref == "" {
+		ref = match.Commit.URL
+	}
+	return fmt.Sprintf("%s: %s", ref, strings.Join(fields, ", "))
+}
+
+// junitFailureText describes a finding's commit and locations.
+func junitFailureText(match models.PIIMatch) string {
+	var b strings.Builder
+	if match.Commit.SHA != "" {
+		fmt.Fprintf(&b, "Commit: %s\n", match.Commit.SHA)
+		fmt.Fprintf(&b, "Date: %s\n", match.Commit.Date.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "URL: %s\n", match.Commit.URL)
+	for _, loc := range match.Locations {
+		fmt.Fprintf(&b, "- %s: %q", loc.Field, loc.Matched)
+		if loc.Fingerprint != "" {
+			fmt.Fprintf(&b, " (ID %s)", loc.Fingerprint)
+		}
+		b.WriteString("\n")
+	}
+	if match.Context != "" {
+		fmt.Fprintf(&b, "Context: %s\n", match.Context)
+	}
+	return b.String()
+}
@@ -0,0 +1,186 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/i18n"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+func init() {
+	Register(Format{Name: "markdown", Aliases: []string{"md"}, Extension: ".md", Writer: WriterFunc(writeMarkdown)})
+}
+
+// writeMarkdown writes a Markdown report in the language of opts.
+func writeMarkdown(w io.Writer, result *models.ScanResult, opts Options) error {
+	p, err := i18n.New(opts.Lang)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, formatMarkdownOutput(result, p))
+	return err
+}
+
+// formatMarkdownOutput renders matches as one table per repository, suitable
+// for pasting into a GitHub issue.
+func formatMarkdownOutput(result *models.ScanResult, p *i18n.Printer) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", p.Sprintf("Scan Results for `%s`", result.Username))
+	fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", p.T("Repositories Scanned"), p.T("Total Commits"), p.T("PII Matches"), p.T("Duration"))
+	fmt.Fprintf(&b, "|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %s |\n\n",
+		result.SearchedRepos, result.TotalCommits, len(result.Matches), p.DurationString(result.ScanDuration))
+	if result.Incomplete {
+		fmt.Fprintf(&b, "> **%s** %s\n\n", p.T("Incomplete scan:"), result.IncompleteReason)
+	}
+	if len(result.SkippedRepos) > 0 {
+		fmt.Fprintf(&b, "> **%s** %s\n\n", p.T("Skipped repositories:"), p.Sprintf("%d, see Errors", len(result.SkippedRepos)))
+	}
+
+	if ps := result.PrivacyScore; ps != nil {
+		fmt.Fprintf(&b, "## %s\n\n", p.Sprintf("Privacy Score: %d/100 (%s)", ps.Score, p.T(string(ps.Rating)+" exposure")))
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", p.T("Category"), p.T("Score"), p.T("Findings"))
+		b.WriteString("|---|---|---|\n")
+		for _, cs := range ps.Categories {
+			fmt.Fprintf(&b, "| %s | %d | %d |\n", categoryTitle(p, cs.Category), cs.Score, cs.Findings)
+		}
+		b.WriteString("\n")
+		for _, r := range ps.Recommendations {
+			fmt.Fprintf(&b, "- %s\n", r)
+		}
+		if len(ps.Recommendations) > 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	if s := result.Summary; s != nil {
+		fmt.Fprintf(&b, "## %s\n\n", p.T("Summary"))
+		if s.FirstLeak != nil {
+			fmt.Fprintf(&b, "- **%s** %s\n", p.T("First leak:"), p.Date(*s.FirstLeak))
+			fmt.Fprintf(&b, "- **%s** %s\n", p.T("Last leak:"), p.Date(*s.LastLeak))
+		}
+		if len(s.TopRepositories) > 0 {
+			fmt.Fprintf(&b, "- **%s** %s\n", p.T("By type:"), escapeMarkdownCell(formatCounts(s.ByPIIType)))
+			fmt.Fprintf(&b, "- **%s** %s\n", p.T("By field:"), escapeMarkdownCell(formatCounts(s.ByField)))
+		}
+		if len(s.ByErrorType) > 0 {
+			fmt.Fprintf(&b, "- **%s** %s\n", p.T("Errors by type:"), escapeMarkdownCell(formatCounts(s.ByErrorType)))
+		}
+		b.WriteString("\n")
+		if len(s.TopRepositories) > 0 {
+			fmt.Fprintf(&b, "| %s | %s |\n", p.T("Repository"), p.T("Matches"))
+			b.WriteString("|---|---|\n")
+			for _, rc := range s.TopRepositories {
+				fmt.Fprintf(&b, "| %s | %d |\n", rc.Repository, rc.Matches)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(result.Clusters) > 0 {
+		fmt.Fprintf(&b, "## %s\n\n", p.T("Clusters"))
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			p.T("Matched"), p.T("Field"), p.T("Via"), p.T("Repos"), p.T("Commits"), p.T("Recommendation"))
+		b.WriteString("|---|---|---|---|---|---|\n")
+		for _, c := range result.Clusters {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d | %d | %s |\n",
+				escapeMarkdownCell(c.Matched), c.Field, c.Via,
+				len(c.Repositories), c.Commits, escapeMarkdownCell(c.Recommendation))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Associations) > 0 {
+		fmt.Fprintf(&b, "## %s\n\n", p.T("Associations"))
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", p.T("Type"), p.T("Location"), p.T("Detail"))
+		b.WriteString("|---|---|---|\n")
+		for _, a := range result.Associations {
+			place := escapeMarkdownCell(associationPlace(a))
+			if a.URL != "" {
+				place = fmt.Sprintf("[%s](%s)", place, a.URL)
+			}
+			fmt.Fprintf(&b, "| %s | %s | `%s` |\n", a.Type, place, strings.ReplaceAll(escapeMarkdownCell(a.Detail), "`", "'"))
+		}
+		b.WriteString("\n")
+	}
+
+	// Group matches by repository, most severe first
+	var repos []string
+	byRepo := make(map[string][]models.PIIMatch)
+	for _, match := range bySeverity(result.Matches) {
+		repo := match.Commit.Repository
+		if _, ok := byRepo[repo]; !ok {
+			repos = append(repos, repo)
+		}
+		byRepo[repo] = append(byRepo[repo], match)
+	}
+
+	for _, repo := range repos {
+		fmt.Fprintf(&b, "## %s\n\n", repo)
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s |\n",
+			p.T("Commit"), p.T("Date"), p.T("Field"), p.T("Match"), p.T("Severity"), p.T("Confidence"), p.T("ID"))
+		b.WriteString("|---|---|---|---|---|---|---|\n")
+		var advice, prs []string
+		for _, match := range byRepo[repo] {
+			if match.Advice != "" && !slices.Contains(advice, match.Advice) {
+				advice = append(advice, match.Advice)
+			}
+			for _, pr := range match.PullRequests {
+				line := fmt.Sprintf("[#%d %s](%s) (%s)", pr.Number, escapeMarkdownCell(pr.Title), pr.URL, p.T(pr.State))
+				if pr.Leaks {
+					line += ", " + p.T("also leaks")
+				}
+				if !slices.Contains(prs, line) {
+					prs = append(prs, line)
+				}
+			}
+			commitRef := ShortSHA(match.Commit.SHA)
+			if match.Commit.URL != "" {
+				commitRef = fmt.Sprintf("[%s](%s)", commitRef, match.Commit.URL)
+			}
+			for _, loc := range match.Locations {
+				fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s |\n",
+					commitRef,
+					p.Date(match.Commit.Date),
+					loc.Field,
+					escapeMarkdownCell(loc.Matched),
+					p.T(string(match.Severity)),
+					p.Float(match.Confidence, 2),
+					loc.Fingerprint)
+			}
+		}
+		b.WriteString("\n")
+		for _, pr := range prs {
+			fmt.Fprintf(&b, "- **%s** %s\n", p.T("Pull request:"), pr)
+		}
+		for _, a := range advice {
+			fmt.Fprintf(&b, "- **%s** %s\n", p.T("Advice:"), a)
+		}
+		if len(advice) > 0 || len(prs) > 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(&b, "## %s\n\n", p.T("Errors"))
+		for _, err := range result.Errors {
+			fmt.Fprintf(&b, "- **%s** %s", p.T(err.Severity), escapeMarkdownCell(err.Message))
+			if err.Repository != "" {
+				fmt.Fprintf(&b, " (`%s`)", err.Repository)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// escapeMarkdownCell escapes characters that would break a Markdown table cell.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
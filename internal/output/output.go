@@ -0,0 +1,200 @@
+// Package output renders scan results in the formats of --output. Formats
+// register themselves by name, so programs embedding the scanner can add
+// their own alongside the built-in json, ndjson, text, csv, markdown, html,
+// junit and template formats.
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/i18n"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Options are the rendering settings shared by every format. Formats ignore
+// those they have no use for.
+type Options struct {
+	// Lang is the language of human-readable reports (default en).
+	Lang string
+	// Template is the path of the Go text/template rendered by the
+	// template format.
+	Template string
+}
+
+// Writer renders scan results in one output format.
+type Writer interface {
+	// Write renders result to w.
+	Write(w io.Writer, result *models.ScanResult, opts Options) error
+}
+
+// WriterFunc adapts a function to a Writer.
+type WriterFunc func(w io.Writer, result *models.ScanResult, opts Options) error
+
+// Write calls f.
+func (f WriterFunc) Write(w io.Writer, result *models.ScanResult, opts Options) error {
+	return f(w, result, opts)
+}
+
+// Format is a registered output format.
+type Format struct {
+	// Name selects the format, as in --output json.
+	Name string
+	// Aliases are other names selecting the format, such as md.
+	Aliases []string
+	// Extension is the file extension of the format, such as ".json",
+	// naming the files of batch scans. Empty uses ".<name>".
+	Extension string
+	// Streams is set when the writer only reads matches with
+	// ScanResult.AllMatches, so results spooled to disk are written without
+	// loading every match in memory.
+	Streams bool
+	Writer  Writer
+}
+
+var (
+	mu      sync.RWMutex
+	formats = make(map[string]Format)
+	aliases = make(map[string]string)
+)
+
+// Register adds an output format. It panics if the name or an alias is
+// already taken, or if the format has no name or writer, like
+// database/sql.Register; it is meant to be called from init functions.
+func Register(f Format) {
+	mu.Lock()
+	defer mu.Unlock()
+	if f.Name == "" || f.Writer == nil {
+		panic("output: Register of a format without a name or writer")
+	}
+	for _, name := range append([]string{f.Name}, f.Aliases...) {
+		if _, ok := formats[name]; ok {
+			panic("output: Register called twice for format " + name)
+		}
+		if _, ok := aliases[name]; ok {
+			panic("output: Register called twice for format " + name)
+		}
+	}
+	if f.Extension == "" {
+		f.Extension = "." + f.Name
+	}
+	formats[f.Name] = f
+	for _, alias := range f.Aliases {
+		aliases[alias] = f.Name
+	}
+}
+
+// Lookup returns the format registered under name or one of its aliases.
+func Lookup(name string) (Format, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if canonical, ok := aliases[name]; ok {
+		name = canonical
+	}
+	f, ok := formats[name]
+	return f, ok
+}
+
+// Names lists the names of the registered formats, without aliases, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Write renders result to w in the named format. Matches spooled to disk are
+// loaded in memory first, unless the format streams them.
+func Write(w io.Writer, result *models.ScanResult, name string, opts Options) error {
+	f, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unsupported output format: %s", name)
+	}
+	if result.Spool != nil && !f.Streams {
+		result.Matches = slices.AppendSeq(result.Matches, result.Spool.All())
+		if err := result.Spool.Err(); err != nil {
+			return err
+		}
+		result.Spool = nil
+	}
+	if err := f.Writer.Write(w, result, opts); err != nil {
+		return err
+	}
+	if result.Spool != nil {
+		return result.Spool.Err()
+	}
+	return nil
+}
+
+// bySeverity returns a copy of matches ordered by severity, then confidence,
+// highest first. Ties keep their scan order.
+func bySeverity(matches []models.PIIMatch) []models.PIIMatch {
+	sorted := make([]models.PIIMatch, len(matches))
+	copy(sorted, matches)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if ri, rj := sorted[i].Severity.Rank(), sorted[j].Severity.Rank(); ri != rj {
+			return ri > rj
+		}
+		return sorted[i].Confidence > sorted[j].Confidence
+	})
+	return sorted
+}
+
+// formatCounts formats counts as "key N" pairs, most frequent first.
+func formatCounts[K ~string](counts map[K]int) string {
+	keys := make([]K, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s %d", k, counts[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// joinRoles formats commit roles as a comma-separated list.
+func joinRoles(roles []models.CommitRole) string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ShortSHA returns the abbreviated form of a commit SHA.
+func ShortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// categoryTitle returns the display name of a privacy score category.
+func categoryTitle(p *i18n.Printer, c models.ExposureCategory) string {
+	switch c {
+	case models.CategoryCommitMetadata:
+		return p.T("Commit metadata")
+	case models.CategoryMessages:
+		return p.T("Messages")
+	case models.CategoryProfile:
+		return p.T("Profile")
+	case models.CategoryContent:
+		return p.T("Content")
+	}
+	return string(c)
+}
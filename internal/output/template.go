@@ -0,0 +1,116 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// repoMatches is the matches of one repository, as returned by the
+// groupByRepo template function.
+type repoMatches struct {
+	Repository string
+	Matches    []models.PIIMatch
+}
+
+// templateFuncs are the helper functions available to output templates.
+var templateFuncs = template.FuncMap{
+	"groupByRepo":      groupByRepo,
+	"sortByConfidence": sortByConfidence,
+	"sortBySeverity":   bySeverity,
+	"truncate":         truncate,
+	"shortSHA":         ShortSHA,
+	"join":             strings.Join,
+	"joinRoles":        joinRoles,
+}
+
+func init() {
+	Register(Format{Name: "template", Writer: WriterFunc(writeTemplate)})
+}
+
+// writeTemplate writes the result rendered through the template of opts.
+func writeTemplate(w io.Writer, result *models.ScanResult, opts Options) error {
+	if opts.Template == "" {
+		return fmt.Errorf("the template output format requires a template")
+	}
+	data, err := formatTemplateOutput(result, opts.Template)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// LoadTemplate parses the output template at path.
+func LoadTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// formatTemplateOutput renders the result through the template at path.
+func formatTemplateOutput(result *models.ScanResult, path string) ([]byte, error) {
+	tmpl, err := LoadTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, result); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// groupByRepo groups matches by repository, in the order first found.
+func groupByRepo(matches []models.PIIMatch) []repoMatches {
+	var groups []repoMatches
+	index := make(map[string]int)
+	for _, match := range matches {
+		repo := match.Commit.Repository
+		i, ok := index[repo]
+		if !ok {
+			i = len(groups)
+			index[repo] = i
+			groups = append(groups, repoMatches{Repository: repo})
+		}
+		groups[i].Matches = append(groups[i].Matches, match)
+	}
+	return groups
+}
+
+// sortByConfidence returns a copy of matches ordered by confidence, highest
+// first. Ties keep their scan order.
+func sortByConfidence(matches []models.PIIMatch) []models.PIIMatch {
+	sorted := make([]models.PIIMatch, len(matches))
+	copy(sorted, matches)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Confidence > sorted[j].Confidence
+	})
+	return sorted
+}
+
+// truncate shortens s to at most n characters, ending it with "..." when cut.
+// The length comes first so it can be piped: {{ .Context | truncate 40 }}.
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-3]) + "..."
+}
@@ -0,0 +1,238 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/i18n"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+func init() {
+	Register(Format{Name: "text", Extension: ".txt", Writer: WriterFunc(writeText)})
+}
+
+// writeText writes a human-readable report in the language of opts.
+func writeText(w io.Writer, result *models.ScanResult, opts Options) error {
+	p, err := i18n.New(opts.Lang)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, formatTextOutput(result, p))
+	return err
+}
+
+func formatTextOutput(result *models.ScanResult, p *i18n.Printer) string {
+	var output string
+
+	title := p.Sprintf("Scan Results for: %s", result.Username)
+	output += title + "\n"
+	output += strings.Repeat("=", utf8.RuneCountInString(title)) + "\n\n"
+	if len(result.Providers) > 0 {
+		output += p.Sprintf("Providers: %s", strings.Join(result.Providers, ", ")) + "\n"
+	}
+	output += p.Sprintf("Repositories Scanned: %d", result.SearchedRepos) + "\n"
+	output += p.Sprintf("Total Commits: %d", result.TotalCommits) + "\n"
+	output += p.Sprintf("PII Matches Found: %d", len(result.Matches)) + "\n"
+	output += p.Sprintf("Scan Duration: %s", p.DurationString(result.ScanDuration)) + "\n"
+	if result.Incomplete {
+		output += p.Sprintf("Incomplete: %s", result.IncompleteReason) + "\n"
+	}
+	counts := []struct {
+		format string
+		n      int
+	}{
+		{"Suppressed Findings: %d", result.Suppressed},
+		{"Below Confidence Threshold: %d", result.LowConfidence},
+		{"Skipped Forks: %d", result.SkippedForks},
+		{"Commits Found by Email: %d", result.EmailSearchCommits},
+		{"Commits Found by Name: %d", result.NameSearchCommits},
+		{"Code Search Results Scanned: %d", result.CodeResults},
+		{"Pull Requests Scanned: %d", result.PullRequests},
+		{"Signing Keys Inspected: %d", result.SigningKeys},
+		{"Releases, Tags and Branches Scanned: %d", result.Refs},
+		{"Leak-Prone Files Scanned: %d", result.Files},
+		{"Events Scanned: %d", result.Events},
+		{"Commits Found in Events: %d", result.EventCommits},
+		{"Commits Found Only in GH Archive: %d", result.ArchiveCommits},
+		{"External Repositories: %d", result.ExternalRepos},
+		{"Unchanged Repositories: %d", result.UnchangedRepos},
+		{"Matches Kept from Previous Scan: %d", result.CarriedMatches},
+		{"Ignored Repositories: %d", result.IgnoredRepos},
+		{"Duplicate Commits Skipped: %d", result.DuplicateCommits},
+		{"Bot Commits Skipped: %d", result.BotCommits},
+		{"Mirrored Commits Reported Once: %d", result.MirroredCommits},
+		{"Skipped Repositories: %d (see Errors)", len(result.SkippedRepos)},
+	}
+	for _, c := range counts {
+		if c.n > 0 {
+			output += p.Sprintf(c.format, c.n) + "\n"
+		}
+	}
+	output += "\n"
+
+	if ps := result.PrivacyScore; ps != nil {
+		output += p.Sprintf("Privacy Score: %d/100 (%s)", ps.Score, p.T(string(ps.Rating)+" exposure")) + "\n"
+		output += "-----------------\n\n"
+		width := 16
+		for _, cs := range ps.Categories {
+			width = max(width, utf8.RuneCountInString(p.Sprintf("%s:", categoryTitle(p, cs.Category))))
+		}
+		for _, cs := range ps.Categories {
+			output += fmt.Sprintf("  %-*s %3d/100  %s\n", width, p.Sprintf("%s:", categoryTitle(p, cs.Category)), cs.Score, p.Sprintf("%d finding(s)", cs.Findings))
+		}
+		if len(ps.Recommendations) > 0 {
+			output += "\n" + p.T("Recommendations:") + "\n"
+			for _, r := range ps.Recommendations {
+				output += fmt.Sprintf("  - %s\n", r)
+			}
+		}
+		output += "\n"
+	}
+
+	if s := result.Summary; s != nil {
+		output += textHeading(p.T("Summary:"))
+
+		if s.FirstLeak != nil {
+			output += p.Sprintf("First Leak: %s", p.Date(*s.FirstLeak)) + "\n"
+			output += p.Sprintf("Last Leak: %s", p.Date(*s.LastLeak)) + "\n"
+		}
+		if len(s.TopRepositories) > 0 {
+			output += p.Sprintf("By Type: %s", formatCounts(s.ByPIIType)) + "\n"
+			output += p.Sprintf("By Field: %s", formatCounts(s.ByField)) + "\n"
+			if len(s.ByProvider) > 0 {
+				output += p.Sprintf("By Provider: %s", formatCounts(s.ByProvider)) + "\n"
+			}
+			output += p.T("Top Repositories:") + "\n"
+			for _, rc := range s.TopRepositories {
+				output += fmt.Sprintf("  - %s %s\n", p.Sprintf("%s:", rc.Repository), p.Sprintf("%d match(es)", rc.Matches))
+			}
+		}
+		if len(s.ByErrorType) > 0 {
+			output += p.Sprintf("Errors by Type: %s", formatCounts(s.ByErrorType)) + "\n"
+		}
+		output += "\n"
+	}
+
+	if len(result.Clusters) > 0 {
+		output += textHeading(p.T("Clusters:"))
+
+		for i, c := range result.Clusters {
+			output += fmt.Sprintf("%d. %s", i+1, p.Sprintf("%q in %s", c.Matched, c.Field))
+			if c.Via != "" {
+				output += p.Sprintf(" via %s", c.Via)
+			}
+			output += p.Sprintf(": %d finding(s), %d commit(s), %d repo(s)", c.Findings, c.Commits, len(c.Repositories)) + "\n"
+			output += "   " + p.Sprintf("Recommendation: %s", c.Recommendation) + "\n"
+		}
+		output += "\n"
+	}
+
+	if len(result.Associations) > 0 {
+		output += textHeading(p.T("Associations:"))
+
+		for i, a := range result.Associations {
+			output += fmt.Sprintf("%d. %s: %s\n", i+1, a.Type, associationPlace(a))
+			if a.Detail != "" {
+				output += "   " + p.Sprintf("Detail: %s", a.Detail) + "\n"
+			}
+			if a.URL != "" {
+				output += "   " + p.Sprintf("URL: %s", a.URL) + "\n"
+			}
+		}
+		output += "\n"
+	}
+
+	if len(result.Matches) > 0 {
+		output += textHeading(p.T("Matches:"))
+
+		for i, match := range bySeverity(result.Matches) {
+			output += fmt.Sprintf("%d. %s\n", i+1, p.Sprintf("Repository: %s", match.Commit.Repository))
+			if match.Provider != "" {
+				output += "   " + p.Sprintf("Provider: %s", match.Provider) + "\n"
+			}
+			if match.Source != "" && match.Source != models.SourceCommit {
+				output += "   " + p.Sprintf("Source: %s", match.Source) + "\n"
+			}
+			output += "   " + p.Sprintf("Commit: %s", ShortSHA(match.Commit.SHA)) + "\n"
+			if roles := match.Commit.Roles; len(roles) > 0 && !(len(roles) == 1 && roles[0] == models.RoleAuthor) {
+				output += "   " + p.Sprintf("Role: %s", joinRoles(roles)) + "\n"
+			}
+			output += "   " + p.Sprintf("Date: %s", p.DateTime(match.Commit.Date)) + "\n"
+			output += "   " + p.Sprintf("URL: %s", match.Commit.URL) + "\n"
+			for _, pr := range match.PullRequests {
+				output += "   " + p.Sprintf("Pull Request: #%d %q (%s) %s", pr.Number, pr.Title, p.T(pr.State), pr.URL)
+				if pr.Leaks {
+					output += " - " + p.T("also leaks")
+				}
+				output += "\n"
+			}
+			output += "   " + p.Sprintf("Severity: %s", p.T(string(match.Severity))) + "\n"
+			output += "   " + p.Sprintf("Confidence: %s", p.Float(match.Confidence, 2)) + "\n"
+			output += "   " + p.Sprintf("Locations: %d match(es)", len(match.Locations)) + "\n"
+
+			for _, loc := range match.Locations {
+				output += "     - " + p.Sprintf("Field: %s, Match: %q", loc.Field, loc.Matched)
+				if loc.Identity != "" {
+					output += p.Sprintf(", Identity: %s", loc.Identity)
+				}
+				if loc.Alias != "" {
+					output += p.Sprintf(", Nickname: %s", loc.Alias)
+				}
+				if loc.Rule != "" {
+					output += p.Sprintf(", Rule: %s", loc.Rule)
+				}
+				if loc.Fingerprint != "" {
+					output += p.Sprintf(", ID: %s", loc.Fingerprint)
+				}
+				output += "\n"
+			}
+
+			if match.Context != "" {
+				output += "   " + p.Sprintf("Context: %s", match.Context) + "\n"
+			}
+			if match.Advice != "" {
+				output += "   " + p.Sprintf("Advice: %s", match.Advice) + "\n"
+			}
+			output += "\n"
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		output += "\n" + textHeading(p.T("Errors:"))
+
+		for i, err := range result.Errors {
+			output += fmt.Sprintf("%d. [%s] %s", i+1, p.T(err.Severity), err.Message)
+			if err.Type != "" {
+				output += p.Sprintf(" (Type: %s", err.Type)
+				if err.Retryable {
+					output += ", " + p.T("retryable")
+				}
+				output += ")"
+			}
+			if err.Repository != "" {
+				output += p.Sprintf(" (Repository: %s)", err.Repository)
+			}
+			output += "\n"
+		}
+	}
+
+	return output
+}
+
+// associationPlace names where an association was found: the organization
+// and profile field, or the repository and file line.
+func associationPlace(a models.Association) string {
+	if a.Repository == "" {
+		return strings.Join(slices.DeleteFunc([]string{a.Organization, a.Field}, func(s string) bool { return s == "" }), ", ")
+	}
+	return fmt.Sprintf("%s, %s:%d", a.Repository, a.Path, a.Line)
+}
+
+// textHeading underlines a section title of the text output.
+func textHeading(title string) string {
+	return title + "\n" + strings.Repeat("-", utf8.RuneCountInString(title)) + "\n\n"
+}
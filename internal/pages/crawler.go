@@ -0,0 +1,248 @@
+// Package pages crawls published GitHub Pages sites for PII scanning.
+package pages
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/time/rate"
+)
+
+// CrawlerConfig contains configuration for the Pages crawler.
+type CrawlerConfig struct {
+	MaxPages           int
+	RateLimitPerSecond float64
+	Timeout            time.Duration
+}
+
+// Crawler fetches pages of a published site using its sitemap.
+type Crawler struct {
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+	maxPages    int
+}
+
+// Page is the extracted content of a single published page.
+type Page struct {
+	URL   string
+	Title string
+	Meta  string // author and description metadata
+	Text  string
+}
+
+// maxBodySize caps how much of a single page or sitemap is read.
+const maxBodySize = 5 << 20
+
+// NewCrawler creates a new Pages crawler.
+func NewCrawler(cfg CrawlerConfig) *Crawler {
+	if cfg.MaxPages <= 0 {
+		cfg.MaxPages = 100
+	}
+	if cfg.RateLimitPerSecond <= 0 {
+		cfg.RateLimitPerSecond = 2.0
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	return &Crawler{
+		httpClient:  &http.Client{Timeout: cfg.Timeout},
+		rateLimiter: rate.NewLimiter(rate.Limit(cfg.RateLimitPerSecond), 1),
+		maxPages:    cfg.MaxPages,
+	}
+}
+
+// SiteURL returns the default Pages site URL for a user.
+func SiteURL(username string) string {
+	return fmt.Sprintf("https://%s.github.io/", strings.ToLower(username))
+}
+
+// Crawl fetches the pages listed in the site's sitemap, falling back to the
+// site root when no sitemap is published.
+func (c *Crawler) Crawl(ctx context.Context, siteURL string) ([]*Page, error) {
+	base, err := url.Parse(siteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid site URL %s: %w", siteURL, err)
+	}
+
+	urls, err := c.sitemapURLs(ctx, base.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String(), 0)
+	if err != nil || len(urls) == 0 {
+		urls = []string{base.String()}
+	}
+	if len(urls) > c.maxPages {
+		urls = urls[:c.maxPages]
+	}
+
+	var pages []*Page
+	for _, u := range urls {
+		page, err := c.fetchPage(ctx, u)
+		if err != nil {
+			if ctx.Err() != nil {
+				return pages, ctx.Err()
+			}
+			continue
+		}
+		pages = append(pages, page)
+	}
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no pages could be fetched from %s", siteURL)
+	}
+	return pages, nil
+}
+
+// sitemap covers both urlset and sitemapindex documents.
+type sitemap struct {
+	URLs     []string `xml:"url>loc"`
+	Sitemaps []string `xml:"sitemap>loc"`
+}
+
+// sitemapURLs returns page URLs from a sitemap, following nested sitemap indexes.
+func (c *Crawler) sitemapURLs(ctx context.Context, sitemapURL string, depth int) ([]string, error) {
+	if depth > 2 {
+		return nil, nil
+	}
+
+	body, err := c.get(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var sm sitemap
+	if err := xml.Unmarshal(body, &sm); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap %s: %w", sitemapURL, err)
+	}
+
+	urls := sm.URLs
+	for _, nested := range sm.Sitemaps {
+		if len(urls) >= c.maxPages {
+			break
+		}
+		more, err := c.sitemapURLs(ctx, strings.TrimSpace(nested), depth+1)
+		if err != nil {
+			continue
+		}
+		urls = append(urls, more...)
+	}
+
+	for i := range urls {
+		urls[i] = strings.TrimSpace(urls[i])
+	}
+	return urls, nil
+}
+
+// fetchPage fetches a page and extracts its visible text and metadata.
+func (c *Crawler) fetchPage(ctx context.Context, pageURL string) (*Page, error) {
+	body, err := c.get(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	page := extract(string(body))
+	page.URL = pageURL
+	return page, nil
+}
+
+// get performs a rate-limited GET request.
+func (c *Crawler) get(ctx context.Context, u string) ([]byte, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", u, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+}
+
+// metaNames lists meta tags that commonly carry author identity.
+var metaNames = map[string]bool{
+	"author":          true,
+	"description":     true,
+	"og:title":        true,
+	"og:description":  true,
+	"og:site_name":    true,
+	"article:author":  true,
+	"twitter:creator": true,
+	"twitter:title":   true,
+}
+
+// extract walks an HTML document collecting the title, identity metadata and visible text.
+func extract(doc string) *Page {
+	page := &Page{}
+	var text, meta []string
+	var skip int
+	var inTitle bool
+
+	z := html.NewTokenizer(strings.NewReader(doc))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			page.Text = strings.Join(text, "\n")
+			page.Meta = strings.Join(meta, "\n")
+			return page
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "script", "style", "noscript":
+				if tok.Type == html.StartTagToken {
+					skip++
+				}
+			case "title":
+				inTitle = true
+			case "meta":
+				var name, content string
+				for _, attr := range tok.Attr {
+					switch attr.Key {
+					case "name", "property":
+						name = strings.ToLower(attr.Val)
+					case "content":
+						content = attr.Val
+					}
+				}
+				if metaNames[name] && content != "" {
+					meta = append(meta, content)
+				}
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "script", "style", "noscript":
+				if skip > 0 {
+					skip--
+				}
+			case "title":
+				inTitle = false
+			}
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			t := strings.Join(strings.Fields(string(z.Text())), " ")
+			if t == "" {
+				continue
+			}
+			if inTitle {
+				page.Title = t
+				continue
+			}
+			text = append(text, t)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/bitbucket"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Classify returns the type of an error returned by a provider, or by the
+// other sources a scan reads, such as web pages.
+func Classify(err error) models.ErrorType {
+	if t := github.Classify(err); t != "" {
+		return t
+	}
+	if t := bitbucket.Classify(err); t != "" {
+		return t
+	}
+
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &netErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return models.ErrorNetwork
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return models.ErrorParse
+	}
+	return models.ErrorOther
+}
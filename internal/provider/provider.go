@@ -0,0 +1,188 @@
+// Package provider abstracts the hosting services whose history is scanned.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/bitbucket"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Provider names.
+const (
+	GitHub    = "github"
+	Bitbucket = "bitbucket"
+)
+
+// Provider lists a user's repositories and commits on a hosting service.
+// Implementations must be safe for concurrent use.
+type Provider interface {
+	// Name returns the provider name, e.g. "github".
+	Name() string
+
+	// GetUser retrieves the profile of a user.
+	GetUser(ctx context.Context, username string) (*models.UserProfile, error)
+
+	// ListUserRepos lists the public repositories of a user.
+	ListUserRepos(ctx context.Context, username string) ([]*models.Repository, error)
+
+	// StreamBranchCommits hands each page of the commits selected by filter
+	// on branch (the default branch when empty) to fn, with their Roles set,
+	// stopping after limit commits (0 means no limit). Missing branches and
+	// inaccessible repositories yield no commits.
+	StreamBranchCommits(ctx context.Context, owner, repo, branch string, filter models.CommitFilter, limit int, fn func([]*models.Commit) error) error
+
+	// GetFileContent retrieves a file from the default branch of a
+	// repository, returning nil content when it does not exist.
+	GetFileContent(ctx context.Context, owner, repo, path string) ([]byte, error)
+}
+
+// CommitSampler is implemented by providers that can sample the history of a
+// branch without listing all of it.
+type CommitSampler interface {
+	// SampleBranchCommits is like Provider.StreamBranchCommits but hands
+	// about n of the selected commits, spread across the branch history
+	// rather than the latest ones.
+	SampleBranchCommits(ctx context.Context, owner, repo, branch string, filter models.CommitFilter, n int, fn func([]*models.Commit) error) error
+}
+
+// RepoSearcher is implemented by providers that can discover the repositories
+// a user contributed to, including ones owned by others, by searching commits.
+type RepoSearcher interface {
+	SearchContributedRepos(ctx context.Context, username string) ([]*models.Repository, error)
+}
+
+// EmailSearcher is implemented by providers that can find commits by author
+// email, whichever account they are attributed to.
+type EmailSearcher interface {
+	SearchCommitsByEmail(ctx context.Context, email string) ([]*models.Commit, error)
+}
+
+// EventLister is implemented by providers that expose a user's recent public
+// activity.
+type EventLister interface {
+	// ListUserEvents lists the recent public events performed by a user
+	// that carry text, newest first.
+	ListUserEvents(ctx context.Context, username string) ([]*models.ActivityEvent, error)
+}
+
+// RefLister is implemented by providers that can list the releases, tags and
+// branches of a repository.
+type RefLister interface {
+	// ListRefs lists the releases, annotated tags and branches of a
+	// repository. Inaccessible repositories have none.
+	ListRefs(ctx context.Context, owner, repo string) ([]*models.Ref, error)
+}
+
+// NameSearcher is implemented by providers that can find commits by author
+// name across all repositories.
+type NameSearcher interface {
+	SearchCommitsByName(ctx context.Context, name string) ([]*models.Commit, error)
+}
+
+// CodeSearcher is implemented by providers that can search the contents of
+// files across repositories.
+type CodeSearcher interface {
+	// SearchCode returns the files of public repositories matching query,
+	// with the fragments that matched.
+	SearchCode(ctx context.Context, query string) ([]*models.CodeResult, error)
+}
+
+// PullRequestLister is implemented by providers that can find the pull
+// requests including a commit.
+type PullRequestLister interface {
+	// ListCommitPullRequests lists the pull requests of a repository that
+	// include the commit sha. Inaccessible repositories have none.
+	ListCommitPullRequests(ctx context.Context, owner, repo, sha string) ([]*models.PullRequest, error)
+}
+
+// GPGKeyLister is implemented by providers that publish the GPG keys of
+// their accounts.
+type GPGKeyLister interface {
+	// ListGPGKeys lists the public GPG keys of the account login.
+	ListGPGKeys(ctx context.Context, login string) ([]*models.GPGKey, error)
+}
+
+// OrgLister is implemented by providers with organizations that users can
+// publicly belong to.
+type OrgLister interface {
+	// ListUserOrgs lists the organizations a user is a public member of,
+	// with their public profile.
+	ListUserOrgs(ctx context.Context, username string) ([]*models.Organization, error)
+}
+
+// FileLister is implemented by providers that can list the files of a
+// repository.
+type FileLister interface {
+	// ListFiles lists the files and directories in dir of a repository's
+	// default branch, its root when dir is empty. Missing directories and
+	// inaccessible repositories have none.
+	ListFiles(ctx context.Context, owner, repo, dir string) ([]*models.RepoFile, error)
+}
+
+// ErrNotModified is returned by HeadReader.Head when the branch has not moved
+// since the request that returned the given ETag.
+var ErrNotModified = github.ErrNotModified
+
+// HeadReader is implemented by providers that support incremental scans.
+type HeadReader interface {
+	// Head returns the newest commit of a repository's default branch, or a
+	// zero state if it has none or cannot be read. Given the ETag of a
+	// previous call, it returns ErrNotModified if the branch has not moved.
+	Head(ctx context.Context, owner, repo, etag string) (models.RepoState, error)
+}
+
+// CommitCounter is implemented by providers that can count the commits of a
+// repository from its statistics, without listing them.
+type CommitCounter interface {
+	// CountCommits returns the number of commits on the default branch of a
+	// repository authored by username and by anyone. ok is false when the
+	// statistics are not available, e.g. while they are being computed.
+	CountCommits(ctx context.Context, owner, repo, username string) (user, total int, ok bool, err error)
+}
+
+// Paced is implemented by providers that pace their requests, so scans can
+// size their concurrency to what the provider sustains.
+type Paced interface {
+	// Pace returns the requests per second the provider currently allows: its
+	// rate limiter, lowered when the remaining API budget would run out
+	// before it resets. latency is the average duration of recent requests,
+	// zero until one completed.
+	Pace() (rps float64, latency time.Duration)
+}
+
+// New creates the provider selected by cfg.Provider.
+func New(cfg *config.Config) (Provider, error) {
+	switch cfg.Provider {
+	case "", GitHub:
+		client, err := github.NewClient(github.ClientConfig{
+			Token:                        cfg.GitHub.Token,
+			Tokens:                       cfg.GitHub.Tokens,
+			RateLimitPerSecond:           cfg.GitHub.RateLimitPerSecond,
+			RateLimitBurst:               cfg.GitHub.RateLimitBurst,
+			SearchRateLimitPerMinute:     cfg.GitHub.SearchRateLimitPerMinute,
+			CodeSearchRateLimitPerMinute: cfg.GitHub.CodeSearchRateLimitPerMinute,
+			Timeout:                      time.Duration(cfg.GitHub.TimeoutSeconds) * time.Second,
+			PageConcurrency:              cfg.GitHub.PageConcurrency,
+			BaseURL:                      cfg.GitHub.BaseURL,
+			UploadURL:                    cfg.GitHub.UploadURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case Bitbucket:
+		return bitbucket.NewClient(bitbucket.ClientConfig{
+			Username:           cfg.Bitbucket.Username,
+			AppPassword:        cfg.Bitbucket.AppPassword,
+			RateLimitPerSecond: cfg.Bitbucket.RateLimitPerSecond,
+			Timeout:            time.Duration(cfg.Bitbucket.TimeoutSeconds) * time.Second,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,197 @@
+// Package remediate turns scan findings into history-rewrite instructions.
+package remediate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// DefaultReplacement is the text that leaked PII is replaced with in commit messages.
+const DefaultReplacement = "***REDACTED***"
+
+// SupportURL is the GitHub form for purging cached views of rewritten commits.
+const SupportURL = "https://support.github.com/contact/private-information"
+
+// Identity is the public identity that leaked author data is rewritten to.
+type Identity struct {
+	Name  string
+	Email string
+}
+
+// Options controls plan generation.
+type Options struct {
+	Identity    Identity
+	Replacement string
+}
+
+// RepoPlan holds the remediation steps for a single repository.
+type RepoPlan struct {
+	Repository string
+	Owned      bool
+	// Mailmap contains git mailmap lines for author/committer rewrites.
+	Mailmap []string
+	// Replacements contains git filter-repo --replace-message expressions.
+	Replacements []string
+	// Commits lists URLs of affected commits, for the GitHub support request.
+	Commits []string
+	// Pages lists published site URLs that need to be edited and republished.
+	Pages []string
+}
+
+// Plan is the complete remediation plan for a scan result.
+type Plan struct {
+	Username string
+	Repos    []*RepoPlan
+}
+
+// BuildPlan groups findings by repository and derives remediation files.
+func BuildPlan(result *models.ScanResult, opts Options) *Plan {
+	if opts.Replacement == "" {
+		opts.Replacement = DefaultReplacement
+	}
+	if opts.Identity.Name == "" {
+		opts.Identity.Name = result.Username
+	}
+	if opts.Identity.Email == "" {
+		opts.Identity.Email = fmt.Sprintf("%s@users.noreply.github.com", result.Username)
+	}
+
+	plans := make(map[string]*RepoPlan)
+	seen := make(map[string]bool)
+	add := func(set *[]string, repo, value string) {
+		key := repo + "\x00" + value
+		if !seen[key] {
+			seen[key] = true
+			*set = append(*set, value)
+		}
+	}
+
+	for _, match := range result.Matches {
+		repo := match.Commit.Repository
+		rp, ok := plans[repo]
+		if !ok {
+			owner := strings.SplitN(repo, "/", 2)[0]
+			rp = &RepoPlan{
+				Repository: repo,
+				Owned:      strings.EqualFold(owner, result.Username),
+			}
+			plans[repo] = rp
+		}
+
+		if match.Source == models.SourcePagesSite {
+			add(&rp.Pages, repo, match.Commit.URL)
+			continue
+		}
+		if match.Commit.URL != "" {
+			add(&rp.Commits, repo, match.Commit.URL)
+		}
+
+		for _, loc := range match.Locations {
+			switch loc.Field {
+			case "author_name":
+				add(&rp.Mailmap, repo, mailmapLine(opts.Identity, match.Commit.Author))
+			case "committer_name":
+				add(&rp.Mailmap, repo, mailmapLine(opts.Identity, match.Commit.Committer))
+			default:
+				add(&rp.Replacements, repo, fmt.Sprintf("literal:%s==>%s", loc.Matched, opts.Replacement))
+			}
+		}
+	}
+
+	plan := &Plan{Username: result.Username}
+	for _, rp := range plans {
+		sort.Strings(rp.Mailmap)
+		sort.Strings(rp.Replacements)
+		plan.Repos = append(plan.Repos, rp)
+	}
+	sort.Slice(plan.Repos, func(i, j int) bool {
+		return plan.Repos[i].Repository < plan.Repos[j].Repository
+	})
+	return plan
+}
+
+// mailmapLine maps an old author identity onto the public identity.
+func mailmapLine(to Identity, from models.Author) string {
+	return fmt.Sprintf("%s <%s> %s <%s>", to.Name, to.Email, from.Name, from.Email)
+}
+
+// MailmapFile returns the contents of the repository's mailmap file.
+func (rp *RepoPlan) MailmapFile() string {
+	return joinLines(rp.Mailmap)
+}
+
+// ReplacementsFile returns the contents of the repository's --replace-message file.
+func (rp *RepoPlan) ReplacementsFile() string {
+	return joinLines(rp.Replacements)
+}
+
+// Instructions returns step-by-step remediation instructions for the repository.
+// dir is where the mailmap and replacement files are written.
+func (rp *RepoPlan) Instructions(dir string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Remediation for %s\n\n", rp.Repository)
+
+	if len(rp.Pages) > 0 {
+		b.WriteString("## Published site\n\n")
+		b.WriteString("Edit the sources of these pages, republish the site, then request removal of cached copies:\n\n")
+		for _, p := range rp.Pages {
+			fmt.Fprintf(&b, "- %s\n", p)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(rp.Mailmap) == 0 && len(rp.Replacements) == 0 {
+		return b.String()
+	}
+
+	if !rp.Owned {
+		b.WriteString("You do not own this repository. Ask its maintainers to rewrite the affected\n")
+		b.WriteString("commits using the steps below, or to remove the references manually.\n\n")
+	}
+
+	b.WriteString("## 1. Rewrite history\n\n")
+	b.WriteString("Requires git-filter-repo (https://github.com/newren/git-filter-repo).\n\n")
+	b.WriteString("```bash\n")
+	fmt.Fprintf(&b, "git clone --mirror https://github.com/%s.git\n", rp.Repository)
+	fmt.Fprintf(&b, "cd %s.git\n", repoName(rp.Repository))
+	b.WriteString("git filter-repo")
+	if len(rp.Mailmap) > 0 {
+		fmt.Fprintf(&b, " \\\n  --mailmap %s/mailmap", dir)
+	}
+	if len(rp.Replacements) > 0 {
+		fmt.Fprintf(&b, " \\\n  --replace-message %s/replacements.txt", dir)
+	}
+	b.WriteString("\n```\n\n")
+
+	b.WriteString("## 2. Force-push the rewritten history\n\n")
+	b.WriteString("```bash\n")
+	fmt.Fprintf(&b, "git remote add origin https://github.com/%s.git  # filter-repo removes it\n", rp.Repository)
+	b.WriteString("git push --force --mirror origin\n")
+	b.WriteString("```\n\n")
+	b.WriteString("Collaborators must re-clone; forks keep the old history and must be handled by their owners.\n\n")
+
+	b.WriteString("## 3. Purge cached views on GitHub\n\n")
+	fmt.Fprintf(&b, "Old commits stay reachable by SHA until GitHub purges them. Submit %s\n", SupportURL)
+	b.WriteString("listing these commit URLs:\n\n")
+	for _, c := range rp.Commits {
+		fmt.Fprintf(&b, "- %s\n", c)
+	}
+
+	return b.String()
+}
+
+func repoName(fullName string) string {
+	parts := strings.SplitN(fullName, "/", 2)
+	return parts[len(parts)-1]
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Advise sets the remediation advice of every match of result. Matches in
+// repositories owned by the scanned user are advised a history rewrite;
+// others, contacting the repository owner.
+func Advise(result *models.ScanResult) {
+	result.RewriteMatches(func(match models.PIIMatch) (models.PIIMatch, bool) {
+		match.Advice = Advice(match, result.Username)
+		return match, true
+	})
+}
+
+// Advice returns what the person scanned as username can do about match: one
+// sentence per kind of field it was found in. It does not repeat the matched
+// text, so it needs no redaction.
+func Advice(match models.PIIMatch, username string) string {
+	if match.Source == models.SourceArchive {
+		return "This commit is no longer on GitHub, but GH Archive keeps every public event and does not remove them. Ask GitHub Support to purge cached views of the commit, and treat any email address in it as public."
+	}
+
+	repo := match.Commit.Repository
+	owner, _, _ := strings.Cut(repo, "/")
+	owned := strings.EqualFold(owner, username)
+
+	var identity, email, message, page, event, ref, pr, signer bool
+	for _, loc := range match.Locations {
+		switch {
+		case loc.Field == "author_name" || loc.Field == "committer_name" || loc.Field == "tagger_name":
+			identity = true
+		case loc.Field == "author_email" || loc.Field == "committer_email":
+			email = true
+		case strings.HasPrefix(loc.Field, "page_"):
+			page = true
+		case strings.HasPrefix(loc.Field, "event_"):
+			event = true
+		case strings.HasPrefix(loc.Field, "pr_"):
+			pr = true
+		case loc.Field == "signer_uid":
+			signer = true
+		case strings.HasPrefix(loc.Field, "release_") || strings.HasPrefix(loc.Field, "tag_") || loc.Field == "branch_name":
+			ref = true
+		default:
+			message = true
+		}
+	}
+
+	var advice []string
+	if identity {
+		if owned {
+			advice = append(advice, "Rewrite the author and committer with git filter-repo --mailmap, and commit under a public handle and your GitHub noreply address from now on (Settings → Emails → Keep my email addresses private).")
+		} else {
+			advice = append(advice, "Commit under a public handle and your GitHub noreply address from now on (Settings → Emails → Keep my email addresses private); the existing commits can only be rewritten by the owner of "+repo+".")
+		}
+	}
+	if email {
+		advice = append(advice, "Set git config --global user.email to your GitHub noreply address and enable \"Block command line pushes that expose my email\" (Settings → Emails).")
+		if owned && !identity {
+			advice = append(advice, "Rewrite the existing commits with git filter-repo --mailmap.")
+		}
+	}
+	if message {
+		if owned {
+			advice = append(advice, "Rewrite the commit message with git filter-repo --replace-message, force-push, then ask GitHub Support to purge cached views of the old commits.")
+		} else {
+			advice = append(advice, "Ask the owner of "+repo+" to rewrite the commit message, or ask GitHub Support to remove it.")
+		}
+	}
+	if page {
+		advice = append(advice, "Edit the site sources, republish the site, then rewrite the history of its publishing branch.")
+	}
+	if ref {
+		if owned {
+			advice = append(advice, "Edit the release notes, or delete the tag or branch and push it again under another name or message.")
+		} else {
+			advice = append(advice, "Ask the owner of "+repo+" to edit the release notes or to rename the tag or branch.")
+		}
+	}
+	if event {
+		advice = append(advice, "Edit or delete the comment, issue, pull request, release or repository description, then delete its edit history (⋯ → Edited → Delete revision).")
+	}
+	if pr {
+		advice = append(advice, "Edit the title and description of the pull request, then delete its edit history (⋯ → Edited → Delete revision); if you did not open it, ask its author or the owner of "+repo+".")
+	}
+	if signer {
+		advice = append(advice, "Revoke the user ID with gpg --quick-revuid, or create a signing key whose only user ID is your GitHub noreply address, and replace the key in Settings → SSH and GPG keys.")
+	}
+	return strings.Join(advice, " ")
+}
@@ -0,0 +1,123 @@
+// Package report derives aggregate views from scan results.
+package report
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// trailerPattern recognizes git trailer lines such as "Signed-off-by:".
+var trailerPattern = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z-]*-[Bb]y):`)
+
+// Clusters groups findings by matched text (case-insensitive), field and the
+// trailer the match appeared in, if any. Clusters are ordered by
+// the number of repositories affected.
+func Clusters(result *models.ScanResult) []models.Cluster {
+	type clusterState struct {
+		cluster models.Cluster
+		repos   map[string]bool
+		commits map[string]bool
+	}
+
+	var order []string
+	clusters := make(map[string]*clusterState)
+
+	for match := range result.AllMatches() {
+		for _, loc := range match.Locations {
+			via := ""
+			switch {
+			case strings.HasPrefix(loc.Field, models.TrailerFieldPrefix):
+				via = trailerKey(match.Commit, loc.Line)
+			case loc.Field == "message":
+				via = trailerAt(match.Commit.Message, loc.Line)
+			}
+
+			key := strings.ToLower(loc.Matched) + "\x00" + loc.Field + "\x00" + strings.ToLower(via)
+			cs, ok := clusters[key]
+			if !ok {
+				cs = &clusterState{
+					cluster: models.Cluster{
+						Matched: loc.Matched,
+						Field:   loc.Field,
+						Via:     via,
+					},
+					repos:   make(map[string]bool),
+					commits: make(map[string]bool),
+				}
+				clusters[key] = cs
+				order = append(order, key)
+			}
+
+			cs.cluster.Findings++
+			if !cs.repos[match.Commit.Repository] {
+				cs.repos[match.Commit.Repository] = true
+				cs.cluster.Repositories = append(cs.cluster.Repositories, match.Commit.Repository)
+			}
+			commitKey := match.Commit.Repository + "@" + match.Commit.SHA + match.Commit.URL
+			if !cs.commits[commitKey] {
+				cs.commits[commitKey] = true
+				cs.cluster.Commits++
+			}
+		}
+	}
+
+	out := make([]models.Cluster, 0, len(order))
+	for _, key := range order {
+		c := clusters[key].cluster
+		sort.Strings(c.Repositories)
+		c.Recommendation = recommend(c)
+		out = append(out, c)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if len(out[i].Repositories) != len(out[j].Repositories) {
+			return len(out[i].Repositories) > len(out[j].Repositories)
+		}
+		return out[i].Findings > out[j].Findings
+	})
+	return out
+}
+
+// trailerKey returns the key, as written, of the commit trailer covering the
+// given 1-based message line.
+func trailerKey(commit models.Commit, line int) string {
+	for _, t := range commit.Trailers {
+		if line >= t.Line && line < t.Line+max(t.Lines, 1) {
+			return t.Key
+		}
+	}
+	return trailerAt(commit.Message, line)
+}
+
+// trailerAt returns the trailer key on the given 1-based line of a message.
+func trailerAt(message string, line int) string {
+	lines := strings.Split(message, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	if m := trailerPattern.FindStringSubmatch(lines[line-1]); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// recommend returns a single remediation recommendation for a cluster.
+func recommend(c models.Cluster) string {
+	switch {
+	case c.Field == "author_name" || c.Field == "committer_name":
+		return fmt.Sprintf("Set git user.name to a public handle and rewrite %q with a filter-repo mailmap in %d repo(s)",
+			c.Matched, len(c.Repositories))
+	case c.Via != "":
+		return fmt.Sprintf("Configure %s trailers to use a public identity and rewrite existing %s lines with filter-repo --replace-message in %d repo(s)",
+			c.Via, c.Via, len(c.Repositories))
+	case strings.HasPrefix(c.Field, "page_"):
+		return fmt.Sprintf("Remove %q from the site sources and republish", c.Matched)
+	default:
+		return fmt.Sprintf("Redact %q from commit messages with filter-repo --replace-message in %d repo(s)",
+			c.Matched, len(c.Repositories))
+	}
+}
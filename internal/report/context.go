@@ -0,0 +1,24 @@
+package report
+
+import (
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+)
+
+// TrimContext narrows the context of every match of result to size
+// characters on each side of its first location, for output formats that
+// show less context than was extracted.
+func TrimContext(result *models.ScanResult, size int) {
+	result.RewriteMatches(func(match models.PIIMatch) (models.PIIMatch, bool) {
+		return TrimMatchContext(match, size), true
+	})
+}
+
+// TrimMatchContext returns match with its context narrowed to size
+// characters on each side of its first location.
+func TrimMatchContext(match models.PIIMatch, size int) models.PIIMatch {
+	if len(match.Locations) > 0 {
+		match.Context = pii.TrimContext(match.Context, match.Locations[0].Matched, size)
+	}
+	return match
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Filter selects the matches of a saved result to report again.
+type Filter struct {
+	// MinConfidence drops matches below this confidence.
+	MinConfidence float64
+	// Repos, if set, keeps only the matches of repositories matching one
+	// of these owner/name globs, e.g. "acme/*". ExcludeRepos drops those
+	// matching one.
+	Repos        []string
+	ExcludeRepos []string
+}
+
+// Validate checks the repository patterns of f.
+func (f Filter) Validate() error {
+	for _, glob := range append(f.Repos, f.ExcludeRepos...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid repository pattern %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// Apply drops the matches of result f rejects, counting those below
+// MinConfidence in LowConfidence, and returns the number dropped. Summary and
+// PrivacyScore are left to be recomputed.
+func (f Filter) Apply(result *models.ScanResult) int {
+	dropped := 0
+	result.RewriteMatches(func(match models.PIIMatch) (models.PIIMatch, bool) {
+		repo := strings.ToLower(match.Commit.Repository)
+		switch {
+		case match.Confidence < f.MinConfidence:
+			result.LowConfidence++
+		case len(f.Repos) > 0 && !matchRepo(f.Repos, repo):
+		case matchRepo(f.ExcludeRepos, repo):
+		default:
+			return match, true
+		}
+		dropped++
+		return match, false
+	})
+	return dropped
+}
+
+// matchRepo reports whether repo matches one of globs, case-insensitively.
+func matchRepo(globs []string, repo string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(strings.ToLower(glob), repo); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Merge combines the results of scanning one identity on several providers
+// into one, attributing each match, error and skipped repository to its
+// provider. results[i] is the result of providers[i], nil if that scan
+// failed. A commit mirrored on several providers, recognized by its SHA, is
+// reported once, on the first provider that found it.
+func Merge(providers []string, results []*models.ScanResult) *models.ScanResult {
+	merged := &models.ScanResult{
+		SchemaVersion: models.SchemaVersion,
+		Providers:     providers,
+		Matches:       []models.PIIMatch{},
+		Errors:        []models.ScanError{},
+	}
+
+	var usernames, incomplete []string
+	owners := make(map[string]string) // provider of each commit SHA reported
+	mirrored := make(map[string]bool)
+	for i, r := range results {
+		if r == nil {
+			continue
+		}
+		p := providers[i]
+		if !slices.Contains(usernames, r.Username) {
+			usernames = append(usernames, r.Username)
+		}
+
+		for match := range r.AllMatches() {
+			if sha := match.Commit.SHA; sha != "" {
+				if owner, ok := owners[sha]; ok && owner != p {
+					if !mirrored[sha] {
+						mirrored[sha] = true
+						merged.MirroredCommits++
+					}
+					continue
+				}
+				owners[sha] = p
+			}
+			match.Provider = p
+			merged.Matches = append(merged.Matches, match)
+		}
+		for _, e := range r.Errors {
+			e.Provider = p
+			merged.Errors = append(merged.Errors, e)
+		}
+		for _, sr := range r.SkippedRepos {
+			sr.Provider = p
+			merged.SkippedRepos = append(merged.SkippedRepos, sr)
+		}
+		merged.Associations = append(merged.Associations, r.Associations...)
+		if r.Incomplete {
+			incomplete = append(incomplete, p+": "+r.IncompleteReason)
+		}
+
+		merged.SearchedRepos += r.SearchedRepos
+		merged.TotalCommits += r.TotalCommits
+		merged.Suppressed += r.Suppressed
+		merged.LowConfidence += r.LowConfidence
+		merged.SkippedForks += r.SkippedForks
+		merged.IgnoredRepos += r.IgnoredRepos
+		merged.ExternalRepos += r.ExternalRepos
+		merged.DuplicateCommits += r.DuplicateCommits
+		merged.BotCommits += r.BotCommits
+		merged.EmailSearchCommits += r.EmailSearchCommits
+		merged.NameSearchCommits += r.NameSearchCommits
+		merged.CodeResults += r.CodeResults
+		merged.PullRequests += r.PullRequests
+		merged.SigningKeys += r.SigningKeys
+		merged.EventCommits += r.EventCommits
+		merged.Events += r.Events
+		merged.Refs += r.Refs
+		merged.Files += r.Files
+		merged.ArchiveCommits += r.ArchiveCommits
+		merged.UnchangedRepos += r.UnchangedRepos
+		merged.CarriedMatches += r.CarriedMatches
+	}
+
+	merged.Username = strings.Join(usernames, ", ")
+	if len(incomplete) > 0 {
+		merged.Incomplete = true
+		merged.IncompleteReason = strings.Join(incomplete, "; ")
+	}
+	merged.Summary = Summarize(merged)
+	merged.PrivacyScore = Score(merged)
+	return merged
+}
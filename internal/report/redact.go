@@ -0,0 +1,157 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Redact masks the matched PII throughout result, so it can be shared without
+// leaking it again, as well as the emails of associations and the names of
+// .mailmap entries. Repository names, commit SHAs and URLs are kept.
+func Redact(result *models.ScanResult) {
+	var texts []string
+	result.RewriteMatches(func(match models.PIIMatch) (models.PIIMatch, bool) {
+		for _, loc := range match.Locations {
+			texts = append(texts, loc.Matched)
+		}
+		return RedactMatch(match), true
+	})
+
+	r := newRedactor(texts)
+	for i := range result.Clusters {
+		c := &result.Clusters[i]
+		c.Matched = r.redact(c.Matched)
+		c.Recommendation = r.redact(c.Recommendation)
+	}
+	for i := range result.Associations {
+		a := &result.Associations[i]
+		a.Detail = emailPattern.ReplaceAllStringFunc(r.redact(a.Detail), mask)
+		if a.Type == models.AssociationMailmap {
+			a.Detail = redactMailmapNames(a.Detail)
+		}
+	}
+}
+
+// redactMailmapNames masks the names of a .mailmap entry, the text outside
+// the angle brackets around emails.
+func redactMailmapNames(line string) string {
+	var b strings.Builder
+	for line != "" {
+		i := strings.IndexByte(line, '<')
+		if i < 0 {
+			i = len(line)
+		}
+		if strings.TrimSpace(line[:i]) != "" {
+			b.WriteString(mask(line[:i]))
+		} else {
+			b.WriteString(line[:i])
+		}
+		line = line[i:]
+
+		j := strings.IndexByte(line, '>') + 1
+		if j == 0 {
+			j = len(line)
+		}
+		b.WriteString(line[:j])
+		line = line[j:]
+	}
+	return b.String()
+}
+
+// emailPattern matches the email addresses left in association details, such
+// as those of .mailmap entries.
+var emailPattern = regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+
+// RedactMatch returns a copy of match with its matched text masked wherever it
+// appears: in the locations, the context, the commit and its pull requests.
+func RedactMatch(match models.PIIMatch) models.PIIMatch {
+	texts := make([]string, len(match.Locations))
+	for i, loc := range match.Locations {
+		texts[i] = loc.Matched
+	}
+	r := newRedactor(texts)
+
+	match.Locations = append([]models.Location(nil), match.Locations...)
+	for i := range match.Locations {
+		match.Locations[i].Matched = mask(match.Locations[i].Matched)
+	}
+	match.Context = r.redact(match.Context)
+
+	c := &match.Commit
+	c.Message = r.redact(c.Message)
+	c.Author.Name = r.redact(c.Author.Name)
+	c.Author.Email = r.redact(c.Author.Email)
+	c.Committer.Name = r.redact(c.Committer.Name)
+	c.Committer.Email = r.redact(c.Committer.Email)
+	c.Trailers = append([]models.Trailer(nil), c.Trailers...)
+	for i := range c.Trailers {
+		c.Trailers[i].Value = r.redact(c.Trailers[i].Value)
+	}
+	match.PullRequests = append([]models.PullRequest(nil), match.PullRequests...)
+	for i := range match.PullRequests {
+		match.PullRequests[i].Title = r.redact(match.PullRequests[i].Title)
+	}
+	return match
+}
+
+// redactor replaces every occurrence of a set of texts, ignoring case.
+type redactor struct {
+	pattern *regexp.Regexp
+}
+
+func newRedactor(texts []string) redactor {
+	seen := make(map[string]bool)
+	var quoted []string
+	for _, t := range texts {
+		key := strings.ToLower(t)
+		if strings.TrimSpace(t) == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		quoted = append(quoted, regexp.QuoteMeta(t))
+	}
+	if len(quoted) == 0 {
+		return redactor{}
+	}
+
+	// Longest first, so "John Doe" is masked as a whole rather than as "John"
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return redactor{pattern: regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))}
+}
+
+func (r redactor) redact(s string) string {
+	if r.pattern == nil || s == "" {
+		return s
+	}
+	return r.pattern.ReplaceAllStringFunc(s, mask)
+}
+
+// mask hides a matched text: emails are replaced by a short hash, so the same
+// address can still be recognized across findings, and other text keeps only
+// the first letter of each word ("John Doe" becomes "J*** D**").
+func mask(text string) string {
+	if strings.Contains(text, "@") {
+		sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(text))))
+		return "email:" + hex.EncodeToString(sum[:])[:12]
+	}
+
+	var b strings.Builder
+	for i, word := range strings.Split(text, " ") {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		first, size := utf8.DecodeRuneInString(word)
+		if size == 0 {
+			continue
+		}
+		b.WriteRune(first)
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(word[size:])))
+	}
+	return b.String()
+}
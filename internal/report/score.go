@@ -0,0 +1,134 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// exposureCategories are the categories of a privacy score, in the order
+// reported.
+var exposureCategories = []models.ExposureCategory{
+	models.CategoryCommitMetadata,
+	models.CategoryMessages,
+	models.CategoryProfile,
+	models.CategoryContent,
+}
+
+// categoryWeights scale how much each category adds to the overall score.
+// Commit metadata ties a name or email to every commit and is copied by every
+// clone; repository files and page text are the easiest to fix.
+var categoryWeights = map[models.ExposureCategory]float64{
+	models.CategoryCommitMetadata: 1.0,
+	models.CategoryProfile:        0.9,
+	models.CategoryMessages:       0.8,
+	models.CategoryContent:        0.7,
+}
+
+// saturation is the sum of the confidences of a category's findings at which
+// its score reaches 63; the score approaches 100 as findings add up.
+const saturation = 3.0
+
+// Score rates the exposure found by result, after suppressions. Every
+// location adds its confidence to its category (see Category), and the
+// overall score combines the categories as independent exposures, each
+// weighted by categoryWeights. Recommendations start with the most exposed
+// category.
+func Score(result *models.ScanResult) *models.PrivacyScore {
+	sums := make(map[models.ExposureCategory]float64)
+	counts := make(map[models.ExposureCategory]int)
+	emails := false
+	for match := range result.AllMatches() {
+		for _, loc := range match.Locations {
+			c := Category(match, loc)
+			confidence := loc.Confidence
+			if confidence == 0 {
+				confidence = match.Confidence
+			}
+			sums[c] += confidence
+			counts[c]++
+			emails = emails || loc.Field == "author_email" || loc.Field == "committer_email"
+		}
+	}
+
+	ps := &models.PrivacyScore{Categories: make([]models.CategoryScore, 0, len(exposureCategories))}
+	unexposed := 1.0
+	for _, c := range exposureCategories {
+		exposure := 1 - math.Exp(-sums[c]/saturation)
+		unexposed *= 1 - categoryWeights[c]*exposure
+		ps.Categories = append(ps.Categories, models.CategoryScore{
+			Category: c,
+			Score:    int(math.Round(100 * exposure)),
+			Findings: counts[c],
+		})
+	}
+	ps.Score = int(math.Round(100 * (1 - unexposed)))
+	ps.Rating = rating(ps.Score)
+
+	byScore := slices.Clone(ps.Categories)
+	slices.SortStableFunc(byScore, func(a, b models.CategoryScore) int { return b.Score - a.Score })
+	for _, cs := range byScore {
+		if cs.Findings > 0 {
+			ps.Recommendations = append(ps.Recommendations, recommendation(cs.Category, emails))
+		}
+	}
+	if s := result.Summary; s != nil && len(s.TopRepositories) > 1 {
+		top := s.TopRepositories[0]
+		ps.Recommendations = append(ps.Recommendations, fmt.Sprintf("Start with %s, which has the most findings (%d of %d).", top.Repository, top.Matches, result.MatchCount()))
+	}
+	if result.Incomplete || len(result.Errors) > 0 || len(result.SkippedRepos) > 0 {
+		ps.Recommendations = append(ps.Recommendations, "Parts of the scan failed, so the score may be understated; scan again once the errors are resolved.")
+	} else if result.MatchCount() == 0 {
+		ps.Recommendations = append(ps.Recommendations, "Nothing was found. Keep committing under a public handle and your GitHub noreply address.")
+	}
+	return ps
+}
+
+// Category returns the category of exposure of a location of match.
+func Category(match models.PIIMatch, loc models.Location) models.ExposureCategory {
+	switch f := loc.Field; {
+	case f == "author_name" || f == "committer_name" || f == "tagger_name" ||
+		f == "author_email" || f == "committer_email" || f == "signer_uid" || strings.HasPrefix(f, models.TrailerFieldPrefix):
+		return models.CategoryCommitMetadata
+	case f == "message" || f == "tag_message" || f == "tag_name" || strings.HasPrefix(f, "release_") || f == "branch_name":
+		return models.CategoryMessages
+	case strings.HasPrefix(f, "event_") || strings.HasPrefix(f, "pr_") || f == "page_title" || f == "page_meta" || match.Source == models.SourceEvents:
+		return models.CategoryProfile
+	}
+	return models.CategoryContent
+}
+
+// rating labels a privacy score.
+func rating(score int) models.ExposureRating {
+	switch {
+	case score == 0:
+		return models.RatingNone
+	case score < 25:
+		return models.RatingLow
+	case score < 50:
+		return models.RatingModerate
+	case score < 75:
+		return models.RatingHigh
+	}
+	return models.RatingSevere
+}
+
+// recommendation returns what reduces the exposure of a category. Emails
+// reports whether commit emails were found.
+func recommendation(c models.ExposureCategory, emails bool) string {
+	switch c {
+	case models.CategoryCommitMetadata:
+		if emails {
+			return "Commit metadata: set git config --global user.email to your GitHub noreply address, enable \"Block command line pushes that expose my email\" (Settings → Emails), and rewrite your own repositories with git filter-repo --mailmap."
+		}
+		return "Commit metadata: commit under a public handle from now on (git config --global user.name), and rewrite your own repositories with git filter-repo --mailmap."
+	case models.CategoryMessages:
+		return "Messages: keep names and emails out of commit messages, tag messages and release notes, and rewrite those of your own repositories with git filter-repo --replace-message."
+	case models.CategoryProfile:
+		return "Profile: edit or delete the comments, issues and pull requests that name you, and remove personal details from your Pages site's title and metadata."
+	}
+	return "Content: replace your name and email in package manifests, author lists, workflows and site pages with a public handle or your noreply address."
+}
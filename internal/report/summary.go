@@ -0,0 +1,76 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// topRepositories is the number of repositories listed in
+// Summary.TopRepositories.
+const topRepositories = 5
+
+// Summarize aggregates the matches of result by repository, PII type and
+// field, and its errors by type. It returns nil when there are neither
+// matches nor errors.
+func Summarize(result *models.ScanResult) *models.Summary {
+	if result.MatchCount() == 0 && len(result.Errors) == 0 {
+		return nil
+	}
+
+	s := &models.Summary{
+		ByRepository: make(map[string]int),
+		ByPIIType:    make(map[models.PIIType]int),
+		ByField:      make(map[string]int),
+	}
+	for _, e := range result.Errors {
+		if s.ByErrorType == nil {
+			s.ByErrorType = make(map[models.ErrorType]int)
+		}
+		t := e.Type
+		if t == "" {
+			t = models.ErrorOther
+		}
+		s.ByErrorType[t]++
+	}
+	for match := range result.AllMatches() {
+		s.ByRepository[match.Commit.Repository]++
+		s.ByPIIType[match.PIIType]++
+		if match.Provider != "" {
+			if s.ByProvider == nil {
+				s.ByProvider = make(map[string]int)
+			}
+			s.ByProvider[match.Provider]++
+		}
+		for _, loc := range match.Locations {
+			s.ByField[loc.Field]++
+		}
+
+		// Pages site matches have no commit date
+		date := match.Commit.Date
+		if date.IsZero() {
+			continue
+		}
+		if s.FirstLeak == nil || date.Before(*s.FirstLeak) {
+			s.FirstLeak = &date
+		}
+		if s.LastLeak == nil || date.After(*s.LastLeak) {
+			s.LastLeak = &date
+		}
+	}
+
+	for repo, n := range s.ByRepository {
+		s.TopRepositories = append(s.TopRepositories, models.RepoCount{Repository: repo, Matches: n})
+	}
+	sort.Slice(s.TopRepositories, func(i, j int) bool {
+		a, b := s.TopRepositories[i], s.TopRepositories[j]
+		if a.Matches != b.Matches {
+			return a.Matches > b.Matches
+		}
+		return a.Repository < b.Repository
+	})
+	if len(s.TopRepositories) > topRepositories {
+		s.TopRepositories = s.TopRepositories[:topRepositories]
+	}
+	return s
+}
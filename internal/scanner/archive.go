@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// scanArchive scans the commits of the user's push events recorded in GH
+// Archive between Config.ArchiveFrom and Config.ArchiveTo. It runs after the
+// other steps, so the commits it scans are those no longer found on GitHub:
+// leaks removed by a history rewrite that persist in the archive. It returns
+// the number of commits scanned.
+func (s *Scanner) scanArchive(ctx context.Context, login string, result *models.ScanResult, seen *shaSet) int {
+	ctx, span := tracer.Start(ctx, "scanner.archive")
+
+	s.log("Reading GH Archive from %s to %s", s.config.ArchiveFrom.Format("2006-01-02T15"), s.config.ArchiveTo.Format("2006-01-02T15"))
+	var events []*models.ActivityEvent
+	err := s.retry(ctx, "GH Archive", func() (err error) {
+		events, err = s.config.Archive.PushEvents(ctx, login, s.config.ArchiveFrom, s.config.ArchiveTo)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			s.emit(Event{Type: EventError, Err: err})
+			result.Errors = append(result.Errors, scanError("", err))
+		}
+		tracing.EndSpan(span, err)
+		return 0
+	}
+
+	total := s.scanPushedCommits(events, models.SourceArchive, span.SpanContext(), result, seen)
+	result.ArchiveCommits += total
+	span.SetAttributes(attribute.Int("scanner.events", len(events)), attribute.Int("scanner.commits", total))
+	tracing.EndSpan(span, nil)
+	return total
+}
@@ -0,0 +1,198 @@
+package scanner
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/ignore"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// codeownersDirs are the directories GitHub looks for a CODEOWNERS file in,
+// the root being empty.
+var codeownersDirs = []string{".github", "", "docs"}
+
+// scanOrgs records the organizations username is a public member of, and
+// the fields of their profiles naming the user, as associations.
+func (s *Scanner) scanOrgs(ctx context.Context, username string, result *models.ScanResult) {
+	lister, ok := s.client.(provider.OrgLister)
+	if !ok {
+		return
+	}
+	ctx, span := tracer.Start(ctx, "scanner.orgs")
+
+	var orgs []*models.Organization
+	err := s.retry(ctx, "the organizations of "+username, func() (err error) {
+		orgs, err = lister.ListUserOrgs(ctx, username)
+		return err
+	})
+	if err != nil && ctx.Err() == nil {
+		s.emit(Event{Type: EventError, Err: err})
+		result.Errors = append(result.Errors, scanError("", err))
+	}
+	for _, org := range orgs {
+		result.Associations = append(result.Associations, s.orgAssociations(org)...)
+	}
+
+	span.SetAttributes(attribute.Int("scanner.orgs", len(orgs)))
+	tracing.EndSpan(span, err)
+}
+
+// orgAssociations returns the membership of org, detailed with what its
+// profile tells, followed by the fields of the profile where the names or
+// emails searched for were found.
+func (s *Scanner) orgAssociations(org *models.Organization) []models.Association {
+	var detail []string
+	for _, v := range []string{org.Name, org.Location, org.Email, org.Blog} {
+		if v = strings.TrimSpace(v); v != "" {
+			detail = append(detail, v)
+		}
+	}
+	associations := []models.Association{{
+		Type:         models.AssociationOrgMembership,
+		Organization: org.Login,
+		Detail:       strings.Join(detail, ", "),
+		URL:          org.URL,
+	}}
+
+	fields := []struct{ name, value string }{
+		{"email", org.Email},
+		{"name", org.Name},
+		{"blog", org.Blog},
+		{"location", org.Location},
+		{"description", org.Description},
+	}
+	texts := make([]pii.Text, 0, len(fields))
+	for _, f := range fields {
+		texts = append(texts, pii.Text{Text: f.value, Field: f.name})
+	}
+	matches, _ := pii.ApplyCommonWordMode(s.config.CommonWords, s.detector.DetectInTexts(texts))
+	matches, _ = s.applyIgnoreRules(s.config.Ignore, matches)
+	for _, f := range fields {
+		if slices.ContainsFunc(matches, func(m pii.Match) bool { return m.Field == f.name }) {
+			associations = append(associations, models.Association{
+				Type:         models.AssociationOrgProfile,
+				Organization: org.Login,
+				Field:        f.name,
+				Detail:       f.value,
+				URL:          org.URL,
+			})
+		}
+	}
+	return associations
+}
+
+// fileAssociations returns the lines of the CODEOWNERS and .mailmap files of
+// repo's default branch linking username to an identity: CODEOWNERS rules
+// naming the account or an email searched for, and .mailmap entries mapping
+// the account's noreply email. Paths ignored by rules are skipped. Providers
+// that cannot list files yield none.
+func (s *Scanner) fileAssociations(ctx context.Context, repo *models.Repository, username string, rules *ignore.Rules) ([]models.Association, error) {
+	lister, ok := s.client.(provider.FileLister)
+	if !ok {
+		return nil, nil
+	}
+	root, err := lister.ListFiles(ctx, repo.Owner, repo.Name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// The first CODEOWNERS file found is the one GitHub uses
+	var files []*models.RepoFile
+	for _, dir := range codeownersDirs {
+		entries := root
+		if dir != "" {
+			if !slices.ContainsFunc(root, func(f *models.RepoFile) bool { return f.Dir && f.Path == dir }) {
+				continue
+			}
+			if entries, err = lister.ListFiles(ctx, repo.Owner, repo.Name, dir); err != nil {
+				return nil, err
+			}
+		}
+		if i := slices.IndexFunc(entries, isFile("CODEOWNERS")); i >= 0 {
+			files = append(files, entries[i])
+			break
+		}
+	}
+	mailmap := slices.IndexFunc(root, isFile(".mailmap"))
+	if mailmap >= 0 {
+		files = append(files, root[mailmap])
+	}
+
+	var associations []models.Association
+	for _, f := range files {
+		if rules.MatchPath(f.Path) {
+			continue
+		}
+		data, err := s.client.GetFileContent(ctx, repo.Owner, repo.Name, f.Path)
+		if err != nil {
+			return nil, err
+		}
+		typ, names := models.AssociationCodeowners, s.codeownerNames(username)
+		if mailmap >= 0 && f == root[mailmap] {
+			typ, names = models.AssociationMailmap, noreplyPattern(username)
+		}
+		for i, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") || !names.MatchString(line) {
+				continue
+			}
+			url := f.URL
+			if url != "" {
+				url = fmt.Sprintf("%s#L%d", url, i+1)
+			}
+			associations = append(associations, models.Association{
+				Type:       typ,
+				Repository: repo.FullName,
+				Path:       f.Path,
+				Line:       i + 1,
+				Detail:     line,
+				URL:        url,
+			})
+		}
+	}
+	return associations, nil
+}
+
+// sortAssociations orders associations by repository, the organization ones
+// first, then by path and line.
+func sortAssociations(associations []models.Association) {
+	slices.SortStableFunc(associations, func(a, b models.Association) int {
+		return cmp.Or(
+			cmp.Compare(a.Repository, b.Repository),
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Line, b.Line),
+		)
+	})
+}
+
+// isFile returns a function reporting whether an entry of a directory
+// listing is a file named name.
+func isFile(name string) func(*models.RepoFile) bool {
+	return func(f *models.RepoFile) bool { return !f.Dir && path.Base(f.Path) == name }
+}
+
+// codeownerNames matches the owners of a CODEOWNERS rule that are the
+// account username or an email searched for.
+func (s *Scanner) codeownerNames(username string) *regexp.Regexp {
+	owners := []string{"@" + regexp.QuoteMeta(username)}
+	for _, email := range s.searchEmails() {
+		owners = append(owners, regexp.QuoteMeta(email))
+	}
+	return regexp.MustCompile(`(?i)\s(` + strings.Join(owners, "|") + `)(\s|#|$)`)
+}
+
+// noreplyPattern matches the GitHub noreply emails of username, with or
+// without the account ID prefix.
+func noreplyPattern(username string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)[<\s+]` + regexp.QuoteMeta(username) + `@users\.noreply\.github\.com>`)
+}
@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CodeSearch selects where code search looks for the searched emails and
+// names.
+type CodeSearch string
+
+const (
+	// CodeSearchOff skips code search (the default).
+	CodeSearchOff CodeSearch = ""
+	// CodeSearchRepos searches the repositories the user owns and those
+	// scanned.
+	CodeSearchRepos CodeSearch = "repos"
+	// CodeSearchGlobal searches all public repositories.
+	CodeSearchGlobal CodeSearch = "global"
+)
+
+// ParseCodeSearch validates a code search scope; empty and off disable code
+// search.
+func ParseCodeSearch(s string) (CodeSearch, error) {
+	switch c := CodeSearch(strings.ToLower(s)); c {
+	case "", "off":
+		return CodeSearchOff, nil
+	case CodeSearchRepos, CodeSearchGlobal:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid code search scope %q: use repos, global or off", s)
+}
+
+// maxCodeQueryLength is the longest query code search accepts.
+const maxCodeQueryLength = 256
+
+// codeTerms returns the code search terms of the search criteria: each email
+// and full name, quoted to match exactly.
+func (s *Scanner) codeTerms() []string {
+	var terms []string
+	for _, email := range s.searchEmails() {
+		terms = append(terms, strconv.Quote(email))
+	}
+	for _, id := range s.criteria.AllIdentities() {
+		if id.FullName == "" {
+			continue
+		}
+		if t := strconv.Quote(id.FullName); !slices.Contains(terms, t) {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// scopedCodeQueries restricts each term to the repositories of username and
+// to repos owned by others, packing as many repo qualifiers into each query
+// as its length allows.
+func scopedCodeQueries(terms []string, username string, repos []*models.Repository) []string {
+	qualifiers := []string{"user:" + username}
+	for _, repo := range repos {
+		if !strings.EqualFold(repo.Owner, username) {
+			qualifiers = append(qualifiers, "repo:"+repo.FullName)
+		}
+	}
+
+	var queries []string
+	for _, term := range terms {
+		query := term
+		for _, q := range qualifiers {
+			if query != term && len(query)+1+len(q) > maxCodeQueryLength {
+				queries = append(queries, query)
+				query = term
+			}
+			query += " " + q
+		}
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+// scanCode runs each code search query and scans the fragments that matched,
+// recording each file once.
+func (s *Scanner) scanCode(ctx context.Context, queries []string, result *models.ScanResult) {
+	if len(queries) == 0 {
+		s.log("Code search skipped: no emails or full names to search for")
+		return
+	}
+	searcher, ok := s.client.(provider.CodeSearcher)
+	if !ok {
+		err := fmt.Errorf("code search is not supported by the %s provider", s.client.Name())
+		s.emit(Event{Type: EventError, Err: err})
+		result.Errors = append(result.Errors, scanError("", err))
+		return
+	}
+
+	ctx, span := tracer.Start(ctx, "scanner.code_search")
+
+	seen := make(map[string]bool)
+	for _, query := range queries {
+		if ctx.Err() != nil {
+			break
+		}
+		s.log("Searching code for %s", query)
+		var found []*models.CodeResult
+		err := s.retry(ctx, "the code search for "+query, func() (err error) {
+			found, err = searcher.SearchCode(ctx, query)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				s.emit(Event{Type: EventError, Err: err})
+				result.Errors = append(result.Errors, scanError("", err))
+			}
+			continue
+		}
+
+		for _, file := range found {
+			if seen[file.URL] || s.config.Ignore.MatchRepo(file.Repository) || s.config.Ignore.MatchPath(file.Path) {
+				continue
+			}
+			seen[file.URL] = true
+			result.CodeResults++
+
+			doc := document{Commit: &models.Commit{
+				Repository: file.Repository,
+				Message:    file.Path,
+				URL:        file.URL,
+			}}
+			for _, fragment := range file.Fragments {
+				doc.Texts = append(doc.Texts, pii.Text{Text: fragment, Field: "code"})
+			}
+			s.scanDocument(doc, models.SourceCodeSearch, result)
+		}
+	}
+	span.SetAttributes(attribute.Int("scanner.code_results", result.CodeResults))
+	tracing.EndSpan(span, nil)
+}
@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+)
+
+// defaultLatency is assumed for requests until the provider measured some.
+const defaultLatency = 500 * time.Millisecond
+
+// autoscaleInterval is how often the fetch workers are resized.
+var autoscaleInterval = 2 * time.Second
+
+// autoscale resizes the fetch workers of a scan to the pace of the provider
+// until ctx is done, so workers do not pile up waiting on its rate limiter.
+// setWorkers is called with the initial size right away. Providers that do
+// not report their pace keep MaxWorkers.
+func (s *Scanner) autoscale(ctx context.Context, setWorkers func(int)) {
+	paced, ok := s.client.(provider.Paced)
+	if !ok {
+		return
+	}
+	resize := func() {
+		rps, latency := paced.Pace()
+		setWorkers(fetchWorkers(rps, latency, s.config.MaxWorkers))
+	}
+	resize()
+
+	go func() {
+		ticker := time.NewTicker(autoscaleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				resize()
+			}
+		}
+	}()
+}
+
+// fetchWorkers returns the number of workers that keep requests flowing at
+// rps requests per second when each takes latency: by Little's law, rps times
+// latency requests are in flight, and one more worker absorbs the time spent
+// between requests. The result is between 1 and maxWorkers.
+func fetchWorkers(rps float64, latency time.Duration, maxWorkers int) int {
+	if latency <= 0 {
+		latency = defaultLatency
+	}
+	if rps <= 0 || math.IsInf(rps, 1) {
+		return maxWorkers
+	}
+	n := int(math.Ceil(rps*latency.Seconds())) + 1
+	return min(max(n, 1), maxWorkers)
+}
+
+// smallerRepo orders repositories smallest first, so many small ones finish
+// early and large ones do not hold up the start of the scan. Repositories of
+// unknown size come last.
+func smallerRepo(a, b *models.Repository) bool {
+	if a.Size == 0 || b.Size == 0 {
+		return a.Size != 0 && b.Size == 0
+	}
+	return a.Size < b.Size
+}
@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+)
+
+// Discovery selects how the repositories to scan are found.
+type Discovery string
+
+const (
+	// DiscoveryRepos scans the repositories the user owns (the default).
+	DiscoveryRepos Discovery = "repos"
+	// DiscoverySearch scans the repositories where commit search finds the
+	// user's commits, including upstream projects they contributed to.
+	DiscoverySearch Discovery = "search"
+	// DiscoveryBoth scans the union of both, without duplicates.
+	DiscoveryBoth Discovery = "both"
+	// DiscoveryNone scans no repositories, only the other sources enabled,
+	// such as events, for lightweight monitoring.
+	DiscoveryNone Discovery = "none"
+)
+
+// ParseDiscovery validates a discovery mode; empty selects repos.
+func ParseDiscovery(s string) (Discovery, error) {
+	switch d := Discovery(strings.ToLower(s)); d {
+	case "":
+		return DiscoveryRepos, nil
+	case DiscoveryRepos, DiscoverySearch, DiscoveryBoth, DiscoveryNone:
+		return d, nil
+	}
+	return "", fmt.Errorf("invalid discovery mode %q: use repos, search, both or none", s)
+}
+
+// discoverRepos lists the repositories to scan according to the discovery
+// mode. In both mode a failed search is reported as a scan error and the
+// owned repositories are still scanned.
+func (s *Scanner) discoverRepos(ctx context.Context, username string, result *models.ScanResult) ([]*models.Repository, error) {
+	var repos []*models.Repository
+	if s.config.Discovery == DiscoveryNone {
+		return repos, nil
+	}
+	if s.config.Discovery != DiscoverySearch {
+		var owned []*models.Repository
+		err := s.retry(ctx, "the repositories of "+username, func() (err error) {
+			owned, err = s.client.ListUserRepos(ctx, username)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		repos = owned
+	}
+	if s.config.Discovery == DiscoveryRepos {
+		return repos, nil
+	}
+
+	searcher, ok := s.client.(provider.RepoSearcher)
+	if !ok {
+		return nil, fmt.Errorf("discovery mode %q is not supported by the %s provider", s.config.Discovery, s.client.Name())
+	}
+	s.log("Searching for repositories with commits by %s...", username)
+	var found []*models.Repository
+	err := s.retry(ctx, "the repository search", func() (err error) {
+		found, err = searcher.SearchContributedRepos(ctx, username)
+		return err
+	})
+	if err != nil {
+		if s.config.Discovery == DiscoverySearch || ctx.Err() != nil {
+			return nil, err
+		}
+		s.emit(Event{Type: EventError, Err: err})
+		result.Errors = append(result.Errors, scanError("", err))
+		return repos, nil
+	}
+
+	known := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		known[strings.ToLower(repo.FullName)] = true
+	}
+	for _, repo := range found {
+		key := strings.ToLower(repo.FullName)
+		if known[key] {
+			continue
+		}
+		known[key] = true
+		repos = append(repos, repo)
+		if !strings.EqualFold(repo.Owner, username) {
+			result.ExternalRepos++
+		}
+	}
+	return repos, nil
+}
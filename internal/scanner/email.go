@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// searchEmails returns the distinct emails of the search criteria and its
+// identities.
+func (s *Scanner) searchEmails() []string {
+	var emails []string
+	seen := make(map[string]bool)
+	add := func(list []string) {
+		for _, e := range list {
+			key := strings.ToLower(strings.TrimSpace(e))
+			if key != "" && !seen[key] {
+				seen[key] = true
+				emails = append(emails, e)
+			}
+		}
+	}
+	add(s.criteria.Emails)
+	for _, id := range s.criteria.Identities {
+		add(id.Emails)
+	}
+	return emails
+}
+
+// scanEmailCommits searches for commits authored with each criteria email and
+// scans those not already scanned. This finds commits made under another
+// account, or none, such as before a username change. It returns the number
+// of commits scanned.
+func (s *Scanner) scanEmailCommits(ctx context.Context, result *models.ScanResult, seen *shaSet) int {
+	emails := s.searchEmails()
+	if len(emails) == 0 {
+		s.log("Email discovery skipped: no emails to search for")
+		return 0
+	}
+	searcher, ok := s.client.(provider.EmailSearcher)
+	if !ok {
+		err := fmt.Errorf("email discovery is not supported by the %s provider", s.client.Name())
+		s.emit(Event{Type: EventError, Err: err})
+		result.Errors = append(result.Errors, scanError("", err))
+		return 0
+	}
+
+	ctx, span := tracer.Start(ctx, "scanner.email_search")
+
+	total := 0
+	for _, email := range emails {
+		if ctx.Err() != nil {
+			break
+		}
+		s.log("Searching for commits authored with %s", email)
+		var commits []*models.Commit
+		err := s.retry(ctx, "the search for "+email, func() (err error) {
+			commits, err = searcher.SearchCommitsByEmail(ctx, email)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				s.emit(Event{Type: EventError, Err: err})
+				result.Errors = append(result.Errors, scanError("", err))
+			}
+			continue
+		}
+
+		n := s.scanFoundCommits(commits, models.SourceEmailSearch, span.SpanContext(), result, seen)
+		total += n
+		result.EmailSearchCommits += n
+	}
+	span.SetAttributes(attribute.Int("scanner.commits", total))
+	tracing.EndSpan(span, nil)
+	return total
+}
+
+// scanNameCommits searches for commits whose author name is the full name of
+// the search criteria or of one of its identities, and scans those not
+// already scanned. It returns the number of commits scanned.
+func (s *Scanner) scanNameCommits(ctx context.Context, result *models.ScanResult, seen *shaSet) int {
+	var names []string
+	for _, id := range s.criteria.AllIdentities() {
+		if id.FullName != "" && !slices.Contains(names, id.FullName) {
+			names = append(names, id.FullName)
+		}
+	}
+	if len(names) == 0 {
+		return 0
+	}
+	searcher, ok := s.client.(provider.NameSearcher)
+	if !ok {
+		err := fmt.Errorf("name search is not supported by the %s provider", s.client.Name())
+		s.emit(Event{Type: EventError, Err: err})
+		result.Errors = append(result.Errors, scanError("", err))
+		return 0
+	}
+
+	ctx, span := tracer.Start(ctx, "scanner.name_search")
+
+	total := 0
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		s.log("Searching for commits authored as %s", name)
+		var commits []*models.Commit
+		err := s.retry(ctx, "the search for "+name, func() (err error) {
+			commits, err = searcher.SearchCommitsByName(ctx, name)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				s.emit(Event{Type: EventError, Err: err})
+				result.Errors = append(result.Errors, scanError("", err))
+			}
+			continue
+		}
+
+		n := s.scanFoundCommits(commits, models.SourceNameSearch, span.SpanContext(), result, seen)
+		total += n
+		result.NameSearchCommits += n
+	}
+	span.SetAttributes(attribute.Int("scanner.commits", total))
+	tracing.EndSpan(span, nil)
+	return total
+}
+
+// scanFoundCommits scans commits found by a search, grouped by repository in
+// the order found, skipping ignored repositories and commits already
+// scanned. It returns the number of commits scanned.
+func (s *Scanner) scanFoundCommits(commits []*models.Commit, source models.Source, span trace.SpanContext, result *models.ScanResult, seen *shaSet) int {
+	var repos []*models.Repository
+	byRepo := make(map[string][]*models.Commit)
+	for _, commit := range commits {
+		if s.config.Ignore.MatchRepo(commit.Repository) {
+			continue
+		}
+		if _, ok := byRepo[commit.Repository]; !ok {
+			owner, name, _ := strings.Cut(commit.Repository, "/")
+			repos = append(repos, &models.Repository{FullName: commit.Repository, Owner: owner, Name: name})
+		}
+		byRepo[commit.Repository] = append(byRepo[commit.Repository], commit)
+	}
+
+	return s.scanGroupedCommits(repos, byRepo, source, span, result, seen)
+}
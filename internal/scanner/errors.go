@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"context"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+)
+
+// scanError returns the warning recording err in a result, with its type.
+// repo is where err happened, if anywhere in particular.
+func scanError(repo string, err error) models.ScanError {
+	t := provider.Classify(err)
+	return models.ScanError{
+		Repository: repo,
+		Message:    err.Error(),
+		Severity:   "warning",
+		Type:       t,
+		Retryable:  t.Retryable(),
+	}
+}
+
+// retry calls fn again after retryable errors (see provider.Classify) until
+// Config.MaxRepoErrors attempts have failed. what names what fn fetches in
+// the log.
+func (s *Scanner) retry(ctx context.Context, what string, fn func() error) error {
+	for failures := 1; ; failures++ {
+		err := fn()
+		if err == nil || ctx.Err() != nil || failures >= s.config.MaxRepoErrors || !provider.Classify(err).Retryable() {
+			return err
+		}
+		s.log("Retrying %s after error: %v", what, err)
+		select {
+		case <-time.After(repoRetryDelay * time.Duration(failures)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
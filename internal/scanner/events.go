@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scanEvents scans the user's recent public events: the pushed commits not
+// already scanned, and the text of comments, reviews, opened issues and pull
+// requests, releases and created repositories. The events feed is updated
+// within minutes, so recent leaks are found before the repositories they
+// were pushed to are crawled. It returns the number of commits scanned.
+func (s *Scanner) scanEvents(ctx context.Context, username string, result *models.ScanResult, seen *shaSet) int {
+	lister, ok := s.client.(provider.EventLister)
+	if !ok {
+		err := fmt.Errorf("event scanning is not supported by the %s provider", s.client.Name())
+		s.emit(Event{Type: EventError, Err: err})
+		result.Errors = append(result.Errors, scanError("", err))
+		return 0
+	}
+
+	ctx, span := tracer.Start(ctx, "scanner.events")
+
+	s.log("Fetching recent public events of %s", username)
+	var events []*models.ActivityEvent
+	err := s.retry(ctx, "the events of "+username, func() (err error) {
+		events, err = lister.ListUserEvents(ctx, username)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			s.emit(Event{Type: EventError, Err: err})
+			result.Errors = append(result.Errors, scanError("", err))
+		}
+		tracing.EndSpan(span, err)
+		return 0
+	}
+	result.Events = len(events)
+
+	// Scan the text of the events, skipping ignored repositories
+	for _, ev := range events {
+		if (ev.Title == "" && ev.Body == "") || s.config.Ignore.MatchRepo(ev.Repository) {
+			continue
+		}
+		message := ev.Title
+		if message == "" {
+			message = ev.Body
+		}
+		doc := document{
+			Commit: &models.Commit{
+				Repository: ev.Repository,
+				Message:    message,
+				URL:        ev.URL,
+				Date:       ev.CreatedAt,
+			},
+			Texts: []pii.Text{
+				{Text: ev.Title, Field: "event_title"},
+				{Text: ev.Body, Field: "event_body"},
+			},
+		}
+		s.scanDocument(doc, models.SourceEvents, result)
+	}
+
+	total := s.scanPushedCommits(events, models.SourceEvents, span.SpanContext(), result, seen)
+	result.EventCommits += total
+	span.SetAttributes(attribute.Int("scanner.events", len(events)), attribute.Int("scanner.commits", total))
+	tracing.EndSpan(span, nil)
+	return total
+}
+
+// scanPushedCommits scans the commits of push events not already scanned,
+// grouped by repository in the order found, skipping ignored repositories.
+// It returns the number of commits scanned.
+func (s *Scanner) scanPushedCommits(events []*models.ActivityEvent, source models.Source, span trace.SpanContext, result *models.ScanResult, seen *shaSet) int {
+	var repos []*models.Repository
+	byRepo := make(map[string][]*models.Commit)
+	for _, ev := range events {
+		if len(ev.Commits) == 0 || s.config.Ignore.MatchRepo(ev.Repository) {
+			continue
+		}
+		if _, ok := byRepo[ev.Repository]; !ok {
+			owner, name, _ := strings.Cut(ev.Repository, "/")
+			repos = append(repos, &models.Repository{FullName: ev.Repository, Owner: owner, Name: name})
+		}
+		byRepo[ev.Repository] = append(byRepo[ev.Repository], ev.Commits...)
+	}
+
+	return s.scanGroupedCommits(repos, byRepo, source, span, result, seen)
+}
@@ -0,0 +1,210 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/ignore"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+)
+
+// workflowsDir holds the GitHub Actions workflows of a repository.
+const workflowsDir = ".github/workflows"
+
+// maxWorkflowFiles caps the workflows fetched per repository, one request
+// each.
+const maxWorkflowFiles = 20
+
+// leakFile is a kind of file that systematically holds real names and
+// emails, such as package manifests and author lists.
+type leakFile struct {
+	// field reports the matches found in files of the kind.
+	field string
+	// names are the file names of the kind, matched case-insensitively; a
+	// leading "*" matches any prefix.
+	names []string
+	// extract returns the parts of a file holding names and emails, nil to
+	// scan all of it.
+	extract func(content string) [][2]int
+}
+
+// leakFiles are the kinds of files scanned at the root of each repository.
+var leakFiles = []leakFile{
+	{field: "package_json", names: []string{"package.json"}, extract: packageJSONPeople},
+	{field: "mailmap", names: []string{".mailmap"}},
+	{field: "authors_file", names: []string{
+		"AUTHORS", "AUTHORS.md", "AUTHORS.txt",
+		"CONTRIBUTORS", "CONTRIBUTORS.md", "CONTRIBUTORS.txt",
+		"MAINTAINERS", "MAINTAINERS.md",
+	}},
+	{field: "setup_py", names: []string{"setup.py"}, extract: matchLines(setupPyPeople)},
+	{field: "gemspec", names: []string{"*.gemspec"}, extract: matchLines(gemspecPeople)},
+	{field: "pom_xml", names: []string{"pom.xml"}, extract: matchLines(pomPeople)},
+}
+
+// workflowFile is the kind of GitHub Actions workflows, found in workflowsDir.
+var workflowFile = leakFile{field: "workflow", names: []string{"*.yml", "*.yaml"}, extract: matchLines(workflowPeople)}
+
+var (
+	setupPyPeople  = regexp.MustCompile(`(?m)^.*\b(author|author_email|maintainer|maintainer_email)\s*=.*$`)
+	gemspecPeople  = regexp.MustCompile(`(?m)^.*\.(author|authors|email)\s*=.*$`)
+	pomPeople      = regexp.MustCompile(`(?s)<(developers|contributors)>.*?</(developers|contributors)>`)
+	workflowPeople = regexp.MustCompile(`(?mi)^.*(user\.name|user\.email|git_(author|committer)_(name|email)|commit_user_(name|email)|commit_author|(author|committer)_(name|email)).*$`)
+)
+
+// matches reports whether a file name is of the kind.
+func (f leakFile) matches(name string) bool {
+	for _, n := range f.names {
+		if suffix, ok := strings.CutPrefix(n, "*"); ok {
+			if len(name) > len(suffix) && strings.HasSuffix(strings.ToLower(name), strings.ToLower(suffix)) {
+				return true
+			}
+		} else if strings.EqualFold(name, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// text returns the content of a file as scanned: the lines holding names
+// and emails, the others left empty so that lines keep their numbers.
+func (f leakFile) text(content string) string {
+	if f.extract == nil {
+		return content
+	}
+	var b strings.Builder
+	start := 0
+	for _, span := range f.extract(content) {
+		// Whole lines are kept
+		from := strings.LastIndexByte(content[:span[0]], '\n') + 1
+		to := len(content)
+		if i := strings.IndexByte(content[span[1]:], '\n'); i >= 0 {
+			to = span[1] + i
+		}
+		if from < start {
+			from = start
+		}
+		if to < from {
+			continue
+		}
+		b.WriteString(strings.Repeat("\n", strings.Count(content[start:from], "\n")))
+		b.WriteString(content[from:to])
+		start = to
+	}
+	return b.String()
+}
+
+// matchLines returns an extract function keeping the lines of the matches of
+// re.
+func matchLines(re *regexp.Regexp) func(string) [][2]int {
+	return func(content string) [][2]int {
+		var spans [][2]int
+		for _, m := range re.FindAllStringIndex(content, -1) {
+			spans = append(spans, [2]int{m[0], m[1]})
+		}
+		return spans
+	}
+}
+
+// packageJSONPeople returns the values of the author, contributors and
+// maintainers fields of a package.json file.
+func packageJSONPeople(content string) [][2]int {
+	dec := json.NewDecoder(strings.NewReader(content))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	var spans [][2]int
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return spans
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return spans
+		}
+		if key == "author" || key == "contributors" || key == "maintainers" {
+			end := int(dec.InputOffset())
+			spans = append(spans, [2]int{end - len(bytes.TrimSpace(value)), end})
+		}
+	}
+	return spans
+}
+
+// fileDocuments returns the leak-prone files of repo's default branch as
+// documents: the files of leakFiles at its root and its workflows, skipping
+// the paths ignored by rules. Only the files found when listing the root and
+// the workflows are fetched. Providers that cannot list files yield none.
+func (s *Scanner) fileDocuments(ctx context.Context, repo *models.Repository, rules *ignore.Rules) ([]document, error) {
+	lister, ok := s.client.(provider.FileLister)
+	if !ok {
+		return nil, nil
+	}
+	root, err := lister.ListFiles(ctx, repo.Owner, repo.Name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	type wanted struct {
+		file *models.RepoFile
+		kind leakFile
+	}
+	var files []wanted
+	workflows := false
+	for _, f := range root {
+		if f.Dir {
+			workflows = workflows || f.Path == ".github"
+			continue
+		}
+		for _, kind := range leakFiles {
+			if kind.matches(path.Base(f.Path)) {
+				files = append(files, wanted{f, kind})
+				break
+			}
+		}
+	}
+	if workflows {
+		found, err := lister.ListFiles(ctx, repo.Owner, repo.Name, workflowsDir)
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		for _, f := range found {
+			if !f.Dir && workflowFile.matches(path.Base(f.Path)) && n < maxWorkflowFiles {
+				files = append(files, wanted{f, workflowFile})
+				n++
+			}
+		}
+	}
+
+	var docs []document
+	for _, w := range files {
+		if rules.MatchPath(w.file.Path) {
+			continue
+		}
+		data, err := s.client.GetFileContent(ctx, repo.Owner, repo.Name, w.file.Path)
+		if err != nil {
+			return nil, err
+		}
+		text := w.kind.text(string(data))
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		docs = append(docs, document{
+			Commit: &models.Commit{
+				Repository: repo.FullName,
+				Message:    w.file.Path,
+				URL:        w.file.URL,
+			},
+			Texts: []pii.Text{{Text: text, Field: w.kind.field}},
+		})
+	}
+	return docs, nil
+}
@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/metrics"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// identityLabel names the identity of the search criteria in results, in
+// place of a username: its full name and emails.
+func (s *Scanner) identityLabel() string {
+	var parts []string
+	for _, id := range s.criteria.AllIdentities() {
+		if id.FullName != "" {
+			parts = append(parts, id.FullName)
+		}
+	}
+	for _, email := range s.searchEmails() {
+		parts = append(parts, "<"+email+">")
+	}
+	return strings.Join(parts, " ")
+}
+
+// ScanIdentity scans for traces of the search criteria without an account,
+// such as one deleted or renamed: commits found by author email and author
+// name, and public code mentioning the emails or full names. Results name the
+// identity in place of a username.
+func (s *Scanner) ScanIdentity(ctx context.Context) (result *models.ScanResult, err error) {
+	label := s.identityLabel()
+	if label == "" {
+		return nil, fmt.Errorf("no emails or full name to search for")
+	}
+
+	startTime := time.Now()
+	s.startedAt.Store(startTime.UnixNano())
+	metrics.ScanStarted()
+	ctx, span := tracer.Start(ctx, "scanner.scan_identity", trace.WithAttributes(
+		attribute.String("scanner.provider", s.client.Name())))
+	defer func() {
+		metrics.ScanFinished(time.Since(startTime), result, err)
+		if result != nil {
+			span.SetAttributes(
+				attribute.Int("scanner.commits", result.TotalCommits),
+				attribute.Int("scanner.matches", result.MatchCount()),
+				attribute.Bool("scanner.incomplete", result.Incomplete),
+			)
+		}
+		tracing.EndSpan(span, err)
+	}()
+
+	result = &models.ScanResult{
+		SchemaVersion: models.SchemaVersion,
+		Username:      label,
+		Matches:       []models.PIIMatch{},
+		Errors:        []models.ScanError{},
+	}
+	if s.config.Spool != nil {
+		result.Spool = s.config.Spool
+	}
+
+	s.emit(Event{Type: EventScanStarted, Message: fmt.Sprintf("Starting scan for identity: %s", label)})
+	s.log("Starting scan for identity: %s", label)
+
+	seen := newSHASet()
+	totalCommits := s.scanEmailCommits(ctx, result, seen)
+	if ctx.Err() == nil {
+		totalCommits += s.scanNameCommits(ctx, result, seen)
+	}
+	if ctx.Err() == nil {
+		s.scanCode(ctx, s.codeTerms(), result)
+	}
+	if s.config.CheckSignatures && ctx.Err() == nil {
+		s.scanSignatures(ctx, result)
+	}
+	if s.config.PRContext && ctx.Err() == nil {
+		s.scanPullRequests(ctx, result)
+	}
+
+	if ctx.Err() != nil {
+		result.Incomplete = true
+		result.IncompleteReason = context.Cause(ctx).Error()
+		s.emit(Event{Type: EventInfo, Message: "Scan interrupted: " + result.IncompleteReason})
+	}
+
+	if s.config.Spool != nil {
+		if err := s.config.Spool.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	result.TotalCommits = totalCommits
+	result.Summary = report.Summarize(result)
+	result.PrivacyScore = report.Score(result)
+	result.ScanDuration = time.Since(startTime).String()
+
+	s.emit(Event{
+		Type:    EventScanFinished,
+		Commits: result.TotalCommits,
+		Matches: result.MatchCount(),
+		Message: fmt.Sprintf("Scan complete: %d commits, %d code results, %d matches, duration: %s",
+			result.TotalCommits, result.CodeResults, result.MatchCount(), result.ScanDuration),
+	})
+
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/ignore"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+)
+
+// ignoreFile is the outcome of loading the ignore file of a repository.
+type ignoreFile struct {
+	rules   *ignore.Rules     // the configured rules, merged with those of the file
+	warning *models.ScanError // why the file is not used, if it is not
+}
+
+// repoIgnoreRules returns the rules applying to repo: the configured ones,
+// merged with those of its ignore file when ignore files are honored. An
+// ignore file that cannot be fetched or parsed is left out, with a warning
+// saying so. Ignore files are loaded once per scan.
+func (s *Scanner) repoIgnoreRules(ctx context.Context, repo *models.Repository, username string) (*ignore.Rules, *models.ScanError) {
+	if !s.config.RespectIgnoreFiles || !strings.EqualFold(repo.Owner, username) {
+		return s.config.Ignore, nil
+	}
+
+	s.ignoreMu.Lock()
+	f, ok := s.ignoreFiles[repo.FullName]
+	s.ignoreMu.Unlock()
+	if ok {
+		return f.rules, f.warning
+	}
+
+	f = ignoreFile{rules: s.config.Ignore}
+	repoRules, err := s.loadIgnoreRules(ctx, repo)
+	var parseErr *ignoreParseError
+	switch {
+	case errors.As(err, &parseErr):
+		// Not a fetch error: the next scan fails the same way
+		f.warning = &models.ScanError{
+			Repository: repo.FullName,
+			Message:    fmt.Sprintf("%v; scanning with the configured ignore rules only", err),
+			Severity:   "warning",
+		}
+	case err != nil:
+		e := scanError(repo.FullName, fmt.Errorf("failed to fetch %s, scanning with the configured ignore rules only: %w", ignore.FileName, err))
+		f.warning = &e
+	default:
+		f.rules = f.rules.Merge(repoRules)
+	}
+	s.ignoreMu.Lock()
+	if s.ignoreFiles == nil {
+		s.ignoreFiles = make(map[string]ignoreFile)
+	}
+	s.ignoreFiles[repo.FullName] = f
+	s.ignoreMu.Unlock()
+	return f.rules, f.warning
+}
+
+// ignoreParseError is the error of an invalid ignore file.
+type ignoreParseError struct {
+	repo string
+	err  error
+}
+
+func (e *ignoreParseError) Error() string {
+	return fmt.Sprintf("invalid ignore file in %s: %v", e.repo, e.err)
+}
+
+func (e *ignoreParseError) Unwrap() error { return e.err }
+
+// loadIgnoreRules fetches and parses the repository's ignore file, if any.
+// Fetching is retried after retryable errors.
+func (s *Scanner) loadIgnoreRules(ctx context.Context, repo *models.Repository) (*ignore.Rules, error) {
+	var data []byte
+	err := s.retry(ctx, ignore.FileName+" of "+repo.FullName, func() (err error) {
+		data, err = s.client.GetFileContent(ctx, repo.Owner, repo.Name, ignore.FileName)
+		return err
+	})
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	rules, err := ignore.Parse(data)
+	if err != nil {
+		return nil, &ignoreParseError{repo: repo.FullName, err: err}
+	}
+	if !rules.Empty() {
+		s.log("Using %s from %s", ignore.FileName, repo.FullName)
+	}
+	return rules, nil
+}
+
+// ignoreLocations drops the locations of a recorded match accepted by the
+// ignore rules and returns the match with the number dropped.
+func ignoreLocations(rules *ignore.Rules, match models.PIIMatch) (models.PIIMatch, int) {
+	if rules.Empty() {
+		return match, 0
+	}
+
+	kept := make([]models.Location, 0, len(match.Locations))
+	for _, loc := range match.Locations {
+		if !rules.MatchText(loc.Matched, match.Context) {
+			kept = append(kept, loc)
+		}
+	}
+	ignored := len(match.Locations) - len(kept)
+	match.Locations = kept
+	return match, ignored
+}
+
+// applyIgnoreRules drops matches accepted by the ignore rules and returns the
+// remaining matches with the number dropped.
+func (s *Scanner) applyIgnoreRules(rules *ignore.Rules, matches []pii.Match) ([]pii.Match, int) {
+	if rules.Empty() {
+		return matches, 0
+	}
+
+	kept := matches[:0]
+	suppressed := 0
+	for _, m := range matches {
+		if rules.MatchText(m.Text, m.Context) {
+			suppressed++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept, suppressed
+}
@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+)
+
+// Incremental is what an incremental scan builds on: the repository states
+// recorded by previous scans and the matches found then.
+type Incremental struct {
+	// Repos are the recorded repository states, by full name.
+	Repos map[string]models.RepoState
+	// Matches are the commit matches of the scan each state was recorded
+	// with, by repository full name. They are kept for the repositories
+	// scanned incrementally.
+	Matches map[string][]models.PIIMatch
+}
+
+// fingerprint identifies the settings that decide which commits match, so
+// states recorded with other settings are not reused. Ignore rules are left
+// out: they are applied to the matches carried over instead, since those of
+// repositories are only known once their ignore file is fetched.
+func fingerprint(criteria models.PIISearchCriteria, config Config) string {
+	botCommits := string(config.BotCommits)
+	if config.BotCommits == pii.BotCommitsOff {
+		botCommits = "" // as recorded before bot commits could be left out
+	}
+	postProcessors := config.PostProcessors.Settings()
+	if slices.Equal(postProcessors, pii.DefaultPostProcessors) {
+		postProcessors = nil // as recorded before post-processors were included
+	}
+	data, _ := json.Marshal(struct {
+		Criteria       models.PIISearchCriteria
+		Roles          []models.CommitRole
+		MinConfidence  float64
+		CommonWords    string
+		ContextSize    int
+		EmailConfig    bool
+		Gravatar       bool     `json:",omitempty"`
+		Signatures     bool     `json:",omitempty"`
+		SkipFields     []string `json:",omitempty"`
+		PagesBranch    bool     `json:",omitempty"`
+		BotCommits     string   `json:",omitempty"`
+		PostProcessors []string `json:",omitempty"`
+	}{criteria, config.CommitRoles, config.MinConfidence, string(config.CommonWords), config.ContextSize, config.CheckEmailConfig, config.CheckGravatar, config.CheckSignatures, config.Fields.Disabled(), config.ScanPages, botCommits, postProcessors})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// previousState returns the state recorded for repo with the current
+// settings, if any.
+func (s *Scanner) previousState(repo string) (models.RepoState, bool) {
+	if s.config.Incremental == nil {
+		return models.RepoState{}, false
+	}
+	st, ok := s.config.Incremental.Repos[repo]
+	if !ok || st.Head == "" || st.Fingerprint != s.fingerprint {
+		return models.RepoState{}, false
+	}
+	return st, true
+}
+
+// carryMatches adds the previous commit matches of the repositories that will
+// be scanned incrementally to result, and marks their commits as seen.
+// Matches accepted by the current ignore rules, including those of the
+// repository's ignore file, are dropped.
+func (s *Scanner) carryMatches(ctx context.Context, repos []*models.Repository, username string, result *models.ScanResult, seen *shaSet) {
+	if s.config.Incremental == nil {
+		return
+	}
+	for _, repo := range repos {
+		if _, ok := s.previousState(repo.FullName); !ok {
+			continue
+		}
+		matches := s.config.Incremental.Matches[repo.FullName]
+		if len(matches) == 0 {
+			continue
+		}
+		// A warning about the ignore file is reported when the repository is fetched
+		rules, _ := s.repoIgnoreRules(ctx, repo, username)
+		// Pages site, refs, events and email search matches are found again
+		// by their own steps
+		for _, match := range matches {
+			carried := match.Source == models.SourceCommit || (match.Source == models.SourcePagesBranch && s.config.ScanPages)
+			if !carried || !seen.add(match.Commit.SHA) {
+				continue
+			}
+			var ignored int
+			if match, ignored = ignoreLocations(rules, match); ignored > 0 {
+				result.Suppressed += ignored
+				if len(match.Locations) == 0 {
+					continue
+				}
+			}
+			s.record(result, match)
+			result.CarriedMatches++
+			s.matches.Add(1)
+			s.emit(Event{Type: EventMatchFound, Repository: repo.FullName, Match: &match})
+		}
+	}
+	if result.CarriedMatches > 0 {
+		s.log("Kept %d matches from the previous scan", result.CarriedMatches)
+	}
+}
+
+// checkHead compares the head of repo with its recorded state. It returns the
+// state to record once the repository is scanned, whether it is unchanged,
+// and the date from which commits must be fetched.
+func (s *Scanner) checkHead(ctx context.Context, repo *models.Repository) (state *models.RepoState, unchanged bool, since time.Time, err error) {
+	hr, ok := s.client.(provider.HeadReader)
+	if !ok || s.config.Incremental == nil {
+		return nil, false, time.Time{}, nil
+	}
+
+	prev, hasPrev := s.previousState(repo.FullName)
+	head, err := hr.Head(ctx, repo.Owner, repo.Name, prev.ETag)
+	switch {
+	case errors.Is(err, provider.ErrNotModified):
+		return &prev, true, time.Time{}, nil
+	case err != nil:
+		return nil, false, time.Time{}, err
+	case head.Head == "":
+		return nil, false, time.Time{}, nil
+	}
+
+	head.Fingerprint = s.fingerprint
+	if !hasPrev {
+		return &head, false, time.Time{}, nil
+	}
+	if head.Head == prev.Head {
+		return &head, true, time.Time{}, nil
+	}
+	return &head, false, prev.Date, nil
+}
+
+// RepoStates returns the state of every repository scanned completely by an
+// incremental scan, by full name, to be recorded for the next one.
+func (s *Scanner) RepoStates() map[string]models.RepoState {
+	return s.repoStates
+}
@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+)
+
+// ProviderScan is the scan of one provider in a multi-provider scan.
+type ProviderScan struct {
+	// Provider names the provider in the merged result, e.g. "github".
+	Provider string
+	Scanner  *Scanner
+	// Username is the user's name on the provider.
+	Username string
+}
+
+// ScanProviders scans the same identity on several providers concurrently
+// and merges the results, in the order of scans (see report.Merge). A
+// provider whose scan fails is recorded as an error of the merged result;
+// ScanProviders fails only when every scan does.
+func ScanProviders(ctx context.Context, scans []ProviderScan) (*models.ScanResult, error) {
+	startTime := time.Now()
+	providers := make([]string, len(scans))
+	results := make([]*models.ScanResult, len(scans))
+	errs := make([]error, len(scans))
+
+	var wg sync.WaitGroup
+	for i, ps := range scans {
+		providers[i] = ps.Provider
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = ps.Scanner.ScanUser(ctx, ps.Username)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == len(scans) {
+		return nil, fmt.Errorf("%s: %w", providers[0], errs[0])
+	}
+
+	merged := report.Merge(providers, results)
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		e := scanError("", fmt.Errorf("%s scan failed: %w", providers[i], err))
+		e.Severity = "error"
+		e.Provider = providers[i]
+		merged.Errors = append(merged.Errors, e)
+	}
+	merged.Summary = report.Summarize(merged)
+	merged.PrivacyScore = report.Score(merged)
+	merged.ScanDuration = time.Since(startTime).String()
+	return merged, nil
+}
@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/pages"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+)
+
+// streamPagesCommits streams the commits selected by filter on the
+// repository's gh-pages branch to fn, skipping commits already seen on the
+// default branch.
+func (s *Scanner) streamPagesCommits(ctx context.Context, repo *models.Repository, filter models.CommitFilter, known map[string]bool, fn func([]*models.Commit) error) error {
+	return s.streamBranch(ctx, repo, pagesBranch, filter, func(commits []*models.Commit) error {
+		var unique []*models.Commit
+		for _, c := range commits {
+			if !known[c.SHA] {
+				unique = append(unique, c)
+			}
+		}
+		return fn(unique)
+	})
+}
+
+// scanPagesSite crawls the user's published Pages site and records matches
+// found in page metadata and visible text.
+func (s *Scanner) scanPagesSite(ctx context.Context, username string, result *models.ScanResult) {
+	siteURL := s.config.PagesURL
+	if siteURL == "" {
+		siteURL = pages.SiteURL(username)
+	}
+	host := siteURL
+	if u, err := url.Parse(siteURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	s.log("Crawling Pages site %s", siteURL)
+	crawler := pages.NewCrawler(pages.CrawlerConfig{MaxPages: s.config.MaxPages})
+	var sitePages []*pages.Page
+	err := s.retry(ctx, siteURL, func() (err error) {
+		sitePages, err = crawler.Crawl(ctx, siteURL)
+		return err
+	})
+	if err != nil {
+		s.emit(Event{Type: EventError, Repository: host, Err: err})
+		result.Errors = append(result.Errors, scanError(host, err))
+		return
+	}
+	s.log("Scanning %d pages from %s", len(sitePages), host)
+
+	for _, page := range sitePages {
+		doc := document{
+			Commit: &models.Commit{
+				Repository: host,
+				Message:    page.Title,
+				URL:        page.URL,
+			},
+			Texts: []pii.Text{
+				{Text: page.Title, Field: "page_title"},
+				{Text: page.Meta, Field: "page_meta"},
+				{Text: page.Text, Field: "page_content"},
+			},
+		}
+		s.scanDocument(doc, models.SourcePagesSite, result)
+	}
+}
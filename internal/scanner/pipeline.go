@@ -0,0 +1,445 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/ignore"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/metrics"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The scan runs as a three-stage pipeline: fetch workers page through each
+// repository's commits, detection workers scan every page as it arrives, and a
+// single collector assembles the result. Channels between the stages are
+// bounded, so at most a few pages per worker are held in memory and fetching
+// overlaps with detection.
+
+// commitBatch is one page of commits passed from the fetch to the detection stage.
+type commitBatch struct {
+	Repo    *models.Repository
+	Source  models.Source
+	Ignore  *ignore.Rules
+	Commits []*models.Commit
+	// Docs is text found outside of commits, such as release notes.
+	Docs []document
+
+	// Span is the fetch span the batch came from, used to parent its
+	// detection span.
+	Span trace.SpanContext
+}
+
+// document is text scanned outside of commits, such as release notes or a
+// web page, reported as a match of Commit.
+type document struct {
+	Commit *models.Commit
+	Texts  []pii.Text
+}
+
+// detectedBatch is the outcome of scanning a commitBatch.
+type detectedBatch struct {
+	Repo          *models.Repository
+	Source        models.Source
+	Commits       int // commits scanned
+	Docs          int // documents scanned
+	Duplicates    int // commits skipped because they were already scanned
+	Bots          int // bot-generated and templated commits skipped
+	Suppressed    int // matches dropped by the repository's ignore file
+	LowConfidence int // commits and documents with matches below the confidence threshold
+	Matches       []models.PIIMatch
+}
+
+// repoCommits is the outcome of fetching a repository: the number of batches
+// sent to the detection stage and the error that stopped fetching, if any.
+type repoCommits struct {
+	Repo    *models.Repository
+	Batches int
+	Err     error
+
+	// State is the repository state to record, in incremental scans.
+	State *models.RepoState
+	// Unchanged is set when an incremental scan skipped the default branch.
+	Unchanged bool
+	// Associations are the CODEOWNERS and .mailmap entries naming the user.
+	Associations []models.Association
+	// Warnings are the errors that did not stop fetching, such as an invalid
+	// ignore file.
+	Warnings []models.ScanError
+}
+
+// errRepoTimeout is the cause of the cancellation of a repository fetch that
+// exceeded Config.RepoTimeout.
+var errRepoTimeout = errors.New("repository timeout")
+
+// repoRetryDelay is the pause before fetching a repository again after an
+// error, multiplied by the number of consecutive errors.
+var repoRetryDelay = 2 * time.Second
+
+// shaSet records commit SHAs seen during a scan. It is safe for concurrent use.
+type shaSet struct {
+	mu   sync.Mutex
+	shas map[string]bool
+}
+
+func newSHASet() *shaSet {
+	return &shaSet{shas: make(map[string]bool)}
+}
+
+// add records sha and reports whether it was not seen before.
+func (s *shaSet) add(sha string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shas[sha] {
+		return false
+	}
+	s.shas[sha] = true
+	return true
+}
+
+// commitFilter selects the commits of username to scan, identifying them as
+// a co-author by the emails of the search criteria and identities.
+func (s *Scanner) commitFilter(username string) models.CommitFilter {
+	emails := slices.Clone(s.criteria.Emails)
+	for _, id := range s.criteria.Identities {
+		emails = append(emails, id.Emails...)
+	}
+	return models.NewCommitFilter(username, emails, s.config.CommitRoles)
+}
+
+// fetchRepo streams a repository's commits to batches, one page at a time.
+func (s *Scanner) fetchRepo(ctx context.Context, repo *models.Repository, username string, batches chan<- commitBatch) (rc *repoCommits) {
+	ctx, span := tracer.Start(ctx, "scanner.fetch_repo", trace.WithAttributes(attribute.String("github.repository", repo.FullName)))
+	defer func() {
+		span.SetAttributes(attribute.Int("scanner.batches", rc.Batches))
+		tracing.EndSpan(span, rc.Err)
+	}()
+
+	s.emit(Event{Type: EventRepoStarted, Repository: repo.FullName})
+	rc = &repoCommits{Repo: repo}
+
+	// Batches already sent are still scanned when the repository times out.
+	// The timeout cancels rather than sets a deadline, which rate limiters
+	// would refuse to wait past.
+	if s.config.RepoTimeout > 0 {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		timer := time.AfterFunc(s.config.RepoTimeout, func() { cancel(errRepoTimeout) })
+		defer func() {
+			timer.Stop()
+			cancel(nil)
+		}()
+		defer func() {
+			if rc.Err != nil && errors.Is(context.Cause(ctx), errRepoTimeout) {
+				rc.Err = fmt.Errorf("skipped after exceeding the %s repository timeout", s.config.RepoTimeout)
+			}
+		}()
+	}
+
+	rules, warning := s.repoIgnoreRules(ctx, repo, username)
+	if warning != nil {
+		rc.Warnings = append(rc.Warnings, *warning)
+	}
+
+	send := func(b commitBatch) error {
+		if len(b.Commits) == 0 && len(b.Docs) == 0 {
+			return nil
+		}
+		b.Repo, b.Ignore, b.Span = repo, rules, span.SpanContext()
+		select {
+		case batches <- b:
+			rc.Batches++
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// Default branch SHAs, to skip the same commits on gh-pages
+	var known map[string]bool
+	if s.config.ScanPages {
+		known = make(map[string]bool)
+	}
+
+	filter := s.commitFilter(username)
+	rc.State, rc.Unchanged, filter.Since, rc.Err = s.checkHead(ctx, repo)
+	if rc.Err != nil {
+		return rc
+	}
+	if !rc.Unchanged {
+		rc.Err = s.fetchWithRetry(ctx, repo, func(fn func([]*models.Commit) error) error {
+			return s.streamBranch(ctx, repo, "", filter, fn)
+		}, func(commits []*models.Commit) error {
+			if known != nil {
+				for _, c := range commits {
+					known[c.SHA] = true
+				}
+			}
+			return send(commitBatch{Source: models.SourceCommit, Commits: commits})
+		})
+	}
+	// Only repositories publishing a site have a gh-pages branch worth the
+	// request. Incremental scans fetch its commits since the recorded state.
+	if rc.Err == nil && s.config.ScanPages && repo.HasPages {
+		pagesFilter := s.commitFilter(username)
+		if prev, ok := s.previousState(repo.FullName); ok {
+			pagesFilter.Since = prev.Date
+		}
+		rc.Err = s.fetchWithRetry(ctx, repo, func(fn func([]*models.Commit) error) error {
+			return s.streamPagesCommits(ctx, repo, pagesFilter, known, fn)
+		}, func(commits []*models.Commit) error {
+			return send(commitBatch{Source: models.SourcePagesBranch, Commits: commits})
+		})
+	}
+	if rc.Err == nil && s.config.ScanRefs {
+		var docs []document
+		if docs, rc.Err = s.refDocuments(ctx, repo); rc.Err == nil {
+			rc.Err = send(commitBatch{Source: models.SourceRefs, Docs: docs})
+		}
+	}
+	if rc.Err == nil && s.config.ScanFiles {
+		var docs []document
+		if docs, rc.Err = s.fileDocuments(ctx, repo, rules); rc.Err == nil {
+			rc.Err = send(commitBatch{Source: models.SourceFiles, Docs: docs})
+		}
+	}
+	if rc.Err == nil && s.config.Associations {
+		rc.Associations, rc.Err = s.fileAssociations(ctx, repo, username, rules)
+	}
+	return rc
+}
+
+// streamBranch streams the commits selected by filter on a branch of repo
+// (the default branch when empty) to fn, sampled or limited as configured.
+func (s *Scanner) streamBranch(ctx context.Context, repo *models.Repository, branch string, filter models.CommitFilter, fn func([]*models.Commit) error) error {
+	if s.config.Sample > 0 {
+		if sampler, ok := s.client.(provider.CommitSampler); ok {
+			return sampler.SampleBranchCommits(ctx, repo.Owner, repo.Name, branch, filter, s.config.Sample, fn)
+		}
+	}
+	return s.client.StreamBranchCommits(ctx, repo.Owner, repo.Name, branch, filter, s.commitLimit(), fn)
+}
+
+// commitLimit returns the number of commits scanned per repository and
+// branch, 0 for all.
+func (s *Scanner) commitLimit() int {
+	if s.config.Sample > 0 {
+		return s.config.Sample
+	}
+	return s.config.MaxCommitsPerRepo
+}
+
+// fetchWithRetry runs fetch, which streams commits to fn, again after
+// retryable errors (see provider.Classify) until Config.MaxRepoErrors
+// consecutive attempts have failed. An attempt that delivers new commits
+// resets the count, and commits delivered by earlier attempts are not
+// delivered again.
+func (s *Scanner) fetchWithRetry(ctx context.Context, repo *models.Repository, fetch func(fn func([]*models.Commit) error) error, fn func([]*models.Commit) error) error {
+	if s.config.MaxRepoErrors <= 1 {
+		return fetch(fn)
+	}
+
+	delivered := make(map[string]bool)
+	failures := 0
+	for {
+		progressed := false
+		err := fetch(func(commits []*models.Commit) error {
+			fresh := make([]*models.Commit, 0, len(commits))
+			for _, c := range commits {
+				if !delivered[c.SHA] {
+					delivered[c.SHA] = true
+					fresh = append(fresh, c)
+				}
+			}
+			progressed = progressed || len(fresh) > 0
+			return fn(fresh)
+		})
+		if err == nil || ctx.Err() != nil || !provider.Classify(err).Retryable() {
+			return err
+		}
+
+		if progressed {
+			failures = 0
+		}
+		failures++
+		if failures >= s.config.MaxRepoErrors {
+			return fmt.Errorf("skipped after %d consecutive errors: %w", failures, err)
+		}
+		s.log("Retrying %s after error: %v", repo.FullName, err)
+		select {
+		case <-time.After(repoRetryDelay * time.Duration(failures)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// detectChunk is the most commits of a repository scanned as one batch when
+// found outside the pipeline, by search or in events: the page size of
+// commit listings.
+const detectChunk = 100
+
+// scanGroupedCommits scans commits found outside the pipeline, grouped by
+// repository in the order given, skipping commits already scanned. Batches
+// are scanned on the detection workers and collected in order. It returns the
+// number of commits scanned.
+func (s *Scanner) scanGroupedCommits(repos []*models.Repository, byRepo map[string][]*models.Commit, source models.Source, span trace.SpanContext, result *models.ScanResult, seen *shaSet) int {
+	var batches []commitBatch
+	for _, repo := range repos {
+		for commits := range slices.Chunk(byRepo[repo.FullName], detectChunk) {
+			batches = append(batches, commitBatch{Repo: repo, Source: source, Ignore: s.config.Ignore, Commits: commits, Span: span})
+		}
+	}
+
+	detected := make([]detectedBatch, len(batches))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(s.config.DetectionWorkers, len(batches)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				detected[i] = s.detectBatch(batches[i], seen)
+			}
+		}()
+	}
+	for i := range batches {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	total := 0
+	for _, db := range detected {
+		result.BotCommits += db.Bots
+		if db.Commits == 0 {
+			continue
+		}
+		total += db.Commits
+		result.Suppressed += db.Suppressed
+		result.LowConfidence += db.LowConfidence
+		s.commits.Add(int64(db.Commits))
+		s.matches.Add(int64(len(db.Matches)))
+		metrics.ObserveCommits(db.Commits, len(db.Matches))
+		for i := range db.Matches {
+			s.emit(Event{Type: EventMatchFound, Repository: db.Repo.FullName, Match: &db.Matches[i]})
+		}
+		s.record(result, db.Matches...)
+		s.emit(Event{Type: EventCommitsProcessed, Repository: db.Repo.FullName, Commits: db.Commits, Matches: len(db.Matches)})
+	}
+	return total
+}
+
+// detectBatch scans a page of commits for PII, skipping commits already seen
+// in another repository, typically a fork.
+func (s *Scanner) detectBatch(b commitBatch, seen *shaSet) detectedBatch {
+	_, span := tracer.Start(trace.ContextWithSpanContext(context.Background(), b.Span), "scanner.detect_batch",
+		trace.WithAttributes(attribute.String("github.repository", b.Repo.FullName)))
+	defer span.End()
+
+	db := detectedBatch{Repo: b.Repo, Source: b.Source}
+	for _, commit := range b.Commits {
+		if !seen.add(commit.SHA) {
+			db.Duplicates++
+			continue
+		}
+		bot := s.config.BotCommits != pii.BotCommitsOff && pii.IsBotCommit(commit)
+		if bot && s.config.BotCommits == pii.BotCommitsSkip {
+			db.Bots++
+			continue
+		}
+		db.Commits++
+		if s.config.CheckSignatures {
+			s.signers.add(commit)
+		}
+
+		matches, common := pii.ApplyCommonWordMode(s.config.CommonWords, s.detector.DetectInCommit(commit))
+		if s.config.CheckEmailConfig {
+			matches = append(matches, pii.DetectEmailConfig(commit, s.config.Fields)...)
+		}
+		if s.config.CheckGravatar {
+			matches = append(matches, s.detector.DetectGravatar(commit)...)
+		}
+		if bot {
+			pii.MarkBotCommit(matches)
+		}
+		matches = s.config.PostProcessors.Process(matches)
+		matches, suppressed := s.applyIgnoreRules(b.Ignore, matches)
+		db.Suppressed += common + suppressed
+		if len(matches) > 0 {
+			piiMatch := s.buildPIIMatch(commit, matches)
+			if piiMatch.Confidence < s.config.MinConfidence {
+				db.LowConfidence++
+				continue
+			}
+			piiMatch.Source = b.Source
+			db.Matches = append(db.Matches, piiMatch)
+		}
+	}
+	for _, doc := range b.Docs {
+		db.Docs++
+		match, suppressed, low := s.detectDocument(doc, b.Ignore)
+		db.Suppressed += suppressed
+		if low {
+			db.LowConfidence++
+		}
+		if match != nil {
+			match.Source = b.Source
+			db.Matches = append(db.Matches, *match)
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("scanner.commits", db.Commits),
+		attribute.Int("scanner.duplicates", db.Duplicates),
+		attribute.Int("scanner.bot_commits", db.Bots),
+		attribute.Int("scanner.matches", len(db.Matches)),
+	)
+	return db
+}
+
+// detectDocument scans a document for PII. It returns the match of the
+// document, if any, the number of matches suppressed and whether the match
+// was dropped for its low confidence.
+func (s *Scanner) detectDocument(doc document, rules *ignore.Rules) (match *models.PIIMatch, suppressed int, lowConfidence bool) {
+	matches, common := pii.ApplyCommonWordMode(s.config.CommonWords, s.detector.DetectInTexts(doc.Texts))
+	if s.config.CheckGravatar {
+		matches = append(matches, s.detector.DetectGravatarInTexts(doc.Texts)...)
+	}
+	matches = s.config.PostProcessors.Process(matches)
+	matches, ignored := s.applyIgnoreRules(rules, matches)
+	suppressed = common + ignored
+	if len(matches) == 0 {
+		return nil, suppressed, false
+	}
+
+	piiMatch := s.buildPIIMatch(doc.Commit, matches)
+	if piiMatch.Confidence < s.config.MinConfidence {
+		return nil, suppressed, true
+	}
+	return &piiMatch, suppressed, false
+}
+
+// scanDocument scans a document found outside of the repositories, such as
+// a web page, recording its match from source in result.
+func (s *Scanner) scanDocument(doc document, source models.Source, result *models.ScanResult) {
+	match, suppressed, low := s.detectDocument(doc, s.config.Ignore)
+	result.Suppressed += suppressed
+	if low {
+		result.LowConfidence++
+	}
+	if match == nil {
+		return
+	}
+	match.Source = source
+	s.matches.Add(1)
+	s.emit(Event{Type: EventMatchFound, Repository: doc.Commit.Repository, Match: match})
+	s.record(result, *match)
+}
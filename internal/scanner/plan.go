@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+)
+
+// Plan is what a scan would fetch, estimated from repository statistics
+// without listing any commit.
+type Plan struct {
+	Username     string        `json:"username"`
+	Repos        []PlannedRepo `json:"repos"`
+	SkippedForks int           `json:"skipped_forks,omitempty"`
+	IgnoredRepos int           `json:"ignored_repos,omitempty"`
+	// Commits is the estimated number of commits scanned in the repositories
+	// whose statistics are available.
+	Commits int `json:"commits"`
+	// Requests is the estimated number of API requests made by the scan.
+	// Repositories without statistics are counted as a single page.
+	Requests int `json:"requests"`
+	// UnknownRepos is the number of repositories without statistics.
+	UnknownRepos int                `json:"unknown_repos,omitempty"`
+	Errors       []models.ScanError `json:"errors,omitempty"`
+}
+
+// PlannedRepo is the estimated cost of scanning one repository.
+type PlannedRepo struct {
+	Repository string `json:"repository"`
+	Commits    int    `json:"commits"`
+	Requests   int    `json:"requests"`
+	// Unknown is set when the repository statistics were not available.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Plan lists the repositories a scan of username would fetch and estimates
+// its commits and API requests, without scanning. Counting commits costs one
+// request per repository with providers that support it (see
+// provider.CommitCounter); with others, every repository is unknown.
+func (s *Scanner) Plan(ctx context.Context, username string) (*Plan, error) {
+	if _, err := s.client.GetUser(ctx, username); err != nil {
+		return nil, err
+	}
+	var result models.ScanResult
+	repos, err := s.selectRepos(ctx, username, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		Username:     username,
+		Repos:        make([]PlannedRepo, 0, len(repos)),
+		SkippedForks: result.SkippedForks,
+		IgnoredRepos: result.IgnoredRepos,
+		Errors:       result.Errors,
+	}
+	// The profile, the repository listing, the email searches and the events
+	plan.Requests = 1 + pageCount(len(repos), 100)
+	if s.config.EmailDiscovery {
+		plan.Requests += len(s.criteria.Emails)
+	}
+	if s.config.ScanEvents {
+		plan.Requests += 3
+	}
+	// The releases, tags and branches of each repository, without the
+	// annotated tags
+	if s.config.ScanRefs {
+		plan.Requests += 3 * len(repos)
+	}
+	// The root of each repository and a few of the files found
+	if s.config.ScanFiles {
+		plan.Requests += 3 * len(repos)
+	}
+	// The organizations and their profiles, and the root of each repository
+	// and the CODEOWNERS and .mailmap files found
+	if s.config.Associations {
+		plan.Requests += 2 + 3*len(repos)
+	}
+	// One page of results per query
+	switch s.config.CodeSearch {
+	case CodeSearchRepos:
+		plan.Requests += len(scopedCodeQueries(s.codeTerms(), username, repos))
+	case CodeSearchGlobal:
+		plan.Requests += len(s.codeTerms())
+	}
+
+	counter, _ := s.client.(provider.CommitCounter)
+	for _, repo := range repos {
+		pr := PlannedRepo{Repository: repo.FullName, Unknown: true}
+		var user, total int
+		if counter != nil {
+			var ok bool
+			user, total, ok, err = counter.CountCommits(ctx, repo.Owner, repo.Name, username)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, err
+				}
+				plan.Errors = append(plan.Errors, scanError(repo.FullName, err))
+			}
+			pr.Unknown = !ok
+		}
+		pr.Commits, pr.Requests = s.estimateRepo(repo, username, user, total)
+
+		plan.Repos = append(plan.Repos, pr)
+		plan.Commits += pr.Commits
+		plan.Requests += pr.Requests
+		if pr.Unknown {
+			plan.UnknownRepos++
+		}
+	}
+	return plan, nil
+}
+
+// estimateRepo estimates the commits scanned and the requests made in a
+// repository where username authored user of its total commits, mirroring
+// fetchRepo.
+func (s *Scanner) estimateRepo(repo *models.Repository, username string, user, total int) (commits, requests int) {
+	limit := s.commitLimit()
+	capped := func(n int) int {
+		if limit > 0 && n > limit {
+			return limit
+		}
+		return n
+	}
+	perPage := 100
+	if limit > 0 && limit < perPage {
+		perPage = limit
+	}
+	_, sampled := s.client.(provider.CommitSampler)
+	sampled = sampled && s.config.Sample > 0
+
+	filter := s.commitFilter(username)
+	commits = capped(user)
+	branches := 1
+	if s.config.ScanPages && repo.HasPages {
+		branches++
+	}
+	for range branches {
+		switch {
+		case sampled:
+			// A first page, then the picked runs of 10 commits
+			sample := 1 + pageCount(commits, 10)
+			if filter.CoAuthor || filter.Author {
+				requests += sample
+			}
+			if filter.Committer && !filter.CoAuthor {
+				requests += sample
+			}
+		case filter.CoAuthor:
+			// Every commit is listed to read its trailers
+			requests += pageCount(total, 100)
+		default:
+			// Committer listings are assumed as long as author ones
+			if filter.Author {
+				requests += pageCount(commits, perPage)
+			}
+			if filter.Committer {
+				requests += pageCount(commits, perPage)
+			}
+		}
+	}
+
+	if s.config.RespectIgnoreFiles && strings.EqualFold(repo.Owner, username) {
+		requests++
+	}
+	if s.config.Incremental != nil {
+		requests++
+	}
+	return commits, requests
+}
+
+// pageCount returns the number of pages listing n items takes, at least one.
+func pageCount(n, perPage int) int {
+	return max(1, (n+perPage-1)/perPage)
+}
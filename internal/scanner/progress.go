@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// EventType identifies a progress event.
+type EventType string
+
+const (
+	// EventScanStarted is emitted once when a scan begins.
+	EventScanStarted EventType = "scan_started"
+	// EventReposDiscovered carries the number of repositories to scan in Repos.
+	EventReposDiscovered EventType = "repos_discovered"
+	// EventRepoStarted is emitted when a worker starts fetching a repository.
+	EventRepoStarted EventType = "repo_started"
+	// EventRepoFinished is emitted when a repository's commits have been scanned.
+	EventRepoFinished EventType = "repo_finished"
+	// EventCommitsProcessed carries the number of commits scanned in a batch.
+	EventCommitsProcessed EventType = "commits_processed"
+	// EventMatchFound carries a detected match in Match, as soon as it is found.
+	EventMatchFound EventType = "match_found"
+	// EventError is emitted for non-fatal errors; the scan continues.
+	EventError EventType = "error"
+	// EventInfo carries a human-readable status message.
+	EventInfo EventType = "info"
+	// EventScanFinished is emitted once when a scan completes.
+	EventScanFinished EventType = "scan_finished"
+)
+
+// Event describes a step of scan progress. Only the fields relevant to the
+// event type are set.
+type Event struct {
+	Type       EventType
+	Time       time.Time
+	Repository string
+	Repos      int // repositories discovered
+	Commits    int // commits processed in this event
+	Matches    int // matches found in this event
+	Err        error
+	Message    string
+	Match      *models.PIIMatch
+}
+
+// ProgressReporter receives progress events. Report may be called from
+// multiple goroutines and must not block for long.
+type ProgressReporter interface {
+	Report(Event)
+}
+
+// ProgressFunc adapts a function into a ProgressReporter.
+type ProgressFunc func(Event)
+
+// Report calls f.
+func (f ProgressFunc) Report(e Event) { f(e) }
+
+// MultiReporter fans events out to several reporters.
+type MultiReporter []ProgressReporter
+
+// Report forwards the event to every reporter.
+func (m MultiReporter) Report(e Event) {
+	for _, r := range m {
+		r.Report(e)
+	}
+}
+
+// LogReporter writes events to a structured logger. Repository names, commit
+// and match counts and errors are logged as attributes.
+type LogReporter struct {
+	Logger *slog.Logger
+}
+
+// Report logs the event.
+func (r LogReporter) Report(e Event) {
+	switch e.Type {
+	case EventInfo:
+		if e.Repository != "" {
+			r.Logger.Info(e.Message, "repo", e.Repository)
+		} else {
+			r.Logger.Info(e.Message)
+		}
+	case EventReposDiscovered:
+		r.Logger.Info("Found public repositories", "repos", e.Repos)
+	case EventRepoStarted:
+		r.Logger.Debug("Fetching commits", "repo", e.Repository)
+	case EventCommitsProcessed:
+		r.Logger.Debug("Scanned commit batch", "repo", e.Repository, "commits", e.Commits, "matches", e.Matches)
+	case EventRepoFinished:
+		r.Logger.Info("Scanned repository", "repo", e.Repository, "commits", e.Commits, "matches", e.Matches)
+	case EventError:
+		if e.Repository != "" {
+			r.Logger.Warn("Scan error", "repo", e.Repository, "error", e.Err)
+		} else {
+			r.Logger.Warn("Scan error", "error", e.Err)
+		}
+	case EventScanFinished:
+		r.Logger.Info(e.Message, "commits", e.Commits, "matches", e.Matches)
+	}
+}
+
+// emit sends an event to the configured reporter.
+func (s *Scanner) emit(e Event) {
+	if s.config.Progress == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	s.config.Progress.Report(e)
+}
+
+// log emits an informational message.
+func (s *Scanner) log(format string, args ...interface{}) {
+	if s.config.Progress == nil {
+		return
+	}
+	s.emit(Event{Type: EventInfo, Message: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxPullRequestCommits caps the commits whose pull requests are looked up,
+// one request each.
+const maxPullRequestCommits = 500
+
+// scanPullRequests links the matches found in commit messages to the pull
+// requests including their commit, where the conversation around them
+// happened, and scans the title and description of those pull requests,
+// recording each that leaks as a finding of its own.
+func (s *Scanner) scanPullRequests(ctx context.Context, result *models.ScanResult) {
+	lister, ok := s.client.(provider.PullRequestLister)
+	if !ok {
+		err := fmt.Errorf("pull request lookup is not supported by the %s provider", s.client.Name())
+		s.emit(Event{Type: EventError, Err: err})
+		result.Errors = append(result.Errors, scanError("", err))
+		return
+	}
+
+	// Commits with matches in their message, in the order found
+	var commits []models.Commit
+	wanted := make(map[string]bool)
+	for match := range result.AllMatches() {
+		key := match.Commit.Repository + "@" + match.Commit.SHA
+		if wanted[key] || !inMessage(match) {
+			continue
+		}
+		wanted[key] = true
+		commits = append(commits, match.Commit)
+	}
+	if len(commits) == 0 {
+		return
+	}
+	if len(commits) > maxPullRequestCommits {
+		s.log("Looking up the pull requests of the first %d of %d commits with matches in their message", maxPullRequestCommits, len(commits))
+		commits = commits[:maxPullRequestCommits]
+	}
+
+	ctx, span := tracer.Start(ctx, "scanner.pull_requests")
+
+	found := make(map[string][]models.PullRequest)
+	var leaking []models.PIIMatch
+	leaks := make(map[string]bool)
+	for _, commit := range commits {
+		if ctx.Err() != nil {
+			break
+		}
+		owner, name, _ := strings.Cut(commit.Repository, "/")
+		var prs []*models.PullRequest
+		err := s.retry(ctx, "the pull requests of "+commit.SHA, func() (err error) {
+			prs, err = lister.ListCommitPullRequests(ctx, owner, name, commit.SHA)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				s.emit(Event{Type: EventError, Err: err})
+				result.Errors = append(result.Errors, scanError(commit.Repository, err))
+			}
+			continue
+		}
+
+		key := commit.Repository + "@" + commit.SHA
+		found[key] = []models.PullRequest{}
+		for _, pr := range prs {
+			leaked, ok := leaks[pr.URL]
+			if !ok {
+				doc := document{
+					Commit: &models.Commit{
+						Repository: commit.Repository,
+						Message:    pr.Title,
+						URL:        pr.URL,
+					},
+					Texts: []pii.Text{
+						{Text: pr.Title, Field: "pr_title"},
+						{Text: pr.Body, Field: "pr_body"},
+					},
+				}
+				match, suppressed, low := s.detectDocument(doc, s.config.Ignore)
+				result.Suppressed += suppressed
+				if low {
+					result.LowConfidence++
+				}
+				leaked = match != nil
+				leaks[pr.URL] = leaked
+				result.PullRequests++
+				if leaked {
+					match.Source = models.SourcePullRequest
+					leaking = append(leaking, *match)
+				}
+			}
+			link := *pr
+			link.Body = ""
+			link.Leaks = leaked
+			found[key] = append(found[key], link)
+		}
+	}
+
+	result.RewriteMatches(func(match models.PIIMatch) (models.PIIMatch, bool) {
+		if prs, ok := found[match.Commit.Repository+"@"+match.Commit.SHA]; ok && inMessage(match) {
+			match.PullRequests = slices.Clone(prs)
+		}
+		return match, true
+	})
+	// Recorded after the rewrite, which must not see them
+	for i := range leaking {
+		s.matches.Add(1)
+		s.emit(Event{Type: EventMatchFound, Repository: leaking[i].Commit.Repository, Match: &leaking[i]})
+	}
+	s.record(result, leaking...)
+
+	span.SetAttributes(attribute.Int("scanner.pull_requests", result.PullRequests))
+	tracing.EndSpan(span, nil)
+}
+
+// inMessage reports whether match was found in the message of a commit.
+func inMessage(match models.PIIMatch) bool {
+	if match.Commit.SHA == "" || match.Commit.Repository == "" || match.PullRequests != nil {
+		return false
+	}
+	for _, loc := range match.Locations {
+		if loc.Field == "message" {
+			return true
+		}
+	}
+	return false
+}
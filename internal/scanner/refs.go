@@ -0,0 +1,44 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+)
+
+// refDocuments returns the releases, annotated tags and branches of repo as
+// documents: release names and notes, tag names, messages and taggers, and
+// branch names. Providers that cannot list them yield none.
+func (s *Scanner) refDocuments(ctx context.Context, repo *models.Repository) ([]document, error) {
+	lister, ok := s.client.(provider.RefLister)
+	if !ok {
+		return nil, nil
+	}
+	refs, err := lister.ListRefs(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]document, 0, len(refs))
+	for _, ref := range refs {
+		doc := document{Commit: &models.Commit{
+			Repository: repo.FullName,
+			Message:    ref.Name,
+			URL:        ref.URL,
+			Date:       ref.Date,
+			Author:     ref.Tagger,
+		}}
+		switch ref.Kind {
+		case models.RefRelease:
+			doc.Texts = []pii.Text{{Text: ref.Name, Field: "release_name"}, {Text: ref.Body, Field: "release_body"}}
+		case models.RefTag:
+			doc.Texts = []pii.Text{{Text: ref.Name, Field: "tag_name"}, {Text: ref.Body, Field: "tag_message"}, {Text: ref.Tagger.Name, Field: "tagger_name"}}
+		case models.RefBranch:
+			doc.Texts = []pii.Text{{Text: ref.Name, Field: "branch_name"}}
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
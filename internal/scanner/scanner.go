@@ -3,65 +3,319 @@ package scanner
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/h4n0sh1/GoGitSomePrivacy/internal/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/archive"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/ignore"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/metrics"
 	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/spool"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
 	"github.com/h4n0sh1/GoGitSomePrivacy/internal/worker"
 	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = tracing.Tracer("github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner")
+
 // Config contains scanner configuration.
 type Config struct {
-	MaxWorkers     int
-	ContextSize    int
-	ProgressLogger *log.Logger
+	// MaxWorkers caps the repositories fetched concurrently (default 10).
+	// With providers that report their pace (see provider.Paced), fewer are
+	// fetched when the rate limit cannot keep them all busy.
+	MaxWorkers  int
+	ContextSize int
+
+	// MaxRepos limits how many repositories are scanned (0 means all).
+	MaxRepos int
+	// MaxCommitsPerRepo limits scanning to the latest commits of each repository (0 means all).
+	MaxCommitsPerRepo int
+	// Sample, if set, scans about this many commits of each repository
+	// picked across its history, for a quick estimate of the exposure (see
+	// provider.CommitSampler). With other providers the latest commits are
+	// scanned. It takes precedence over MaxCommitsPerRepo.
+	Sample int
+	// RepoTimeout caps the time spent fetching a repository (0 means no
+	// limit). A repository that takes longer is skipped.
+	RepoTimeout time.Duration
+	// MaxRepoErrors is the number of consecutive failed attempts at fetching
+	// a repository, or at another request, after which it is given up on
+	// (default 3). Only retryable errors (see provider.Classify) are
+	// retried.
+	MaxRepoErrors int
+	// SkipForks excludes forked repositories from the scan.
+	SkipForks bool
+	// Discovery selects how repositories to scan are found (default repos).
+	Discovery Discovery
+	// EmailDiscovery also searches for commits authored with the criteria
+	// emails anywhere on the provider, whichever account made them.
+	EmailDiscovery bool
+	// ScanEvents also scans the user's recent public events (see
+	// provider.EventLister): pushed commits and the text of comments,
+	// issues, pull requests, releases and created repositories.
+	ScanEvents bool
+	// Archive, if set, also scans the commits of the user's push events
+	// recorded in GH Archive from the hour ArchiveFrom to ArchiveTo, finding
+	// leaks since removed from GitHub.
+	Archive                *archive.Client
+	ArchiveFrom, ArchiveTo time.Time
+	// CommitRoles selects the commits scanned by the user's role on them
+	// (default author only). Co-authors are matched by username and by the
+	// emails of the search criteria.
+	CommitRoles []models.CommitRole
+	// MinConfidence drops commits whose matches score below this value.
+	MinConfidence float64
+	// CommonWords selects how single common words matched outside the author
+	// and committer names are treated (default downgrade).
+	CommonWords pii.CommonWordMode
+	// Fields toggles the commit fields scanned (default all, see
+	// pii.Fields).
+	Fields pii.Fields
+	// BotCommits selects how bot-generated and templated commits, such as
+	// dependency updates and merge commits, are treated (default off, see
+	// pii.IsBotCommit).
+	BotCommits pii.BotCommitMode
+
+	// DetectionWorkers is the number of goroutines scanning fetched commits
+	// (default GOMAXPROCS).
+	DetectionWorkers int
+
+	// Progress receives structured progress events. It may be nil.
+	Progress ProgressReporter
+
+	// ScanPages includes gh-pages branches and the published Pages site.
+	ScanPages bool
+	// PagesURL overrides the Pages site URL (default: https://<user>.github.io/).
+	PagesURL string
+	// MaxPages caps how many site pages are fetched.
+	MaxPages int
+
+	// ScanRefs also scans the releases, annotated tags and branch names of
+	// every repository (see provider.RefLister).
+	ScanRefs bool
+
+	// ScanFiles also scans the package manifests, author lists and GitHub
+	// Actions workflows of the default branch of every repository (see
+	// provider.FileLister).
+	ScanFiles bool
+
+	// CodeSearch also searches file contents for the searched emails and
+	// full names with code search, in the user's repositories or globally.
+	CodeSearch CodeSearch
+
+	// PRContext links the matches found in commit messages to the pull
+	// requests including their commit and scans the title and description
+	// of those pull requests (see provider.PullRequestLister).
+	PRContext bool
+
+	// CheckSignatures scans the user IDs of the GPG keys the user signed
+	// commits with, flagging those exposing a personal email or a name
+	// searched for that the commits do not show (see
+	// provider.GPGKeyLister).
+	CheckSignatures bool
+
+	// Associations reports the public organizations of the user, the
+	// fields of their profiles naming the user, and the CODEOWNERS and
+	// .mailmap entries of every repository linking the username to an
+	// identity (see provider.OrgLister and provider.FileLister).
+	Associations bool
+
+	// RespectIgnoreFiles honors .gogitsomeprivacyignore files in owned repos.
+	RespectIgnoreFiles bool
+	// CheckEmailConfig flags commits the user made with a personal email
+	// address instead of the GitHub noreply one (see pii.DetectEmailConfig).
+	CheckEmailConfig bool
+	// CheckGravatar flags commit emails and hashes in text sharing the
+	// Gravatar hash of an email searched for (see pii.Detector.DetectGravatar).
+	CheckGravatar bool
+	// Ignore holds rules applied to every repository, such as those from the
+	// config file. It may be nil.
+	Ignore *ignore.Rules
+
+	// PostProcessors run in order over the matches of every commit. A nil
+	// chain uses pii.DefaultPostProcessors. Commits are scanned concurrently,
+	// so post-processors must be safe for concurrent use.
+	PostProcessors pii.Chain
+
+	// Incremental, if set, skips repositories whose default branch has not
+	// moved since their recorded state and fetches only newer commits from
+	// the others, keeping their previous matches. States are recorded for
+	// the next scan (see RepoStates). Requires a provider.HeadReader.
+	Incremental *Incremental
+
+	// Spool, if set, receives the matches of the scan instead of
+	// ScanResult.Matches, keeping them on disk; the result refers to it as
+	// ScanResult.Spool.
+	Spool *spool.Spool
+}
+
+// record adds matches to result, or to the spool when there is one.
+func (s *Scanner) record(result *models.ScanResult, matches ...models.PIIMatch) {
+	if s.config.Spool == nil {
+		result.Matches = append(result.Matches, matches...)
+		return
+	}
+	for _, match := range matches {
+		s.config.Spool.Add(match)
+	}
 }
 
-// Scanner scans GitHub commits for PII.
+// pagesBranch is the conventional GitHub Pages publishing branch.
+const pagesBranch = "gh-pages"
+
+// Scanner scans the commits of a provider for PII.
 type Scanner struct {
-	client   *github.Client
+	client   provider.Provider
 	criteria models.PIISearchCriteria
 	config   Config
 	detector *pii.Detector
+
+	fingerprint string
+	repoStates  map[string]models.RepoState
+	signers     *signerSet // keys commits were signed with, if CheckSignatures
+
+	ignoreMu    sync.Mutex
+	ignoreFiles map[string]ignoreFile // of owned repositories, by full name
+
+	startedAt    atomic.Int64
+	reposTotal   atomic.Int64
+	reposScanned atomic.Int64
+	commits      atomic.Int64
+	matches      atomic.Int64
+}
+
+// Stats is a snapshot of scan progress.
+type Stats struct {
+	StartedAt    time.Time
+	ReposTotal   int
+	ReposScanned int
+	Commits      int
+	Matches      int
+}
+
+// Stats returns a snapshot of the progress of the running scan. It is safe to
+// call concurrently with ScanUser.
+func (s *Scanner) Stats() Stats {
+	var started time.Time
+	if ns := s.startedAt.Load(); ns != 0 {
+		started = time.Unix(0, ns)
+	}
+	return Stats{
+		StartedAt:    started,
+		ReposTotal:   int(s.reposTotal.Load()),
+		ReposScanned: int(s.reposScanned.Load()),
+		Commits:      int(s.commits.Load()),
+		Matches:      int(s.matches.Load()),
+	}
 }
 
 // NewScanner creates a new scanner.
-func NewScanner(client *github.Client, criteria models.PIISearchCriteria, config Config) *Scanner {
+func NewScanner(client provider.Provider, criteria models.PIISearchCriteria, config Config) *Scanner {
 	if config.MaxWorkers <= 0 {
 		config.MaxWorkers = 10
 	}
 	if config.ContextSize <= 0 {
 		config.ContextSize = 50
 	}
+	if config.DetectionWorkers <= 0 {
+		config.DetectionWorkers = runtime.GOMAXPROCS(0)
+	}
+	if config.MaxRepoErrors <= 0 {
+		config.MaxRepoErrors = 3
+	}
+	if len(config.CommitRoles) == 0 {
+		config.CommitRoles = []models.CommitRole{models.RoleAuthor}
+	}
+	if config.Discovery == "" {
+		config.Discovery = DiscoveryRepos
+	}
+	if config.CommonWords == "" {
+		config.CommonWords = pii.CommonWordsDowngrade
+	}
+	if config.BotCommits == "" {
+		config.BotCommits = pii.BotCommitsOff
+	}
+	if config.PostProcessors == nil {
+		config.PostProcessors, _ = pii.NewChain(pii.DefaultPostProcessors, pii.PostProcessorOptions{})
+	}
 
 	return &Scanner{
-		client:   client,
-		criteria: criteria,
-		config:   config,
-		detector: pii.NewDetector(criteria, config.ContextSize),
+		client:      client,
+		criteria:    criteria,
+		config:      config,
+		detector:    pii.NewDetector(criteria, config.ContextSize).WithFields(config.Fields),
+		fingerprint: fingerprint(criteria, config),
+		repoStates:  make(map[string]models.RepoState),
+		signers:     newSignerSet(),
 	}
 }
 
-// repoCommits holds commits for a repository.
-type repoCommits struct {
-	Repo    *models.Repository
-	Commits []*models.Commit
-	Err     error
+// selectRepos discovers the repositories of username and drops those excluded
+// by the configuration, counting them in result.
+func (s *Scanner) selectRepos(ctx context.Context, username string, result *models.ScanResult) ([]*models.Repository, error) {
+	s.log("Fetching repositories...")
+	repos, err := s.discoverRepos(ctx, username, result)
+	if err != nil {
+		return nil, err
+	}
+	kept := repos[:0]
+	for _, repo := range repos {
+		switch {
+		case s.config.SkipForks && repo.Fork:
+			result.SkippedForks++
+		case s.config.Ignore.MatchRepo(repo.FullName):
+			result.IgnoredRepos++
+		default:
+			kept = append(kept, repo)
+		}
+	}
+	repos = kept
+	if s.config.MaxRepos > 0 && len(repos) > s.config.MaxRepos {
+		repos = repos[:s.config.MaxRepos]
+	}
+	return repos, nil
 }
 
 // ScanUser scans all commits by a user for PII.
-func (s *Scanner) ScanUser(ctx context.Context, username string) (*models.ScanResult, error) {
+func (s *Scanner) ScanUser(ctx context.Context, username string) (result *models.ScanResult, err error) {
 	startTime := time.Now()
+	s.startedAt.Store(startTime.UnixNano())
+	metrics.ScanStarted()
+	ctx, span := tracer.Start(ctx, "scanner.scan_user", trace.WithAttributes(
+		attribute.String("github.user", username),
+		attribute.String("scanner.provider", s.client.Name())))
+	defer func() {
+		metrics.ScanFinished(time.Since(startTime), result, err)
+		if result != nil {
+			span.SetAttributes(
+				attribute.Int("scanner.repos", result.SearchedRepos),
+				attribute.Int("scanner.commits", result.TotalCommits),
+				attribute.Int("scanner.matches", result.MatchCount()),
+				attribute.Bool("scanner.incomplete", result.Incomplete),
+			)
+		}
+		tracing.EndSpan(span, err)
+	}()
 
-	result := &models.ScanResult{
-		Username: username,
-		Matches:  []models.PIIMatch{},
-		Errors:   []models.ScanError{},
+	result = &models.ScanResult{
+		SchemaVersion: models.SchemaVersion,
+		Username:      username,
+		Matches:       []models.PIIMatch{},
+		Errors:        []models.ScanError{},
+	}
+	if s.config.Spool != nil {
+		result.Spool = s.config.Spool
 	}
 
+	s.emit(Event{Type: EventScanStarted, Message: fmt.Sprintf("Starting scan for user: %s", username)})
 	s.log("Starting scan for user: %s", username)
 
 	// Get user profile
@@ -71,87 +325,243 @@ func (s *Scanner) ScanUser(ctx context.Context, username string) (*models.ScanRe
 	}
 	s.log("Found user: %s (%s)", profile.Login, profile.Name)
 
-	// List all repositories
-	s.log("Fetching repositories...")
-	repos, err := s.client.ListUserRepos(ctx, username)
+	repos, err := s.selectRepos(ctx, username, result)
 	if err != nil {
 		return nil, err
 	}
 	result.SearchedRepos = len(repos)
-	s.log("Found %d public repositories", len(repos))
+	s.reposTotal.Store(int64(len(repos)))
+	s.emit(Event{Type: EventReposDiscovered, Repos: len(repos)})
 
-	// Create worker pool
-	pool := worker.NewPool(s.config.MaxWorkers, func(ctx context.Context, repo *models.Repository) (*repoCommits, error) {
-		commits, err := s.client.ListUserCommits(ctx, repo.Owner, repo.Name, username)
-		return &repoCommits{Repo: repo, Commits: commits, Err: err}, nil
-	})
+	batches := make(chan commitBatch, s.config.MaxWorkers*2)
+	detected := make(chan detectedBatch, s.config.MaxWorkers*2)
+	seen := newSHASet()
+	s.ignoreMu.Lock()
+	s.ignoreFiles = nil // ignore files may have changed since the last scan
+	s.ignoreMu.Unlock()
+	s.carryMatches(ctx, repos, username, result, seen)
 
-	// Start workers
+	// Fetch stage
+	// All repositories are queued at once so the smallest are fetched first
+	pool := worker.NewPool(s.config.MaxWorkers, func(ctx context.Context, repo *models.Repository) (*repoCommits, error) {
+		return s.fetchRepo(ctx, repo, username, batches), nil
+	}, worker.Options[*models.Repository]{Name: "fetch", Less: smallerRepo, QueueSize: len(repos)})
+	scaleCtx, stopScaling := context.WithCancel(ctx)
+	defer stopScaling()
+	s.autoscale(scaleCtx, pool.SetWorkers)
 	pool.Start(ctx)
 
-	// Submit repos to pool
 	go func() {
+		defer pool.Close()
 		for _, repo := range repos {
-			pool.Submit(repo)
+			if pool.Submit(ctx, repo) != nil {
+				return
+			}
 		}
-		pool.Close()
 	}()
 
-	// Collect results and scan for PII
-	var mu sync.Mutex
-	var totalCommits int
+	// Detection stage
+	var detectWG sync.WaitGroup
+	for i := 0; i < s.config.DetectionWorkers; i++ {
+		detectWG.Add(1)
+		go func() {
+			defer detectWG.Done()
+			for b := range batches {
+				detected <- s.detectBatch(b, seen)
+			}
+		}()
+	}
+	go func() {
+		detectWG.Wait()
+		close(detected)
+	}()
 
-	for task := range pool.Results() {
-		if task.Err != nil {
-			mu.Lock()
-			result.Errors = append(result.Errors, models.ScanError{
-				Repository: task.Result.Repo.FullName,
-				Message:    task.Err.Error(),
-				Severity:   "warning",
-			})
-			mu.Unlock()
-			continue
+	// Collect results. A repository is finished once it has been fetched and
+	// all of its batches have been collected.
+	type repoState struct {
+		fetched            bool
+		batches, collected int
+		commits, matches   int
+	}
+	states := make(map[*models.Repository]*repoState)
+	state := func(repo *models.Repository) *repoState {
+		st, ok := states[repo]
+		if !ok {
+			st = &repoState{}
+			states[repo] = st
+		}
+		return st
+	}
+	finish := func(repo *models.Repository, st *repoState) {
+		if !st.fetched || st.collected < st.batches {
+			return
 		}
+		delete(states, repo)
+		s.reposScanned.Add(1)
+		s.emit(Event{Type: EventRepoFinished, Repository: repo.FullName, Commits: st.commits, Matches: st.matches})
+	}
+
+	var totalCommits int
+	results, detections := pool.Results(), (<-chan detectedBatch)(detected)
+	for results != nil || detections != nil {
+		select {
+		case task, ok := <-results:
+			if !ok {
+				// No fetch worker is left to send batches
+				results = nil
+				close(batches)
+				continue
+			}
+			rc := task.Result
+			err := task.Err
+			if err == nil {
+				err = rc.Err
+			}
+			// Repositories cut short by cancellation are not reported as
+			// errors; the result is marked incomplete instead.
+			if err != nil && ctx.Err() != nil {
+				delete(states, rc.Repo)
+				continue
+			}
+			if err != nil {
+				s.emit(Event{Type: EventError, Repository: rc.Repo.FullName, Err: err})
+				result.Errors = append(result.Errors, scanError(rc.Repo.FullName, err))
+				result.SkippedRepos = append(result.SkippedRepos, models.SkippedRepo{
+					Repository: rc.Repo.FullName,
+					Reason:     err.Error(),
+				})
+			}
+			if err == nil {
+				result.Associations = append(result.Associations, rc.Associations...)
+			}
+			for _, w := range rc.Warnings {
+				s.emit(Event{Type: EventError, Repository: rc.Repo.FullName, Err: errors.New(w.Message)})
+				result.Errors = append(result.Errors, w)
+			}
+			if err == nil && rc.State != nil {
+				s.repoStates[rc.Repo.FullName] = *rc.State
+			}
+			if rc.Unchanged {
+				result.UnchangedRepos++
+			}
+			st := state(rc.Repo)
+			st.fetched = true
+			st.batches = rc.Batches
+			finish(rc.Repo, st)
+
+		case db, ok := <-detections:
+			if !ok {
+				detections = nil
+				continue
+			}
+			totalCommits += db.Commits
+			result.DuplicateCommits += db.Duplicates
+			result.BotCommits += db.Bots
+			if db.Source == models.SourceFiles {
+				result.Files += db.Docs
+			} else {
+				result.Refs += db.Docs
+			}
+			result.Suppressed += db.Suppressed
+			result.LowConfidence += db.LowConfidence
+			s.commits.Add(int64(db.Commits))
+			s.matches.Add(int64(len(db.Matches)))
+			metrics.ObserveCommits(db.Commits, len(db.Matches))
+			for i := range db.Matches {
+				s.emit(Event{Type: EventMatchFound, Repository: db.Repo.FullName, Match: &db.Matches[i]})
+			}
+			s.record(result, db.Matches...)
+			s.emit(Event{Type: EventCommitsProcessed, Repository: db.Repo.FullName, Commits: db.Commits, Matches: len(db.Matches)})
 
-		rc := task.Result
-		if rc.Err != nil {
-			mu.Lock()
-			result.Errors = append(result.Errors, models.ScanError{
-				Repository: rc.Repo.FullName,
-				Message:    rc.Err.Error(),
-				Severity:   "warning",
-			})
-			mu.Unlock()
-			continue
+			st := state(db.Repo)
+			st.collected++
+			st.commits += db.Commits
+			st.matches += len(db.Matches)
+			finish(db.Repo, st)
 		}
+	}
+
+	// Scan commits found by author email, outside the scanned repositories
+	if s.config.EmailDiscovery && ctx.Err() == nil {
+		totalCommits += s.scanEmailCommits(ctx, result, seen)
+	}
 
-		s.log("Scanning %d commits in %s", len(rc.Commits), rc.Repo.FullName)
+	// Scan recent public activity
+	if s.config.ScanEvents && ctx.Err() == nil {
+		totalCommits += s.scanEvents(ctx, username, result, seen)
+	}
 
-		for _, commit := range rc.Commits {
-			totalCommits++
+	// Scan archived pushes after the other commit sources, so only commits
+	// no longer found on GitHub are left
+	if s.config.Archive != nil && ctx.Err() == nil {
+		totalCommits += s.scanArchive(ctx, profile.Login, result, seen)
+	}
 
-			// Detect PII
-			matches := s.detector.DetectInCommit(commit)
-			if len(matches) > 0 {
-				piiMatch := s.buildPIIMatch(commit, matches)
-				mu.Lock()
-				result.Matches = append(result.Matches, piiMatch)
-				mu.Unlock()
-			}
+	// Scan the published Pages site
+	if s.config.ScanPages && ctx.Err() == nil {
+		s.scanPagesSite(ctx, username, result)
+	}
+
+	// Search file contents, which commit history does not cover
+	switch {
+	case ctx.Err() != nil:
+	case s.config.CodeSearch == CodeSearchRepos:
+		s.scanCode(ctx, scopedCodeQueries(s.codeTerms(), profile.Login, repos), result)
+	case s.config.CodeSearch == CodeSearchGlobal:
+		s.scanCode(ctx, s.codeTerms(), result)
+	}
+
+	// Inspect the keys commits were signed with once every commit has been
+	// scanned
+	if s.config.CheckSignatures && ctx.Err() == nil {
+		s.scanSignatures(ctx, result)
+	}
+
+	// List the organizations of the user, then order the associations
+	// found as repositories were fetched
+	if s.config.Associations && ctx.Err() == nil {
+		s.scanOrgs(ctx, profile.Login, result)
+	}
+	sortAssociations(result.Associations)
+
+	// Look up pull requests once every commit has been scanned
+	if s.config.PRContext && ctx.Err() == nil {
+		s.scanPullRequests(ctx, result)
+	}
+
+	if ctx.Err() != nil {
+		result.Incomplete = true
+		result.IncompleteReason = fmt.Sprintf("%v after %d of %d repositories", context.Cause(ctx), s.reposScanned.Load(), len(repos))
+		s.emit(Event{Type: EventInfo, Message: "Scan interrupted: " + result.IncompleteReason})
+	}
+
+	if s.config.Spool != nil {
+		if err := s.config.Spool.Err(); err != nil {
+			return nil, err
 		}
 	}
 
 	result.TotalCommits = totalCommits
+	result.Summary = report.Summarize(result)
+	result.PrivacyScore = report.Score(result)
 	result.ScanDuration = time.Since(startTime).String()
 
-	s.log("Scan complete: %d commits, %d matches, duration: %s",
-		result.TotalCommits, len(result.Matches), result.ScanDuration)
+	s.emit(Event{
+		Type:    EventScanFinished,
+		Commits: result.TotalCommits,
+		Matches: result.MatchCount(),
+		Message: fmt.Sprintf("Scan complete: %d commits, %d matches, duration: %s",
+			result.TotalCommits, result.MatchCount(), result.ScanDuration),
+	})
 
 	return result, nil
 }
 
-// buildPIIMatch builds a PIIMatch from detected matches.
+// buildPIIMatch builds a PIIMatch from detected matches, with one location
+// per distinct leak: a name matched as full, first and last name at once is
+// reported, and scored, as one.
 func (s *Scanner) buildPIIMatch(commit *models.Commit, matches []pii.Match) models.PIIMatch {
+	matches = pii.MergeOverlaps(matches)
 	locations := make([]models.Location, len(matches))
 	for i, m := range matches {
 		locations[i] = models.Location{
@@ -159,6 +569,13 @@ func (s *Scanner) buildPIIMatch(commit *models.Commit, matches []pii.Match) mode
 			Line:    m.Line,
 			Column:  m.Column,
 			Matched: m.Text,
+
+			Confidence: m.Confidence,
+			Rule:       m.Rule,
+			Identity:   m.Identity,
+			Alias:      m.Alias,
+
+			Fingerprint: models.Fingerprint(*commit, m.Field, m.Start, m.Type),
 		}
 	}
 
@@ -174,18 +591,14 @@ func (s *Scanner) buildPIIMatch(commit *models.Commit, matches []pii.Match) mode
 		context = matches[0].Context
 	}
 
+	confidence := pii.CalculateConfidence(matches)
 	return models.PIIMatch{
 		Commit:     *commit,
 		PIIType:    piiType,
 		Locations:  locations,
-		Confidence: pii.CalculateConfidence(matches),
+		Confidence: confidence,
+		Severity:   pii.SeverityFor(confidence),
 		Context:    context,
-	}
-}
-
-// log logs a message if verbose logging is enabled.
-func (s *Scanner) log(format string, args ...interface{}) {
-	if s.config.ProgressLogger != nil {
-		s.config.ProgressLogger.Printf(format, args...)
+		Source:     models.SourceCommit,
 	}
 }
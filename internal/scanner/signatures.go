@@ -0,0 +1,206 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/gpg"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// signingKey is a GPG key commits of the user were signed with.
+type signingKey struct {
+	ID string
+	// Commit is the earliest commit signed with the key, in which its
+	// findings are reported.
+	Commit *models.Commit
+	// Login is the account of the committer, whose published keys are
+	// looked up.
+	Login string
+	// UserID is the signer's user ID some signatures name.
+	UserID string
+}
+
+// signerSet records the keys commits were signed with during a scan. It is
+// safe for concurrent use.
+type signerSet struct {
+	mu   sync.Mutex
+	keys map[string]*signingKey
+}
+
+func newSignerSet() *signerSet {
+	return &signerSet{keys: make(map[string]*signingKey)}
+}
+
+// add records the key commit was signed with, if the user signed it with
+// GPG. SSH and X.509 signatures and unreadable ones are skipped.
+func (s *signerSet) add(commit *models.Commit) {
+	if commit.Signature == "" || !gpg.IsSignature(commit.Signature) || !signedByUser(commit) {
+		return
+	}
+	sig, err := gpg.ParseSignature(commit.Signature)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[sig.KeyID]
+	if !ok {
+		key = &signingKey{ID: sig.KeyID, Commit: commit}
+		s.keys[sig.KeyID] = key
+	}
+	// The earliest commit, so that findings keep their fingerprint across
+	// scans whatever order commits are detected in
+	if commit.Date.Before(key.Commit.Date) || (commit.Date.Equal(key.Commit.Date) && commit.SHA < key.Commit.SHA) {
+		key.Commit = commit
+	}
+	if key.Login == "" {
+		key.Login = commit.Committer.Login
+	}
+	if key.UserID == "" {
+		key.UserID = sig.UserID
+	}
+}
+
+// sorted returns the keys by ID.
+func (s *signerSet) sorted() []*signingKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]*signingKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b *signingKey) int { return strings.Compare(a.ID, b.ID) })
+	return keys
+}
+
+// signedByUser reports whether the user scanned signed commit: they committed
+// it, or authored it and committed it themselves. Commits without roles are
+// treated as authored.
+func signedByUser(commit *models.Commit) bool {
+	if slices.Contains(commit.Roles, models.RoleCommitter) {
+		return true
+	}
+	if len(commit.Roles) > 0 && !slices.Contains(commit.Roles, models.RoleAuthor) {
+		return false
+	}
+	a, c := commit.Author, commit.Committer
+	return (a.Login != "" && strings.EqualFold(a.Login, c.Login)) ||
+		(a.Email != "" && strings.EqualFold(a.Email, c.Email))
+}
+
+// scanSignatures scans the user IDs of the GPG keys the user signed commits
+// with, as named by the signatures and by the keys the committer published,
+// recording a finding for each key whose user IDs expose an identity the
+// commits do not show: a personal email, or a name searched for.
+func (s *Scanner) scanSignatures(ctx context.Context, result *models.ScanResult) {
+	keys := s.signers.sorted()
+	if len(keys) == 0 {
+		return
+	}
+	lister, ok := s.client.(provider.GPGKeyLister)
+	if !ok {
+		err := fmt.Errorf("GPG key lookup is not supported by the %s provider", s.client.Name())
+		s.emit(Event{Type: EventError, Err: err})
+		result.Errors = append(result.Errors, scanError("", err))
+		return
+	}
+
+	ctx, span := tracer.Start(ctx, "scanner.signatures")
+
+	published := make(map[string][]*models.GPGKey) // by login
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, ok := published[key.Login]; !ok && key.Login != "" {
+			var found []*models.GPGKey
+			err := s.retry(ctx, "the GPG keys of "+key.Login, func() (err error) {
+				found, err = lister.ListGPGKeys(ctx, key.Login)
+				return err
+			})
+			if err != nil && ctx.Err() == nil {
+				s.emit(Event{Type: EventError, Err: err})
+				result.Errors = append(result.Errors, scanError("", err))
+			}
+			published[key.Login] = found
+		}
+		result.SigningKeys++
+
+		var uids []string
+		if key.UserID != "" {
+			uids = append(uids, key.UserID)
+		}
+		for _, k := range published[key.Login] {
+			if slices.ContainsFunc(k.KeyIDs, func(id string) bool { return strings.EqualFold(id, key.ID) }) {
+				uids = append(uids, k.UserIDs...)
+			}
+		}
+		match, suppressed, low := s.detectSigner(key.Commit, uids)
+		result.Suppressed += suppressed
+		if low {
+			result.LowConfidence++
+		}
+		if match != nil {
+			match.Source = models.SourceSignature
+			s.matches.Add(1)
+			s.emit(Event{Type: EventMatchFound, Repository: match.Commit.Repository, Match: match})
+			s.record(result, *match)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("scanner.signing_keys", result.SigningKeys))
+	tracing.EndSpan(span, nil)
+}
+
+// detectSigner scans the user IDs of the key commit was signed with that
+// differ from its author and committer, one per line of the signer_uid field.
+// It returns the match, if any, the number of matches suppressed and whether
+// the match was dropped for its low confidence, as detectDocument does.
+func (s *Scanner) detectSigner(commit *models.Commit, uids []string) (match *models.PIIMatch, suppressed int, lowConfidence bool) {
+	var lines []string
+	for _, uid := range uids {
+		name, email := gpg.ParseUserID(uid)
+		if slices.Contains(lines, uid) || shownIn(commit, name, email) {
+			continue
+		}
+		lines = append(lines, uid)
+	}
+	if len(lines) == 0 {
+		return nil, 0, false
+	}
+
+	text := strings.Join(lines, "\n")
+	matches, common := pii.ApplyCommonWordMode(s.config.CommonWords, s.detector.DetectInTexts([]pii.Text{{Text: text, Field: "signer_uid"}}))
+	matches = append(matches, pii.DetectSignerEmails(text, "signer_uid")...)
+	matches = s.config.PostProcessors.Process(matches)
+	matches, ignored := s.applyIgnoreRules(s.config.Ignore, matches)
+	suppressed = common + ignored
+	if len(matches) == 0 {
+		return nil, suppressed, false
+	}
+	piiMatch := s.buildPIIMatch(commit, matches)
+	if piiMatch.Confidence < s.config.MinConfidence {
+		return nil, suppressed, true
+	}
+	return &piiMatch, suppressed, false
+}
+
+// shownIn reports whether the identity of a user ID is the author or
+// committer of commit already: the same email, under the same name if any.
+func shownIn(commit *models.Commit, name, email string) bool {
+	for _, a := range []models.Author{commit.Author, commit.Committer} {
+		if strings.EqualFold(email, a.Email) && (name == "" || strings.EqualFold(name, a.Name)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+)
+
+// Status is the lifecycle state of a scan job.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusDone      Status = "done"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// subscriberBuffer is how many events a slow SSE client may lag behind before
+// events are dropped for it.
+const subscriberBuffer = 256
+
+// Job is a queued or running scan. It implements scanner.ProgressReporter to
+// fan progress events out to SSE subscribers.
+type Job struct {
+	ID      string
+	Request ScanRequest
+
+	mu       sync.Mutex
+	status   Status
+	created  time.Time
+	started  time.Time
+	finished time.Time
+	err      string
+	result   *models.ScanResult
+	scanner  *scanner.Scanner
+	storedID int64
+	cancel   context.CancelCauseFunc
+	subs     map[chan scanner.Event]struct{}
+}
+
+// JobView is the JSON representation of a job.
+type JobView struct {
+	ID         string             `json:"id"`
+	Username   string             `json:"username"`
+	Status     Status             `json:"status"`
+	CreatedAt  time.Time          `json:"created_at"`
+	StartedAt  *time.Time         `json:"started_at,omitempty"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Progress   *Progress          `json:"progress,omitempty"`
+	StoredID   int64              `json:"stored_scan_id,omitempty"`
+	Result     *models.ScanResult `json:"result,omitempty"`
+}
+
+// Progress is a snapshot of a running scan.
+type Progress struct {
+	ReposTotal   int `json:"repos_total"`
+	ReposScanned int `json:"repos_scanned"`
+	Commits      int `json:"commits"`
+	Matches      int `json:"matches"`
+}
+
+func newJob(req ScanRequest) *Job {
+	return &Job{
+		ID:      newJobID(),
+		Request: req,
+		status:  StatusQueued,
+		created: time.Now(),
+		subs:    make(map[chan scanner.Event]struct{}),
+	}
+}
+
+// newJobID returns a random 16-character hex identifier.
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Report forwards a progress event to every subscriber without blocking.
+func (j *Job) Report(e scanner.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for ch := range j.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of progress events that is closed when the job
+// ends, and a function to unsubscribe early.
+func (j *Job) Subscribe() (<-chan scanner.Event, func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ch := make(chan scanner.Event, subscriberBuffer)
+	if j.ended() {
+		close(ch)
+		return ch, func() {}
+	}
+	j.subs[ch] = struct{}{}
+	return ch, func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if _, ok := j.subs[ch]; ok {
+			delete(j.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Cancel stops the job. Queued jobs are cancelled immediately; running jobs
+// stop after the scanner drains and keep their partial result.
+func (j *Job) Cancel() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	switch j.status {
+	case StatusQueued:
+		j.finishLocked(StatusCancelled, nil, "cancelled before start")
+	case StatusRunning:
+		j.cancel(errCancelled)
+	}
+}
+
+// View returns the JSON representation of the job, with the full result only
+// if withResult is set.
+func (j *Job) View(withResult bool) JobView {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	v := JobView{
+		ID:        j.ID,
+		Username:  j.Request.Username,
+		Status:    j.status,
+		CreatedAt: j.created,
+		Error:     j.err,
+		StoredID:  j.storedID,
+	}
+	if !j.started.IsZero() {
+		started := j.started
+		v.StartedAt = &started
+	}
+	if !j.finished.IsZero() {
+		finished := j.finished
+		v.FinishedAt = &finished
+	}
+	if j.scanner != nil {
+		stats := j.scanner.Stats()
+		v.Progress = &Progress{
+			ReposTotal:   stats.ReposTotal,
+			ReposScanned: stats.ReposScanned,
+			Commits:      stats.Commits,
+			Matches:      stats.Matches,
+		}
+	}
+	if withResult {
+		v.Result = j.result
+	}
+	return v
+}
+
+// start marks the job running. It returns false if the job was cancelled
+// while queued.
+func (j *Job) start(s *scanner.Scanner, cancel context.CancelCauseFunc) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status != StatusQueued {
+		return false
+	}
+	j.status = StatusRunning
+	j.started = time.Now()
+	j.scanner = s
+	j.cancel = cancel
+	return true
+}
+
+// finish records the outcome of the job and closes all subscriptions.
+func (j *Job) finish(status Status, result *models.ScanResult, errMsg string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.finishLocked(status, result, errMsg)
+}
+
+func (j *Job) finishLocked(status Status, result *models.ScanResult, errMsg string) {
+	j.status = status
+	j.finished = time.Now()
+	j.result = result
+	j.err = errMsg
+	for ch := range j.subs {
+		delete(j.subs, ch)
+		close(ch)
+	}
+}
+
+// finishedAt returns when the job ended, or the zero time if it has not.
+func (j *Job) finishedAt() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.finished
+}
+
+func (j *Job) ended() bool {
+	return j.status != StatusQueued && j.status != StatusRunning
+}
@@ -0,0 +1,611 @@
+// Package server exposes scans over an HTTP REST API.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/metrics"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/sink"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/store"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+)
+
+// errCancelled is the cancellation cause of jobs stopped through the API.
+var errCancelled = errors.New("cancelled through the API")
+
+// Options configures a Server.
+type Options struct {
+	// Concurrency is the number of scans run at the same time (default 1).
+	Concurrency int
+	// QueueSize is the number of scans that may wait for a runner (default 100).
+	QueueSize int
+	// MaxFinishedJobs is the number of finished jobs kept in memory, the
+	// oldest submitted being forgotten first (default 100).
+	MaxFinishedJobs int
+	// JobTTL, if set, forgets finished jobs this long after they finish.
+	JobTTL time.Duration
+	// AuthToken, if set, must be sent as a bearer token with every request.
+	AuthToken string
+	// StorePath, if set, persists finished results into this SQLite database.
+	StorePath string
+	// Sinks receive every finished result, after suppressions are applied.
+	Sinks []sink.Sink
+}
+
+// ScanRequest is the body of POST /scans.
+type ScanRequest struct {
+	Username        string   `json:"username"`
+	FullName        string   `json:"full_name,omitempty"`
+	FirstName       string   `json:"first_name,omitempty"`
+	LastName        string   `json:"last_name,omitempty"`
+	Emails          []string `json:"emails,omitempty"`
+	Identities      []string `json:"identities,omitempty"`
+	Exact           bool     `json:"exact,omitempty"`
+	CaseSensitive   bool     `json:"case_sensitive,omitempty"`
+	ExpandNicknames bool     `json:"expand_nicknames,omitempty"`
+	Pages           bool     `json:"pages,omitempty"`
+	SkipForks       bool     `json:"skip_forks,omitempty"`
+	MinConfidence   float64  `json:"min_confidence,omitempty"`
+	Discovery       string   `json:"discovery,omitempty"`   // repos, search, both or none (default: config)
+	CodeSearch      string   `json:"code_search,omitempty"` // repos, global or off (default: config)
+
+	// MaxCommitsPerRepo and Sample override the configured limits on the
+	// commits scanned per repository; at most one may be set.
+	MaxCommitsPerRepo int `json:"max_commits_per_repo,omitempty"`
+	Sample            int `json:"sample,omitempty"`
+
+	IncludeCommitter bool `json:"include_committer,omitempty"`
+	IncludeCoAuthor  bool `json:"include_co_author,omitempty"`
+	EmailDiscovery   bool `json:"email_discovery,omitempty"`
+	Events           bool `json:"events,omitempty"`
+	Refs             bool `json:"refs,omitempty"`
+	Files            bool `json:"files,omitempty"`
+	Gravatar         bool `json:"gravatar,omitempty"`
+	PRContext        bool `json:"pr_context,omitempty"`
+	Signatures       bool `json:"signatures,omitempty"`
+	Associations     bool `json:"associations,omitempty"`
+}
+
+// Server runs scan jobs submitted over HTTP.
+type Server struct {
+	cfg    *config.Config
+	opts   Options
+	client provider.Provider
+
+	queue chan *Job
+	mu    sync.RWMutex
+	jobs  map[string]*Job
+	order []string // job IDs, oldest submitted first
+}
+
+// New creates a server. All jobs share one provider client, and so one rate limit.
+func New(cfg *config.Config, opts Options) (*Server, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+	if opts.MaxFinishedJobs <= 0 {
+		opts.MaxFinishedJobs = 100
+	}
+	client, err := provider.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		cfg:    cfg,
+		opts:   opts,
+		client: client,
+		queue:  make(chan *Job, opts.QueueSize),
+		jobs:   make(map[string]*Job),
+	}, nil
+}
+
+// Run starts the scan runners and blocks until ctx is cancelled. Running
+// scans are cancelled with ctx and keep their partial results.
+func (s *Server) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	if s.opts.JobTTL > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(min(s.opts.JobTTL, time.Minute))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C:
+					s.prune(now)
+				}
+			}
+		}()
+	}
+	for i := 0; i < s.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-s.queue:
+					metrics.SetQueueDepth(len(s.queue))
+					s.runJob(ctx, job)
+					s.prune(time.Now())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Handler returns the HTTP handler serving the API. /healthz and /metrics are
+// served without authentication so probes and scrapers need no token.
+func (s *Server) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("POST /scans", s.handleCreate)
+	api.HandleFunc("GET /scans", s.handleList)
+	api.HandleFunc("GET /scans/{id}", s.handleGet)
+	api.HandleFunc("DELETE /scans/{id}", s.handleCancel)
+	api.HandleFunc("GET /scans/{id}/events", s.handleEvents)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", HealthHandler)
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.Handle("/", s.authenticate(api))
+	return mux
+}
+
+// HealthHandler reports that the process is up.
+func HealthHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// authenticate enforces the bearer token, if one is configured.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.opts.AuthToken == "" {
+		return next
+	}
+	want := []byte("Bearer " + s.opts.AuthToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req ScanRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if _, err := s.criteria(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.MaxCommitsPerRepo < 0 || req.Sample < 0 {
+		writeError(w, http.StatusBadRequest, "max_commits_per_repo and sample must not be negative")
+		return
+	}
+	if req.MaxCommitsPerRepo > 0 && req.Sample > 0 {
+		writeError(w, http.StatusBadRequest, "max_commits_per_repo and sample cannot be used together")
+		return
+	}
+	if req.Pages && s.client.Name() != provider.GitHub {
+		writeError(w, http.StatusBadRequest, "pages scanning is only supported with the github provider")
+		return
+	}
+	if req.EmailDiscovery {
+		if _, ok := s.client.(provider.EmailSearcher); !ok {
+			writeError(w, http.StatusBadRequest, "email discovery is only supported with the github provider")
+			return
+		}
+	}
+	if req.Refs {
+		if _, ok := s.client.(provider.RefLister); !ok {
+			writeError(w, http.StatusBadRequest, "refs scanning is only supported with the github provider")
+			return
+		}
+	}
+	if req.Events {
+		if _, ok := s.client.(provider.EventLister); !ok {
+			writeError(w, http.StatusBadRequest, "event scanning is only supported with the github provider")
+			return
+		}
+	}
+	discovery, err := scanner.ParseDiscovery(req.Discovery)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := s.client.(provider.RepoSearcher); !ok && (discovery == scanner.DiscoverySearch || discovery == scanner.DiscoveryBoth) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("discovery %q is only supported with the github provider", discovery))
+		return
+	}
+
+	codeSearch, err := scanner.ParseCodeSearch(req.CodeSearch)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := s.client.(provider.CodeSearcher); !ok && codeSearch != scanner.CodeSearchOff {
+		writeError(w, http.StatusBadRequest, "code search is only supported with the github provider")
+		return
+	}
+	if _, ok := s.client.(provider.PullRequestLister); !ok && req.PRContext {
+		writeError(w, http.StatusBadRequest, "pr_context is only supported with the github provider")
+		return
+	}
+	if _, ok := s.client.(provider.GPGKeyLister); !ok && req.Signatures {
+		writeError(w, http.StatusBadRequest, "signatures is only supported with the github provider")
+		return
+	}
+
+	job := newJob(req)
+	select {
+	case s.queue <- job:
+		metrics.SetQueueDepth(len(s.queue))
+	default:
+		writeError(w, http.StatusServiceUnavailable, "scan queue is full")
+		return
+	}
+
+	s.mu.Lock()
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+	s.mu.Unlock()
+	s.prune(time.Now())
+
+	w.Header().Set("Location", "/scans/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job.View(false))
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	views := make([]JobView, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		views = append(views, s.jobs[s.order[i]].View(false))
+	}
+	s.mu.RUnlock()
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	job := s.lookup(w, r)
+	if job == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, job.View(true))
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	job := s.lookup(w, r)
+	if job == nil {
+		return
+	}
+	job.Cancel()
+	writeJSON(w, http.StatusAccepted, job.View(false))
+}
+
+// handleEvents streams a job's progress as server-sent events. The stream
+// starts and ends with a "status" event carrying the job view.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	job := s.lookup(w, r)
+	if job == nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	events, unsubscribe := job.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	writeSSE(w, "status", job.View(false))
+	flusher.Flush()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				writeSSE(w, "status", job.View(false))
+				flusher.Flush()
+				return
+			}
+			writeSSE(w, string(e.Type), newEventView(e))
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// prune forgets the finished jobs beyond MaxFinishedJobs, oldest submitted
+// first, and those that finished more than JobTTL ago. Their results are
+// then only kept in the store, if any.
+func (s *Server) prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := 0
+	for i := len(s.order) - 1; i >= 0; i-- {
+		id := s.order[i]
+		finished := s.jobs[id].finishedAt()
+		if finished.IsZero() {
+			continue
+		}
+		if kept == s.opts.MaxFinishedJobs || (s.opts.JobTTL > 0 && now.Sub(finished) >= s.opts.JobTTL) {
+			delete(s.jobs, id)
+			continue
+		}
+		kept++
+	}
+	s.order = slices.DeleteFunc(s.order, func(id string) bool {
+		_, ok := s.jobs[id]
+		return !ok
+	})
+}
+
+// lookup returns the job named in the path, or writes a 404 and returns nil.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *Job {
+	s.mu.RLock()
+	job := s.jobs[r.PathValue("id")]
+	s.mu.RUnlock()
+	if job == nil {
+		writeError(w, http.StatusNotFound, "scan not found")
+	}
+	return job
+}
+
+// runJob runs a scan job to completion.
+func (s *Server) runJob(ctx context.Context, job *Job) {
+	criteria, err := s.criteria(job.Request)
+	if err != nil {
+		job.finish(StatusFailed, nil, err.Error())
+		return
+	}
+	chain, err := pii.NewChain(s.cfg.Scan.PostProcessors, pii.PostProcessorOptions{
+		Allowlist:     s.cfg.Scan.Allowlist,
+		MinConfidence: s.cfg.Scan.MinConfidence,
+	})
+	if err != nil {
+		job.finish(StatusFailed, nil, err.Error())
+		return
+	}
+	ignoreRules, err := s.cfg.IgnoreRules()
+	if err != nil {
+		job.finish(StatusFailed, nil, err.Error())
+		return
+	}
+
+	maxCommits, sample := s.commitLimits(job.Request)
+	sc := scanner.NewScanner(s.client, criteria, scanner.Config{
+		MaxWorkers:         s.cfg.Scan.MaxWorkers,
+		DetectionWorkers:   s.cfg.Scan.DetectionWorkers,
+		ContextSize:        s.cfg.Scan.ContextSize,
+		SkipForks:          s.cfg.Scan.SkipForks || job.Request.SkipForks,
+		Discovery:          s.discovery(job.Request),
+		CommitRoles:        s.commitRoles(job.Request),
+		EmailDiscovery:     s.cfg.Scan.EmailDiscovery || job.Request.EmailDiscovery,
+		ScanEvents:         s.cfg.Scan.ScanEvents || job.Request.Events,
+		ScanRefs:           s.cfg.Scan.ScanRefs || job.Request.Refs,
+		ScanFiles:          s.cfg.Scan.ScanFiles || job.Request.Files,
+		CodeSearch:         s.codeSearch(job.Request),
+		PRContext:          s.cfg.Scan.PRContext || job.Request.PRContext,
+		MinConfidence:      max(s.cfg.Scan.MinConfidence, job.Request.MinConfidence),
+		CommonWords:        pii.CommonWordMode(s.cfg.Scan.CommonWords),
+		Fields:             s.cfg.Scan.Fields,
+		BotCommits:         pii.BotCommitMode(s.cfg.Scan.BotCommits),
+		Progress:           job,
+		ScanPages:          s.cfg.Scan.ScanPages || job.Request.Pages,
+		MaxPages:           s.cfg.Scan.MaxPages,
+		RespectIgnoreFiles: s.cfg.Scan.RespectIgnoreFiles,
+		CheckEmailConfig:   s.cfg.Scan.CheckEmailConfig,
+		CheckGravatar:      s.cfg.Scan.CheckGravatar || job.Request.Gravatar,
+		CheckSignatures:    s.cfg.Scan.CheckSignatures || job.Request.Signatures,
+		Associations:       s.cfg.Scan.Associations || job.Request.Associations,
+		RepoTimeout:        time.Duration(s.cfg.Scan.RepoTimeoutSeconds) * time.Second,
+		MaxRepoErrors:      s.cfg.Scan.MaxRepoErrors,
+		MaxCommitsPerRepo:  maxCommits,
+		Sample:             sample,
+		Ignore:             ignoreRules,
+		PostProcessors:     chain,
+	})
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if !job.start(sc, cancel) {
+		return
+	}
+
+	result, err := sc.ScanUser(jobCtx, job.Request.Username)
+	if err != nil {
+		job.finish(StatusFailed, nil, err.Error())
+		return
+	}
+
+	if s.opts.StorePath != "" {
+		if id, err := s.save(result); err != nil {
+			result.Errors = append(result.Errors, models.ScanError{Message: err.Error(), Severity: "warning"})
+		} else {
+			job.mu.Lock()
+			job.storedID = id
+			job.mu.Unlock()
+		}
+	}
+
+	st, err := baseline.LoadDir(s.cfg.State.Dir)
+	if err != nil {
+		job.finish(StatusFailed, nil, fmt.Sprintf("failed to load state: %v", err))
+		return
+	}
+	result.Suppressed += st.Filter(result)
+	result.Summary = report.Summarize(result)
+	result.PrivacyScore = report.Score(result)
+	report.Advise(result)
+
+	// Export even when shutting down, so partial results are not lost
+	if err := sink.ExportAll(context.WithoutCancel(ctx), s.opts.Sinks, result); err != nil {
+		result.Errors = append(result.Errors, models.ScanError{Message: err.Error(), Severity: "warning"})
+	}
+
+	status := StatusDone
+	if errors.Is(context.Cause(jobCtx), errCancelled) {
+		status = StatusCancelled
+	}
+	job.finish(status, result, "")
+}
+
+// save persists an unfiltered result into the results store.
+func (s *Server) save(result *models.ScanResult) (int64, error) {
+	rs, err := store.Open(s.opts.StorePath)
+	if err != nil {
+		return 0, err
+	}
+	defer rs.Close()
+
+	id, err := rs.SaveScan(result, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to store results: %w", err)
+	}
+	return id, nil
+}
+
+// discovery returns the discovery mode of a request, defaulting to the
+// configured one. Requests are validated when submitted.
+func (s *Server) discovery(req ScanRequest) scanner.Discovery {
+	if req.Discovery == "" {
+		return scanner.Discovery(s.cfg.Scan.Discovery)
+	}
+	d, _ := scanner.ParseDiscovery(req.Discovery)
+	return d
+}
+
+// codeSearch returns the code search scope of a request, defaulting to the
+// configured one. Requests are validated when submitted.
+func (s *Server) codeSearch(req ScanRequest) scanner.CodeSearch {
+	if req.CodeSearch == "" {
+		c, _ := scanner.ParseCodeSearch(s.cfg.Scan.CodeSearch)
+		return c
+	}
+	c, _ := scanner.ParseCodeSearch(req.CodeSearch)
+	return c
+}
+
+// commitLimits returns the commits scanned per repository of a request: its
+// limit or sample size if it sets one, the configured ones otherwise.
+func (s *Server) commitLimits(req ScanRequest) (maxCommits, sample int) {
+	if req.MaxCommitsPerRepo > 0 || req.Sample > 0 {
+		return req.MaxCommitsPerRepo, req.Sample
+	}
+	return s.cfg.Scan.MaxCommitsPerRepo, s.cfg.Scan.Sample
+}
+
+// commitRoles returns the configured commit roles plus those requested.
+func (s *Server) commitRoles(req ScanRequest) []models.CommitRole {
+	roles := s.cfg.CommitRoles()
+	if req.IncludeCommitter && !slices.Contains(roles, models.RoleCommitter) {
+		roles = append(roles, models.RoleCommitter)
+	}
+	if req.IncludeCoAuthor && !slices.Contains(roles, models.RoleCoAuthor) {
+		roles = append(roles, models.RoleCoAuthor)
+	}
+	return roles
+}
+
+// criteria builds and validates the search criteria of a request.
+func (s *Server) criteria(req ScanRequest) (models.PIISearchCriteria, error) {
+	if strings.TrimSpace(req.Username) == "" {
+		return models.PIISearchCriteria{}, fmt.Errorf("username is required")
+	}
+	criteria, err := s.cfg.Criteria(config.SearchOptions{
+		FullName:      req.FullName,
+		FirstName:     req.FirstName,
+		LastName:      req.LastName,
+		Emails:        req.Emails,
+		Identities:    req.Identities,
+		Exact:         req.Exact,
+		CaseSensitive: req.CaseSensitive,
+
+		ExpandNicknames: req.ExpandNicknames,
+	})
+	if errors.Is(err, config.ErrNoCriteria) {
+		return criteria, fmt.Errorf("at least one of full_name, first_name, last_name, emails or identities must be specified")
+	}
+	return criteria, err
+}
+
+// eventView is the JSON representation of a progress event.
+type eventView struct {
+	Type       scanner.EventType `json:"type"`
+	Time       time.Time         `json:"time"`
+	Repository string            `json:"repository,omitempty"`
+	Repos      int               `json:"repos,omitempty"`
+	Commits    int               `json:"commits,omitempty"`
+	Matches    int               `json:"matches,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	Match      *models.PIIMatch  `json:"match,omitempty"`
+}
+
+func newEventView(e scanner.Event) eventView {
+	v := eventView{
+		Type:       e.Type,
+		Time:       e.Time,
+		Repository: e.Repository,
+		Repos:      e.Repos,
+		Commits:    e.Commits,
+		Matches:    e.Matches,
+		Message:    e.Message,
+		Match:      e.Match,
+	}
+	if e.Err != nil {
+		v.Error = e.Err.Error()
+	}
+	return v
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeSSE(w http.ResponseWriter, event string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+)
+
+// newTestServer returns a server whose jobs stay queued, since its runners
+// are never started.
+func newTestServer(t *testing.T, opts Options) *Server {
+	t.Helper()
+	s, err := New(config.DefaultConfig(), opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s
+}
+
+// do sends a request to h with token as bearer token, if any, and decodes
+// the JSON response into v, if not nil.
+func do(t *testing.T, h http.Handler, method, path, body, token string, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: invalid response %q: %v", method, path, rec.Body, err)
+		}
+	}
+	return rec
+}
+
+func TestAuthentication(t *testing.T) {
+	h := newTestServer(t, Options{AuthToken: "secret"}).Handler()
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "missing token", path: "/scans", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", path: "/scans", token: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "token prefix", path: "/scans", token: "secre", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", path: "/scans", token: "secret", wantStatus: http.StatusOK},
+		{name: "unknown scan with valid token", path: "/scans/nope", token: "secret", wantStatus: http.StatusNotFound},
+		{name: "unknown scan without token", path: "/scans/nope", wantStatus: http.StatusUnauthorized},
+		{name: "health without token", path: "/healthz", wantStatus: http.StatusOK},
+		{name: "metrics without token", path: "/metrics", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, h, http.MethodGet, tt.path, "", tt.token, nil)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCreateScan(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "queued", body: `{"username": "octocat", "full_name": "John Doe"}`, wantStatus: http.StatusAccepted},
+		{name: "invalid JSON", body: `{"username":`, wantStatus: http.StatusBadRequest, wantError: "invalid request body"},
+		{name: "no username", body: `{"full_name": "John Doe"}`, wantStatus: http.StatusBadRequest, wantError: "username is required"},
+		{name: "no criteria", body: `{"username": "octocat"}`, wantStatus: http.StatusBadRequest, wantError: "at least one of"},
+		{name: "negative limit", body: `{"username": "octocat", "full_name": "John Doe", "sample": -1}`, wantStatus: http.StatusBadRequest, wantError: "must not be negative"},
+		{name: "limit and sample", body: `{"username": "octocat", "full_name": "John Doe", "sample": 5, "max_commits_per_repo": 5}`, wantStatus: http.StatusBadRequest, wantError: "cannot be used together"},
+		{name: "invalid discovery", body: `{"username": "octocat", "full_name": "John Doe", "discovery": "all"}`, wantStatus: http.StatusBadRequest, wantError: "discovery"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestServer(t, Options{}).Handler()
+			var resp map[string]any
+			rec := do(t, h, http.MethodPost, "/scans", tt.body, "", &resp)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("POST /scans status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantError != "" {
+				if msg, _ := resp["error"].(string); !strings.Contains(msg, tt.wantError) {
+					t.Errorf("error = %q, want it to contain %q", msg, tt.wantError)
+				}
+				return
+			}
+
+			if resp["status"] != string(StatusQueued) || resp["username"] != "octocat" {
+				t.Errorf("POST /scans = %v, want a queued scan of octocat", resp)
+			}
+			location := rec.Header().Get("Location")
+			if location != "/scans/"+resp["id"].(string) {
+				t.Errorf("Location = %q, want /scans/%s", location, resp["id"])
+			}
+			var job JobView
+			if rec := do(t, h, http.MethodGet, location, "", "", &job); rec.Code != http.StatusOK || job.Status != StatusQueued {
+				t.Errorf("GET %s = %d %+v, want the queued scan", location, rec.Code, job)
+			}
+		})
+	}
+}
+
+func TestCreateScan_QueueFull(t *testing.T) {
+	h := newTestServer(t, Options{QueueSize: 1}).Handler()
+	body := `{"username": "octocat", "full_name": "John Doe"}`
+	if rec := do(t, h, http.MethodPost, "/scans", body, "", nil); rec.Code != http.StatusAccepted {
+		t.Fatalf("first POST /scans status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if rec := do(t, h, http.MethodPost, "/scans", body, "", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("second POST /scans status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestCancelScan(t *testing.T) {
+	h := newTestServer(t, Options{}).Handler()
+	var job JobView
+	do(t, h, http.MethodPost, "/scans", `{"username": "octocat", "full_name": "John Doe"}`, "", &job)
+
+	var cancelled JobView
+	if rec := do(t, h, http.MethodDelete, "/scans/"+job.ID, "", "", &cancelled); rec.Code != http.StatusAccepted {
+		t.Fatalf("DELETE status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if cancelled.Status != StatusCancelled || cancelled.FinishedAt == nil {
+		t.Errorf("DELETE = %+v, want a finished cancelled scan", cancelled)
+	}
+
+	// Cancelling again changes nothing
+	var again JobView
+	do(t, h, http.MethodDelete, "/scans/"+job.ID, "", "", &again)
+	if again.Status != StatusCancelled || !again.FinishedAt.Equal(*cancelled.FinishedAt) {
+		t.Errorf("second DELETE = %+v, want %+v", again, cancelled)
+	}
+
+	if rec := do(t, h, http.MethodDelete, "/scans/nope", "", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE unknown scan status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestFinishedJobRetention(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		finishedAt []time.Duration // before now, of the jobs oldest first; -1 for unfinished
+		want       []int           // indexes of the jobs kept
+	}{
+		{
+			name:       "under the cap",
+			opts:       Options{MaxFinishedJobs: 3},
+			finishedAt: []time.Duration{time.Minute, time.Minute},
+			want:       []int{0, 1},
+		},
+		{
+			name:       "oldest finished forgotten first",
+			opts:       Options{MaxFinishedJobs: 2},
+			finishedAt: []time.Duration{time.Minute, time.Hour, time.Second, time.Second},
+			want:       []int{2, 3},
+		},
+		{
+			name:       "unfinished jobs kept and not counted",
+			opts:       Options{MaxFinishedJobs: 1},
+			finishedAt: []time.Duration{-1, time.Minute, -1, time.Second},
+			want:       []int{0, 2, 3},
+		},
+		{
+			name:       "expired",
+			opts:       Options{JobTTL: time.Hour},
+			finishedAt: []time.Duration{2 * time.Hour, -1, time.Hour, time.Minute},
+			want:       []int{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, tt.opts)
+			now := time.Now()
+			var jobs []*Job
+			for _, ago := range tt.finishedAt {
+				job := newJob(ScanRequest{Username: "octocat"})
+				if ago >= 0 {
+					job.finish(StatusDone, nil, "")
+					job.finished = now.Add(-ago)
+				}
+				jobs = append(jobs, job)
+				s.jobs[job.ID] = job
+				s.order = append(s.order, job.ID)
+			}
+
+			s.prune(now)
+
+			var want []string
+			for _, i := range tt.want {
+				want = append(want, jobs[i].ID)
+			}
+			if strings.Join(s.order, ",") != strings.Join(want, ",") {
+				t.Errorf("kept %v, want %v", s.order, want)
+			}
+			if len(s.jobs) != len(want) {
+				t.Errorf("%d jobs in memory, want %d", len(s.jobs), len(want))
+			}
+		})
+	}
+}
+
+// TestFinishedJobRetention_Handler checks that submitting a scan forgets the
+// finished jobs beyond the cap, which are then not found.
+func TestFinishedJobRetention_Handler(t *testing.T) {
+	h := newTestServer(t, Options{MaxFinishedJobs: 2}).Handler()
+	body := `{"username": "octocat", "full_name": "John Doe"}`
+	var ids []string
+	for range 4 {
+		var job JobView
+		do(t, h, http.MethodPost, "/scans", body, "", &job)
+		do(t, h, http.MethodDelete, "/scans/"+job.ID, "", "", nil)
+		ids = append(ids, job.ID)
+	}
+	var job JobView
+	do(t, h, http.MethodPost, "/scans", body, "", &job)
+
+	var list []JobView
+	do(t, h, http.MethodGet, "/scans", "", "", &list)
+	var got []string
+	for _, v := range list {
+		got = append(got, v.ID)
+	}
+	want := []string{job.ID, ids[3], ids[2]}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("GET /scans = %v, want %v", got, want)
+	}
+	if rec := do(t, h, http.MethodGet, "/scans/"+ids[0], "", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET forgotten scan status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,36 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// File writes each result as JSON to Dir/<username>/<timestamp>.json.
+type File struct {
+	Dir string
+}
+
+// Name returns "file".
+func (f *File) Name() string { return "file" }
+
+// Export writes the result.
+func (f *File) Export(ctx context.Context, result *models.ScanResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	path := filepath.Join(f.Dir, filepath.FromSlash(objectName(result, time.Now())))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	return nil
+}
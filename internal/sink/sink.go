@@ -0,0 +1,66 @@
+// Package sink exports scan results to external systems: HTTP webhooks,
+// S3-compatible object storage and local files.
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Sink receives the results of finished scans.
+type Sink interface {
+	Name() string
+	Export(ctx context.Context, result *models.ScanResult) error
+}
+
+// New creates the sinks configured in cfgs, which must have been validated.
+func New(cfgs []config.SinkConfig) []Sink {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var sinks []Sink
+	for _, c := range cfgs {
+		switch c.Type {
+		case "webhook":
+			sinks = append(sinks, &Webhook{URL: c.URL, Headers: c.Headers, Secret: c.Secret, PerMatch: c.PerMatch, Client: client})
+		case "s3":
+			sinks = append(sinks, &S3{
+				Endpoint:        c.Endpoint,
+				Bucket:          c.Bucket,
+				Region:          c.Region,
+				Prefix:          c.Prefix,
+				AccessKeyID:     c.AccessKeyID,
+				SecretAccessKey: c.SecretAccessKey,
+				PathStyle:       c.PathStyle,
+				Client:          client,
+			})
+		case "file":
+			sinks = append(sinks, &File{Dir: c.Dir})
+		}
+	}
+	return sinks
+}
+
+// ExportAll exports result to every sink, returning the joined errors.
+func ExportAll(ctx context.Context, sinks []Sink, result *models.ScanResult) error {
+	var errs []error
+	for _, s := range sinks {
+		if err := s.Export(ctx, result); err != nil {
+			errs = append(errs, fmt.Errorf("%s sink: %w", s.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// objectName names the exported result of a scan finished at t, as
+// <username>/<timestamp>.json.
+func objectName(result *models.ScanResult, t time.Time) string {
+	user := strings.NewReplacer("/", "_", "\\", "_").Replace(result.Username)
+	return user + "/" + t.UTC().Format("20060102T150405Z") + ".json"
+}
@@ -0,0 +1,87 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body, keyed with
+// the webhook secret, as "sha256=<hex>".
+const SignatureHeader = "X-GGSP-Signature"
+
+// EventHeader tells whether the body is a whole result ("scan") or a single
+// match ("match").
+const EventHeader = "X-GGSP-Event"
+
+// MatchEvent is the body posted for each match by a per-match webhook.
+type MatchEvent struct {
+	Username string          `json:"username"`
+	Match    models.PIIMatch `json:"match"`
+}
+
+// Webhook POSTs results as JSON to a URL.
+type Webhook struct {
+	URL     string
+	Headers map[string]string
+	// Secret, if set, signs every request body (see SignatureHeader).
+	Secret string
+	// PerMatch posts each match as a MatchEvent instead of the whole result.
+	PerMatch bool
+	Client   *http.Client
+}
+
+// Name returns "webhook".
+func (w *Webhook) Name() string { return "webhook" }
+
+// Export posts the result, or each of its matches.
+func (w *Webhook) Export(ctx context.Context, result *models.ScanResult) error {
+	if !w.PerMatch {
+		return w.post(ctx, "scan", result)
+	}
+	for _, match := range result.Matches {
+		if err := w.post(ctx, "match", MatchEvent{Username: result.Username, Match: match}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post posts v as JSON and fails on non-2xx responses.
+func (w *Webhook) post(ctx context.Context, event string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", event, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post %s: %w", event, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
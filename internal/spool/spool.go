@@ -0,0 +1,180 @@
+// Package spool keeps the matches of large scans in a temporary NDJSON file
+// rather than in memory.
+package spool
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"sync"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// maxLine caps the length of a spooled match, commit message included.
+const maxLine = 64 << 20
+
+var errClosed = errors.New("spool is closed")
+
+// Spool is a models.MatchSpool backed by a temporary file, one JSON-encoded
+// match per line. It is safe for concurrent use, but matches added while
+// iterating are not seen by the iteration.
+type Spool struct {
+	dir string
+
+	mu   sync.Mutex
+	file *os.File
+	buf  *bufio.Writer
+	n    int
+	err  error
+}
+
+// New creates an empty spool in dir, the default directory for temporary
+// files when empty.
+func New(dir string) (*Spool, error) {
+	f, err := os.CreateTemp(dir, "ggsp-spool-*.ndjson")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool: %w", err)
+	}
+	return &Spool{dir: dir, file: f, buf: bufio.NewWriter(f)}, nil
+}
+
+// Add appends match to the spool.
+func (s *Spool) Add(match models.PIIMatch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	if s.file == nil {
+		s.err = errClosed
+		return
+	}
+	data, err := json.Marshal(match)
+	if err == nil {
+		data = append(data, '\n')
+		_, err = s.buf.Write(data)
+	}
+	if err != nil {
+		s.err = fmt.Errorf("failed to spool match: %w", err)
+		return
+	}
+	s.n++
+}
+
+// Len returns the number of matches spooled.
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.n
+}
+
+// All iterates over the spooled matches in the order they were added,
+// reading them back from the file.
+func (s *Spool) All() iter.Seq[models.PIIMatch] {
+	return func(yield func(models.PIIMatch) bool) {
+		s.mu.Lock()
+		if s.err != nil || s.file == nil {
+			s.mu.Unlock()
+			return
+		}
+		if err := s.buf.Flush(); err != nil {
+			s.err = fmt.Errorf("failed to spool match: %w", err)
+			s.mu.Unlock()
+			return
+		}
+		r, err := os.Open(s.file.Name())
+		s.mu.Unlock()
+		if err != nil {
+			s.fail(fmt.Errorf("failed to read spool: %w", err))
+			return
+		}
+		defer r.Close()
+
+		sc := bufio.NewScanner(r)
+		sc.Buffer(nil, maxLine)
+		for sc.Scan() {
+			var match models.PIIMatch
+			if err := json.Unmarshal(sc.Bytes(), &match); err != nil {
+				s.fail(fmt.Errorf("failed to read spool: %w", err))
+				return
+			}
+			if !yield(match) {
+				return
+			}
+		}
+		if err := sc.Err(); err != nil {
+			s.fail(fmt.Errorf("failed to read spool: %w", err))
+		}
+	}
+}
+
+// Rewrite replaces each spooled match with the one fn returns, dropping those
+// for which it returns false. The matches are written to a new file, which
+// replaces the current one.
+func (s *Spool) Rewrite(fn func(models.PIIMatch) (models.PIIMatch, bool)) {
+	next, err := New(s.dir)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	for match := range s.All() {
+		if match, ok := fn(match); ok {
+			next.Add(match)
+		}
+	}
+	if err := s.Err(); err != nil {
+		next.Close()
+		return
+	}
+	if err := next.Err(); err != nil {
+		next.Close()
+		s.fail(err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove()
+	s.file, s.buf, s.n = next.file, next.buf, next.n
+}
+
+// Err returns the first error met writing or reading matches.
+func (s *Spool) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close deletes the spool file.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remove()
+}
+
+func (s *Spool) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// remove closes and deletes the file. s.mu must be held.
+func (s *Spool) remove() error {
+	if s.file == nil {
+		return nil
+	}
+	s.buf.Reset(io.Discard)
+	err := s.file.Close()
+	if rmErr := os.Remove(s.file.Name()); err == nil {
+		err = rmErr
+	}
+	s.file = nil
+	return err
+}
@@ -0,0 +1,77 @@
+package store
+
+// Diff compares the findings of two scans.
+type Diff struct {
+	From       ScanInfo  `json:"from"`
+	To         ScanInfo  `json:"to"`
+	New        []Finding `json:"new"`
+	Resolved   []Finding `json:"resolved"`
+	Persisting []Finding `json:"persisting"`
+}
+
+// Diff compares the findings of scan fromID against scan toID.
+func (s *Store) Diff(fromID, toID int64) (*Diff, error) {
+	from, err := s.GetScan(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.GetScan(toID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.Findings(fromID)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.Findings(toID)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Diff{
+		From:       from,
+		To:         to,
+		New:        []Finding{},
+		Resolved:   []Finding{},
+		Persisting: []Finding{},
+	}
+
+	// Findings are compared by fingerprint, unless either scan was stored
+	// before fingerprints existed
+	key := func(f Finding) string { return f.Fingerprint }
+	if !fingerprinted(before) || !fingerprinted(after) {
+		key = func(f Finding) string { return f.Key() }
+	}
+
+	seenBefore := make(map[string]bool, len(before))
+	for _, f := range before {
+		seenBefore[key(f)] = true
+	}
+	seenAfter := make(map[string]bool, len(after))
+	for _, f := range after {
+		seenAfter[key(f)] = true
+		if seenBefore[key(f)] {
+			d.Persisting = append(d.Persisting, f)
+		} else {
+			d.New = append(d.New, f)
+		}
+	}
+	for _, f := range before {
+		if !seenAfter[key(f)] {
+			d.Resolved = append(d.Resolved, f)
+		}
+	}
+
+	return d, nil
+}
+
+// fingerprinted reports whether every finding has a fingerprint.
+func fingerprinted(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Fingerprint == "" {
+			return false
+		}
+	}
+	return true
+}
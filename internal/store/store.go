@@ -0,0 +1,297 @@
+// Package store persists scan results in SQLite for historical comparison.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+
+	_ "modernc.org/sqlite" // SQLite driver
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	username    TEXT NOT NULL,
+	scanned_at  TIMESTAMP NOT NULL,
+	result_json TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_scans_username ON scans(username, scanned_at);
+
+CREATE TABLE IF NOT EXISTS findings (
+	scan_id    INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+	repository TEXT NOT NULL,
+	sha        TEXT NOT NULL,
+	field      TEXT NOT NULL,
+	matched    TEXT NOT NULL,
+	pii_type   TEXT NOT NULL,
+	confidence REAL NOT NULL,
+	url        TEXT NOT NULL,
+	fingerprint TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_findings_scan ON findings(scan_id);
+
+CREATE TABLE IF NOT EXISTS repo_states (
+	username    TEXT NOT NULL,
+	repository  TEXT NOT NULL,
+	head        TEXT NOT NULL,
+	head_date   TIMESTAMP NOT NULL,
+	etag        TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	scan_id     INTEGER NOT NULL,
+	PRIMARY KEY (username, repository)
+);
+`
+
+// Store is a SQLite-backed scan result store.
+type Store struct {
+	db *sql.DB
+}
+
+// ScanInfo describes a stored scan.
+type ScanInfo struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// Finding is a single stored match location.
+type Finding struct {
+	baseline.Entry
+	PIIType    models.PIIType `json:"pii_type"`
+	Confidence float64        `json:"confidence"`
+	URL        string         `json:"url"`
+}
+
+// Open opens (and if needed creates) a results database.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store %s: %w", path, err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate store %s: %w", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// migrate adds the columns missing from the tables of stores created by
+// earlier versions. Findings stored before fingerprints existed have none.
+func migrate(db *sql.DB) error {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('findings') WHERE name = 'fingerprint'`).Scan(&n)
+	if err != nil || n > 0 {
+		return err
+	}
+	_, err = db.Exec(`ALTER TABLE findings ADD COLUMN fingerprint TEXT NOT NULL DEFAULT ''`)
+	return err
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveScan persists a scan result and its findings, returning the scan ID.
+func (s *Store) SaveScan(result *models.ScanResult, scannedAt time.Time) (int64, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO scans (username, scanned_at, result_json) VALUES (?, ?, ?)`,
+		result.Username, scannedAt.UTC(), string(data))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert scan: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO findings
+		(scan_id, repository, sha, field, matched, pii_type, confidence, url, fingerprint)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for match := range result.AllMatches() {
+		for _, loc := range match.Locations {
+			e := baseline.EntryFor(match, loc)
+			if _, err := stmt.Exec(id, e.Repository, e.SHA, e.Field, e.Matched,
+				string(match.PIIType), match.Confidence, match.Commit.URL, e.Fingerprint); err != nil {
+				return 0, fmt.Errorf("failed to insert finding: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// ListScans returns the stored scans for a user, newest first.
+func (s *Store) ListScans(username string) ([]ScanInfo, error) {
+	rows, err := s.db.Query(`SELECT id, username, scanned_at FROM scans
+		WHERE username = ? ORDER BY scanned_at DESC, id DESC`, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scans []ScanInfo
+	for rows.Next() {
+		var info ScanInfo
+		if err := rows.Scan(&info.ID, &info.Username, &info.ScannedAt); err != nil {
+			return nil, err
+		}
+		scans = append(scans, info)
+	}
+	return scans, rows.Err()
+}
+
+// GetScan returns metadata for a stored scan.
+func (s *Store) GetScan(id int64) (ScanInfo, error) {
+	var info ScanInfo
+	err := s.db.QueryRow(`SELECT id, username, scanned_at FROM scans WHERE id = ?`, id).
+		Scan(&info.ID, &info.Username, &info.ScannedAt)
+	if err == sql.ErrNoRows {
+		return info, fmt.Errorf("scan %d not found", id)
+	}
+	return info, err
+}
+
+// LoadResult returns the full stored result of a scan.
+func (s *Store) LoadResult(id int64) (*models.ScanResult, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT result_json FROM scans WHERE id = ?`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("scan %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.ScanResult
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse stored scan %d: %w", id, err)
+	}
+	return &result, nil
+}
+
+// Findings returns the findings recorded for a scan.
+func (s *Store) Findings(scanID int64) ([]Finding, error) {
+	rows, err := s.db.Query(`SELECT repository, sha, field, matched, pii_type, confidence, url, fingerprint
+		FROM findings WHERE scan_id = ? ORDER BY repository, sha, field`, scanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var findings []Finding
+	for rows.Next() {
+		var f Finding
+		var piiType string
+		if err := rows.Scan(&f.Repository, &f.SHA, &f.Field, &f.Matched, &piiType, &f.Confidence, &f.URL, &f.Fingerprint); err != nil {
+			return nil, err
+		}
+		f.PIIType = models.PIIType(piiType)
+		findings = append(findings, f)
+	}
+	return findings, rows.Err()
+}
+
+// LoadIncremental returns what a user's next incremental scan builds on: the
+// recorded repository states, and the commit matches of each repository in
+// the scan its state was recorded with, by repository full name.
+func (s *Store) LoadIncremental(username string) (map[string]models.RepoState, map[string][]models.PIIMatch, error) {
+	rows, err := s.db.Query(`SELECT repository, head, head_date, etag, fingerprint, scan_id
+		FROM repo_states WHERE username = ?`, username)
+	if err != nil {
+		return nil, nil, err
+	}
+	states := make(map[string]models.RepoState)
+	scanIDs := make(map[string]int64)
+	for rows.Next() {
+		var repo string
+		var st models.RepoState
+		var id int64
+		if err := rows.Scan(&repo, &st.Head, &st.Date, &st.ETag, &st.Fingerprint, &id); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		states[repo] = st
+		scanIDs[repo] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	results := make(map[int64]*models.ScanResult)
+	matches := make(map[string][]models.PIIMatch)
+	for repo, id := range scanIDs {
+		result, ok := results[id]
+		if !ok {
+			if result, err = s.LoadResult(id); err != nil {
+				// The scan was deleted: rescan the repository in full
+				delete(states, repo)
+				continue
+			}
+			results[id] = result
+		}
+		for _, match := range result.Matches {
+			if match.Commit.Repository == repo {
+				matches[repo] = append(matches[repo], match)
+			}
+		}
+	}
+	return states, matches, nil
+}
+
+// SaveRepoStates records repository states for a user's next incremental
+// scan, with the ID of the scan holding their matches. Each replaces the
+// previous state of its repository.
+func (s *Store) SaveRepoStates(username string, scanID int64, states map[string]models.RepoState) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO repo_states
+		(username, repository, head, head_date, etag, fingerprint, scan_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for repo, st := range states {
+		if _, err := stmt.Exec(username, repo, st.Head, st.Date.UTC(), st.ETag, st.Fingerprint, scanID); err != nil {
+			return fmt.Errorf("failed to save state of %s: %w", repo, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"golang.org/x/term"
+)
+
+// Key codes produced by readKey.
+const (
+	keyNone = iota
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyEnter
+	keyBack
+	keyQuit
+)
+
+// Browse shows an interactive list of matches until the user quits.
+// in must be a terminal.
+func Browse(in *os.File, out io.Writer, result *models.ScanResult) error {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("result browser requires an interactive terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	fmt.Fprint(out, altScreenOn+hideCursor)
+	defer fmt.Fprint(out, showCursor+altScreenOff)
+
+	b := &browser{
+		out:    out,
+		in:     bufio.NewReader(in),
+		result: result,
+		fd:     fd,
+	}
+	return b.run()
+}
+
+type browser struct {
+	out      io.Writer
+	in       *bufio.Reader
+	result   *models.ScanResult
+	fd       int
+	selected int
+	offset   int
+	detail   bool
+}
+
+func (b *browser) run() error {
+	for {
+		b.render()
+
+		key, err := b.readKey()
+		if err != nil {
+			return err
+		}
+
+		n := len(b.result.Matches)
+		page := b.pageSize()
+		switch key {
+		case keyQuit:
+			return nil
+		case keyUp:
+			b.selected = max(b.selected-1, 0)
+		case keyDown:
+			b.selected = min(b.selected+1, max(n-1, 0))
+		case keyPageUp:
+			b.selected = max(b.selected-page, 0)
+		case keyPageDown:
+			b.selected = min(b.selected+page, max(n-1, 0))
+		case keyEnter:
+			if n > 0 {
+				b.detail = true
+			}
+		case keyBack:
+			if !b.detail {
+				return nil
+			}
+			b.detail = false
+		}
+	}
+}
+
+// readKey reads one keypress, decoding arrow-key escape sequences.
+func (b *browser) readKey() (int, error) {
+	c, err := b.in.ReadByte()
+	if err != nil {
+		return keyNone, err
+	}
+
+	switch c {
+	case 'q', 3: // Ctrl-C
+		return keyQuit, nil
+	case 'k':
+		return keyUp, nil
+	case 'j':
+		return keyDown, nil
+	case '\r', '\n', 'l':
+		return keyEnter, nil
+	case 127, 'h', 'b':
+		return keyBack, nil
+	case 27:
+		if b.in.Buffered() == 0 {
+			return keyBack, nil
+		}
+		seq := make([]byte, 2)
+		if _, err := io.ReadFull(b.in, seq); err != nil {
+			return keyNone, err
+		}
+		if seq[0] != '[' {
+			return keyNone, nil
+		}
+		switch seq[1] {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'D':
+			return keyBack, nil
+		case 'C':
+			return keyEnter, nil
+		case '5', '6':
+			b.in.ReadByte() // trailing '~'
+			if seq[1] == '5' {
+				return keyPageUp, nil
+			}
+			return keyPageDown, nil
+		}
+	}
+	return keyNone, nil
+}
+
+// size returns the terminal dimensions, with a fallback.
+func (b *browser) size() (int, int) {
+	w, h, err := term.GetSize(b.fd)
+	if err != nil || w <= 0 || h <= 0 {
+		return 80, 24
+	}
+	return w, h
+}
+
+// pageSize is the number of list rows that fit on screen.
+func (b *browser) pageSize() int {
+	_, h := b.size()
+	return max(h-4, 1)
+}
+
+func (b *browser) render() {
+	if b.detail {
+		b.renderDetail()
+	} else {
+		b.renderList()
+	}
+}
+
+func (b *browser) renderList() {
+	width, _ := b.size()
+	page := b.pageSize()
+	if b.selected < b.offset {
+		b.offset = b.selected
+	}
+	if b.selected >= b.offset+page {
+		b.offset = b.selected - page + 1
+	}
+
+	var sb strings.Builder
+	sb.WriteString(clearScreen)
+	fmt.Fprintf(&sb, "%s%d findings for %s%s\r\n\r\n", bold, len(b.result.Matches), sanitize(b.result.Username), reset)
+
+	if len(b.result.Matches) == 0 {
+		sb.WriteString("  No PII found.\r\n")
+	}
+
+	end := min(b.offset+page, len(b.result.Matches))
+	for i := b.offset; i < end; i++ {
+		m := b.result.Matches[i]
+		matched := ""
+		if len(m.Locations) > 0 {
+			matched = m.Locations[0].Matched
+		}
+		line := fmt.Sprintf(" %-30s %-8s %.2f  %q", truncate(sanitize(m.Commit.Repository), 30), shortSHA(sanitize(m.Commit.SHA)), m.Confidence, matched)
+		line = truncate(line, width-1)
+		if i == b.selected {
+			sb.WriteString(reverse + line + reset + "\r\n")
+		} else {
+			sb.WriteString(line + "\r\n")
+		}
+	}
+
+	fmt.Fprintf(&sb, "\r\n%s↑/↓ move  PgUp/PgDn page  Enter details  q quit%s", dim, reset)
+	io.WriteString(b.out, sb.String())
+}
+
+func (b *browser) renderDetail() {
+	m := b.result.Matches[b.selected]
+
+	var sb strings.Builder
+	sb.WriteString(clearScreen)
+	fmt.Fprintf(&sb, "%sFinding %d of %d%s\r\n\r\n", bold, b.selected+1, len(b.result.Matches), reset)
+	fmt.Fprintf(&sb, "Repository:  %s\r\n", sanitize(m.Commit.Repository))
+	if m.Source != "" {
+		fmt.Fprintf(&sb, "Source:      %s\r\n", sanitize(string(m.Source)))
+	}
+	fmt.Fprintf(&sb, "Commit:      %s\r\n", sanitize(m.Commit.SHA))
+	if !m.Commit.Date.IsZero() {
+		fmt.Fprintf(&sb, "Date:        %s\r\n", m.Commit.Date.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&sb, "Author:      %s <%s>\r\n", sanitize(m.Commit.Author.Name), sanitize(m.Commit.Author.Email))
+	fmt.Fprintf(&sb, "URL:         %s\r\n", sanitize(m.Commit.URL))
+	fmt.Fprintf(&sb, "Type:        %s\r\n", sanitize(string(m.PIIType)))
+	fmt.Fprintf(&sb, "Severity:    %s\r\n", m.Severity)
+	fmt.Fprintf(&sb, "Confidence:  %.2f\r\n\r\n", m.Confidence)
+
+	sb.WriteString("Locations:\r\n")
+	for _, loc := range m.Locations {
+		fmt.Fprintf(&sb, "  - %s (line %d, col %d): %q\r\n", sanitize(loc.Field), loc.Line, loc.Column, loc.Matched)
+	}
+	if m.Context != "" {
+		fmt.Fprintf(&sb, "\r\nContext:\r\n  %s\r\n", sanitize(m.Context))
+	}
+	if m.Advice != "" {
+		fmt.Fprintf(&sb, "\r\nAdvice:\r\n  %s\r\n", sanitize(m.Advice))
+	}
+	if m.Commit.Message != "" {
+		sb.WriteString("\r\nMessage:\r\n")
+		for _, line := range strings.Split(m.Commit.Message, "\n") {
+			sb.WriteString("  " + sanitize(line) + "\r\n")
+		}
+	}
+
+	fmt.Fprintf(&sb, "\r\n%s↑/↓ previous/next  ← back  q quit%s", dim, reset)
+	io.WriteString(b.out, sb.String())
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// sanitize drops the control characters from scanned text, such as escape
+// sequences in a commit message, so that they are not interpreted by the
+// terminal.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// shortSHA returns the abbreviated form of a commit SHA.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
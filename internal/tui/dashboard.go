@@ -0,0 +1,129 @@
+// Package tui provides the interactive terminal interface: a live scan
+// dashboard and a keyboard-driven result browser.
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ANSI escape sequences used by the interface.
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	hideCursor   = "\x1b[?25l"
+	showCursor   = "\x1b[?25h"
+	clearScreen  = "\x1b[H\x1b[2J"
+	bold         = "\x1b[1m"
+	reverse      = "\x1b[7m"
+	dim          = "\x1b[2m"
+	reset        = "\x1b[0m"
+)
+
+// Snapshot is the scan state shown on the dashboard.
+type Snapshot struct {
+	Username      string
+	StartedAt     time.Time
+	ReposTotal    int
+	ReposScanned  int
+	Commits       int
+	Matches       int
+	RateLimit     int
+	RateRemaining int
+	RateReset     time.Time
+}
+
+// Dashboard periodically renders a live view of scan progress.
+type Dashboard struct {
+	out      io.Writer
+	source   func() Snapshot
+	interval time.Duration
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewDashboard creates a dashboard that renders snapshots from source to out.
+func NewDashboard(out io.Writer, source func() Snapshot) *Dashboard {
+	return &Dashboard{
+		out:      out,
+		source:   source,
+		interval: 250 * time.Millisecond,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start switches to the alternate screen and begins rendering.
+func (d *Dashboard) Start() {
+	fmt.Fprint(d.out, altScreenOn+hideCursor)
+
+	d.done.Add(1)
+	go func() {
+		defer d.done.Done()
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		for {
+			d.render()
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops rendering and restores the normal screen.
+func (d *Dashboard) Stop() {
+	close(d.stop)
+	d.done.Wait()
+	fmt.Fprint(d.out, showCursor+altScreenOff)
+}
+
+func (d *Dashboard) render() {
+	snap := d.source()
+
+	elapsed := time.Duration(0)
+	if !snap.StartedAt.IsZero() {
+		elapsed = time.Since(snap.StartedAt)
+	}
+	rate := 0.0
+	if secs := elapsed.Seconds(); secs > 0 {
+		rate = float64(snap.Commits) / secs
+	}
+
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	fmt.Fprintf(&b, "%sGoGitSomePrivacy%s  scanning %s\n\n", bold, reset, snap.Username)
+	fmt.Fprintf(&b, "  Repositories  %d / %d scanned  (%d queued)\n",
+		snap.ReposScanned, snap.ReposTotal, max(snap.ReposTotal-snap.ReposScanned, 0))
+	fmt.Fprintf(&b, "  %s\n", progressBar(snap.ReposScanned, snap.ReposTotal, 40))
+	fmt.Fprintf(&b, "  Commits       %d  (%.1f/s)\n", snap.Commits, rate)
+	fmt.Fprintf(&b, "  Findings      %d\n", snap.Matches)
+	if snap.RateLimit > 0 {
+		fmt.Fprintf(&b, "  Rate limit    %d / %d remaining, resets in %s\n",
+			snap.RateRemaining, snap.RateLimit, time.Until(snap.RateReset).Round(time.Second))
+	} else {
+		b.WriteString("  Rate limit    unknown\n")
+	}
+	fmt.Fprintf(&b, "  Elapsed       %s\n\n", elapsed.Round(time.Second))
+	fmt.Fprintf(&b, "%sPress Ctrl-C to abort%s\n", dim, reset)
+
+	io.WriteString(d.out, b.String())
+}
+
+// progressBar renders a fixed-width textual progress bar.
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", width-filled) + "]"
+}
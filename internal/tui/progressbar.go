@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+)
+
+// ProgressBar renders scanner progress events as a single-line progress bar
+// with an ETA. It implements scanner.ProgressReporter.
+type ProgressBar struct {
+	out io.Writer
+
+	mu       sync.Mutex
+	start    time.Time
+	total    int
+	finished int
+	commits  int
+	matches  int
+	errors   int
+	last     time.Time
+	width    int
+}
+
+// NewProgressBar creates a progress bar writing to out (normally stderr).
+func NewProgressBar(out io.Writer) *ProgressBar {
+	return &ProgressBar{out: out}
+}
+
+// Report updates the bar with a progress event.
+func (p *ProgressBar) Report(e scanner.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch e.Type {
+	case scanner.EventScanStarted:
+		p.start = e.Time
+	case scanner.EventReposDiscovered:
+		p.total = e.Repos
+	case scanner.EventRepoFinished:
+		p.finished++
+	case scanner.EventCommitsProcessed:
+		p.commits += e.Commits
+		p.matches += e.Matches
+	case scanner.EventError:
+		p.errors++
+	case scanner.EventScanFinished:
+		p.render(true)
+		fmt.Fprintln(p.out)
+		return
+	default:
+		return
+	}
+
+	// Throttle redraws
+	if time.Since(p.last) >= 100*time.Millisecond {
+		p.render(false)
+	}
+}
+
+func (p *ProgressBar) render(final bool) {
+	p.last = time.Now()
+
+	eta := "--"
+	if final {
+		eta = "done"
+	} else if p.finished > 0 && p.total > p.finished {
+		elapsed := time.Since(p.start)
+		remaining := time.Duration(float64(elapsed) / float64(p.finished) * float64(p.total-p.finished))
+		eta = remaining.Round(time.Second).String()
+	}
+
+	pct := 0
+	if p.total > 0 {
+		pct = p.finished * 100 / p.total
+	}
+
+	line := fmt.Sprintf("%s %3d%%  %d/%d repos  %d commits  %d findings  ETA %s",
+		progressBar(p.finished, p.total, 30), pct, p.finished, p.total, p.commits, p.matches, eta)
+	if p.errors > 0 {
+		line += fmt.Sprintf("  %d errors", p.errors)
+	}
+
+	// Pad to erase leftovers from a longer previous line
+	pad := max(p.width-len(line), 0)
+	p.width = len(line)
+	fmt.Fprint(p.out, "\r"+line+strings.Repeat(" ", pad))
+}
@@ -0,0 +1,173 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/store"
+)
+
+// maxListed caps how many findings are listed in human-readable notifications.
+const maxListed = 20
+
+// Notification reports the findings that appeared since the previous scan of a user.
+type Notification struct {
+	Username  string          `json:"username"`
+	ScanID    int64           `json:"scan_id"`
+	ScannedAt time.Time       `json:"scanned_at"`
+	New       []store.Finding `json:"new"`
+}
+
+// Subject returns a one-line summary of the notification.
+func (n Notification) Subject() string {
+	return fmt.Sprintf("%d new PII finding(s) for GitHub user %s", len(n.New), n.Username)
+}
+
+// Text renders the notification as plain text.
+func (n Notification) Text() string {
+	var b strings.Builder
+	b.WriteString(n.Subject())
+	b.WriteString(":\n")
+	for i, f := range n.New {
+		if i == maxListed {
+			fmt.Fprintf(&b, "...and %d more\n", len(n.New)-maxListed)
+			break
+		}
+		fmt.Fprintf(&b, "- %s %s: %q in %s\n", f.Repository, shortSHA(f.SHA), f.Matched, f.Field)
+		if f.URL != "" {
+			fmt.Fprintf(&b, "  %s\n", f.URL)
+		}
+	}
+	return b.String()
+}
+
+// Notifier delivers notifications to one channel.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NewNotifiers creates the notifiers enabled in cfg.
+func NewNotifiers(cfg config.NotifyConfig) []Notifier {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var notifiers []Notifier
+	if cfg.Slack != nil && cfg.Slack.WebhookURL != "" {
+		notifiers = append(notifiers, &SlackNotifier{WebhookURL: cfg.Slack.WebhookURL, Client: client})
+	}
+	if cfg.Webhook != nil && cfg.Webhook.URL != "" {
+		notifiers = append(notifiers, &WebhookNotifier{URL: cfg.Webhook.URL, Headers: cfg.Webhook.Headers, Client: client})
+	}
+	if cfg.Email != nil && cfg.Email.Host != "" {
+		notifiers = append(notifiers, &EmailNotifier{Config: *cfg.Email})
+	}
+	return notifiers
+}
+
+// SlackNotifier posts to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// Name returns "slack".
+func (s *SlackNotifier) Name() string { return "slack" }
+
+// Notify posts the notification text.
+func (s *SlackNotifier) Notify(ctx context.Context, n Notification) error {
+	return postJSON(ctx, s.Client, s.WebhookURL, nil, map[string]string{"text": n.Text()})
+}
+
+// WebhookNotifier posts the notification as JSON to a URL.
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// Name returns "webhook".
+func (w *WebhookNotifier) Name() string { return "webhook" }
+
+// Notify posts the notification.
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.Client, w.URL, w.Headers, n)
+}
+
+// EmailNotifier sends the notification by email over SMTP.
+type EmailNotifier struct {
+	Config config.EmailConfig
+}
+
+// Name returns "email".
+func (e *EmailNotifier) Name() string { return "email" }
+
+// Notify sends the notification text. SMTP does not honor ctx.
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	if len(e.Config.To) == 0 {
+		return fmt.Errorf("no email recipients configured")
+	}
+	port := e.Config.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(e.Config.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if e.Config.Username != "" {
+		auth = smtp.PlainAuth("", e.Config.Username, e.Config.Password, e.Config.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.Config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.Config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", n.Subject())
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.Text(), "\n", "\r\n"))
+
+	if err := smtp.SendMail(addr, auth, e.Config.From, e.Config.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// postJSON posts v as JSON and fails on non-2xx responses.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,188 @@
+package watch
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes when the next round of scans is due.
+type Schedule interface {
+	// Next returns the first activation time strictly after t.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses a standard five-field cron expression
+// ("minute hour day-of-month month day-of-week"), one of the descriptors
+// @hourly, @daily (@midnight), @weekly and @monthly, or "@every <duration>".
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		if d < time.Minute {
+			return nil, fmt.Errorf("invalid schedule %q: interval must be at least 1m", spec)
+		}
+		return every(d), nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 cron fields", spec)
+	}
+	var c cron
+	var err error
+	if c.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: minute: %w", spec, err)
+	}
+	if c.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: hour: %w", spec, err)
+	}
+	if c.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day of month: %w", spec, err)
+	}
+	if c.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: month: %w", spec, err)
+	}
+	if c.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day of week: %w", spec, err)
+	}
+	if c.dow[7] {
+		c.dow[0] = true // 7 is Sunday too
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	c.everyHour = !slices.Contains(c.hour, false)
+	return c, nil
+}
+
+// every is a fixed-interval schedule.
+type every time.Duration
+
+// Next returns t plus the interval.
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// cron is a parsed five-field cron expression.
+type cron struct {
+	minute, hour, dom, month, dow []bool
+	domAny, dowAny                bool
+	everyHour                     bool
+}
+
+// maxSearch bounds the search for the next activation of expressions that can
+// never fire, such as "0 0 31 2 *".
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Next returns the next minute after t matching the expression in t's
+// location, or the zero time if there is none within five years. Times
+// skipped when clocks go forward do not fire that day. Times repeated when
+// they go back fire once, unless the expression fires every hour.
+func (c cron) Next(t time.Time) time.Time {
+	t = nextMinute(t, t.Hour(), t.Minute()+1)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		switch {
+		case !c.month[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !c.hour[t.Hour()]:
+			t = nextMinute(t, t.Hour()+1, 0)
+		case !c.minute[t.Minute()] && c.everyHour:
+			t = t.Add(time.Minute)
+		case !c.minute[t.Minute()]:
+			t = nextMinute(t, t.Hour(), t.Minute()+1)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// nextMinute returns hour:minute local time on the day of t. The wall clock
+// is used rather than absolute time so zones with offsets that are not whole
+// hours, such as Asia/Kolkata, land on minute 0. In the hour repeated when
+// clocks go back, where the local time may resolve to its first occurrence,
+// it returns the minute after t instead.
+func nextMinute(t time.Time, hour, minute int) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), hour, minute, 0, 0, t.Location())
+	if !next.After(t) {
+		next = t.Truncate(time.Minute).Add(time.Minute)
+	}
+	return next
+}
+
+// dayMatches follows cron semantics: when both day of month and day of week
+// are restricted, either may match.
+func (c cron) dayMatches(t time.Time) bool {
+	dom, dow := c.dom[t.Day()], c.dow[t.Weekday()]
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// parseField parses a comma-separated list of "*", "n", "a-b", each optionally
+// followed by "/step", into a lookup table indexed by value.
+func parseField(field string, min, max int) ([]bool, error) {
+	set := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			n, err := strconv.Atoi(loStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", rng)
+			}
+			lo, hi = n, n
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return nil, fmt.Errorf("invalid value %q", rng)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,166 @@
+package watch
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // zones of the tests, wherever they run
+)
+
+func TestCronNext(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		spec string
+		from string // local time in zone
+		want string // local time in zone, empty for no activation
+	}{
+		{name: "daily in UTC", zone: "UTC", spec: "@daily", from: "2024-01-01 10:00", want: "2024-01-02 00:00"},
+		{name: "every 15 minutes", zone: "UTC", spec: "*/15 * * * *", from: "2024-01-01 10:07", want: "2024-01-01 10:15"},
+		{name: "strictly after", zone: "UTC", spec: "0 9 * * *", from: "2024-01-01 09:00", want: "2024-01-02 09:00"},
+		{name: "weekday and day of month", zone: "UTC", spec: "0 0 13 * 5", from: "2024-01-01 00:00", want: "2024-01-05 00:00"},
+		{name: "never", zone: "UTC", spec: "0 0 31 2 *", from: "2024-01-01 00:00", want: ""},
+
+		// Offsets that are not whole hours
+		{name: "half-hour offset", zone: "Asia/Kolkata", spec: "0 9 * * *", from: "2024-01-01 10:00", want: "2024-01-02 09:00"},
+		{name: "half-hour offset daily", zone: "Asia/Kolkata", spec: "@daily", from: "2024-01-01 10:00", want: "2024-01-02 00:00"},
+		{name: "half-hour offset hourly", zone: "Asia/Kolkata", spec: "@hourly", from: "2024-01-01 10:20", want: "2024-01-01 11:00"},
+		{name: "quarter-hour offset", zone: "Asia/Kathmandu", spec: "0 */6 * * *", from: "2024-01-01 07:00", want: "2024-01-01 12:00"},
+		{name: "45-minute offset", zone: "Pacific/Chatham", spec: "30 8 * * 1", from: "2024-01-01 09:00", want: "2024-01-08 08:30"},
+		{name: "negative half-hour offset", zone: "America/St_Johns", spec: "0 0 1 * *", from: "2024-01-15 12:00", want: "2024-02-01 00:00"},
+
+		// Daylight saving time
+		{name: "clocks go forward", zone: "America/New_York", spec: "0 3 * * *", from: "2024-03-10 00:00", want: "2024-03-10 03:00"},
+		{name: "skipped time does not fire", zone: "America/New_York", spec: "30 2 * * *", from: "2024-03-10 00:00", want: "2024-03-11 02:30"},
+		{name: "hourly across forward", zone: "America/New_York", spec: "@hourly", from: "2024-03-10 01:30", want: "2024-03-10 03:00"},
+		{name: "clocks go back", zone: "America/New_York", spec: "0 2 * * *", from: "2024-11-03 00:30", want: "2024-11-03 02:00"},
+		{name: "half-hour offset with DST", zone: "Australia/Adelaide", spec: "0 9 * * *", from: "2024-10-06 00:00", want: "2024-10-06 09:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Fatalf("LoadLocation(%q) error = %v", tt.zone, err)
+			}
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) error = %v", tt.spec, err)
+			}
+			from, err := time.ParseInLocation("2006-01-02 15:04", tt.from, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := s.Next(from)
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Errorf("Next(%s) = %s, want none", from, got)
+				}
+				return
+			}
+			want, err := time.ParseInLocation("2006-01-02 15:04", tt.want, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", from, got, want)
+			}
+		})
+	}
+}
+
+// TestCronNextRepeatedHour checks that a time repeated when clocks go back
+// fires once unless the schedule fires every hour, and that every activation
+// is after the previous one.
+func TestCronNextRepeatedHour(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		spec string
+		want []string // activations from 2024-11-03 00:00 EDT, in UTC
+	}{
+		{
+			name: "daily in repeated hour",
+			spec: "30 1 * * *",
+			want: []string{"2024-11-03T05:30:00Z", "2024-11-04T06:30:00Z"},
+		},
+		{
+			name: "every 20 minutes through repeated hour",
+			spec: "*/20 * * * *",
+			want: []string{"2024-11-03T04:20:00Z", "2024-11-03T04:40:00Z", "2024-11-03T05:00:00Z", "2024-11-03T05:20:00Z", "2024-11-03T05:40:00Z", "2024-11-03T06:00:00Z", "2024-11-03T06:20:00Z"},
+		},
+		{
+			name: "hourly through repeated hour",
+			spec: "@hourly",
+			want: []string{"2024-11-03T05:00:00Z", "2024-11-03T06:00:00Z", "2024-11-03T07:00:00Z"},
+		},
+		{
+			name: "every 20 minutes of one hour",
+			spec: "*/20 1 * * *",
+			want: []string{"2024-11-03T05:00:00Z", "2024-11-03T05:20:00Z", "2024-11-03T05:40:00Z", "2024-11-04T06:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := time.Date(2024, 11, 3, 0, 0, 0, 0, loc)
+			for _, want := range tt.want {
+				got := s.Next(next)
+				if !got.After(next) {
+					t.Fatalf("Next(%s) = %s, not after it", next, got)
+				}
+				if got.UTC().Format(time.RFC3339) != want {
+					t.Fatalf("Next(%s) = %s, want %s", next, got.UTC().Format(time.RFC3339), want)
+				}
+				next = got
+			}
+		})
+	}
+}
+
+func TestCronNextFromRepeatedHour(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseSchedule("*/15 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 01:10 EST, the second 01:10 of the day
+	from := time.Date(2024, 11, 3, 6, 10, 0, 0, time.UTC).In(loc)
+	if got, want := s.Next(from), time.Date(2024, 11, 3, 6, 15, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%s) = %s, want %s", from, got, want)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{spec: "@daily"},
+		{spec: "@every 1h"},
+		{spec: "0 9 * * 1-5"},
+		{spec: "*/5 * * * *"},
+		{spec: "@every 30s", wantErr: true},
+		{spec: "0 9 * *", wantErr: true},
+		{spec: "60 * * * *", wantErr: true},
+		{spec: "* * * * */0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := ParseSchedule(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSchedule(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
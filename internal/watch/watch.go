@@ -0,0 +1,276 @@
+// Package watch re-scans configured users on a schedule and notifies about
+// findings that were not present in the previous scan.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/baseline"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/config"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/provider"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/sink"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/store"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+)
+
+// Watcher runs rounds of scans over the configured targets.
+type Watcher struct {
+	cfg       *config.Config
+	client    provider.Provider
+	notifiers []Notifier
+	sinks     []sink.Sink
+	logger    *slog.Logger
+}
+
+// New creates a watcher for cfg.Watch.Targets. Every scan result is exported
+// to sinks, after suppressions are applied.
+func New(cfg *config.Config, notifiers []Notifier, sinks []sink.Sink, logger *slog.Logger) (*Watcher, error) {
+	client, err := provider.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		cfg:       cfg,
+		client:    client,
+		notifiers: notifiers,
+		sinks:     sinks,
+		logger:    logger,
+	}, nil
+}
+
+// Run runs a round immediately and then whenever schedule is due, until ctx
+// is cancelled.
+func (w *Watcher) Run(ctx context.Context, schedule Schedule) error {
+	for {
+		w.RunOnce(ctx)
+
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("schedule has no further activations")
+		}
+		w.logger.Info("Next round scheduled", "at", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// RunOnce scans every target once. Failures are logged and do not stop the
+// round; it returns the number of targets that failed.
+func (w *Watcher) RunOnce(ctx context.Context) int {
+	failed := 0
+	for _, target := range w.cfg.Watch.Targets {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := w.check(ctx, target); err != nil {
+			w.logger.Error("Watch failed", "user", target.Username, "error", err)
+			failed++
+		}
+	}
+	return failed
+}
+
+// check scans a target, stores the result and notifies about new findings.
+// The first scan of a user only records a baseline.
+func (w *Watcher) check(ctx context.Context, target config.WatchTarget) error {
+	criteria, err := w.cfg.Criteria(config.SearchOptions{
+		FullName:   target.FullName,
+		FirstName:  target.FirstName,
+		LastName:   target.LastName,
+		Emails:     target.Emails,
+		Identities: target.Identities,
+		Exact:      target.Exact,
+	})
+	if err != nil {
+		return err
+	}
+	chain, err := pii.NewChain(w.cfg.Scan.PostProcessors, pii.PostProcessorOptions{
+		Allowlist:     w.cfg.Scan.Allowlist,
+		MinConfidence: w.cfg.Scan.MinConfidence,
+	})
+	if err != nil {
+		return err
+	}
+	ignoreRules, err := w.cfg.IgnoreRules()
+	if err != nil {
+		return err
+	}
+
+	rs, err := store.Open(w.cfg.Watch.Store)
+	if err != nil {
+		return err
+	}
+	defer rs.Close()
+
+	var inc *scanner.Incremental
+	if w.cfg.Scan.Incremental {
+		states, matches, err := rs.LoadIncremental(target.Username)
+		if err != nil {
+			return fmt.Errorf("failed to load repository states: %w", err)
+		}
+		inc = &scanner.Incremental{Repos: states, Matches: matches}
+	}
+
+	s := scanner.NewScanner(w.client, criteria, scanner.Config{
+		MaxWorkers:         w.cfg.Scan.MaxWorkers,
+		DetectionWorkers:   w.cfg.Scan.DetectionWorkers,
+		ContextSize:        w.cfg.Scan.ContextSize,
+		SkipForks:          w.cfg.Scan.SkipForks,
+		MinConfidence:      w.cfg.Scan.MinConfidence,
+		Discovery:          scanner.Discovery(w.cfg.Scan.Discovery),
+		CommitRoles:        w.cfg.CommitRoles(),
+		EmailDiscovery:     w.cfg.Scan.EmailDiscovery,
+		ScanEvents:         w.cfg.Scan.ScanEvents,
+		ScanRefs:           w.cfg.Scan.ScanRefs,
+		ScanFiles:          w.cfg.Scan.ScanFiles,
+		CodeSearch:         scanner.CodeSearch(w.cfg.Scan.CodeSearch),
+		PRContext:          w.cfg.Scan.PRContext,
+		CommonWords:        pii.CommonWordMode(w.cfg.Scan.CommonWords),
+		Fields:             w.cfg.Scan.Fields,
+		BotCommits:         pii.BotCommitMode(w.cfg.Scan.BotCommits),
+		ScanPages:          w.cfg.Scan.ScanPages,
+		MaxPages:           w.cfg.Scan.MaxPages,
+		RespectIgnoreFiles: w.cfg.Scan.RespectIgnoreFiles,
+		CheckEmailConfig:   w.cfg.Scan.CheckEmailConfig,
+		CheckGravatar:      w.cfg.Scan.CheckGravatar,
+		CheckSignatures:    w.cfg.Scan.CheckSignatures,
+		Associations:       w.cfg.Scan.Associations,
+		RepoTimeout:        time.Duration(w.cfg.Scan.RepoTimeoutSeconds) * time.Second,
+		MaxRepoErrors:      w.cfg.Scan.MaxRepoErrors,
+		MaxCommitsPerRepo:  w.cfg.Scan.MaxCommitsPerRepo,
+		Sample:             w.cfg.Scan.Sample,
+		Ignore:             ignoreRules,
+		PostProcessors:     chain,
+		Incremental:        inc,
+	})
+	result, err := s.ScanUser(ctx, target.Username)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	if result.Incomplete {
+		// A partial scan would report unscanned findings as resolved
+		return fmt.Errorf("scan incomplete (%s); not recorded", result.IncompleteReason)
+	}
+	if reason := failedFetches(result); reason != "" {
+		// The next round would report the findings missed as new
+		return fmt.Errorf("scan %s; not recorded", reason)
+	}
+
+	previous, err := rs.ListScans(target.Username)
+	if err != nil {
+		return fmt.Errorf("failed to list scans: %w", err)
+	}
+	scannedAt := time.Now()
+	id, err := rs.SaveScan(result, scannedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store results: %w", err)
+	}
+	if err := rs.SaveRepoStates(target.Username, id, s.RepoStates()); err != nil {
+		return fmt.Errorf("failed to store repository states: %w", err)
+	}
+
+	// Findings accepted through baselines, suppressions or triage are not news
+	st, err := baseline.LoadDir(w.cfg.State.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(w.sinks) > 0 {
+		result.Suppressed += st.Filter(result)
+		result.Summary = report.Summarize(result)
+		result.PrivacyScore = report.Score(result)
+		report.Advise(result)
+		if err := sink.ExportAll(ctx, w.sinks, result); err != nil {
+			w.logger.Error("Export failed", "user", target.Username, "error", err)
+		}
+	}
+
+	if len(previous) == 0 {
+		w.logger.Info("Recorded baseline", "user", target.Username, "scan_id", id, "matches", len(result.Matches))
+		return nil
+	}
+
+	diff, err := rs.Diff(previous[0].ID, id)
+	if err != nil {
+		return fmt.Errorf("failed to diff scans: %w", err)
+	}
+
+	var fresh []store.Finding
+	for _, f := range diff.New {
+		if !st.Suppressed(f.Entry) {
+			fresh = append(fresh, f)
+		}
+	}
+	w.logger.Info("Scanned",
+		"user", target.Username,
+		"scan_id", id,
+		"commits", result.TotalCommits,
+		"new", len(fresh),
+		"resolved", len(diff.Resolved),
+		"persisting", len(diff.Persisting))
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	return w.notify(ctx, Notification{
+		Username:  target.Username,
+		ScanID:    id,
+		ScannedAt: scannedAt,
+		New:       fresh,
+	})
+}
+
+// failedFetches describes the repositories a scan skipped or the first
+// request that failed, or returns "" if everything was fetched. Warnings that
+// are not about fetching, such as a failure to export, have no error type.
+func failedFetches(result *models.ScanResult) string {
+	switch skipped := result.SkippedRepos; len(skipped) {
+	case 0:
+	case 1:
+		return fmt.Sprintf("skipped %s (%s)", skipped[0].Repository, skipped[0].Reason)
+	default:
+		return fmt.Sprintf("skipped %d repositories, including %s (%s)", len(skipped), skipped[0].Repository, skipped[0].Reason)
+	}
+	for _, e := range result.Errors {
+		if e.Type != "" {
+			return fmt.Sprintf("failed to fetch everything (%s)", e.Message)
+		}
+	}
+	return ""
+}
+
+// notify sends n through every notifier, returning the joined errors.
+func (w *Watcher) notify(ctx context.Context, n Notification) error {
+	if len(w.notifiers) == 0 {
+		for _, f := range n.New {
+			w.logger.Info("New finding",
+				"user", n.Username,
+				"repo", f.Repository,
+				"sha", f.SHA,
+				"field", f.Field,
+				"matched", f.Matched,
+				"pii_type", f.PIIType,
+				"url", f.URL)
+		}
+		return nil
+	}
+	var errs []error
+	for _, notifier := range w.notifiers {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package watch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+func TestFailedFetches(t *testing.T) {
+	tests := []struct {
+		name   string
+		result models.ScanResult
+		want   string // substring of the reason, empty for none
+	}{
+		{name: "complete", result: models.ScanResult{}},
+		{
+			name:   "export warning",
+			result: models.ScanResult{Errors: []models.ScanError{{Message: "failed to export", Severity: "warning"}}},
+		},
+		{
+			name: "skipped repository",
+			result: models.ScanResult{
+				SkippedRepos: []models.SkippedRepo{{Repository: "octocat/hello", Reason: "rate limited"}},
+				Errors:       []models.ScanError{{Repository: "octocat/hello", Message: "rate limited", Type: models.ErrorRateLimited}},
+			},
+			want: "skipped octocat/hello (rate limited)",
+		},
+		{
+			name:   "failed step",
+			result: models.ScanResult{Errors: []models.ScanError{{Message: "event search failed", Type: models.ErrorNetwork}}},
+			want:   "event search failed",
+		},
+		{
+			name:   "permanent error",
+			result: models.ScanResult{Errors: []models.ScanError{{Message: "not found", Type: models.ErrorNotFound}}},
+			want:   "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := failedFetches(&tt.result)
+			if (got == "") != (tt.want == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("failedFetches() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,86 +2,265 @@
 package worker
 
 import (
+	"container/heap"
 	"context"
 	"sync"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/metrics"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var tracer = tracing.Tracer("github.com/h4n0sh1/GoGitSomePrivacy/internal/worker")
+
 // Task represents a unit of work to be processed.
 type Task[T any, R any] struct {
 	Input  T
 	Result R
 	Err    error
+
+	submitted time.Time
+	seq       uint64
 }
 
-// Pool manages a pool of workers for concurrent task processing.
+// Options tunes a Pool.
+type Options[T any] struct {
+	// Name labels the metrics of the pool (default "default").
+	Name string
+	// Less orders queued tasks, the least first; tasks are processed in
+	// submission order when nil or among equals.
+	Less func(a, b T) bool
+	// QueueSize is the number of tasks queued before Submit blocks
+	// (default twice the workers).
+	QueueSize int
+}
+
+// Pool manages a pool of workers for concurrent task processing. The number
+// of workers processing tasks at a time can be lowered below the number
+// started while the pool runs (see SetWorkers), so callers can match it to
+// what a downstream resource sustains.
 type Pool[T any, R any] struct {
 	workers  int
-	taskChan chan *Task[T, R]
+	opts     Options[T]
 	resultCh chan *Task[T, R]
 	wg       sync.WaitGroup
 	process  func(context.Context, T) (R, error)
+
+	mu     sync.Mutex
+	cond   *sync.Cond // signalled when the queue, the limit or active changes
+	queue  taskQueue[T, R]
+	seq    uint64
+	limit  int // workers allowed to process tasks at a time
+	active int // workers processing a task
+	closed bool
 }
 
-// NewPool creates a new worker pool.
-func NewPool[T any, R any](workers int, process func(context.Context, T) (R, error)) *Pool[T, R] {
-	return &Pool[T, R]{
+// NewPool creates a new worker pool starting workers goroutines.
+func NewPool[T any, R any](workers int, process func(context.Context, T) (R, error), opts Options[T]) *Pool[T, R] {
+	if opts.Name == "" {
+		opts.Name = "default"
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = workers * 2
+	}
+	p := &Pool[T, R]{
 		workers:  workers,
-		taskChan: make(chan *Task[T, R], workers*2),
+		opts:     opts,
 		resultCh: make(chan *Task[T, R], workers*2),
 		process:  process,
+		limit:    workers,
 	}
+	p.cond = sync.NewCond(&p.mu)
+	p.queue.less = opts.Less
+	return p
 }
 
 // Start starts the worker pool.
 func (p *Pool[T, R]) Start(ctx context.Context) {
+	metrics.AddWorkerLimit(p.opts.Name, p.workers)
+
+	// Wake up waiting workers and submitters on cancellation
+	stop := context.AfterFunc(ctx, p.broadcast)
+
 	for i := 0; i < p.workers; i++ {
 		p.wg.Add(1)
-		go p.worker(ctx)
+		go p.worker(ctx, i)
 	}
 
 	// Close result channel when all workers are done
 	go func() {
 		p.wg.Wait()
+		stop()
+		p.mu.Lock()
+		metrics.AddWorkerLimit(p.opts.Name, -p.limit)
+		metrics.AddWorkerQueueDepth(p.opts.Name, -p.queue.Len())
+		p.mu.Unlock()
 		close(p.resultCh)
 	}()
 }
 
-// worker processes tasks from the task channel.
-func (p *Pool[T, R]) worker(ctx context.Context) {
+// SetWorkers sets the number of workers processing tasks at a time, between
+// one and the number started. Workers busy beyond a lowered limit finish
+// their current task first.
+func (p *Pool[T, R]) SetWorkers(n int) {
+	n = min(max(n, 1), p.workers)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n == p.limit {
+		return
+	}
+	metrics.AddWorkerLimit(p.opts.Name, n-p.limit)
+	p.limit = n
+	p.cond.Broadcast()
+}
+
+// Workers returns the number of workers processing tasks at a time.
+func (p *Pool[T, R]) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.limit
+}
+
+// worker processes queued tasks.
+func (p *Pool[T, R]) worker(ctx context.Context, id int) {
 	defer p.wg.Done()
 
 	for {
+		task := p.next(ctx)
+		if task == nil {
+			return
+		}
+		taskCtx, span := tracer.Start(ctx, "worker.task")
+		span.SetAttributes(
+			attribute.Int("worker.id", id),
+			attribute.Float64("worker.queued_seconds", time.Since(task.submitted).Seconds()),
+		)
+		result, err := p.process(taskCtx, task.Input)
+		tracing.EndSpan(span, err)
+		task.Result = result
+		task.Err = err
+		p.release()
+
 		select {
+		case p.resultCh <- task:
 		case <-ctx.Done():
 			return
-		case task, ok := <-p.taskChan:
-			if !ok {
-				return
-			}
-			result, err := p.process(ctx, task.Input)
-			task.Result = result
-			task.Err = err
-
-			select {
-			case p.resultCh <- task:
-			case <-ctx.Done():
-				return
-			}
 		}
 	}
 }
 
-// Submit submits a task to the pool.
-func (p *Pool[T, R]) Submit(input T) {
-	p.taskChan <- &Task[T, R]{Input: input}
+// next waits until a task is queued and fewer workers than the limit are
+// busy, and takes the task. It returns nil once the pool is closed and
+// drained, or ctx is done.
+func (p *Pool[T, R]) next(ctx context.Context) *Task[T, R] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if p.queue.Len() > 0 && p.active < p.limit {
+			break
+		}
+		if p.queue.Len() == 0 && p.closed {
+			return nil
+		}
+		p.cond.Wait()
+	}
+
+	task := heap.Pop(&p.queue).(*Task[T, R])
+	p.active++
+	metrics.AddWorkerQueueDepth(p.opts.Name, -1)
+	metrics.AddWorkersActive(p.opts.Name, 1)
+	// A submitter may be waiting for room in the queue
+	p.cond.Broadcast()
+	return task
 }
 
-// Close closes the task channel.
+// release marks a worker as no longer busy.
+func (p *Pool[T, R]) release() {
+	p.mu.Lock()
+	p.active--
+	metrics.AddWorkersActive(p.opts.Name, -1)
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+func (p *Pool[T, R]) broadcast() {
+	p.mu.Lock()
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+// Submit submits a task to the pool. It gives up and returns the context's
+// error if ctx is cancelled before the task is queued.
+func (p *Pool[T, R]) Submit(ctx context.Context, input T) error {
+	stop := context.AfterFunc(ctx, p.broadcast)
+	defer stop()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.queue.Len() >= p.opts.QueueSize {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		p.cond.Wait()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.seq++
+	heap.Push(&p.queue, &Task[T, R]{Input: input, submitted: time.Now(), seq: p.seq})
+	metrics.AddWorkerQueueDepth(p.opts.Name, 1)
+	p.cond.Broadcast()
+	return nil
+}
+
+// Close marks the end of the submitted tasks. Workers exit once the queue is
+// drained.
 func (p *Pool[T, R]) Close() {
-	close(p.taskChan)
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
 }
 
 // Results returns the results channel.
 func (p *Pool[T, R]) Results() <-chan *Task[T, R] {
 	return p.resultCh
 }
+
+// taskQueue is a heap of tasks ordered by less, then by submission.
+type taskQueue[T any, R any] struct {
+	tasks []*Task[T, R]
+	less  func(a, b T) bool
+}
+
+func (q *taskQueue[T, R]) Len() int { return len(q.tasks) }
+
+func (q *taskQueue[T, R]) Less(i, j int) bool {
+	a, b := q.tasks[i], q.tasks[j]
+	if q.less != nil {
+		if q.less(a.Input, b.Input) {
+			return true
+		}
+		if q.less(b.Input, a.Input) {
+			return false
+		}
+	}
+	return a.seq < b.seq
+}
+
+func (q *taskQueue[T, R]) Swap(i, j int) { q.tasks[i], q.tasks[j] = q.tasks[j], q.tasks[i] }
+
+func (q *taskQueue[T, R]) Push(x any) { q.tasks = append(q.tasks, x.(*Task[T, R])) }
+
+func (q *taskQueue[T, R]) Pop() any {
+	n := len(q.tasks) - 1
+	t := q.tasks[n]
+	q.tasks[n] = nil
+	q.tasks = q.tasks[:n]
+	return t
+}
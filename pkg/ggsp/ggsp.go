@@ -0,0 +1,423 @@
+// Package ggsp is the public Go API of GoGitSomePrivacy. It lets other programs
+// scan a GitHub user's public commits for personally identifiable information
+// without shelling out to the CLI.
+//
+// A minimal scan:
+//
+//	client := ggsp.NewClient(ggsp.ClientOptions{Token: os.Getenv("GITHUB_TOKEN")})
+//	scanner := ggsp.NewScanner(client, ggsp.Criteria{FullName: "John Doe"}, ggsp.ScannerOptions{})
+//	result, err := scanner.ScanUser(ctx, "octocat")
+//
+// The result and model types are aliases of the types used by the CLI, so JSON
+// produced by either is interchangeable. Results are rendered in any format of
+// the CLI's --output with WriteOutput, and RegisterOutputFormat adds formats to
+// both, the CLI loading them from Go plugins given with --plugin.
+package ggsp
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/github"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/ignore"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/output"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/report"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/scanner"
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/pii"
+)
+
+// Result models.
+type (
+	ScanResult  = models.ScanResult
+	PIIMatch    = models.PIIMatch
+	PIIType     = models.PIIType
+	Severity    = models.Severity
+	Location    = models.Location
+	ScanError   = models.ScanError
+	ErrorType   = models.ErrorType
+	SkippedRepo = models.SkippedRepo
+	Cluster     = models.Cluster
+	Association = models.Association
+	Summary     = models.Summary
+	RepoCount   = models.RepoCount
+	Source      = models.Source
+	Commit      = models.Commit
+	Author      = models.Author
+	Repository  = models.Repository
+	UserProfile = models.UserProfile
+	Criteria    = models.PIISearchCriteria
+)
+
+// PII types.
+const (
+	PIITypeFullName  = models.PIITypeFullName
+	PIITypeFirstName = models.PIITypeFirstName
+	PIITypeLastName  = models.PIITypeLastName
+	PIITypeEmail     = models.PIITypeEmail
+	PIITypePhone     = models.PIITypePhone
+	PIITypeCustom    = models.PIITypeCustom
+
+	PIITypeExposedEmailConfig = models.PIITypeExposedEmailConfig
+	PIITypeGravatar           = models.PIITypeGravatar
+)
+
+// Severities.
+const (
+	SeverityLow    = models.SeverityLow
+	SeverityMedium = models.SeverityMedium
+	SeverityHigh   = models.SeverityHigh
+)
+
+// Error types.
+const (
+	ErrorRateLimited  = models.ErrorRateLimited
+	ErrorNotFound     = models.ErrorNotFound
+	ErrorAccessDenied = models.ErrorAccessDenied
+	ErrorNetwork      = models.ErrorNetwork
+	ErrorParse        = models.ErrorParse
+	ErrorOther        = models.ErrorOther
+)
+
+// Detection types.
+type (
+	Detector      = pii.Detector
+	Match         = pii.Match
+	Text          = pii.Text
+	PostProcessor = pii.PostProcessor
+	Chain         = pii.Chain
+
+	CommonWordMode = pii.CommonWordMode
+	Fields         = pii.Fields
+	BotCommitMode  = pii.BotCommitMode
+
+	Discovery  = scanner.Discovery
+	CodeSearch = scanner.CodeSearch
+
+	CommitRole = models.CommitRole
+)
+
+// Common-word modes.
+const (
+	CommonWordsDowngrade = pii.CommonWordsDowngrade
+	CommonWordsSuppress  = pii.CommonWordsSuppress
+	CommonWordsOff       = pii.CommonWordsOff
+)
+
+// Bot commit modes.
+const (
+	BotCommitsOff       = pii.BotCommitsOff
+	BotCommitsDowngrade = pii.BotCommitsDowngrade
+	BotCommitsSkip      = pii.BotCommitsSkip
+)
+
+// Commit roles.
+const (
+	RoleAuthor    = models.RoleAuthor
+	RoleCommitter = models.RoleCommitter
+	RoleCoAuthor  = models.RoleCoAuthor
+)
+
+// Discovery modes.
+const (
+	DiscoveryRepos  = scanner.DiscoveryRepos
+	DiscoverySearch = scanner.DiscoverySearch
+	DiscoveryBoth   = scanner.DiscoveryBoth
+	DiscoveryNone   = scanner.DiscoveryNone
+)
+
+// Code search scopes.
+const (
+	CodeSearchOff    = scanner.CodeSearchOff
+	CodeSearchRepos  = scanner.CodeSearchRepos
+	CodeSearchGlobal = scanner.CodeSearchGlobal
+)
+
+// Ignore rule types.
+type (
+	IgnoreRules = ignore.Rules
+	IgnoreSpec  = ignore.Spec
+)
+
+// NewIgnoreRules compiles ignore rules applied to every scanned repository.
+func NewIgnoreRules(spec IgnoreSpec) (*IgnoreRules, error) {
+	return ignore.New(spec)
+}
+
+// NewDetector creates a detector for the given criteria. contextSize is the
+// number of characters of context kept around each match.
+func NewDetector(criteria Criteria, contextSize int) *Detector {
+	return pii.NewDetector(criteria, contextSize)
+}
+
+// Progress reporting types.
+type (
+	Event            = scanner.Event
+	EventType        = scanner.EventType
+	ProgressReporter = scanner.ProgressReporter
+	ProgressFunc     = scanner.ProgressFunc
+)
+
+// Progress event types.
+const (
+	EventScanStarted      = scanner.EventScanStarted
+	EventReposDiscovered  = scanner.EventReposDiscovered
+	EventRepoStarted      = scanner.EventRepoStarted
+	EventRepoFinished     = scanner.EventRepoFinished
+	EventCommitsProcessed = scanner.EventCommitsProcessed
+	EventMatchFound       = scanner.EventMatchFound
+	EventError            = scanner.EventError
+	EventInfo             = scanner.EventInfo
+	EventScanFinished     = scanner.EventScanFinished
+)
+
+// ClientOptions configures a GitHub API client.
+type ClientOptions struct {
+	// Token is a GitHub personal access token. Empty means unauthenticated.
+	Token string
+	// Tokens are rotated with Token, each request using the one with the
+	// most rate limit left.
+	Tokens []string
+	// RateLimitPerSecond caps API requests per second and token (default 1).
+	RateLimitPerSecond float64
+	// RateLimitBurst is the number of requests sent at once before
+	// RateLimitPerSecond paces them (default 1).
+	RateLimitBurst int
+	// SearchRateLimitPerMinute and CodeSearchRateLimitPerMinute cap search
+	// requests per minute and token, apart from other requests (default 30
+	// and 10).
+	SearchRateLimitPerMinute     float64
+	CodeSearchRateLimitPerMinute float64
+	// Timeout is the per-request HTTP timeout (default 30s).
+	Timeout time.Duration
+	// PageConcurrency is the number of pages of a repository's commit
+	// listing fetched at a time (default 4).
+	PageConcurrency int
+}
+
+// Client is a rate-limited GitHub API client.
+type Client struct {
+	client *github.Client
+}
+
+// NewClient creates a GitHub API client.
+func NewClient(opts ClientOptions) *Client {
+	client, _ := NewEnterpriseClient(opts, "", "")
+	return client
+}
+
+// NewEnterpriseClient creates a client for the GitHub Enterprise Server
+// instance at baseURL, e.g. https://github.example.com/. An empty uploadURL
+// defaults to baseURL; an empty baseURL selects github.com.
+func NewEnterpriseClient(opts ClientOptions, baseURL, uploadURL string) (*Client, error) {
+	client, err := github.NewClient(github.ClientConfig{
+		Token:                        opts.Token,
+		Tokens:                       opts.Tokens,
+		RateLimitPerSecond:           opts.RateLimitPerSecond,
+		RateLimitBurst:               opts.RateLimitBurst,
+		SearchRateLimitPerMinute:     opts.SearchRateLimitPerMinute,
+		CodeSearchRateLimitPerMinute: opts.CodeSearchRateLimitPerMinute,
+		Timeout:                      opts.Timeout,
+		PageConcurrency:              opts.PageConcurrency,
+		BaseURL:                      baseURL,
+		UploadURL:                    uploadURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{client: client}, nil
+}
+
+// GetUser retrieves a user's public profile.
+func (c *Client) GetUser(ctx context.Context, username string) (*UserProfile, error) {
+	return c.client.GetUser(ctx, username)
+}
+
+// ScannerOptions configures a Scanner. Zero values select defaults.
+type ScannerOptions struct {
+	// MaxWorkers caps the repositories fetched concurrently (default 10);
+	// fewer are fetched when the rate limit cannot keep them busy.
+	MaxWorkers int
+	// ContextSize is the characters of context kept around matches (default 50).
+	ContextSize int
+	// MaxRepos limits how many repositories are scanned (0 means all).
+	MaxRepos int
+	// MaxCommitsPerRepo limits scanning to the latest commits of each repository (0 means all).
+	MaxCommitsPerRepo int
+	// Sample, if set, scans about this many commits of each repository
+	// picked at random across its history instead, for a quick estimate of
+	// the exposure. With Bitbucket the latest commits are scanned.
+	Sample int
+	// RepoTimeout skips repositories that take longer than this to fetch
+	// (0 means no limit).
+	RepoTimeout time.Duration
+	// MaxRepoErrors is the number of consecutive failed attempts at fetching
+	// a repository, or at another request, after which it is given up on
+	// (default 3). Only rate limits and network errors are retried.
+	MaxRepoErrors int
+	// SkipForks excludes forked repositories from the scan.
+	SkipForks bool
+	// Discovery selects how repositories are found (default DiscoveryRepos).
+	Discovery Discovery
+	// EmailDiscovery also searches GitHub for commits authored with the
+	// criteria emails, whichever account made them.
+	EmailDiscovery bool
+	// ScanEvents also scans the user's recent public GitHub events: pushed
+	// commits and the text of comments, issues, pull requests, releases and
+	// created repositories.
+	ScanEvents bool
+	// ScanRefs also scans release notes, annotated tag messages and branch
+	// names.
+	ScanRefs bool
+	// ScanFiles also scans package manifests, author lists and GitHub
+	// Actions workflows.
+	ScanFiles bool
+	// CodeSearch also searches file contents for the criteria's emails and
+	// full names, in the user's repositories or globally. It requires a
+	// token.
+	CodeSearch CodeSearch
+	// PRContext links the matches found in commit messages to the pull
+	// requests including their commit, and scans the title and description
+	// of those pull requests.
+	PRContext bool
+	// CommitRoles selects the commits scanned by the user's role on them
+	// (default RoleAuthor only).
+	CommitRoles []CommitRole
+	// MinConfidence drops findings whose confidence is below this value.
+	MinConfidence float64
+	// CommonWords selects how single common words matched outside the author
+	// and committer names are treated (default downgrade).
+	CommonWords CommonWordMode
+	// Fields toggles the commit fields scanned by name, such as
+	// "author_name" (default all, see pii.CommitFields).
+	Fields Fields
+	// BotCommits selects how bot-generated and templated commits, such as
+	// dependency updates and merge commits, are treated (default off).
+	BotCommits BotCommitMode
+	// DetectionWorkers is the number of goroutines scanning fetched commits
+	// (default GOMAXPROCS).
+	DetectionWorkers int
+	// ScanPages includes gh-pages branches and the published Pages site.
+	ScanPages bool
+	// PagesURL overrides the Pages site URL.
+	PagesURL string
+	// MaxPages caps how many Pages site URLs are fetched (default 100).
+	MaxPages int
+	// RespectIgnoreFiles honors .gogitsomeprivacyignore files in owned repos.
+	RespectIgnoreFiles bool
+	// CheckEmailConfig flags commits made with a personal email address
+	// instead of the GitHub noreply one, as exposed_email_config matches.
+	CheckEmailConfig bool
+	// CheckGravatar flags commit emails and hashes, such as those of avatar
+	// URLs, sharing the Gravatar hash of a criteria email, as gravatar
+	// matches.
+	CheckGravatar bool
+	// CheckSignatures flags the user IDs of the GPG keys commits were signed
+	// with that expose a personal email or a criteria name the commits do
+	// not show, as signature findings. It requires a GitHub client.
+	CheckSignatures bool
+	// Associations reports the public organizations of the user, and the
+	// CODEOWNERS and .mailmap entries of the scanned repositories linking
+	// the username to an identity, in ScanResult.Associations.
+	Associations bool
+	// Ignore holds known-safe strings, regexes, paths and repositories that
+	// are never reported. Build it with NewIgnoreRules.
+	Ignore *IgnoreRules
+	// PostProcessors run in order over the matches of every commit. Nil uses
+	// the default chain. They must be safe for concurrent use.
+	PostProcessors Chain
+	// Progress receives progress events, including every match as it is found.
+	Progress ProgressReporter
+}
+
+// Scanner scans a user's public commits for PII.
+type Scanner struct {
+	scanner *scanner.Scanner
+}
+
+// NewScanner creates a scanner for the given criteria.
+func NewScanner(client *Client, criteria Criteria, opts ScannerOptions) *Scanner {
+	return &Scanner{
+		scanner: scanner.NewScanner(client.client, criteria, scanner.Config{
+			MaxWorkers:         opts.MaxWorkers,
+			ContextSize:        opts.ContextSize,
+			MaxRepos:           opts.MaxRepos,
+			MaxCommitsPerRepo:  opts.MaxCommitsPerRepo,
+			Sample:             opts.Sample,
+			RepoTimeout:        opts.RepoTimeout,
+			MaxRepoErrors:      opts.MaxRepoErrors,
+			SkipForks:          opts.SkipForks,
+			Discovery:          opts.Discovery,
+			CommitRoles:        opts.CommitRoles,
+			EmailDiscovery:     opts.EmailDiscovery,
+			ScanEvents:         opts.ScanEvents,
+			ScanRefs:           opts.ScanRefs,
+			ScanFiles:          opts.ScanFiles,
+			CodeSearch:         opts.CodeSearch,
+			PRContext:          opts.PRContext,
+			MinConfidence:      opts.MinConfidence,
+			CommonWords:        opts.CommonWords,
+			Fields:             opts.Fields,
+			BotCommits:         opts.BotCommits,
+			DetectionWorkers:   opts.DetectionWorkers,
+			ScanPages:          opts.ScanPages,
+			PagesURL:           opts.PagesURL,
+			MaxPages:           opts.MaxPages,
+			RespectIgnoreFiles: opts.RespectIgnoreFiles,
+			CheckEmailConfig:   opts.CheckEmailConfig,
+			CheckGravatar:      opts.CheckGravatar,
+			CheckSignatures:    opts.CheckSignatures,
+			Associations:       opts.Associations,
+			Ignore:             opts.Ignore,
+			PostProcessors:     opts.PostProcessors,
+			Progress:           opts.Progress,
+		}),
+	}
+}
+
+// ScanUser scans all public commits by username. Each match carries advice
+// on how to remediate it.
+func (s *Scanner) ScanUser(ctx context.Context, username string) (*ScanResult, error) {
+	result, err := s.scanner.ScanUser(ctx, username)
+	if result != nil {
+		report.Advise(result)
+	}
+	return result, err
+}
+
+// ScanIdentity scans for traces of the criteria's emails and full names
+// without an account, such as one deleted or renamed: commits found by author
+// email and name, and public code mentioning them. Code search requires a
+// token.
+func (s *Scanner) ScanIdentity(ctx context.Context) (*ScanResult, error) {
+	result, err := s.scanner.ScanIdentity(ctx)
+	if result != nil {
+		report.Advise(result)
+	}
+	return result, err
+}
+
+// Output rendering types.
+type (
+	OutputWriter     = output.Writer
+	OutputWriterFunc = output.WriterFunc
+	OutputFormat     = output.Format
+	OutputOptions    = output.Options
+)
+
+// RegisterOutputFormat adds an output format, usually from an init function.
+// It panics if the name or an alias of f is already taken.
+func RegisterOutputFormat(f OutputFormat) {
+	output.Register(f)
+}
+
+// OutputFormats lists the names of the registered output formats.
+func OutputFormats() []string {
+	return output.Names()
+}
+
+// WriteOutput renders result to w in the named output format.
+func WriteOutput(w io.Writer, result *ScanResult, format string, opts OutputOptions) error {
+	return output.Write(w, result, format, opts)
+}
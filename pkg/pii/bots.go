@@ -0,0 +1,99 @@
+package pii
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// BotCommitMode selects how commits classified by IsBotCommit are treated.
+type BotCommitMode string
+
+const (
+	// BotCommitsOff scans them like any other commit (the default).
+	BotCommitsOff BotCommitMode = "off"
+	// BotCommitsDowngrade lowers the confidence of their matches.
+	BotCommitsDowngrade BotCommitMode = "downgrade"
+	// BotCommitsSkip does not scan them.
+	BotCommitsSkip BotCommitMode = "skip"
+)
+
+// ParseBotCommitMode validates a bot commit mode; empty selects off.
+func ParseBotCommitMode(s string) (BotCommitMode, error) {
+	switch mode := BotCommitMode(strings.ToLower(s)); mode {
+	case "":
+		return BotCommitsOff, nil
+	case BotCommitsOff, BotCommitsDowngrade, BotCommitsSkip:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid bot commits mode %q: use off, downgrade or skip", s)
+}
+
+// botCommitWeight scales the matches of bot-generated and templated commits,
+// whose text is mostly boilerplate around branch and package names.
+const botCommitWeight = 0.6
+
+// botAccounts are the login and name prefixes of common automation accounts
+// committing without the [bot] suffix of GitHub Apps.
+var botAccounts = []string{
+	"dependabot", "renovate", "github-actions", "greenkeeper", "snyk-bot",
+	"pre-commit-ci", "imgbot", "allcontributors", "semantic-release-bot",
+	"mergify", "pyup-bot", "whitesource", "depfu",
+}
+
+// botMessage matches the first line of the messages generated by merges,
+// dependency updates and release tooling.
+var botMessage = regexp.MustCompile(`^(?:` +
+	`Merge (?:pull request #\d+|branch '|branches '|remote-tracking branch '|tag '|commit '|[0-9a-f]{7,40}\b)` +
+	`|(?:\S+: )?(?:[Bb]ump|[Uu]pdate) \S+ (?:from \S+ )?to v?\d\S*$` +
+	`|(?:chore|build|fix|ci)\(deps(?:-dev)?\): ` +
+	`|[Uu]pdate (?:dependency|module|all dependencies|actions/)` +
+	`|\[(?:Snyk|pre-commit\.ci|ImgBot)\]` +
+	`|chore\(release\): ` +
+	`|Revert "Merge )`)
+
+// IsBotCommit reports whether a commit is bot-generated or templated rather
+// than written by a person: it was authored by an automation account, such
+// as Dependabot, Renovate or any GitHub App login ending in [bot], or its
+// message follows the template of a merge commit or a dependency update.
+func IsBotCommit(commit *models.Commit) bool {
+	if isBotAccount(commit.Author) {
+		return true
+	}
+	subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	return botMessage.MatchString(strings.TrimSpace(subject))
+}
+
+// isBotAccount reports whether a commit author is an automation account.
+func isBotAccount(a models.Author) bool {
+	local, _, _ := strings.Cut(a.Email, "@")
+	for _, s := range []string{a.Login, a.Name, local} {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" {
+			continue
+		}
+		if strings.HasSuffix(s, "[bot]") {
+			return true
+		}
+		// e.g. 49699333+dependabot[bot] in noreply addresses
+		if _, name, ok := strings.Cut(s, "+"); ok {
+			s = name
+		}
+		for _, bot := range botAccounts {
+			if strings.HasPrefix(s, bot) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MarkBotCommit marks matches as found in a bot-generated or templated
+// commit, lowering their score (see ScoreMatch).
+func MarkBotCommit(matches []Match) {
+	for i := range matches {
+		matches[i].BotCommit = true
+	}
+}
@@ -0,0 +1,60 @@
+package pii
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Regexp's \b only knows ASCII word characters: it finds no boundary around
+// "José" or "محمد" and none at all in CJK text. Names are matched without it
+// (see literalMatcher) and each match is checked with atWordBoundaries instead.
+
+// apostrophes and hyphens are the variants a name may be written with, e.g.
+// O'Brien and O’Brien, or Jean-Claude with a non-breaking hyphen.
+const (
+	apostrophes = `'’ʼ‘`
+	hyphens     = `-‐‑–`
+)
+
+// unspacedScripts are written without spaces between words, so names in them
+// are matched anywhere in a run of letters.
+var unspacedScripts = []*unicode.RangeTable{
+	unicode.Han, unicode.Hiragana, unicode.Katakana,
+	unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar,
+}
+
+// isWordRune reports whether r belongs to a word: a letter, a digit, a
+// combining mark or an underscore.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+// spacedWordRune reports whether r belongs to a word of a script that
+// separates words with spaces.
+func spacedWordRune(r rune) bool {
+	return isWordRune(r) && !unicode.In(r, unspacedScripts...)
+}
+
+// atWordBoundaries reports whether text[start:end] is a whole word or run of
+// words rather than part of a longer one. Each side is only checked when both
+// the match and its neighbour are written with spaces between words. Korean
+// particles attach to the end of names (김철수가), so a Hangul name may be
+// followed by more Hangul.
+func atWordBoundaries(text string, start, end int) bool {
+	if start > 0 {
+		first, _ := utf8.DecodeRuneInString(text[start:])
+		prev, _ := utf8.DecodeLastRuneInString(text[:start])
+		if spacedWordRune(first) && spacedWordRune(prev) {
+			return false
+		}
+	}
+	if end < len(text) {
+		last, _ := utf8.DecodeLastRuneInString(text[:end])
+		next, _ := utf8.DecodeRuneInString(text[end:])
+		if spacedWordRune(last) && spacedWordRune(next) &&
+			!(unicode.Is(unicode.Hangul, last) && unicode.Is(unicode.Hangul, next)) {
+			return false
+		}
+	}
+	return true
+}
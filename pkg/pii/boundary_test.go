@@ -0,0 +1,110 @@
+package pii
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+func TestAtWordBoundaries(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		match string // first occurrence in text
+		want  bool
+	}{
+		// Latin
+		{name: "whole word", text: "fixed by Doe today", match: "Doe", want: true},
+		{name: "start of text", text: "Doe fixed it", match: "Doe", want: true},
+		{name: "end of text", text: "fixed by Doe", match: "Doe", want: true},
+		{name: "punctuation around", text: "(Doe), thanks", match: "Doe", want: true},
+		{name: "prefix of longer word", text: "Doerte fixed it", match: "Doe", want: false},
+		{name: "suffix of longer word", text: "McDoe fixed it", match: "Doe", want: false},
+		{name: "inside longer word", text: "undoered", match: "doe", want: false},
+		{name: "accented neighbour", text: "Josée", match: "José", want: false},
+		{name: "accented name", text: "merci José!", match: "José", want: true},
+		{name: "digit neighbour", text: "Doe2", match: "Doe", want: false},
+		{name: "underscore neighbour", text: "jane_doe", match: "doe", want: false},
+		{name: "combining mark neighbour", text: "Doé", match: "Doe", want: false},
+
+		// Apostrophes and hyphens
+		{name: "apostrophe name", text: "by O'Brien.", match: "O'Brien", want: true},
+		{name: "curly apostrophe name", text: "by O’Brien.", match: "O’Brien", want: true},
+		{name: "possessive", text: "O'Brien's patch", match: "O'Brien", want: true},
+		{name: "part of apostrophe name", text: "O'Brien", match: "Brien", want: true},
+		{name: "hyphenated name", text: "Jean-Claude Van Damme", match: "Jean-Claude", want: true},
+		{name: "part of hyphenated name", text: "Jean-Claude", match: "Claude", want: true},
+		{name: "non-breaking hyphen", text: "Jean‑Claude", match: "Jean‑Claude", want: true},
+
+		// Cyrillic
+		{name: "Cyrillic word", text: "исправил Иванов вчера", match: "Иванов", want: true},
+		{name: "Cyrillic inside word", text: "Ивановский", match: "Иванов", want: false},
+		{name: "Cyrillic case ending", text: "Иванова", match: "Иванов", want: false},
+
+		// Arabic
+		{name: "Arabic word", text: "شكرا محمد على", match: "محمد", want: true},
+		{name: "Arabic attached prefix", text: "ومحمد", match: "محمد", want: false},
+		{name: "Arabic punctuation", text: "شكرا، محمد.", match: "محمد", want: true},
+
+		// Scripts without spaces
+		{name: "Han in sentence", text: "修复由王小明提交的问题", match: "王小明", want: true},
+		{name: "Han at start", text: "王小明修复", match: "王小明", want: true},
+		{name: "Japanese", text: "山田太郎さんが修正", match: "山田太郎", want: true},
+		{name: "Thai", text: "แก้ไขโดยสมชาย", match: "สมชาย", want: true},
+		{name: "Han next to Latin", text: "by王小明", match: "王小明", want: true},
+
+		// Hangul
+		{name: "Hangul with subject particle", text: "김철수가 수정함", match: "김철수", want: true},
+		{name: "Hangul with object particle", text: "김철수를 추가", match: "김철수", want: true},
+		{name: "Hangul standalone", text: "작성자 김철수", match: "김철수", want: true},
+		{name: "Hangul preceded by Hangul", text: "박김철수", match: "김철수", want: false},
+		{name: "Hangul followed by Latin", text: "김철수abc", match: "김철수", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := strings.Index(tt.text, tt.match)
+			if start < 0 {
+				t.Fatalf("%q not in %q", tt.match, tt.text)
+			}
+			if got := atWordBoundaries(tt.text, start, start+len(tt.match)); got != tt.want {
+				t.Errorf("atWordBoundaries(%q, %q) = %v, want %v", tt.text, tt.match, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetector_NamesInScripts(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		text     string
+		want     []string // matched full names, in order
+	}{
+		{name: "apostrophe variant", fullName: "Conan O'Brien", text: "Thanks Conan O’Brien", want: []string{"Conan O’Brien"}},
+		{name: "modifier apostrophe", fullName: "Conan O’Brien", text: "by Conan Oʼbrien", want: []string{"Conan Oʼbrien"}},
+		{name: "hyphen variant", fullName: "Jean-Claude Duval", text: "Jean‑Claude Duval wrote", want: []string{"Jean‑Claude Duval"}},
+		{name: "extra whitespace", fullName: "Jean-Claude Duval", text: "Jean-Claude\n  Duval", want: []string{"Jean-Claude\n  Duval"}},
+		{name: "embedded in longer word", fullName: "Ann Lee", text: "Joann Leeds", want: nil},
+		{name: "Cyrillic", fullName: "Иван Петров", text: "автор: иван петров", want: []string{"иван петров"}},
+		{name: "Arabic", fullName: "محمد علي", text: "كتبه محمد علي اليوم", want: []string{"محمد علي"}},
+		{name: "CJK without spaces", fullName: "王小明", text: "修复由王小明提交", want: []string{"王小明"}},
+		{name: "Hangul with particle", fullName: "김철수", text: "김철수가 수정", want: []string{"김철수"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(models.PIISearchCriteria{FullName: tt.fullName}, 20)
+			var got []string
+			for _, m := range d.DetectInText(tt.text, "message") {
+				if m.Type == models.PIITypeFullName {
+					got = append(got, m.Text)
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("DetectInText(%q) full names = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
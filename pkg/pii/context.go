@@ -0,0 +1,63 @@
+package pii
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ContextWindow returns the match text[start:end] with up to size characters
+// of text on each side, whitespace collapsed. The window never splits a UTF-8
+// character, and it is narrowed to whole words unless the word cut into holds
+// the match or the text has no spaces, as in Chinese or Japanese.
+func ContextWindow(text string, start, end, size int) string {
+	from := start
+	for n := 0; n < size && from > 0; n++ {
+		_, w := utf8.DecodeLastRuneInString(text[:from])
+		from -= w
+	}
+	if midWord(text, from) {
+		if i := strings.IndexFunc(text[from:start], unicode.IsSpace); i >= 0 {
+			from += i
+		}
+	}
+
+	to := end
+	for n := 0; n < size && to < len(text); n++ {
+		_, w := utf8.DecodeRuneInString(text[to:])
+		to += w
+	}
+	if midWord(text, to) {
+		if i := strings.LastIndexFunc(text[end:to], unicode.IsSpace); i >= 0 {
+			to = end + i
+		}
+	}
+
+	return strings.Join(strings.Fields(text[from:to]), " ")
+}
+
+// midWord reports whether cutting text at i splits a word.
+func midWord(text string, i int) bool {
+	if i <= 0 || i >= len(text) {
+		return false
+	}
+	before, _ := utf8.DecodeLastRuneInString(text[:i])
+	after, _ := utf8.DecodeRuneInString(text[i:])
+	return !unicode.IsSpace(before) && !unicode.IsSpace(after)
+}
+
+// TrimContext narrows a context returned by ContextWindow to size characters
+// on each side of matched, the text it was extracted around. The context is
+// returned unchanged when matched cannot be found in it.
+func TrimContext(context, matched string, size int) string {
+	matched = strings.Join(strings.Fields(matched), " ")
+	if matched == "" {
+		return context
+	}
+	loc := regexp.MustCompile("(?i)" + regexp.QuoteMeta(matched)).FindStringIndex(context)
+	if loc == nil {
+		return context
+	}
+	return ContextWindow(context, loc[0], loc[1], size)
+}
@@ -2,26 +2,33 @@
 package pii
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
 	"strings"
-	"unicode"
+	"sync"
 
 	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
 )
 
-// Detector detects personally identifiable information in text.
+// Detector detects personally identifiable information in text. Its patterns
+// are compiled once by NewDetector and never modified afterwards, so a single
+// Detector is safe for concurrent use by multiple goroutines.
 type Detector struct {
 	criteria      models.PIISearchCriteria
-	patterns      map[models.PIIType]*regexp.Regexp
+	patterns      []identityPattern
+	literals      *literalMatcher // names and emails, by index in patterns
+	rules         []compiledRule
+	gravatars     map[string]gravatarEmail // by MD5 and SHA-256 hash
 	caseSensitive bool
 	contextSize   int
+	fields        Fields
 }
 
 // NewDetector creates a new PII detector.
 func NewDetector(criteria models.PIISearchCriteria, contextSize int) *Detector {
 	d := &Detector{
 		criteria:      criteria,
-		patterns:      make(map[models.PIIType]*regexp.Regexp),
 		caseSensitive: criteria.CaseSensitive,
 		contextSize:   contextSize,
 	}
@@ -29,34 +36,131 @@ func NewDetector(criteria models.PIISearchCriteria, contextSize int) *Detector {
 	return d
 }
 
-// compilePatterns compiles regex patterns for the search criteria.
+// WithFields returns a copy of d scanning only the enabled commit fields in
+// DetectInCommit and DetectGravatar. The copy shares the compiled patterns.
+func (d *Detector) WithFields(fields Fields) *Detector {
+	c := *d
+	c.fields = fields
+	return &c
+}
+
+// identityPattern is a name or email pattern of one identity, searched as
+// one or more literals.
+type identityPattern struct {
+	identity string
+	piiType  models.PIIType
+	alias    string // the nickname searched instead of the first name, if any
+}
+
+// compiledRule is a user-defined rule ready for matching.
+type compiledRule struct {
+	name    string
+	piiType models.PIIType
+	weight  float64
+	re      *regexp.Regexp
+}
+
+// CompileRule compiles a user-defined rule's pattern, honoring its case sensitivity.
+func CompileRule(rule models.Rule) (*regexp.Regexp, error) {
+	pattern := rule.Pattern
+	if !rule.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern for rule %q: %w", rule.Name, err)
+	}
+	return re, nil
+}
+
+// compilePatterns compiles the patterns for the search criteria: names and
+// emails into a single literal matcher, user-defined rules into regular
+// expressions.
 func (d *Detector) compilePatterns() {
-	flags := ""
-	if !d.caseSensitive {
-		flags = "(?i)"
+	d.literals = newLiteralMatcher()
+	for _, id := range d.criteria.AllIdentities() {
+		d.compileIdentity(id)
+	}
+	d.literals.build()
+
+	// User-defined rules; invalid patterns are rejected by config validation
+	for _, rule := range d.criteria.Rules {
+		re, err := CompileRule(rule)
+		if err != nil {
+			continue
+		}
+		piiType := rule.PIIType
+		if piiType == "" {
+			piiType = models.PIITypeCustom
+		}
+		d.rules = append(d.rules, compiledRule{name: rule.Name, piiType: piiType, weight: rule.Weight, re: re})
+	}
+}
+
+// addPattern adds a pattern of an identity searched as the given literals.
+// Names match any apostrophe or hyphen variant and any whitespace between
+// words; emails match only themselves, ignoring case.
+func (d *Detector) addPattern(p identityPattern, literals ...string) {
+	id := len(d.patterns)
+	d.patterns = append(d.patterns, p)
+	for _, l := range literals {
+		if p.piiType == models.PIITypeEmail {
+			d.literals.add(l, id, true, false)
+		} else {
+			d.literals.add(l, id, !d.caseSensitive, true)
+		}
 	}
+}
 
-	// Full name pattern with word boundaries
-	if d.criteria.FullName != "" {
-		pattern := flags + `\b` + regexp.QuoteMeta(d.criteria.FullName) + `\b`
-		if re, err := regexp.Compile(pattern); err == nil {
-			d.patterns[models.PIITypeFullName] = re
+// compileIdentity compiles the name and email patterns of a single identity.
+func (d *Detector) compileIdentity(id models.Identity) {
+	// Names, matched as whole words (see atWordBoundaries)
+	names := []struct {
+		piiType models.PIIType
+		value   string
+	}{
+		{models.PIITypeFullName, id.FullName},
+		{models.PIITypeFirstName, id.FirstName},
+		{models.PIITypeLastName, id.LastName},
+	}
+	for _, n := range names {
+		if n.value == "" {
+			continue
 		}
+		d.addPattern(identityPattern{identity: id.Name, piiType: n.piiType}, n.value)
+	}
+	if d.criteria.ExpandNicknames {
+		d.compileNicknames(id)
 	}
 
-	// First name pattern with word boundaries
-	if d.criteria.FirstName != "" {
-		pattern := flags + `\b` + regexp.QuoteMeta(d.criteria.FirstName) + `\b`
-		if re, err := regexp.Compile(pattern); err == nil {
-			d.patterns[models.PIITypeFirstName] = re
+	// Email addresses, combined into a single pattern
+	var emails []string
+	for _, email := range id.Emails {
+		if email = strings.TrimSpace(email); email != "" {
+			emails = append(emails, email)
 		}
 	}
+	if len(emails) > 0 {
+		d.addPattern(identityPattern{identity: id.Name, piiType: models.PIITypeEmail}, emails...)
+		d.addGravatar(id.Name, emails)
+	}
+}
+
+// compileNicknames compiles patterns for the nicknames of an identity's first
+// name, and for its full name with a nickname in place of the first word.
+func (d *Detector) compileNicknames(id models.Identity) {
+	compile := func(piiType models.PIIType, value, alias string) {
+		d.addPattern(identityPattern{identity: id.Name, piiType: piiType, alias: alias}, value)
+	}
 
-	// Last name pattern with word boundaries
-	if d.criteria.LastName != "" {
-		pattern := flags + `\b` + regexp.QuoteMeta(d.criteria.LastName) + `\b`
-		if re, err := regexp.Compile(pattern); err == nil {
-			d.patterns[models.PIITypeLastName] = re
+	if id.FirstName != "" {
+		for _, alias := range Nicknames(id.FirstName) {
+			compile(models.PIITypeFirstName, alias, alias)
+		}
+	}
+	if words := strings.Fields(id.FullName); len(words) >= 2 {
+		for _, alias := range Nicknames(words[0]) {
+			compile(models.PIITypeFullName, strings.Join(append([]string{alias}, words[1:]...), " "), alias)
 		}
 	}
 }
@@ -71,65 +175,178 @@ type Match struct {
 	Field   string
 	Line    int
 	Column  int
+
+	// Confidence is the per-match score assigned by the score post-processor.
+	Confidence float64
+
+	// Rule is the name of the user-defined rule that produced the match, and
+	// Weight its base confidence. Both are empty for built-in patterns.
+	Rule   string
+	Weight float64
+
+	// Identity is the name of the identity whose pattern matched, empty for
+	// the unnamed primary identity and for rules.
+	Identity string
+
+	// Alias is the nickname of the first name that matched, when nicknames
+	// are expanded (see Nicknames). Such matches score lower.
+	Alias string
+
+	// CommonWord is set when the match is a single dictionary-common word
+	// outside the author and committer names, such as "Young" in a message.
+	CommonWord bool
+
+	// BotCommit is set on the matches of bot-generated and templated
+	// commits when they are downgraded (see IsBotCommit). Such matches
+	// score lower.
+	BotCommit bool
+}
+
+// trailerFields pools the line to field maps of DetectInCommit.
+var trailerFields = sync.Pool{
+	New: func() any { return make(map[int]string) },
 }
 
-// DetectInCommit detects PII in a commit.
+// DetectInCommit detects PII in the enabled fields of a commit (see
+// WithFields). The author name is scanned when the user authored the commit
+// and the committer name when they committed it; both are scanned for
+// commits without roles.
 func (d *Detector) DetectInCommit(commit *models.Commit) []Match {
-	var matches []Match
+	matches := d.detectInMessage(commit)
+
+	roles := commit.Roles
+	authorName := d.fields.Enabled(FieldAuthorName) && commit.Author.Name != "" &&
+		(len(roles) == 0 || slices.Contains(roles, models.RoleAuthor))
+	if authorName {
+		matches = d.detectInText(matches, commit.Author.Name, FieldAuthorName)
+	}
+	if d.fields.Enabled(FieldCommitterName) && commit.Committer.Name != "" &&
+		(len(roles) == 0 || slices.Contains(roles, models.RoleCommitter)) &&
+		(!authorName || commit.Committer.Name != commit.Author.Name) {
+		matches = d.detectInText(matches, commit.Committer.Name, FieldCommitterName)
+	}
 
-	// Check commit message
-	msgMatches := d.detectInText(commit.Message, "message")
-	matches = append(matches, msgMatches...)
+	return matches
+}
 
-	// Check author name
-	if commit.Author.Name != "" {
-		authorMatches := d.detectInText(commit.Author.Name, "author_name")
-		matches = append(matches, authorMatches...)
+// detectInMessage detects PII in the message of a commit, attributing
+// matches on trailer lines to the trailer and keeping only those of the
+// enabled fields.
+func (d *Detector) detectInMessage(commit *models.Commit) []Match {
+	message, trailerLines := d.fields.Enabled(FieldMessage), d.fields.Enabled(FieldTrailers)
+	if !message && !trailerLines {
+		return nil
+	}
+	matches := d.detectInText(nil, commit.Message, FieldMessage)
+	if len(matches) == 0 {
+		return nil
+	}
+	trailers := commit.Trailers
+	if trailers == nil {
+		trailers = models.ParseTrailers(commit.Message)
+	}
+	if len(trailers) == 0 {
+		if !message {
+			return nil
+		}
+		return matches
 	}
 
-	// Check committer name
-	if commit.Committer.Name != "" && commit.Committer.Name != commit.Author.Name {
-		committerMatches := d.detectInText(commit.Committer.Name, "committer_name")
-		matches = append(matches, committerMatches...)
+	fields := trailerFields.Get().(map[int]string)
+	for _, t := range trailers {
+		for line := t.Line; line < t.Line+max(t.Lines, 1); line++ {
+			fields[line] = models.TrailerField(t.Key)
+		}
+	}
+	kept := matches[:0]
+	for _, m := range matches {
+		field, ok := fields[m.Line]
+		if ok {
+			m.Field = field
+			m.CommonWord = !isIdentityField(field) && IsCommonWord(m.Text)
+		}
+		if (ok && trailerLines) || (!ok && message) {
+			kept = append(kept, m)
+		}
 	}
+	clear(fields)
+	trailerFields.Put(fields)
+	return kept
+}
 
-	return matches
+// DetectInText detects PII in arbitrary text, attributing matches to field.
+func (d *Detector) DetectInText(text, field string) []Match {
+	return d.detectInText(nil, text, field)
 }
 
-// detectInText detects PII in a text string.
-func (d *Detector) detectInText(text, field string) []Match {
-	var matches []Match
+// Text is a piece of text to scan and the field its matches are attributed to.
+type Text struct {
+	Text  string
+	Field string
+}
 
-	for piiType, pattern := range d.patterns {
-		if pattern == nil {
+// DetectInTexts detects PII in several texts at once, returning the matches
+// in the order of texts. Empty texts are skipped.
+func (d *Detector) DetectInTexts(texts []Text) []Match {
+	var matches []Match
+	for _, t := range texts {
+		if t.Text == "" {
 			continue
 		}
+		matches = d.detectInText(matches, t.Text, t.Field)
+	}
+	return matches
+}
+
+// literalHits pools the literal occurrences found by detectInText.
+var literalHits = sync.Pool{
+	New: func() any { return new([]literalHit) },
+}
+
+// detectInText appends the PII found in a text string to dst.
+func (d *Detector) detectInText(dst []Match, text, field string) []Match {
+	if !d.literals.empty() {
+		hits := literalHits.Get().(*[]literalHit)
+		*hits = d.literals.findAll((*hits)[:0], text)
+		for _, h := range *hits {
+			if !atWordBoundaries(text, h.start, h.end) {
+				continue
+			}
+			p := d.patterns[h.id]
+			dst = append(dst, d.newMatch(Match{Type: p.piiType, Identity: p.identity, Alias: p.alias}, text, field, h.start, h.end))
+		}
+		literalHits.Put(hits)
+	}
 
-		allMatches := pattern.FindAllStringIndex(text, -1)
-		for _, loc := range allMatches {
-			start, end := loc[0], loc[1]
-			matchedText := text[start:end]
-
-			// Calculate line and column
-			line, col := d.getLineCol(text, start)
-
-			// Extract context
-			context := d.extractContext(text, start, end)
-
-			matches = append(matches, Match{
-				Type:    piiType,
-				Text:    matchedText,
-				Start:   start,
-				End:     end,
-				Context: context,
-				Field:   field,
-				Line:    line,
-				Column:  col,
-			})
+	for _, rule := range d.rules {
+		proto := Match{Type: rule.piiType, Rule: rule.name, Weight: rule.weight}
+		for _, loc := range rule.re.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			dst = append(dst, d.newMatch(proto, text, field, loc[0], loc[1]))
 		}
 	}
 
-	return matches
+	return dst
+}
+
+// newMatch returns the match of text[start:end], copying Type, Rule, Weight,
+// Identity and Alias from proto.
+func (d *Detector) newMatch(proto Match, text, field string, start, end int) Match {
+	// Calculate line and column
+	line, col := d.getLineCol(text, start)
+
+	m := proto
+	m.Text = text[start:end]
+	m.Start = start
+	m.End = end
+	m.Context = d.extractContext(text, start, end)
+	m.Field = field
+	m.Line = line
+	m.Column = col
+	m.CommonWord = !isIdentityField(field) && IsCommonWord(m.Text)
+	return m
 }
 
 // getLineCol calculates line and column numbers for a position.
@@ -147,88 +364,33 @@ func (d *Detector) getLineCol(text string, pos int) (int, int) {
 	return line, pos - lastNewline + 1
 }
 
-// extractContext extracts surrounding context for a match.
+// extractContext extracts the context of a match (see ContextWindow).
 func (d *Detector) extractContext(text string, start, end int) string {
-	ctxStart := start - d.contextSize
-	if ctxStart < 0 {
-		ctxStart = 0
-	}
-
-	ctxEnd := end + d.contextSize
-	if ctxEnd > len(text) {
-		ctxEnd = len(text)
-	}
-
-	// Trim to word boundaries
-	ctx := text[ctxStart:ctxEnd]
-
-	// Clean up whitespace
-	ctx = strings.Join(strings.Fields(ctx), " ")
-
-	return ctx
+	return ContextWindow(text, start, end, d.contextSize)
 }
 
-// CalculateConfidence calculates a confidence score for matches.
+// CalculateConfidence calculates a confidence score for the matches in one
+// commit: the best ScoreMatch, raised slightly for each corroborating match.
 func CalculateConfidence(matches []Match) float64 {
 	if len(matches) == 0 {
 		return 0.0
 	}
 
-	// Base confidence
-	confidence := 0.7
+	confidence := 0.0
+	for _, m := range matches {
+		confidence = max(confidence, ScoreMatch(m))
+	}
 
 	// More matches = higher confidence
 	if len(matches) > 1 {
 		confidence += 0.05 * float64(min(len(matches)-1, 3))
 	}
 
-	// Full name match is higher confidence
-	for _, m := range matches {
-		if m.Type == models.PIITypeFullName {
-			confidence += 0.1
-			break
-		}
-	}
-
-	// Matches in author field are higher confidence
-	for _, m := range matches {
-		if m.Field == "author_name" || m.Field == "committer_name" {
-			confidence += 0.05
-			break
-		}
-	}
-
-	// Cap at 1.0
-	if confidence > 1.0 {
-		confidence = 1.0
-	}
-
-	return confidence
+	return min(confidence, 1.0)
 }
 
-// IsLikelyFalsePositive checks if a match is likely a false positive.
+// IsLikelyFalsePositive checks if a match is likely a false positive: part of
+// a longer word, with the Unicode-aware rules names are matched with.
 func IsLikelyFalsePositive(match Match, text string) bool {
-	// Check if the match is part of a larger word (shouldn't happen with word boundaries, but double-check)
-	if match.Start > 0 {
-		prevChar := rune(text[match.Start-1])
-		if unicode.IsLetter(prevChar) || unicode.IsDigit(prevChar) {
-			return true
-		}
-	}
-
-	if match.End < len(text) {
-		nextChar := rune(text[match.End])
-		if unicode.IsLetter(nextChar) || unicode.IsDigit(nextChar) {
-			return true
-		}
-	}
-
-	return false
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
+	return !atWordBoundaries(text, match.Start, match.End)
 }
@@ -0,0 +1,96 @@
+package pii
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// TestDetector_Concurrent checks that a Detector shared by many goroutines,
+// including the pools of DetectInCommit, finds what it finds sequentially.
+// Run it with -race.
+func TestDetector_Concurrent(t *testing.T) {
+	criteria := benchmarkCriteria
+	criteria.Rules = []models.Rule{{Name: "ticket", Pattern: `JIRA-\d+`}}
+	d := NewDetector(criteria, 20)
+	trailersOnly, err := ParseFields([]string{FieldTrailers, FieldAuthorName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		detector *Detector
+		commit   *models.Commit
+	}{
+		{name: "long message with trailers", detector: d, commit: benchmarkCommit()},
+		{
+			name:     "parsed trailers",
+			detector: d,
+			commit: &models.Commit{
+				Message: "Fix JIRA-12 for Bob\n\nCo-authored-by: Robert O'Brien <robert@example.com>\nAcked-by: Bobby Tables\n",
+				Author:  models.Author{Name: "Roberta Jean-Smith"},
+			},
+		},
+		{
+			name:     "given trailers",
+			detector: d,
+			commit: &models.Commit{
+				Message:  "Fix it\n\nSigned-off-by: Robert O'Brien <robert@example.com>\n",
+				Trailers: models.ParseTrailers("Fix it\n\nSigned-off-by: Robert O'Brien <robert@example.com>\n"),
+				Roles:    []models.CommitRole{models.RoleCommitter},
+			},
+		},
+		{name: "trailers only", detector: d.WithFields(trailersOnly), commit: benchmarkCommit()},
+		{
+			name:     "no trailers",
+			detector: d,
+			commit:   &models.Commit{Message: "Thanks O’Brien, see bob.obrien@example.org", Committer: models.Author{Name: "Bob"}},
+		},
+		{name: "nothing found", detector: d, commit: &models.Commit{Message: "Refactor the scanner"}},
+	}
+
+	want := make([][]Match, len(tests))
+	for i, tt := range tests {
+		want[i] = tt.detector.DetectInCommit(tt.commit)
+	}
+
+	const goroutines, rounds = 16, 20
+	var wg sync.WaitGroup
+	errs := make(chan string, goroutines*len(tests))
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range rounds {
+				// start each goroutine at a different commit
+				i := (g + r) % len(tests)
+				tt := tests[i]
+				if got := tt.detector.DetectInCommit(tt.commit); !reflect.DeepEqual(got, want[i]) {
+					errs <- tt.name
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for name := range errs {
+		t.Errorf("%s: concurrent DetectInCommit differs from sequential", name)
+	}
+}
+
+// BenchmarkDetectInCommitParallel measures a Detector shared by all
+// goroutines, as in a scan with concurrent repository workers.
+func BenchmarkDetectInCommitParallel(b *testing.B) {
+	d := NewDetector(benchmarkCriteria, 50)
+	commit := benchmarkCommit()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			d.DetectInCommit(commit)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package pii
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/gpg"
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// DetectEmailConfig flags the personal email addresses a commit was made with
+// by the user scanned: the author email when the user authored it and the
+// committer email when the user committed it, unless they are a GitHub
+// noreply address. Commits without roles are treated as authored. Matches
+// have the exposed_email_config type, whether or not any name matched.
+// Fields disabled in fields are skipped.
+func DetectEmailConfig(commit *models.Commit, fields Fields) []Match {
+	roles := commit.Roles
+	if len(roles) == 0 {
+		roles = []models.CommitRole{models.RoleAuthor}
+	}
+
+	var matches []Match
+	authorEmail := fields.Enabled(FieldAuthorEmail)
+	if authorEmail && slices.Contains(roles, models.RoleAuthor) && IsPersonalEmail(commit.Author.Email) {
+		matches = append(matches, emailConfigMatch(commit.Author.Email, FieldAuthorEmail))
+	}
+	if fields.Enabled(FieldCommitterEmail) && slices.Contains(roles, models.RoleCommitter) && IsPersonalEmail(commit.Committer.Email) &&
+		(!authorEmail || !strings.EqualFold(commit.Committer.Email, commit.Author.Email)) {
+		matches = append(matches, emailConfigMatch(commit.Committer.Email, FieldCommitterEmail))
+	}
+	return matches
+}
+
+// IsPersonalEmail reports whether email is set and is not a noreply address
+// of GitHub or GitHub Enterprise Server (users.noreply.<host>), nor the
+// address of commits made on the web.
+func IsPersonalEmail(email string) bool {
+	_, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if !ok || domain == "" {
+		return false
+	}
+	if strings.HasPrefix(domain, "users.noreply.") {
+		return false
+	}
+	return !strings.EqualFold(email, "noreply@github.com")
+}
+
+func emailConfigMatch(email, field string) Match {
+	return Match{
+		Type:    models.PIITypeExposedEmailConfig,
+		Text:    email,
+		End:     len(email),
+		Context: email,
+		Field:   field,
+		Line:    1,
+		Column:  1,
+	}
+}
+
+// DetectSignerEmails flags the personal email addresses of the user IDs in
+// text, one per line, of the key a commit was signed with. Matches have the
+// exposed_email_config type, whether or not any name matched.
+func DetectSignerEmails(text, field string) []Match {
+	var matches []Match
+	offset := 0
+	for i, uid := range strings.Split(text, "\n") {
+		_, email := gpg.ParseUserID(uid)
+		if start := strings.Index(uid, email); IsPersonalEmail(email) && start >= 0 {
+			matches = append(matches, Match{
+				Type:    models.PIITypeExposedEmailConfig,
+				Text:    email,
+				Start:   offset + start,
+				End:     offset + start + len(email),
+				Context: uid,
+				Field:   field,
+				Line:    i + 1,
+				Column:  start + 1,
+			})
+		}
+		offset += len(uid) + 1
+	}
+	return matches
+}
@@ -0,0 +1,74 @@
+package pii
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Commit fields that can be left out of scans (see Fields).
+const (
+	FieldMessage        = "message"
+	FieldTrailers       = "trailers" // the trailer lines of the message, such as Signed-off-by
+	FieldAuthorName     = "author_name"
+	FieldCommitterName  = "committer_name"
+	FieldAuthorEmail    = "author_email"
+	FieldCommitterEmail = "committer_email"
+)
+
+// CommitFields are the commit fields Fields toggles, in scan order.
+var CommitFields = []string{
+	FieldMessage, FieldTrailers, FieldAuthorName, FieldCommitterName, FieldAuthorEmail, FieldCommitterEmail,
+}
+
+// Fields toggles the commit fields scanned by name. Fields missing from the
+// map are scanned, so a nil Fields scans every field. The message and its
+// trailers are toggled separately: with message off and trailers on, only
+// the trailer lines of the message are scanned.
+type Fields map[string]bool
+
+// Enabled reports whether field is scanned.
+func (f Fields) Enabled(field string) bool {
+	on, ok := f[field]
+	return !ok || on
+}
+
+// Disabled returns the commit fields left out of scans, in the order of
+// CommitFields.
+func (f Fields) Disabled() []string {
+	var disabled []string
+	for _, field := range CommitFields {
+		if !f.Enabled(field) {
+			disabled = append(disabled, field)
+		}
+	}
+	return disabled
+}
+
+// ParseFields returns the Fields scanning only the given commit fields,
+// e.g. from a comma-separated command-line list.
+func ParseFields(names []string) (Fields, error) {
+	fields := make(Fields, len(CommitFields))
+	for _, field := range CommitFields {
+		fields[field] = false
+	}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if err := ValidateField(name); err != nil {
+			return nil, err
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// ValidateField checks that name is one of CommitFields.
+func ValidateField(name string) error {
+	if slices.Contains(CommitFields, name) {
+		return nil
+	}
+	if name == "diff" {
+		return fmt.Errorf("invalid field %q: commit diffs are not fetched, so they are never scanned", name)
+	}
+	return fmt.Errorf("invalid field %q: use %s", name, strings.Join(CommitFields, ", "))
+}
@@ -0,0 +1,123 @@
+package pii
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// gravatarHash matches the hex MD5 and SHA-256 hashes Gravatar identifies
+// addresses by, as found in avatar URLs.
+var gravatarHash = regexp.MustCompile(`\b(?:[0-9a-fA-F]{64}|[0-9a-fA-F]{32})\b`)
+
+// gravatarEmail is a personal email of the criteria, by its Gravatar hashes.
+type gravatarEmail struct {
+	identity string
+	email    string
+}
+
+// GravatarHashes returns the hashes Gravatar identifies email by: the hex
+// MD5 and SHA-256 of the trimmed, lowercased address.
+func GravatarHashes(email string) (md5Hex, sha256Hex string) {
+	normalized := []byte(strings.ToLower(strings.TrimSpace(email)))
+	m := md5.Sum(normalized)
+	s := sha256.Sum256(normalized)
+	return hex.EncodeToString(m[:]), hex.EncodeToString(s[:])
+}
+
+// addGravatar indexes the Gravatar hashes of an identity's emails.
+func (d *Detector) addGravatar(identity string, emails []string) {
+	for _, email := range emails {
+		if d.gravatars == nil {
+			d.gravatars = make(map[string]gravatarEmail)
+		}
+		m, s := GravatarHashes(email)
+		d.gravatars[m] = gravatarEmail{identity: identity, email: email}
+		d.gravatars[s] = gravatarEmail{identity: identity, email: email}
+	}
+}
+
+// DetectGravatar flags what links a commit to the emails of the criteria
+// through their Gravatar hashes, whether or not any name matched: the author
+// email when the user authored it and the committer email when the user
+// committed it, if they hash like one of the emails searched for, and hashes
+// of the emails in the message, as in avatar URLs. Commits without roles are
+// treated as authored, and fields disabled with WithFields are skipped.
+// Matches have the gravatar type.
+func (d *Detector) DetectGravatar(commit *models.Commit) []Match {
+	if len(d.gravatars) == 0 {
+		return nil
+	}
+	roles := commit.Roles
+	if len(roles) == 0 {
+		roles = []models.CommitRole{models.RoleAuthor}
+	}
+
+	var matches []Match
+	authorEmail := d.fields.Enabled(FieldAuthorEmail)
+	if authorEmail && slices.Contains(roles, models.RoleAuthor) {
+		matches = d.gravatarEmailMatch(matches, commit.Author.Email, FieldAuthorEmail)
+	}
+	if d.fields.Enabled(FieldCommitterEmail) && slices.Contains(roles, models.RoleCommitter) &&
+		(!authorEmail || !strings.EqualFold(commit.Committer.Email, commit.Author.Email)) {
+		matches = d.gravatarEmailMatch(matches, commit.Committer.Email, FieldCommitterEmail)
+	}
+	if !d.fields.Enabled(FieldMessage) {
+		return matches
+	}
+	return d.detectGravatarHashes(matches, commit.Message, FieldMessage)
+}
+
+// DetectGravatarInTexts flags the Gravatar hashes of the emails of the
+// criteria found in texts, in the order of texts.
+func (d *Detector) DetectGravatarInTexts(texts []Text) []Match {
+	if len(d.gravatars) == 0 {
+		return nil
+	}
+	var matches []Match
+	for _, t := range texts {
+		matches = d.detectGravatarHashes(matches, t.Text, t.Field)
+	}
+	return matches
+}
+
+// gravatarEmailMatch appends the match of a commit email hashing like an
+// email of the criteria to dst.
+func (d *Detector) gravatarEmailMatch(dst []Match, email, field string) []Match {
+	if strings.TrimSpace(email) == "" {
+		return dst
+	}
+	m, _ := GravatarHashes(email)
+	ge, ok := d.gravatars[m]
+	if !ok {
+		return dst
+	}
+	return append(dst, Match{
+		Type:     models.PIITypeGravatar,
+		Text:     email,
+		End:      len(email),
+		Context:  "gravatar " + m + " of " + ge.email,
+		Field:    field,
+		Line:     1,
+		Column:   1,
+		Identity: ge.identity,
+	})
+}
+
+// detectGravatarHashes appends the Gravatar hashes of the emails of the
+// criteria found in text to dst.
+func (d *Detector) detectGravatarHashes(dst []Match, text, field string) []Match {
+	for _, loc := range gravatarHash.FindAllStringIndex(text, -1) {
+		ge, ok := d.gravatars[strings.ToLower(text[loc[0]:loc[1]])]
+		if !ok {
+			continue
+		}
+		dst = append(dst, d.newMatch(Match{Type: models.PIITypeGravatar, Identity: ge.identity}, text, field, loc[0], loc[1]))
+	}
+	return dst
+}
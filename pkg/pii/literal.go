@@ -0,0 +1,262 @@
+package pii
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// literalMatcher finds many literal patterns in a single pass over the text
+// with the Aho-Corasick algorithm, instead of running one regular expression
+// per name, nickname and email. Text is matched as a stream of symbols: runes
+// folded to a single case, apostrophe and hyphen variants replaced by one of
+// them, and runs of whitespace collapsed into a single space. Literals that
+// are case sensitive or must match their punctuation exactly are checked
+// against the text once found.
+type literalMatcher struct {
+	nodes    []literalNode
+	literals []literal
+}
+
+// literalNode is a state of the automaton.
+type literalNode struct {
+	next map[rune]int32
+	fail int32
+	out  []int32 // literals ending here, including through fail links
+}
+
+// literal is a pattern of the matcher.
+type literal struct {
+	id       int    // returned with the hits of the literal
+	size     int    // length in symbols
+	exact    string // text to compare a hit with, if any (see normalizeLiteral)
+	fold     bool
+	variants bool
+}
+
+// literalHit is an occurrence of a literal in text.
+type literalHit struct {
+	id         int
+	start, end int
+	literal    int
+}
+
+// newLiteralMatcher returns an empty matcher.
+func newLiteralMatcher() *literalMatcher {
+	return &literalMatcher{nodes: []literalNode{{}}}
+}
+
+// add adds a literal pattern whose hits carry id. With fold the case of the
+// text is ignored, and with variants any apostrophe or hyphen variant and any
+// run of whitespace between words match alike. Empty patterns are ignored.
+// Without variants a pattern must not contain whitespace, which the text
+// stream collapses: emails, the only such patterns, never do. The matcher
+// must be built before use.
+func (m *literalMatcher) add(pattern string, id int, fold, variants bool) {
+	if variants {
+		pattern = strings.Join(strings.Fields(pattern), " ")
+	}
+	if pattern == "" {
+		return
+	}
+
+	state := int32(0)
+	size := 0
+	for _, r := range pattern {
+		sym := literalSymbol(r)
+		next, ok := m.nodes[state].next[sym]
+		if !ok {
+			next = int32(len(m.nodes))
+			m.nodes = append(m.nodes, literalNode{})
+			if m.nodes[state].next == nil {
+				m.nodes[state].next = make(map[rune]int32)
+			}
+			m.nodes[state].next[sym] = next
+		}
+		state = next
+		size++
+	}
+
+	l := literal{id: id, size: size, fold: fold, variants: variants}
+	if !fold || !variants {
+		l.exact = normalizeLiteral(pattern, fold, variants)
+	}
+	m.nodes[state].out = append(m.nodes[state].out, int32(len(m.literals)))
+	m.literals = append(m.literals, l)
+}
+
+// build computes the fail links of the automaton, breadth first.
+func (m *literalMatcher) build() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for sym, child := range m.nodes[state].next {
+			fail := m.nodes[state].fail
+			for {
+				if next, ok := m.nodes[fail].next[sym]; ok && next != child {
+					fail = next
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			m.nodes[child].fail = fail
+			m.nodes[child].out = append(m.nodes[child].out, m.nodes[fail].out...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// empty reports whether the matcher has no literals.
+func (m *literalMatcher) empty() bool {
+	return len(m.literals) == 0
+}
+
+// findAll appends the occurrences of the literals in text to dst, ordered by
+// id, then by position. Like regexp's FindAll, the occurrences of an id do
+// not overlap, the leftmost winning, then the literal added first.
+func (m *literalMatcher) findAll(dst []literalHit, text string) []literalHit {
+	from := len(dst)
+	state := int32(0)
+	prevSpace := false
+	for i, r := range text {
+		space := isLiteralSpace(r)
+		if space && prevSpace {
+			continue
+		}
+		prevSpace = space
+
+		sym := literalSymbol(r)
+		for {
+			if next, ok := m.nodes[state].next[sym]; ok {
+				state = next
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = m.nodes[state].fail
+		}
+
+		end := i + utf8.RuneLen(r)
+		for _, li := range m.nodes[state].out {
+			l := m.literals[li]
+			start := symbolsBefore(text, end, l.size)
+			if l.exact != "" && normalizeLiteral(text[start:end], l.fold, l.variants) != l.exact {
+				continue
+			}
+			dst = append(dst, literalHit{id: l.id, start: start, end: end, literal: int(li)})
+		}
+	}
+
+	hits := dst[from:]
+	slices.SortFunc(hits, func(a, b literalHit) int {
+		if a.id != b.id {
+			return a.id - b.id
+		}
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return a.literal - b.literal
+	})
+
+	// Drop the occurrences overlapping an earlier one of the same id
+	kept := dst[:from]
+	for _, h := range hits {
+		if n := len(kept); n > from && kept[n-1].id == h.id && h.start < kept[n-1].end {
+			continue
+		}
+		kept = append(kept, h)
+	}
+	return kept
+}
+
+// symbolsBefore returns the offset in text of the symbol n symbols before end.
+func symbolsBefore(text string, end, n int) int {
+	start := end
+	for ; n > 0 && start > 0; n-- {
+		r, size := utf8.DecodeLastRuneInString(text[:start])
+		start -= size
+		if !isLiteralSpace(r) {
+			continue
+		}
+		for start > 0 {
+			r, size := utf8.DecodeLastRuneInString(text[:start])
+			if !isLiteralSpace(r) {
+				break
+			}
+			start -= size
+		}
+	}
+	return start
+}
+
+// literalSymbol maps r to its symbol in the automaton.
+func literalSymbol(r rune) rune {
+	switch {
+	case isLiteralSpace(r):
+		return ' '
+	case strings.ContainsRune(apostrophes, r):
+		return '\''
+	case strings.ContainsRune(hyphens, r):
+		return '-'
+	}
+	return foldRune(r)
+}
+
+// normalizeLiteral returns s as compared by a literal: folded to a single
+// case with fold, with variants replaced and whitespace collapsed with
+// variants.
+func normalizeLiteral(s string, fold, variants bool) string {
+	var b strings.Builder
+	prevSpace := false
+	for _, r := range s {
+		if variants {
+			space := isLiteralSpace(r)
+			if space && prevSpace {
+				continue
+			}
+			prevSpace = space
+			if space || strings.ContainsRune(apostrophes, r) || strings.ContainsRune(hyphens, r) {
+				r = literalSymbol(r)
+			}
+		}
+		if fold {
+			r = foldRune(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// foldRune returns the smallest rune r is equivalent to when case is ignored,
+// the same equivalence as regexp's (?i) flag.
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return r
+	}
+	least := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		least = min(least, f)
+	}
+	return least
+}
+
+// isLiteralSpace reports whether r is whitespace as matched by regexp's \s.
+func isLiteralSpace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\f', '\r':
+		return true
+	}
+	return false
+}
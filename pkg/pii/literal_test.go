@@ -0,0 +1,317 @@
+package pii
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// literalPattern is a pattern of the literal matcher tests: the literals
+// searched as one id, like the emails of an identity.
+type literalPattern struct {
+	literals []string
+	fold     bool
+	variants bool
+}
+
+// regexpLiteral returns the regular expression names and emails were
+// searched with before literalMatcher: any apostrophe or hyphen variant and
+// any run of whitespace between words with variants, (?i) with fold, and an
+// alternation of the literals.
+func regexpLiteral(p literalPattern) *regexp.Regexp {
+	var alts []string
+	for _, l := range p.literals {
+		if !p.variants {
+			alts = append(alts, regexp.QuoteMeta(l))
+			continue
+		}
+		var b strings.Builder
+		for i, word := range strings.Fields(l) {
+			if i > 0 {
+				b.WriteString(`\s+`)
+			}
+			for _, r := range word {
+				switch {
+				case strings.ContainsRune(apostrophes, r):
+					b.WriteString("[" + apostrophes + "]")
+				case strings.ContainsRune(hyphens, r):
+					b.WriteString("[" + hyphens + "]")
+				default:
+					b.WriteString(regexp.QuoteMeta(string(r)))
+				}
+			}
+		}
+		alts = append(alts, b.String())
+	}
+	flags := ""
+	if p.fold {
+		flags = "(?i)"
+	}
+	return regexp.MustCompile(flags + "(?:" + strings.Join(alts, "|") + ")")
+}
+
+// regexpHits returns the hits of patterns in text found with regexpLiteral,
+// in the order of findAll.
+func regexpHits(patterns []literalPattern, text string) []literalHit {
+	var hits []literalHit
+	for id, p := range patterns {
+		for _, loc := range regexpLiteral(p).FindAllStringIndex(text, -1) {
+			if loc[0] < loc[1] {
+				hits = append(hits, literalHit{id: id, start: loc[0], end: loc[1]})
+			}
+		}
+	}
+	return hits
+}
+
+// matcherHits returns the hits of patterns in text found with a
+// literalMatcher, without the literal index regexpHits cannot know.
+func matcherHits(patterns []literalPattern, text string) []literalHit {
+	m := newLiteralMatcher()
+	for id, p := range patterns {
+		for _, l := range p.literals {
+			m.add(l, id, p.fold, p.variants)
+		}
+	}
+	m.build()
+	hits := m.findAll(nil, text)
+	for i := range hits {
+		hits[i].literal = 0
+	}
+	return hits
+}
+
+func TestLiteralMatcher_FindAll(t *testing.T) {
+	name := func(literals ...string) literalPattern {
+		return literalPattern{literals: literals, fold: true, variants: true}
+	}
+	exactName := func(literals ...string) literalPattern {
+		return literalPattern{literals: literals, variants: true}
+	}
+	email := func(literals ...string) literalPattern {
+		return literalPattern{literals: literals, fold: true}
+	}
+
+	tests := []struct {
+		name     string
+		patterns []literalPattern
+		text     string
+		want     []string // matched text, in order
+	}{
+		{name: "single name", patterns: []literalPattern{name("John Doe")}, text: "by John Doe.", want: []string{"John Doe"}},
+		{name: "case folded", patterns: []literalPattern{name("John Doe")}, text: "JOHN doe and john DOE", want: []string{"JOHN doe", "john DOE"}},
+		{name: "case sensitive", patterns: []literalPattern{exactName("John Doe")}, text: "john doe and John Doe", want: []string{"John Doe"}},
+		{name: "whitespace runs", patterns: []literalPattern{name("John Doe")}, text: "John \t\n Doe", want: []string{"John \t\n Doe"}},
+		{name: "apostrophe variants", patterns: []literalPattern{name("O'Brien")}, text: "O’Brien Oʼbrien O‘BRIEN O'Brien", want: []string{"O’Brien", "Oʼbrien", "O‘BRIEN", "O'Brien"}},
+		{name: "hyphen variants", patterns: []literalPattern{name("Jean-Claude")}, text: "Jean‐Claude Jean‑Claude Jean–Claude", want: []string{"Jean‐Claude", "Jean‑Claude", "Jean–Claude"}},
+		{name: "email exact punctuation", patterns: []literalPattern{email("j-doe@ex.com")}, text: "j‐doe@ex.com J-DOE@EX.COM", want: []string{"J-DOE@EX.COM"}},
+		{name: "email keeps whitespace", patterns: []literalPattern{email("a b@x")}, text: "a  b@x a b@x", want: []string{"a b@x"}},
+		{name: "overlapping occurrences", patterns: []literalPattern{name("aba")}, text: "ababa", want: []string{"aba"}},
+		{name: "repeated", patterns: []literalPattern{name("aa")}, text: "aaaaa", want: []string{"aa", "aa"}},
+		{name: "overlapping literals of one id", patterns: []literalPattern{email("doe@x.com", "john.doe@x.com")}, text: "john.doe@x.com", want: []string{"john.doe@x.com"}},
+		{name: "first literal wins at same start", patterns: []literalPattern{email("ab", "abc")}, text: "abc", want: []string{"ab"}},
+		{name: "overlapping literals of two ids", patterns: []literalPattern{name("John"), name("John Doe"), name("Doe")}, text: "John Doe", want: []string{"John", "John Doe", "Doe"}},
+		{name: "suffix through fail link", patterns: []literalPattern{name("she"), name("he"), name("hers")}, text: "ushers", want: []string{"she", "he", "hers"}},
+		{name: "multi-byte runes", patterns: []literalPattern{name("José Núñez")}, text: "por JOSÉ NÚÑEZ y josé núñez", want: []string{"JOSÉ NÚÑEZ", "josé núñez"}},
+		{name: "folds to different byte length", patterns: []literalPattern{name("Kelvin")}, text: "Kelvin kelvin", want: []string{"Kelvin", "kelvin"}},
+		{name: "long s", patterns: []literalPattern{name("Ross")}, text: "Roſs ROSS", want: []string{"Roſs", "ROSS"}},
+		{name: "Greek final sigma", patterns: []literalPattern{name("Σοφοκλής")}, text: "σοφοκλής ΣΟΦΟΚΛΉΣ", want: []string{"σοφοκλής", "ΣΟΦΟΚΛΉΣ"}},
+		{name: "CJK", patterns: []literalPattern{name("王小明")}, text: "修复由王小明提交", want: []string{"王小明"}},
+		{name: "no match", patterns: []literalPattern{name("Jane")}, text: "John Doe", want: nil},
+		{name: "empty text", patterns: []literalPattern{name("Jane")}, text: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := matcherHits(tt.patterns, tt.text)
+			var got []string
+			slices.SortStableFunc(hits, func(a, b literalHit) int { return a.start - b.start })
+			for _, h := range hits {
+				got = append(got, tt.text[h.start:h.end])
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findAll(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if want := regexpHits(tt.patterns, tt.text); !slices.Equal(matcherHits(tt.patterns, tt.text), want) {
+				t.Errorf("findAll(%q) differs from regexp: got %v, want %v", tt.text, matcherHits(tt.patterns, tt.text), want)
+			}
+		})
+	}
+}
+
+// TestLiteralMatcher_MatchesRegexp checks findAll against the regular
+// expressions it replaced on random text and patterns drawn from runes that
+// fold, vary or change byte length.
+func TestLiteralMatcher_MatchesRegexp(t *testing.T) {
+	alphabet := []rune("aAbBsSſkKKéÉ -'’‐\n\tσςΣ王")
+	rng := rand.New(rand.NewPCG(1, 2))
+	randString := func(n int) string {
+		var b strings.Builder
+		for range n {
+			b.WriteRune(alphabet[rng.IntN(len(alphabet))])
+		}
+		return b.String()
+	}
+
+	for i := range 2000 {
+		var patterns []literalPattern
+		for range 1 + rng.IntN(4) {
+			p := literalPattern{fold: rng.IntN(2) == 0, variants: rng.IntN(2) == 0}
+			for range 1 + rng.IntN(3) {
+				l := randString(1 + rng.IntN(4))
+				if p.variants {
+					l = strings.Join(strings.Fields(l), " ")
+				} else {
+					// like emails, see literalMatcher.add
+					l = strings.Join(strings.Fields(l), "")
+				}
+				if l != "" {
+					p.literals = append(p.literals, l)
+				}
+			}
+			if len(p.literals) > 0 {
+				patterns = append(patterns, p)
+			}
+		}
+		text := randString(rng.IntN(40))
+
+		got, want := matcherHits(patterns, text), regexpHits(patterns, text)
+		if !slices.Equal(got, want) {
+			t.Fatalf("case %d: findAll(%q) with %+v = %v, regexp finds %v", i, text, patterns, got, want)
+		}
+	}
+}
+
+// benchmarkCriteria searches a full name with its nicknames, two more
+// identities and a few emails, as a typical config does.
+var benchmarkCriteria = models.PIISearchCriteria{
+	FullName:        "Robert O'Brien",
+	FirstName:       "Robert",
+	LastName:        "O'Brien",
+	Emails:          []string{"robert@example.com", "bob.obrien@example.org"},
+	ExpandNicknames: true,
+	Identities: []models.Identity{
+		{Name: "maiden", FullName: "Roberta Jean-Smith", Emails: []string{"rjs@example.net"}},
+		{Name: "alias", FullName: "Bobby Tables", Emails: []string{"bobby@tables.example"}},
+	},
+}
+
+// benchmarkCommit returns a commit with a long message mentioning the
+// criteria a few times.
+func benchmarkCommit() *models.Commit {
+	var b strings.Builder
+	b.WriteString("Refactor the scanner pipeline and fix the pagination of commit listings\n\n")
+	for i := range 30 {
+		fmt.Fprintf(&b, "- Step %d: move the detection of personal data into its own stage, keep pages bounded.\n", i)
+	}
+	b.WriteString("\nReported-by: Bob O’Brien <robert@example.com>\nSigned-off-by: Roberta Jean-Smith <rjs@example.net>\n")
+	return &models.Commit{
+		Message:   b.String(),
+		Author:    models.Author{Name: "Robert O'Brien", Email: "robert@example.com"},
+		Committer: models.Author{Name: "GitHub", Email: "noreply@github.com"},
+	}
+}
+
+// regexpDetector searches the names and emails of criteria with one regular
+// expression per pattern, as the detector did before literalMatcher.
+type regexpDetector struct {
+	d        *Detector
+	patterns []*regexp.Regexp
+}
+
+func newRegexpDetector(criteria models.PIISearchCriteria) *regexpDetector {
+	d := NewDetector(criteria, 50)
+	rd := &regexpDetector{d: d}
+	for _, id := range criteria.AllIdentities() {
+		var names []string
+		for _, n := range []string{id.FullName, id.FirstName, id.LastName} {
+			if n != "" {
+				names = append(names, n)
+			}
+		}
+		if criteria.ExpandNicknames {
+			names = append(names, Nicknames(id.FirstName)...)
+			if words := strings.Fields(id.FullName); len(words) >= 2 {
+				for _, alias := range Nicknames(words[0]) {
+					names = append(names, strings.Join(append([]string{alias}, words[1:]...), " "))
+				}
+			}
+		}
+		for _, n := range names {
+			rd.patterns = append(rd.patterns, regexpLiteral(literalPattern{literals: []string{n}, fold: !criteria.CaseSensitive, variants: true}))
+		}
+		if len(id.Emails) > 0 {
+			rd.patterns = append(rd.patterns, regexpLiteral(literalPattern{literals: id.Emails, fold: true}))
+		}
+	}
+	return rd
+}
+
+func (rd *regexpDetector) detectInText(dst []Match, text, field string) []Match {
+	for _, re := range rd.patterns {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] || !atWordBoundaries(text, loc[0], loc[1]) {
+				continue
+			}
+			dst = append(dst, rd.d.newMatch(Match{}, text, field, loc[0], loc[1]))
+		}
+	}
+	return dst
+}
+
+func (rd *regexpDetector) detectInCommit(commit *models.Commit) []Match {
+	matches := rd.detectInText(nil, commit.Message, FieldMessage)
+	matches = rd.detectInText(matches, commit.Author.Name, FieldAuthorName)
+	return rd.detectInText(matches, commit.Committer.Name, FieldCommitterName)
+}
+
+// BenchmarkDetectInCommit compares the literal matcher with one regular
+// expression per name and email.
+func BenchmarkDetectInCommit(b *testing.B) {
+	commit := benchmarkCommit()
+
+	b.Run("literal", func(b *testing.B) {
+		d := NewDetector(benchmarkCriteria, 50)
+		b.ReportAllocs()
+		for b.Loop() {
+			d.DetectInCommit(commit)
+		}
+	})
+	b.Run("regexp", func(b *testing.B) {
+		rd := newRegexpDetector(benchmarkCriteria)
+		b.ReportAllocs()
+		for b.Loop() {
+			rd.detectInCommit(commit)
+		}
+	})
+}
+
+// TestRegexpDetectorAgrees checks that the benchmark compares like with
+// like: both detectors find the same text in the benchmark commit. Matches
+// in trailers are compared as message matches, since regexpDetector does not
+// attribute them to their trailer.
+func TestRegexpDetectorAgrees(t *testing.T) {
+	commit := benchmarkCommit()
+	texts := func(matches []Match) []string {
+		var s []string
+		for _, m := range matches {
+			field := m.Field
+			if strings.HasPrefix(field, "trailer:") {
+				field = FieldMessage
+			}
+			s = append(s, fmt.Sprintf("%s:%d-%d", field, m.Start, m.End))
+		}
+		slices.Sort(s)
+		return slices.Compact(s)
+	}
+	got := texts(NewDetector(benchmarkCriteria, 50).DetectInCommit(commit))
+	want := texts(newRegexpDetector(benchmarkCriteria).detectInCommit(commit))
+	if !slices.Equal(got, want) {
+		t.Errorf("literal matcher found %v, regexp %v", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package pii
+
+import (
+	_ "embed"
+	"slices"
+	"strings"
+)
+
+//go:embed data/nicknames.txt
+var nicknamesData string
+
+// nicknames maps each name of the embedded dictionary to its aliases: the
+// other names of every group it belongs to.
+var nicknames = parseNicknames(nicknamesData)
+
+// parseNicknames parses groups of names, one per line. Blank lines and lines
+// starting with # are skipped.
+func parseNicknames(data string) map[string][]string {
+	aliases := make(map[string][]string)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		group := strings.Fields(strings.ToLower(line))
+		for _, name := range group {
+			for _, alias := range group {
+				if alias != name && !slices.Contains(aliases[name], alias) {
+					aliases[name] = append(aliases[name], alias)
+				}
+			}
+		}
+	}
+	return aliases
+}
+
+// Nicknames returns the known aliases of a given name, e.g. Bob and Rob for
+// Robert or Robert for Bob, capitalized. It returns nil for unknown names.
+func Nicknames(name string) []string {
+	aliases := nicknames[strings.ToLower(strings.TrimSpace(name))]
+	out := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		out = append(out, strings.ToUpper(alias[:1])+alias[1:])
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,279 @@
+package pii
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// PostProcessor transforms the matches detected in a single commit. Post-processors
+// are composed into a Chain and run in order; each receives the output of the previous one.
+type PostProcessor interface {
+	Name() string
+	Process(matches []Match) []Match
+}
+
+// Chain is an ordered list of post-processors.
+type Chain []PostProcessor
+
+// Process runs every post-processor in order.
+func (c Chain) Process(matches []Match) []Match {
+	for _, p := range c {
+		if len(matches) == 0 {
+			break
+		}
+		matches = p.Process(matches)
+	}
+	return matches
+}
+
+// Names returns the names of the post-processors in the chain.
+func (c Chain) Names() []string {
+	names := make([]string, len(c))
+	for i, p := range c {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// Settings returns the names of the post-processors in the chain, followed by
+// the options they were built with, if any, to tell chains apart.
+func (c Chain) Settings() []string {
+	settings := c.Names()
+	for i, p := range c {
+		if f, ok := p.(PostProcessorFunc); ok && f.Options != "" {
+			settings[i] += ":" + f.Options
+		}
+	}
+	return settings
+}
+
+// PostProcessorFunc adapts a function into a PostProcessor.
+type PostProcessorFunc struct {
+	ProcessorName string
+	Fn            func([]Match) []Match
+	// Options describes the options Fn was built with, if any.
+	Options string
+}
+
+// Name returns the processor name.
+func (f PostProcessorFunc) Name() string { return f.ProcessorName }
+
+// Process calls the wrapped function.
+func (f PostProcessorFunc) Process(matches []Match) []Match { return f.Fn(matches) }
+
+// Built-in post-processor names.
+const (
+	ProcessorDedupe        = "dedupe"
+	ProcessorMergeOverlaps = "merge_overlaps"
+	ProcessorAllowlist     = "allowlist"
+	ProcessorRedactContext = "redact_context"
+	ProcessorScore         = "score"
+)
+
+// DefaultPostProcessors is the chain used when none is configured.
+var DefaultPostProcessors = []string{ProcessorDedupe}
+
+// PostProcessorOptions configures the built-in post-processors.
+type PostProcessorOptions struct {
+	// Allowlist contains strings that are never reported (case-insensitive).
+	Allowlist []string
+	// MinConfidence drops matches scored below this value by the score processor.
+	MinConfidence float64
+}
+
+// NewChain builds a chain of built-in post-processors by name.
+func NewChain(names []string, opts PostProcessorOptions) (Chain, error) {
+	chain := make(Chain, 0, len(names))
+	for _, name := range names {
+		p, err := NewPostProcessor(name, opts)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, p)
+	}
+	return chain, nil
+}
+
+// NewPostProcessor creates a built-in post-processor by name.
+func NewPostProcessor(name string, opts PostProcessorOptions) (PostProcessor, error) {
+	switch name {
+	case ProcessorDedupe:
+		return PostProcessorFunc{ProcessorName: name, Fn: Dedupe}, nil
+	case ProcessorMergeOverlaps:
+		return PostProcessorFunc{ProcessorName: name, Fn: MergeOverlaps}, nil
+	case ProcessorAllowlist:
+		allowed := make([]string, 0, len(opts.Allowlist))
+		for _, a := range opts.Allowlist {
+			allowed = append(allowed, strings.ToLower(strings.TrimSpace(a)))
+		}
+		slices.Sort(allowed)
+		return PostProcessorFunc{ProcessorName: name, Fn: Allowlist(opts.Allowlist), Options: strings.Join(slices.Compact(allowed), ",")}, nil
+	case ProcessorRedactContext:
+		return PostProcessorFunc{ProcessorName: name, Fn: RedactContext}, nil
+	case ProcessorScore:
+		return PostProcessorFunc{ProcessorName: name, Fn: Score(opts.MinConfidence), Options: strconv.FormatFloat(opts.MinConfidence, 'g', -1, 64)}, nil
+	default:
+		return nil, fmt.Errorf("unknown post-processor %q", name)
+	}
+}
+
+// Dedupe removes matches with the same type, field and position.
+func Dedupe(matches []Match) []Match {
+	type key struct {
+		piiType    models.PIIType
+		field      string
+		start, end int
+	}
+	seen := make(map[key]bool, len(matches))
+	kept := matches[:0]
+	for _, m := range matches {
+		k := key{m.Type, m.Field, m.Start, m.End}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// specificity ranks the PII types of names and emails; more specific types
+// win overlap merges. Other types are never merged.
+var specificity = map[models.PIIType]int{
+	models.PIITypeEmail:     4,
+	models.PIITypeFullName:  3,
+	models.PIITypeLastName:  2,
+	models.PIITypeFirstName: 1,
+}
+
+// MergeOverlaps collapses the name and email matches whose spans overlap
+// within the same field into one match per leak: the most specific type,
+// then the longest span, with the highest confidence of the group. "John
+// Doe" matched as full, first and last name is one leak. Matches of other
+// types are kept as they are, and the order of the matches is kept.
+func MergeOverlaps(matches []Match) []Match {
+	matches = slices.Clone(matches)
+	order := make([]int, 0, len(matches))
+	for i, m := range matches {
+		if specificity[m.Type] > 0 {
+			order = append(order, i)
+		}
+	}
+	if len(order) < 2 {
+		return matches
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := matches[order[i]], matches[order[j]]
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Start < b.Start
+	})
+
+	// Each group of overlapping matches is replaced by its best match, in
+	// the position of that match
+	drop := make(map[int]bool)
+	best, groupEnd := -1, -1
+	for _, i := range order {
+		m := matches[i]
+		if best >= 0 && matches[best].Field == m.Field && m.Start < groupEnd {
+			confidence := max(matches[best].Confidence, m.Confidence)
+			if better(m, matches[best]) {
+				drop[best] = true
+				best = i
+			} else {
+				drop[i] = true
+			}
+			matches[best].Confidence = confidence
+			groupEnd = max(groupEnd, m.End)
+			continue
+		}
+		best, groupEnd = i, m.End
+	}
+
+	kept := make([]Match, 0, len(matches)-len(drop))
+	for i, m := range matches {
+		if !drop[i] {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// better reports whether a is a more specific match than b.
+func better(a, b Match) bool {
+	if specificity[a.Type] != specificity[b.Type] {
+		return specificity[a.Type] > specificity[b.Type]
+	}
+	return a.End-a.Start > b.End-b.Start
+}
+
+// Allowlist returns a post-processor dropping matches whose text is allowed.
+func Allowlist(allowed []string) func([]Match) []Match {
+	set := make(map[string]bool, len(allowed))
+	for _, a := range allowed {
+		set[strings.ToLower(strings.TrimSpace(a))] = true
+	}
+	return func(matches []Match) []Match {
+		if len(set) == 0 {
+			return matches
+		}
+		kept := matches[:0]
+		for _, m := range matches {
+			if !set[strings.ToLower(m.Text)] {
+				kept = append(kept, m)
+			}
+		}
+		return kept
+	}
+}
+
+// RedactContext masks the matched text inside each match's context. The
+// matched text itself and the commit are left as is; report.Redact masks a
+// whole result.
+func RedactContext(matches []Match) []Match {
+	for i := range matches {
+		matches[i].Context = strings.ReplaceAll(matches[i].Context, matches[i].Text, Mask(matches[i].Text))
+	}
+	return matches
+}
+
+// Mask keeps the first letter of each word and replaces the rest with '*'.
+func Mask(s string) string {
+	var b strings.Builder
+	start := true
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == '-' || r == '\'':
+			b.WriteRune(r)
+			start = true
+		case start:
+			b.WriteRune(r)
+			start = false
+		default:
+			b.WriteRune('*')
+		}
+	}
+	return b.String()
+}
+
+// Score returns a post-processor assigning each match its own confidence and
+// dropping matches scored below min.
+func Score(minConfidence float64) func([]Match) []Match {
+	return func(matches []Match) []Match {
+		kept := matches[:0]
+		for _, m := range matches {
+			m.Confidence = CalculateConfidence([]Match{m})
+			if m.Confidence < minConfidence {
+				continue
+			}
+			kept = append(kept, m)
+		}
+		return kept
+	}
+}
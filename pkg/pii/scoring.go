@@ -0,0 +1,246 @@
+package pii
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// typeWeights are the base scores of built-in PII types. Custom rules use
+// their configured weight instead, when set.
+var typeWeights = map[models.PIIType]float64{
+	models.PIITypeEmail:     0.85,
+	models.PIITypeFullName:  0.8,
+	models.PIITypePhone:     0.7,
+	models.PIITypeLastName:  0.6,
+	models.PIITypeCustom:    0.6,
+	models.PIITypeFirstName: 0.45,
+
+	models.PIITypeExposedEmailConfig: 0.6,
+	models.PIITypeGravatar:           0.8,
+}
+
+// defaultTypeWeight scores types missing from typeWeights.
+const defaultTypeWeight = 0.5
+
+// fieldWeights scale a match's score by where it was found. Identity fields
+// leak the name by construction; free-form page text is the noisiest.
+var fieldWeights = map[string]float64{
+	"author_name":    1.2,
+	"committer_name": 1.2,
+	"signer_uid":     1.2,
+	"message":        1.0,
+	"page_title":     1.0,
+	"page_meta":      1.0,
+	"page_content":   0.9,
+	"event_title":    1.0,
+	"event_body":     0.9,
+	"pr_title":       1.0,
+	"pr_body":        0.9,
+	"release_name":   1.0,
+	"release_body":   1.0,
+	"tag_name":       1.0,
+	"tag_message":    1.0,
+	"tagger_name":    1.2,
+	"branch_name":    0.9,
+}
+
+// nicknameWeight scales matches of a nickname instead of the first name
+// searched for, which may well belong to someone else.
+const nicknameWeight = 0.8
+
+// trailerFieldWeight scales matches in commit trailers. Trailers such as
+// Co-authored-by and Signed-off-by attach a name and email to the commit on
+// purpose, making them the most reliable leaks.
+const trailerFieldWeight = 1.25
+
+// maxCommonPenalty is the score reduction for the most common name in a
+// frequency list; rarer names are penalized proportionally less.
+const maxCommonPenalty = 0.4
+
+var (
+	//go:embed data/first_names.txt
+	firstNamesData string
+	//go:embed data/last_names.txt
+	lastNamesData string
+	//go:embed data/english_words.txt
+	englishWordsData string
+
+	commonFirstNames = parseFrequencyList(firstNamesData)
+	commonLastNames  = parseFrequencyList(lastNamesData)
+	englishWords     = parseFrequencyList(englishWordsData)
+)
+
+// parseFrequencyList maps each name of a most-frequent-first list to its
+// rank. Blank lines and lines starting with '#' are ignored.
+func parseFrequencyList(data string) map[string]int {
+	ranks := make(map[string]int)
+	sc := bufio.NewScanner(strings.NewReader(data))
+	for sc.Scan() {
+		name := strings.TrimSpace(sc.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		if _, ok := ranks[name]; !ok {
+			ranks[name] = len(ranks)
+		}
+	}
+	return ranks
+}
+
+// commonPenalty returns how much less likely name is to identify a person
+// because it is common, between 0 and maxCommonPenalty.
+func commonPenalty(ranks map[string]int, name string) float64 {
+	rank, ok := ranks[strings.ToLower(name)]
+	if !ok {
+		return 0
+	}
+	return maxCommonPenalty * (1 - float64(rank)/float64(len(ranks)))
+}
+
+// commonWordPenalty is the score reduction for a match flagged CommonWord.
+const commonWordPenalty = 0.5
+
+// isIdentityField reports whether field holds nothing but a person's
+// identity, so a common word found there is still their name: the author and
+// committer names and "-by" trailers such as Co-authored-by.
+func isIdentityField(field string) bool {
+	switch {
+	case field == "author_name" || field == "committer_name" || field == "tagger_name":
+		return true
+	case strings.HasPrefix(field, models.TrailerFieldPrefix):
+		return strings.HasSuffix(field, "-by")
+	}
+	return false
+}
+
+// IsCommonWord reports whether token is a single word found in the bundled
+// dictionaries of common English words, given names or surnames.
+func IsCommonWord(token string) bool {
+	if strings.ContainsFunc(token, unicode.IsSpace) {
+		return false
+	}
+	token = strings.ToLower(token)
+	_, english := englishWords[token]
+	_, first := commonFirstNames[token]
+	_, last := commonLastNames[token]
+	return english || first || last
+}
+
+// CommonWordMode selects how matches flagged CommonWord are treated.
+type CommonWordMode string
+
+const (
+	// CommonWordsDowngrade lowers their confidence (the default).
+	CommonWordsDowngrade CommonWordMode = "downgrade"
+	// CommonWordsSuppress drops them.
+	CommonWordsSuppress CommonWordMode = "suppress"
+	// CommonWordsOff treats them like any other match.
+	CommonWordsOff CommonWordMode = "off"
+)
+
+// ParseCommonWordMode validates a common-word mode; empty selects downgrade.
+func ParseCommonWordMode(s string) (CommonWordMode, error) {
+	switch mode := CommonWordMode(strings.ToLower(s)); mode {
+	case "":
+		return CommonWordsDowngrade, nil
+	case CommonWordsDowngrade, CommonWordsSuppress, CommonWordsOff:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid common words mode %q: use downgrade, suppress or off", s)
+}
+
+// ApplyCommonWordMode applies mode to matches, returning the kept matches and
+// how many were suppressed.
+func ApplyCommonWordMode(mode CommonWordMode, matches []Match) ([]Match, int) {
+	switch mode {
+	case CommonWordsSuppress:
+		kept := matches[:0]
+		for _, m := range matches {
+			if !m.CommonWord {
+				kept = append(kept, m)
+			}
+		}
+		return kept, len(matches) - len(kept)
+	case CommonWordsOff:
+		for i := range matches {
+			matches[i].CommonWord = false
+		}
+	}
+	return matches, 0
+}
+
+// namePenalty returns the common-name penalty of a match. Full names are only
+// penalized when both the first and the last word are common, and by half.
+func namePenalty(m Match) float64 {
+	switch m.Type {
+	case models.PIITypeFirstName:
+		return commonPenalty(commonFirstNames, m.Text)
+	case models.PIITypeLastName:
+		return commonPenalty(commonLastNames, m.Text)
+	case models.PIITypeFullName:
+		words := strings.Fields(m.Text)
+		if len(words) < 2 {
+			return 0
+		}
+		return min(commonPenalty(commonFirstNames, words[0]), commonPenalty(commonLastNames, words[len(words)-1])) / 2
+	}
+	return 0
+}
+
+// ScoreMatch scores a single match between 0 and 1: the base weight of its
+// type (or its rule), scaled by the weight of its field and reduced for
+// common names, common words, nicknames and bot commits.
+func ScoreMatch(m Match) float64 {
+	base := m.Weight
+	if base <= 0 {
+		var ok bool
+		if base, ok = typeWeights[m.Type]; !ok {
+			base = defaultTypeWeight
+		}
+	}
+	fieldWeight, ok := fieldWeights[m.Field]
+	switch {
+	case ok:
+	case strings.HasPrefix(m.Field, models.TrailerFieldPrefix):
+		fieldWeight = trailerFieldWeight
+	default:
+		fieldWeight = 1.0
+	}
+
+	penalty := namePenalty(m)
+	if m.CommonWord {
+		penalty = max(penalty, commonWordPenalty)
+	}
+
+	score := base * fieldWeight * (1 - penalty)
+	if m.Alias != "" {
+		score *= nicknameWeight
+	}
+	if m.BotCommit {
+		score *= botCommitWeight
+	}
+	return min(max(score, 0), 1)
+}
+
+// Severity thresholds on confidence.
+const (
+	HighSeverityThreshold   = 0.75
+	MediumSeverityThreshold = 0.5
+)
+
+// SeverityFor maps a confidence score to a severity.
+func SeverityFor(confidence float64) models.Severity {
+	switch {
+	case confidence >= HighSeverityThreshold:
+		return models.SeverityHigh
+	case confidence >= MediumSeverityThreshold:
+		return models.SeverityMedium
+	default:
+		return models.SeverityLow
+	}
+}
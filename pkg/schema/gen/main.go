@@ -0,0 +1,27 @@
+// Command gen writes the schema of scan results to the file named by its
+// argument. Run it with go generate in pkg/schema after changing the result
+// models.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/pkg/schema"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: gen <schema file>")
+		os.Exit(2)
+	}
+	data, err := schema.Generate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate schema: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(os.Args[1], append(data, '\n'), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write schema: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// draft is the JSON Schema dialect of the schema.
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// Generate returns the schema of models.ScanResult as indented JSON. Struct
+// types are defined once in $defs and referenced; fields the encoder always
+// writes are required.
+func Generate() ([]byte, error) {
+	g := &generator{defs: make(map[string]any)}
+	root := g.object(reflect.TypeFor[models.ScanResult]())
+	root["properties"].(map[string]any)["schema_version"] = map[string]any{"type": "string", "const": Version}
+	root["$schema"] = draft
+	root["$id"] = ID
+	root["title"] = "GoGitSomePrivacy scan result"
+	root["description"] = "The result of a scan, as written by --output json. Version " + Version + "."
+	root["$defs"] = g.defs
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// generator builds the schemas of Go types.
+type generator struct {
+	defs map[string]any
+}
+
+// schema returns the schema of the JSON encoding of t. Nullable adds null
+// to the types allowed, for nil pointers, slices and maps.
+func (g *generator) schema(t reflect.Type, nullable bool) map[string]any {
+	var s map[string]any
+	switch {
+	case t == timeType:
+		s = map[string]any{"type": "string", "format": "date-time"}
+	case t == durationType:
+		s = map[string]any{"type": "integer"}
+	default:
+		switch t.Kind() {
+		case reflect.Pointer:
+			s = g.schema(t.Elem(), false)
+		case reflect.Struct:
+			s = g.ref(t)
+		case reflect.Slice, reflect.Array:
+			if t.Elem().Kind() == reflect.Uint8 {
+				s = map[string]any{"type": "string", "contentEncoding": "base64"}
+			} else {
+				s = map[string]any{"type": "array", "items": g.schema(t.Elem(), false)}
+			}
+		case reflect.Map:
+			s = map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem(), false)}
+		case reflect.String:
+			s = map[string]any{"type": "string"}
+		case reflect.Bool:
+			s = map[string]any{"type": "boolean"}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s = map[string]any{"type": "integer"}
+		case reflect.Float32, reflect.Float64:
+			s = map[string]any{"type": "number"}
+		default:
+			s = map[string]any{}
+		}
+	}
+
+	nullable = nullable && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map)
+	if !nullable {
+		return s
+	}
+	if typ, ok := s["type"].(string); ok {
+		s["type"] = []string{typ, "null"}
+		return s
+	}
+	return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+}
+
+// ref defines a struct type in $defs, once, and returns a reference to it.
+func (g *generator) ref(t reflect.Type) map[string]any {
+	if _, ok := g.defs[t.Name()]; !ok {
+		g.defs[t.Name()] = nil // defined while its fields are generated
+		g.defs[t.Name()] = g.object(t)
+	}
+	return map[string]any{"$ref": "#/$defs/" + t.Name()}
+}
+
+// object returns the schema of a struct type.
+func (g *generator) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+	g.fields(t, properties, &required)
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// fields adds the properties of the fields of a struct type, including those
+// of embedded structs, which the encoder promotes.
+func (g *generator) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			g.fields(f.Type, properties, required)
+			continue
+		}
+		if !f.IsExported() || (name == "-" && opts == "") {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		omitempty := strings.Contains(","+opts+",", ",omitempty,")
+		properties[name] = g.schema(f.Type, !omitempty)
+		if !omitempty {
+			*required = append(*required, name)
+		}
+	}
+}
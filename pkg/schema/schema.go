@@ -0,0 +1,28 @@
+// Package schema publishes the JSON Schema of scan results, the contract of
+// the json output format, and validates results against it.
+//
+// The schema is generated from the result models by Generate and embedded as
+// JSON. Results carry the version of the schema they follow in their
+// schema_version field. Adding optional fields keeps the version; removing,
+// renaming or retyping a field, or making it required, bumps it.
+package schema
+
+//go:generate go run ./gen scan-result.v1.schema.json
+
+import (
+	_ "embed"
+
+	"github.com/h4n0sh1/GoGitSomePrivacy/internal/models"
+)
+
+// Version is the version of the schema, recorded in the schema_version field
+// of results.
+const Version = models.SchemaVersion
+
+// ID identifies the schema in its $id.
+const ID = "https://github.com/h4n0sh1/GoGitSomePrivacy/schema/scan-result.v" + Version + ".schema.json"
+
+// JSON is the published schema, as generated by Generate.
+//
+//go:embed scan-result.v1.schema.json
+var JSON []byte
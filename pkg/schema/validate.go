@@ -0,0 +1,177 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Violation is a part of a document that does not conform to the schema.
+type Violation struct {
+	// Path is the JSON pointer of the offending value, "" for the document.
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// Validate checks a JSON document against the published schema and returns
+// its violations, ordered by path. It supports the keywords Generate uses:
+// $ref, anyOf, type, const, format date-time, properties, required, items and
+// additionalProperties.
+func Validate(data []byte) ([]Violation, error) {
+	var root map[string]any
+	if err := json.Unmarshal(JSON, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("failed to parse document: more than one JSON value")
+	}
+
+	defs, _ := root["$defs"].(map[string]any)
+	v := &validator{defs: defs}
+	v.check(root, doc, "")
+	sort.SliceStable(v.violations, func(i, j int) bool { return v.violations[i].Path < v.violations[j].Path })
+	return v.violations, nil
+}
+
+// validator collects the violations of a document.
+type validator struct {
+	defs       map[string]any
+	violations []Violation
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// check checks value, found at path, against schema s.
+func (v *validator) check(s map[string]any, value any, path string) {
+	if ref, ok := s["$ref"].(string); ok {
+		def, ok := v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		if !ok {
+			v.fail(path, "unresolved reference %s", ref)
+			return
+		}
+		v.check(def, value, path)
+		return
+	}
+
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		var first []Violation
+		for i, alt := range anyOf {
+			sub := &validator{defs: v.defs}
+			sub.check(alt.(map[string]any), value, path)
+			if len(sub.violations) == 0 {
+				return
+			}
+			if i == 0 {
+				first = sub.violations
+			}
+		}
+		v.violations = append(v.violations, first...)
+		return
+	}
+
+	if types := schemaTypes(s["type"]); len(types) > 0 && !slices.Contains(types, jsonType(value)) {
+		if !(jsonType(value) == "integer" && slices.Contains(types, "number")) {
+			v.fail(path, "expected %s, got %s", strings.Join(types, " or "), jsonType(value))
+			return
+		}
+	}
+	if want, ok := s["const"]; ok && fmt.Sprint(want) != fmt.Sprint(value) {
+		v.fail(path, "expected %v, got %v", want, value)
+	}
+
+	switch value := value.(type) {
+	case string:
+		if s["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				v.fail(path, "invalid date-time %q", value)
+			}
+		}
+
+	case []any:
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, item := range value {
+				v.check(items, item, path+"/"+strconv.Itoa(i))
+			}
+		}
+
+	case map[string]any:
+		if required, ok := s["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := value[name.(string)]; !ok {
+					v.fail(path, "missing required property %q", name)
+				}
+			}
+		}
+		properties, _ := s["properties"].(map[string]any)
+		additional, _ := s["additionalProperties"].(map[string]any)
+		for name, field := range value {
+			fieldPath := path + "/" + escapePointer(name)
+			if prop, ok := properties[name].(map[string]any); ok {
+				v.check(prop, field, fieldPath)
+			} else if additional != nil {
+				v.check(additional, field, fieldPath)
+			}
+		}
+	}
+}
+
+// schemaTypes returns the types allowed by a type keyword.
+func schemaTypes(t any) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, s := range t {
+			types = append(types, s.(string))
+		}
+		return types
+	}
+	return nil
+}
+
+// jsonType returns the JSON Schema type of a decoded value.
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
+
+// escapePointer escapes a property name as a JSON pointer token.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}